@@ -0,0 +1,17 @@
+/*
+The flatbytes package implements the IPLD Flexible Byte Layout (FBL) ADL:
+a bytes node whose contents are chunked into many blocks, linked together in a tree,
+much like the files of UnixFS.
+The FBL spec can be found at https://ipld.io/specs/advanced-data-layouts/fbl/spec/ .
+
+Use Write to chunk the contents of an io.Reader into a new FBL;
+it returns the link to the root block.
+
+To read an FBL, load its root block, and use Reify to get a Node which behaves like bytes.
+That Node is a datamodel.LargeBytesNode: its AsLargeBytes method returns an io.ReadSeeker
+which loads only the blocks it needs to, as it reads.
+
+Registering Reify in a LinkSystem's KnownReifiers lets selectors use `ExploreInterpretAs{as: "flatbytes"}`;
+matchers with a subset range beneath that read only the blocks covering the range.
+*/
+package flatbytes
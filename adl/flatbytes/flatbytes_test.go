@@ -0,0 +1,182 @@
+package flatbytes_test
+
+import (
+	"bytes"
+	"io"
+	"math/rand"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/adl/flatbytes"
+	_ "github.com/ipld/go-ipld-prime/codec/dagcbor"
+	_ "github.com/ipld/go-ipld-prime/codec/raw"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+	"github.com/ipld/go-ipld-prime/traversal"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+var (
+	cborLinkProto = cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x71, MhType: 0x12, MhLength: 32}}
+	rawLinkProto  = cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x55, MhType: 0x12, MhLength: 32}}
+)
+
+func randomBytes(size int) []byte {
+	buf := make([]byte, size)
+	rand.New(rand.NewSource(int64(size))).Read(buf)
+	return buf
+}
+
+// countingLinkSystem returns a LinkSystem reading from store, which counts the blocks it loads.
+func countingLinkSystem(store *memstore.Store, loads *int) *linking.LinkSystem {
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		*loads++
+		return store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	return &lsys
+}
+
+// writeAndReify writes data into a fresh store, then loads and reifies the root from it.
+func writeAndReify(t *testing.T, data []byte, opts ...flatbytes.Option) (datamodel.Node, *memstore.Store, datamodel.Link) {
+	t.Helper()
+	store := &memstore.Store{}
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetWriteStorage(store)
+	lnk, err := flatbytes.Write(linking.LinkContext{}, &lsys, cborLinkProto, bytes.NewReader(data), opts...)
+	qt.Assert(t, err, qt.IsNil)
+
+	lsys.SetReadStorage(store)
+	root, err := lsys.Load(linking.LinkContext{}, lnk, basicnode.Prototype.Any)
+	qt.Assert(t, err, qt.IsNil)
+	n, err := flatbytes.Reify(linking.LinkContext{}, root, &lsys)
+	qt.Assert(t, err, qt.IsNil)
+	return n, store, lnk
+}
+
+func TestRoundtrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		size int
+		opts []flatbytes.Option
+	}{
+		{"empty", 0, nil},
+		{"single leaf", 1000, nil},
+		{"exact chunks", 4096, []flatbytes.Option{flatbytes.ChunkSize(1024)}},
+		{"one layer", 10000, []flatbytes.Option{flatbytes.ChunkSize(1024)}},
+		{"many layers", 100000, []flatbytes.Option{flatbytes.ChunkSize(100), flatbytes.MaxLinksPerBlock(3)}},
+		{"raw leaves", 100000, []flatbytes.Option{flatbytes.ChunkSize(1000), flatbytes.MaxLinksPerBlock(8), flatbytes.LeafLinkPrototype(rawLinkProto)}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := randomBytes(tc.size)
+			n, _, _ := writeAndReify(t, data, tc.opts...)
+			qt.Check(t, n.Kind(), qt.Equals, datamodel.Kind_Bytes)
+
+			got, err := n.AsBytes()
+			qt.Assert(t, err, qt.IsNil)
+			qt.Check(t, bytes.Equal(got, data), qt.IsTrue)
+
+			rs, err := n.(datamodel.LargeBytesNode).AsLargeBytes()
+			qt.Assert(t, err, qt.IsNil)
+			got, err = io.ReadAll(rs)
+			qt.Assert(t, err, qt.IsNil)
+			qt.Check(t, bytes.Equal(got, data), qt.IsTrue)
+
+			// Copying into basicnode works, by streaming.
+			nb := basicnode.Prototype.Bytes.NewBuilder()
+			qt.Assert(t, nb.AssignNode(n), qt.IsNil)
+		})
+	}
+}
+
+func TestSeek(t *testing.T) {
+	data := randomBytes(50000)
+	n, store, lnk := writeAndReify(t, data, flatbytes.ChunkSize(100), flatbytes.MaxLinksPerBlock(4))
+
+	var loads int
+	lsys := countingLinkSystem(store, &loads)
+	root, err := lsys.Load(linking.LinkContext{}, lnk, flatbytes.Prototype.FlexibleByteLayout.Representation())
+	qt.Assert(t, err, qt.IsNil)
+	n, err = flatbytes.Reify(linking.LinkContext{}, root, lsys)
+	qt.Assert(t, err, qt.IsNil)
+
+	rs, err := n.(datamodel.LargeBytesNode).AsLargeBytes()
+	qt.Assert(t, err, qt.IsNil)
+	end, err := rs.Seek(0, io.SeekEnd)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, end, qt.Equals, int64(len(data)))
+
+	loads = 0
+	for _, off := range []int64{0, 12345, 49990, 250, 30000} {
+		_, err := rs.Seek(off, io.SeekStart)
+		qt.Assert(t, err, qt.IsNil)
+		buf := make([]byte, 10)
+		_, err = io.ReadFull(rs, buf)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, buf, qt.DeepEquals, data[off:off+10])
+	}
+	// Each read spans one leaf: with 500 leaves, a fanout of 4 means 5 layers of branches above them,
+	// so reaching the five leaves can't take more than 5 loads of branches each, plus the leaves themselves.
+	qt.Check(t, loads <= 5*5+5, qt.IsTrue, qt.Commentf("%d loads", loads))
+
+	_, err = rs.Seek(-1, io.SeekStart)
+	qt.Check(t, err, qt.IsNotNil)
+	_, err = rs.Seek(10, io.SeekEnd)
+	qt.Assert(t, err, qt.IsNil)
+	_, err = rs.Read(make([]byte, 1))
+	qt.Check(t, err, qt.Equals, io.EOF)
+}
+
+func TestSelectorSubset(t *testing.T) {
+	data := randomBytes(100000)
+	_, store, lnk := writeAndReify(t, data, flatbytes.ChunkSize(1000), flatbytes.MaxLinksPerBlock(10))
+
+	var loads int
+	lsys := countingLinkSystem(store, &loads)
+	lsys.KnownReifiers = map[string]linking.NodeReifier{"flatbytes": flatbytes.Reify}
+
+	sel, err := selectorparse.ParseAndCompileJSONSelector(`{"~":{"as":"flatbytes",">":{".":{"subset":{"[":54321,"]":55321}}}}}`)
+	qt.Assert(t, err, qt.IsNil)
+	root, err := lsys.Load(linking.LinkContext{}, lnk, basicnode.Prototype.Any)
+	qt.Assert(t, err, qt.IsNil)
+
+	loads = 0
+	var got []byte
+	err = traversal.Progress{Cfg: &traversal.Config{LinkSystem: *lsys}}.WalkMatching(root, sel, func(prog traversal.Progress, n datamodel.Node) error {
+		rs, err := n.(datamodel.LargeBytesNode).AsLargeBytes()
+		if err != nil {
+			return err
+		}
+		got, err = io.ReadAll(rs)
+		return err
+	})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, got, qt.DeepEquals, data[54321:55321])
+	// The range covers two of the 100 leaves, which are both under the same one of the root's 10 branches.
+	qt.Check(t, loads, qt.Equals, 3)
+}
+
+func TestInvalidLengths(t *testing.T) {
+	store := &memstore.Store{}
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(store)
+	lsys.SetWriteStorage(store)
+	leaf, err := lsys.Store(linking.LinkContext{}, rawLinkProto, basicnode.NewBytes([]byte("hello")))
+	qt.Assert(t, err, qt.IsNil)
+
+	// A root which claims its leaf is longer than it is.
+	root := flatbytes.FlexibleByteLayout{NestedByteList: &[]flatbytes.NestedByteListLayer{
+		{Bytes: leaf, Length: 5},
+		{Bytes: leaf, Length: 6},
+	}}
+	n, err := flatbytes.Reify(linking.LinkContext{}, bindnode.Wrap(&root, flatbytes.TypeSystem.TypeByName("FlexibleByteLayout")).Representation(), &lsys)
+	qt.Assert(t, err, qt.IsNil)
+	_, err = n.AsBytes()
+	qt.Check(t, err, qt.ErrorMatches, ".*block holds 5 bytes, but its parent claims 6")
+}
@@ -0,0 +1,245 @@
+package flatbytes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ipld/go-ipld-prime/adl"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/node/mixins"
+)
+
+// -- Node -->
+
+var _ adl.ADL = (*Node)(nil)
+var _ datamodel.LargeBytesNode = (*Node)(nil)
+
+// Node is the synthesized view of an FBL: a bytes node, whose contents may be spread across many blocks.
+//
+// The contents are best read using AsLargeBytes, which loads only the blocks covering the ranges that are read.
+// AsBytes loads every block and returns the concatenation of all of them.
+//
+// Branch blocks are cached once loaded, so that seeking around within the same region doesn't repeat loads;
+// leaf blocks aren't, since they're where the bulk of the data is.
+type Node struct {
+	root   *FlexibleByteLayout
+	length int64
+	ctx    context.Context
+	lsys   *linking.LinkSystem
+
+	mu       sync.Mutex
+	branches map[datamodel.Link]*FlexibleByteLayout
+}
+
+// checkLayout checks that a block holds exactly as many bytes as its parent claims it does,
+// returning the error for the parent to report if it doesn't.
+func checkLayout(fbl *FlexibleByteLayout, expected int64) error {
+	length, err := layoutLength(fbl)
+	if err != nil {
+		return err
+	}
+	if length != expected {
+		return fmt.Errorf("block holds %d bytes, but its parent claims %d", length, expected)
+	}
+	return nil
+}
+
+// layoutLength returns the number of bytes a block holds, or claims its children hold.
+func layoutLength(fbl *FlexibleByteLayout) (int64, error) {
+	if fbl.Bytes != nil {
+		return int64(len(*fbl.Bytes)), nil
+	}
+	var length int64
+	for _, layer := range *fbl.NestedByteList {
+		if layer.Length < 0 {
+			return 0, fmt.Errorf("negative length %d", layer.Length)
+		}
+		length += layer.Length
+		if length < 0 {
+			return 0, fmt.Errorf("total length overflows")
+		}
+	}
+	return length, nil
+}
+
+func (n *Node) load(lnk datamodel.Link, expected int64) (*FlexibleByteLayout, error) {
+	n.mu.Lock()
+	fbl, ok := n.branches[lnk]
+	n.mu.Unlock()
+	if ok {
+		return fbl, nil
+	}
+	if n.lsys == nil {
+		return nil, fmt.Errorf("flatbytes: cannot load block %s: no LinkSystem configured", lnk)
+	}
+	nb := Prototype.FlexibleByteLayout.Representation().NewBuilder()
+	if err := n.lsys.Fill(linking.LinkContext{Ctx: n.ctx}, lnk, nb); err != nil {
+		return nil, fmt.Errorf("flatbytes: cannot load block %s: %w", lnk, err)
+	}
+	fbl = bindnode.Unwrap(nb.Build()).(*FlexibleByteLayout)
+	if err := checkLayout(fbl, expected); err != nil {
+		return nil, fmt.Errorf("flatbytes: invalid block %s: %w", lnk, err)
+	}
+	if fbl.NestedByteList != nil {
+		n.mu.Lock()
+		n.branches[lnk] = fbl
+		n.mu.Unlock()
+	}
+	return fbl, nil
+}
+
+// leafAt finds the leaf containing the byte at offset, returning its contents and the offset at which they begin.
+func (n *Node) leafAt(offset int64) ([]byte, int64, error) {
+	fbl, start := n.root, int64(0)
+	for fbl.Bytes == nil {
+		var next *FlexibleByteLayout
+		for _, layer := range *fbl.NestedByteList {
+			if offset >= start+layer.Length {
+				start += layer.Length
+				continue
+			}
+			var err error
+			next, err = n.load(layer.Bytes, layer.Length)
+			if err != nil {
+				return nil, 0, err
+			}
+			break
+		}
+		if next == nil {
+			// Only possible if the offset is past the end, which callers check for.
+			return nil, 0, io.EOF
+		}
+		fbl = next
+	}
+	return *fbl.Bytes, start, nil
+}
+
+func (*Node) Kind() datamodel.Kind {
+	return datamodel.Kind_Bytes
+}
+func (*Node) LookupByString(string) (datamodel.Node, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.LookupByString("")
+}
+func (*Node) LookupByNode(datamodel.Node) (datamodel.Node, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.LookupByNode(nil)
+}
+func (*Node) LookupByIndex(idx int64) (datamodel.Node, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.LookupByIndex(idx)
+}
+func (*Node) LookupBySegment(seg datamodel.PathSegment) (datamodel.Node, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.LookupBySegment(seg)
+}
+func (*Node) MapIterator() datamodel.MapIterator {
+	return nil
+}
+func (*Node) ListIterator() datamodel.ListIterator {
+	return nil
+}
+func (*Node) Length() int64 {
+	return -1
+}
+func (*Node) IsAbsent() bool {
+	return false
+}
+func (*Node) IsNull() bool {
+	return false
+}
+func (*Node) AsBool() (bool, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.AsBool()
+}
+func (*Node) AsInt() (int64, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.AsInt()
+}
+func (*Node) AsFloat() (float64, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.AsFloat()
+}
+func (*Node) AsString() (string, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.AsString()
+}
+
+// AsBytes loads every block of the FBL, and returns all of its contents.
+// Prefer AsLargeBytes for anything which might be large.
+func (n *Node) AsBytes() ([]byte, error) {
+	buf := make([]byte, n.length)
+	if _, err := io.ReadFull(n.newReader(), buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+func (*Node) AsLink() (datamodel.Link, error) {
+	return mixins.Bytes{TypeName: "flatbytes.Node"}.AsLink()
+}
+
+// AsLargeBytes returns a new io.ReadSeeker over the contents of the FBL.
+// Blocks are loaded as reads reach them; seeking doesn't load anything,
+// and seeking relative to the end is cheap, since the total length is known from the root block.
+func (n *Node) AsLargeBytes() (io.ReadSeeker, error) {
+	return n.newReader(), nil
+}
+
+// Prototype returns basicnode's bytes prototype:
+// the FBL's contents can be copied into a node built with it, but won't be chunked.
+// Use Write to create a new FBL.
+func (*Node) Prototype() datamodel.NodePrototype {
+	return basicnode.Prototype.Bytes
+}
+
+// Substrate returns the root block of the FBL's substrate, as a typed node.
+// The other blocks are reachable by links from it, when it's a NestedByteList.
+func (n *Node) Substrate() datamodel.Node {
+	return bindnode.Wrap(n.root, TypeSystem.TypeByName("FlexibleByteLayout")).Representation()
+}
+
+// -- io.ReadSeeker -->
+
+type reader struct {
+	n      *Node
+	offset int64
+
+	// The most recently loaded leaf, and the offset its contents begin at.
+	leaf      []byte
+	leafStart int64
+}
+
+func (n *Node) newReader() *reader {
+	return &reader{n: n}
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.offset >= r.n.length {
+		return 0, io.EOF
+	}
+	if r.leaf == nil || r.offset < r.leafStart || r.offset >= r.leafStart+int64(len(r.leaf)) {
+		leaf, start, err := r.n.leafAt(r.offset)
+		if err != nil {
+			return 0, err
+		}
+		r.leaf, r.leafStart = leaf, start
+	}
+	n := copy(p, r.leaf[r.offset-r.leafStart:])
+	r.offset += int64(n)
+	return n, nil
+}
+
+func (r *reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.n.length
+	default:
+		return r.offset, fmt.Errorf("flatbytes: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return r.offset, errors.New("flatbytes: seek to a negative offset")
+	}
+	r.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,61 @@
+package flatbytes
+
+import (
+	"github.com/ipld/go-ipld-prime/datamodel"
+)
+
+const (
+	// DefaultChunkSize is the size of the leaf blocks written by Write, unless the ChunkSize option is used.
+	DefaultChunkSize = 256 << 10
+	// DefaultMaxLinksPerBlock is the number of links in each branch block written by Write,
+	// unless the MaxLinksPerBlock option is used.
+	DefaultMaxLinksPerBlock = 1024
+)
+
+type config struct {
+	chunkSize         int
+	maxLinksPerBlock  int
+	leafLinkPrototype datamodel.LinkPrototype
+}
+
+// Option configures Write.
+type Option func(*config)
+
+func applyOptions(opts []Option) config {
+	cfg := config{
+		chunkSize:        DefaultChunkSize,
+		maxLinksPerBlock: DefaultMaxLinksPerBlock,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// ChunkSize sets the number of bytes in each leaf block.
+// The last leaf may be shorter.
+func ChunkSize(size int) Option {
+	return func(cfg *config) {
+		cfg.chunkSize = size
+	}
+}
+
+// MaxLinksPerBlock sets the number of links each branch block may hold before
+// another layer is added to the tree.
+// It must be at least 2.
+func MaxLinksPerBlock(n int) Option {
+	return func(cfg *config) {
+		cfg.maxLinksPerBlock = n
+	}
+}
+
+// LeafLinkPrototype sets the LinkPrototype used to store leaf blocks,
+// which otherwise use the same LinkPrototype as the branch blocks.
+//
+// Leaves are just bytes, so this is typically used to store them with the raw codec (0x55),
+// as UnixFS does, while branches use a codec which can encode lists and links, such as DAG-CBOR.
+func LeafLinkPrototype(lp datamodel.LinkPrototype) Option {
+	return func(cfg *config) {
+		cfg.leafLinkPrototype = lp
+	}
+}
@@ -0,0 +1,51 @@
+package flatbytes
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+)
+
+var _ linking.NodeReifier = Reify
+
+// Reify examines data in a Node to see if it matches the shape of a FlexibleByteLayout,
+// and if so, returns a Node which presents the FBL as bytes.
+//
+// Reify fits the linking.NodeReifier function interface,
+// so it can be registered in a LinkSystem's KnownReifiers, for use by selectors:
+//
+//	lsys.KnownReifiers = map[string]linking.NodeReifier{"flatbytes": flatbytes.Reify}
+//
+// An `ExploreInterpretAs{as: "flatbytes"}` clause wrapping a Matcher with a subset range
+// then reads just that range, loading only the blocks which cover it.
+//
+// Only the root block is examined by Reify; the other blocks are loaded through the given LinkSystem,
+// which the returned Node retains, as reads require them.
+func Reify(lnkCtx linking.LinkContext, maybeRoot datamodel.Node, lsys *linking.LinkSystem) (datamodel.Node, error) {
+	root, ok := bindnode.Unwrap(maybeRoot).(*FlexibleByteLayout)
+	if !ok {
+		nb := Prototype.FlexibleByteLayout.Representation().NewBuilder()
+		if err := nb.AssignNode(maybeRoot); err != nil {
+			return nil, fmt.Errorf("flatbytes: data does not match expected shape for substrate: %w", err)
+		}
+		root = bindnode.Unwrap(nb.Build()).(*FlexibleByteLayout)
+	}
+	length, err := layoutLength(root)
+	if err != nil {
+		return nil, fmt.Errorf("flatbytes: invalid root block: %w", err)
+	}
+	ctx := lnkCtx.Ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Node{
+		root:     root,
+		length:   length,
+		ctx:      ctx,
+		lsys:     lsys,
+		branches: make(map[datamodel.Link]*FlexibleByteLayout),
+	}, nil
+}
@@ -0,0 +1,83 @@
+package flatbytes
+
+import (
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// FlexibleByteLayout is one block of an FBL, as described by the IPLD Flexible Byte Layout spec.
+// It's either a leaf holding bytes directly, or a list of links to further FlexibleByteLayout blocks,
+// whose contents are concatenated.
+// Exactly one of the fields is set.
+type FlexibleByteLayout struct {
+	Bytes          *[]byte
+	NestedByteList *[]NestedByteListLayer
+}
+
+// NestedByteListLayer is one entry in a NestedByteList:
+// a link to a FlexibleByteLayout block, and the total number of bytes that block contains.
+type NestedByteListLayer struct {
+	Bytes  datamodel.Link
+	Length int64
+}
+
+// TypeSystem is the compiled form of the substrate schema of the Flexible Byte Layout spec:
+//
+//	type FlexibleByteLayout union {
+//		| Bytes bytes
+//		| NestedByteList list
+//	} representation kinded
+//
+//	type NestedByteList [NestedByteListLayer]
+//
+//	type NestedByteListLayer struct {
+//		bytes &FlexibleByteLayout
+//		length Int
+//	} representation tuple
+var TypeSystem schema.TypeSystem
+
+// Prototype contains schema.TypedPrototype values for the substrate types of the FBL ADL.
+//
+// Prototype.FlexibleByteLayout.Representation() is the best choice of prototype to load an FBL root block with,
+// since Reify can then use the loaded data without copying it.
+var Prototype struct {
+	FlexibleByteLayout schema.TypedPrototype
+}
+
+func init() {
+	var ts schema.TypeSystem
+	ts.Init()
+
+	ts.Accumulate(schema.SpawnInt("Int"))
+	ts.Accumulate(schema.SpawnBytes("Bytes"))
+
+	ts.Accumulate(schema.SpawnUnion("FlexibleByteLayout",
+		[]schema.TypeName{
+			"Bytes",
+			"NestedByteList",
+		},
+		schema.SpawnUnionRepresentationKinded(map[datamodel.Kind]schema.TypeName{
+			datamodel.Kind_Bytes: "Bytes",
+			datamodel.Kind_List:  "NestedByteList",
+		}),
+	))
+	ts.Accumulate(schema.SpawnList("NestedByteList", "NestedByteListLayer", false))
+	ts.Accumulate(schema.SpawnStruct("NestedByteListLayer",
+		[]schema.StructField{
+			schema.SpawnStructField("bytes", "Link__FlexibleByteLayout", false, false),
+			schema.SpawnStructField("length", "Int", false, false),
+		},
+		schema.SpawnStructRepresentationTuple(),
+	))
+	ts.Accumulate(schema.SpawnLinkReference("Link__FlexibleByteLayout", "FlexibleByteLayout"))
+
+	if errs := ts.ValidateGraph(); errs != nil {
+		for _, err := range errs {
+			panic(err)
+		}
+	}
+	TypeSystem = ts
+
+	Prototype.FlexibleByteLayout = bindnode.Prototype((*FlexibleByteLayout)(nil), ts.TypeByName("FlexibleByteLayout"))
+}
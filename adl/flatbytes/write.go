@@ -0,0 +1,112 @@
+package flatbytes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+)
+
+// Write chunks the contents of r into leaf blocks, arranges them into a balanced tree of
+// NestedByteList branch blocks, stores all of them using lsys, and returns the link to the root.
+//
+// Branch blocks are stored using lp, so its codec must be able to encode lists and links.
+// Leaf blocks are stored using lp too, unless the LeafLinkPrototype option is given.
+// If the data fits in a single chunk, the root is a leaf block.
+//
+// Write streams: it holds at most one chunk, and one partial branch block per layer of the tree, in memory at a time.
+func Write(lnkCtx linking.LinkContext, lsys *linking.LinkSystem, lp datamodel.LinkPrototype, r io.Reader, opts ...Option) (datamodel.Link, error) {
+	cfg := applyOptions(opts)
+	if cfg.chunkSize < 1 {
+		return nil, fmt.Errorf("flatbytes: invalid chunk size %d", cfg.chunkSize)
+	}
+	if cfg.maxLinksPerBlock < 2 {
+		return nil, fmt.Errorf("flatbytes: invalid max links per block %d: must be at least 2", cfg.maxLinksPerBlock)
+	}
+	leafLp := cfg.leafLinkPrototype
+	if leafLp == nil {
+		leafLp = lp
+	}
+	w := treeWriter{lnkCtx: lnkCtx, lsys: lsys, lp: lp, maxLinks: cfg.maxLinksPerBlock}
+
+	buf := make([]byte, cfg.chunkSize)
+	for first := true; ; first = false {
+		n, err := io.ReadFull(r, buf)
+		last := err != nil
+		switch {
+		case err == nil, errors.Is(err, io.ErrUnexpectedEOF):
+		case errors.Is(err, io.EOF):
+			if !first {
+				return w.finish()
+			}
+			// Empty input still gets a (zero-length) leaf, so that there's something to link to.
+		default:
+			return nil, fmt.Errorf("flatbytes: failed to read input: %w", err)
+		}
+		lnk, err := lsys.Store(lnkCtx, leafLp, basicnode.NewBytes(buf[:n]))
+		if err != nil {
+			return nil, fmt.Errorf("flatbytes: failed to store leaf: %w", err)
+		}
+		if err := w.add(0, NestedByteListLayer{Bytes: lnk, Length: int64(n)}); err != nil {
+			return nil, err
+		}
+		if last {
+			return w.finish()
+		}
+	}
+}
+
+// treeWriter accumulates the layers of an FBL tree as leaves are added, from the bottom up.
+// levels[0] holds links to leaves; each level above it holds links to full branch blocks of the level below.
+type treeWriter struct {
+	lnkCtx   linking.LinkContext
+	lsys     *linking.LinkSystem
+	lp       datamodel.LinkPrototype
+	maxLinks int
+	levels   [][]NestedByteListLayer
+}
+
+func (w *treeWriter) add(level int, layer NestedByteListLayer) error {
+	if level == len(w.levels) {
+		w.levels = append(w.levels, nil)
+	}
+	w.levels[level] = append(w.levels[level], layer)
+	if len(w.levels[level]) < w.maxLinks {
+		return nil
+	}
+	return w.flush(level)
+}
+
+// flush stores the pending entries at a level as a branch block, and adds a link to it to the level above.
+func (w *treeWriter) flush(level int) error {
+	layers := w.levels[level]
+	w.levels[level] = nil
+	var length int64
+	for _, layer := range layers {
+		length += layer.Length
+	}
+	fbl := FlexibleByteLayout{NestedByteList: &layers}
+	lnk, err := w.lsys.Store(w.lnkCtx, w.lp, bindnode.Wrap(&fbl, TypeSystem.TypeByName("FlexibleByteLayout")).Representation())
+	if err != nil {
+		return fmt.Errorf("flatbytes: failed to store branch: %w", err)
+	}
+	return w.add(level+1, NestedByteListLayer{Bytes: lnk, Length: length})
+}
+
+// finish flushes every partial level, returning the link to the root of the tree.
+func (w *treeWriter) finish() (datamodel.Link, error) {
+	for level := 0; ; level++ {
+		if level == len(w.levels)-1 && len(w.levels[level]) == 1 {
+			return w.levels[level][0].Bytes, nil
+		}
+		if len(w.levels[level]) > 0 {
+			if err := w.flush(level); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package hamt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/node/mixins"
+)
+
+const (
+	// DefaultBitWidth is the bitWidth used when a NodePrototype doesn't specify one.
+	// With a bitWidth of 8, each shard has up to 256 elements.
+	DefaultBitWidth = 8
+	// DefaultBucketSize is the bucketSize used when a NodePrototype doesn't specify one.
+	DefaultBucketSize = 3
+	// DefaultHashAlg is the hash function used when a NodePrototype doesn't specify one:
+	// murmur3-x64-64, as recommended by the HashMap spec.
+	DefaultHashAlg = 0x22
+)
+
+// -- NodePrototype -->
+
+var _ datamodel.NodePrototype = NodePrototype{}
+
+// NodePrototype builds new HAMTs.
+// Use it like any other NodePrototype: get a NodeBuilder, BeginMap, and assemble entries.
+//
+// All the entries are held in memory until the map assembler's Finish is called,
+// at which point the shards are computed and every shard except the root is written
+// using LinkSystem.Store with the LinkPrototype.
+// (The root is not written, since its link is what a user of the HAMT will want to keep hold of;
+// store the Substrate of the built Node to get that link.)
+// The LinkSystem and LinkPrototype may be left nil for small maps that fit in a root shard,
+// but it's an error to build a larger map without them.
+//
+// The zero values of BitWidth, BucketSize, and HashAlg mean the defaults.
+type NodePrototype struct {
+	LinkSystem    *linking.LinkSystem
+	LinkPrototype datamodel.LinkPrototype
+
+	BitWidth   int
+	BucketSize int
+	HashAlg    uint64
+}
+
+func (np NodePrototype) withDefaults() NodePrototype {
+	if np.BitWidth == 0 {
+		np.BitWidth = DefaultBitWidth
+	}
+	if np.BucketSize == 0 {
+		np.BucketSize = DefaultBucketSize
+	}
+	if np.HashAlg == 0 {
+		np.HashAlg = DefaultHashAlg
+	}
+	return np
+}
+
+func (np NodePrototype) NewBuilder() datamodel.NodeBuilder {
+	return &Builder{np: np.withDefaults()}
+}
+
+// -- NodeBuilder -->
+
+var _ datamodel.NodeBuilder = (*Builder)(nil)
+
+// Builder is the NodeBuilder returned by NodePrototype.NewBuilder.
+type Builder struct {
+	np    NodePrototype
+	ma    *mapAssembler
+	built *Node
+}
+
+func (b *Builder) Build() datamodel.Node {
+	if b.built == nil {
+		panic("invalid state: cannot call Build on an assembler that's not finished")
+	}
+	return b.built
+}
+func (b *Builder) Reset() {
+	*b = Builder{np: b.np}
+}
+
+// -- NodeAssembler -->
+
+func (b *Builder) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
+	if b.ma != nil {
+		panic("invalid state: cannot assemble into an assembler that's already started")
+	}
+	if b.np.BitWidth < 3 || b.np.BitWidth > 16 {
+		return nil, fmt.Errorf("hamt: invalid bitWidth %d: must be between 3 and 16", b.np.BitWidth)
+	}
+	if b.np.BucketSize < 1 {
+		return nil, fmt.Errorf("hamt: invalid bucketSize %d: must be at least 1", b.np.BucketSize)
+	}
+	if _, err := hashKey(b.np.HashAlg, nil); err != nil {
+		return nil, err
+	}
+	b.ma = &mapAssembler{b: b, seen: make(map[string]struct{})}
+	return b.ma, nil
+}
+func (*Builder) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.BeginList(0)
+}
+func (*Builder) AssignNull() error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignNull()
+}
+func (*Builder) AssignBool(bool) error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignBool(false)
+}
+func (*Builder) AssignInt(int64) error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignInt(0)
+}
+func (*Builder) AssignFloat(float64) error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignFloat(0)
+}
+func (*Builder) AssignString(string) error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignString("")
+}
+func (*Builder) AssignBytes([]byte) error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignBytes(nil)
+}
+func (*Builder) AssignLink(datamodel.Link) error {
+	return mixins.MapAssembler{TypeName: "hamt.Node"}.AssignLink(nil)
+}
+func (b *Builder) AssignNode(v datamodel.Node) error {
+	if v.Kind() != datamodel.Kind_Map {
+		return datamodel.ErrWrongKind{TypeName: "hamt.Node", MethodName: "AssignNode", AppropriateKind: datamodel.KindSet_JustMap, ActualKind: v.Kind()}
+	}
+	ma, err := b.BeginMap(v.Length())
+	if err != nil {
+		return err
+	}
+	for itr := v.MapIterator(); !itr.Done(); {
+		k, v, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		if err := ma.AssembleKey().AssignNode(k); err != nil {
+			return err
+		}
+		if err := ma.AssembleValue().AssignNode(v); err != nil {
+			return err
+		}
+	}
+	return ma.Finish()
+}
+func (b *Builder) Prototype() datamodel.NodePrototype {
+	return b.np
+}
+
+// -- MapAssembler -->
+
+type hashedEntry struct {
+	hash []byte
+	BucketEntry
+}
+
+type mapAssembler struct {
+	b       *Builder
+	seen    map[string]struct{}
+	entries []hashedEntry
+
+	// Each value is assembled into a fresh builder, and moved into entries when the next key (or the Finish) arrives.
+	key     string
+	value   datamodel.NodeBuilder
+	hashErr error
+}
+
+func (ma *mapAssembler) commitValue() error {
+	if ma.value == nil {
+		return nil
+	}
+	value := ma.value.Build()
+	ma.value = nil
+	hash, err := hashKey(ma.b.np.HashAlg, []byte(ma.key))
+	if err != nil {
+		return err
+	}
+	ma.entries = append(ma.entries, hashedEntry{hash, BucketEntry{Key: []byte(ma.key), Value: value}})
+	return nil
+}
+
+func (ma *mapAssembler) AssembleKey() datamodel.NodeAssembler {
+	if err := ma.commitValue(); err != nil {
+		ma.hashErr = err
+	}
+	return &keyAssembler{ma}
+}
+func (ma *mapAssembler) AssembleValue() datamodel.NodeAssembler {
+	ma.value = basicnode.Prototype.Any.NewBuilder()
+	return ma.value
+}
+func (ma *mapAssembler) AssembleEntry(k string) (datamodel.NodeAssembler, error) {
+	if err := (&keyAssembler{ma}).AssignString(k); err != nil {
+		return nil, err
+	}
+	return ma.AssembleValue(), nil
+}
+func (ma *mapAssembler) KeyPrototype() datamodel.NodePrototype {
+	return basicnode.Prototype.String
+}
+func (ma *mapAssembler) ValuePrototype(k string) datamodel.NodePrototype {
+	return basicnode.Prototype.Any
+}
+
+func (ma *mapAssembler) Finish() error {
+	if err := ma.commitValue(); err != nil {
+		return err
+	}
+	if ma.hashErr != nil {
+		return ma.hashErr
+	}
+	np := ma.b.np
+	root := &buildShard{bitfield: make([]byte, (1<<np.BitWidth)/8)}
+	for _, entry := range ma.entries {
+		if err := root.insert(np, entry, 0); err != nil {
+			return err
+		}
+	}
+	w := shardWriter{np: np, stored: make(map[datamodel.Link]*HashMapNode)}
+	hamt, err := w.write(root)
+	if err != nil {
+		return err
+	}
+	n := newNode(context.Background(), &HashMapRoot{
+		HashAlg:    int64(np.HashAlg),
+		BucketSize: int64(np.BucketSize),
+		Hamt:       hamt,
+	}, np.BitWidth, np.LinkSystem, np.LinkPrototype)
+	n.shards = w.stored // everything we just wrote is already in memory; no need to load it again.
+	n.length = int64(len(ma.entries))
+	ma.b.built = n
+	return nil
+}
+
+// -- key NodeAssembler -->
+
+type keyAssembler struct {
+	ma *mapAssembler
+}
+
+func (keyAssembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.BeginMap(0)
+}
+func (keyAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.BeginList(0)
+}
+func (keyAssembler) AssignNull() error {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.AssignNull()
+}
+func (keyAssembler) AssignBool(bool) error {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.AssignBool(false)
+}
+func (keyAssembler) AssignInt(int64) error {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.AssignInt(0)
+}
+func (keyAssembler) AssignFloat(float64) error {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.AssignFloat(0)
+}
+func (ka *keyAssembler) AssignString(k string) error {
+	ma := ka.ma
+	if err := ma.commitValue(); err != nil {
+		return err
+	}
+	if _, exists := ma.seen[k]; exists {
+		return datamodel.ErrRepeatedMapKey{Key: basicnode.NewString(k)}
+	}
+	ma.seen[k] = struct{}{}
+	ma.key = k
+	return nil
+}
+func (keyAssembler) AssignBytes([]byte) error {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.AssignBytes(nil)
+}
+func (keyAssembler) AssignLink(datamodel.Link) error {
+	return mixins.StringAssembler{TypeName: "hamt.Node key"}.AssignLink(nil)
+}
+func (ka *keyAssembler) AssignNode(v datamodel.Node) error {
+	k, err := v.AsString()
+	if err != nil {
+		return err
+	}
+	return ka.AssignString(k)
+}
+func (keyAssembler) Prototype() datamodel.NodePrototype {
+	return basicnode.Prototype.String
+}
+
+// -- shard construction -->
+
+// buildShard is the in-memory form of a shard while a HAMT is being built.
+// Each element holds either a child shard or a bucket.
+type buildShard struct {
+	bitfield []byte
+	elements []buildElement
+}
+
+type buildElement struct {
+	child  *buildShard
+	bucket []hashedEntry
+}
+
+func (s *buildShard) insert(np NodePrototype, entry hashedEntry, depth int) error {
+	idx, err := indexAt(entry.hash, depth, np.BitWidth)
+	if err != nil {
+		return err
+	}
+	pos := onesBefore(s.bitfield, idx)
+	if !bitIsSet(s.bitfield, idx) {
+		setBit(s.bitfield, idx)
+		s.elements = append(s.elements, buildElement{})
+		copy(s.elements[pos+1:], s.elements[pos:])
+		s.elements[pos] = buildElement{bucket: []hashedEntry{entry}}
+		return nil
+	}
+	elem := &s.elements[pos]
+	if elem.child != nil {
+		return elem.child.insert(np, entry, depth+1)
+	}
+	if len(elem.bucket) < np.BucketSize {
+		i := sort.Search(len(elem.bucket), func(i int) bool {
+			return bytes.Compare(elem.bucket[i].Key, entry.Key) >= 0
+		})
+		elem.bucket = append(elem.bucket, hashedEntry{})
+		copy(elem.bucket[i+1:], elem.bucket[i:])
+		elem.bucket[i] = entry
+		return nil
+	}
+	// The bucket is full: replace it with a child shard holding all of its entries, plus the new one.
+	child := &buildShard{bitfield: make([]byte, len(s.bitfield))}
+	for _, existing := range elem.bucket {
+		if err := child.insert(np, existing, depth+1); err != nil {
+			return err
+		}
+	}
+	if err := child.insert(np, entry, depth+1); err != nil {
+		return err
+	}
+	*elem = buildElement{child: child}
+	return nil
+}
+
+// shardWriter converts buildShards into HashMapNodes, storing every shard it's asked to link to.
+type shardWriter struct {
+	np     NodePrototype
+	stored map[datamodel.Link]*HashMapNode
+}
+
+func (w *shardWriter) write(s *buildShard) (HashMapNode, error) {
+	hn := HashMapNode{
+		Map:  s.bitfield,
+		Data: make([]Element, len(s.elements)),
+	}
+	for i, elem := range s.elements {
+		if elem.child == nil {
+			bucket := make([]BucketEntry, len(elem.bucket))
+			for j, entry := range elem.bucket {
+				bucket[j] = entry.BucketEntry
+			}
+			hn.Data[i] = Element{Bucket: &bucket}
+			continue
+		}
+		child, err := w.write(elem.child)
+		if err != nil {
+			return hn, err
+		}
+		if w.np.LinkSystem == nil || w.np.LinkPrototype == nil {
+			return hn, fmt.Errorf("hamt: map is too large for a single shard, and no LinkSystem and LinkPrototype are configured to store more")
+		}
+		lnk, err := w.np.LinkSystem.Store(
+			linking.LinkContext{Ctx: context.Background()},
+			w.np.LinkPrototype,
+			bindnode.Wrap(&child, TypeSystem.TypeByName("HashMapNode")).Representation(),
+		)
+		if err != nil {
+			return hn, fmt.Errorf("hamt: failed to store shard: %w", err)
+		}
+		w.stored[lnk] = &child
+		hn.Data[i] = Element{HashMapNode: &lnk}
+	}
+	return hn, nil
+}
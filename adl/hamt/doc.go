@@ -0,0 +1,19 @@
+/*
+The hamt package implements the IPLD HashMap ADL: a map whose entries are spread across
+a Hash Array Mapped Trie of linked blocks, so that maps of any size can be stored and
+looked up without loading the whole thing.
+The HashMap spec can be found at https://ipld.io/specs/advanced-data-layouts/hamt/spec/ .
+
+There are several ways to move data in and out of the ADL:
+
+  - build a new HAMT using a NodePrototype, which is configured with a LinkSystem and LinkPrototype for storing shards;
+    then store the Substrate of the resulting Node to get a link to the HAMT's root;
+  - load up the root block of an existing HAMT, and use Reify to get a Node which behaves like a regular map
+    (loading the root block with Prototype.HashMapRoot.Representation() saves Reify some work);
+  - register Reify in a LinkSystem's KnownReifiers, and use `ExploreInterpretAs{as: "hamt"}` in selectors
+    to have traversals see the map instead of the raw shards.
+
+Lookups and iteration load only the shards they need to, when they need them,
+through the LinkSystem which was given to Reify (or to the NodePrototype that built the Node).
+*/
+package hamt
@@ -0,0 +1,251 @@
+package hamt_test
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/adl/hamt"
+	_ "github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+var linkProto = cidlink.LinkPrototype{Prefix: cid.Prefix{
+	Version:  1,
+	Codec:    0x71, // dag-cbor
+	MhType:   0x12, // sha2-256
+	MhLength: 32,
+}}
+
+func newLinkSystem() (*linking.LinkSystem, *memstore.Store) {
+	store := &memstore.Store{}
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(store)
+	lsys.SetWriteStorage(store)
+	return &lsys, store
+}
+
+// buildMap builds a HAMT holding "key-0" through "key-<n-1>", in the given order,
+// and stores its root, returning the link to it.
+func buildMap(t *testing.T, np hamt.NodePrototype, order []int) (datamodel.Node, datamodel.Link) {
+	t.Helper()
+	n, err := qp.BuildMap(np, int64(len(order)), func(ma datamodel.MapAssembler) {
+		for _, i := range order {
+			qp.MapEntry(ma, fmt.Sprintf("key-%d", i), qp.Int(int64(i)))
+		}
+	})
+	qt.Assert(t, err, qt.IsNil)
+	lnk, err := np.LinkSystem.Store(linking.LinkContext{}, linkProto, n.(*hamt.Node).Substrate())
+	qt.Assert(t, err, qt.IsNil)
+	return n, lnk
+}
+
+func sequence(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+func checkMap(t *testing.T, n datamodel.Node, size int) {
+	t.Helper()
+	qt.Check(t, n.Kind(), qt.Equals, datamodel.Kind_Map)
+	qt.Check(t, n.Length(), qt.Equals, int64(size))
+	for i := 0; i < size; i++ {
+		v, err := n.LookupByString(fmt.Sprintf("key-%d", i))
+		qt.Assert(t, err, qt.IsNil)
+		vi, err := v.AsInt()
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, vi, qt.Equals, int64(i))
+	}
+	_, err := n.LookupByString("missing")
+	qt.Check(t, err, qt.ErrorAs, new(datamodel.ErrNotExists))
+
+	var keys []string
+	for itr := n.MapIterator(); !itr.Done(); {
+		k, _, err := itr.Next()
+		qt.Assert(t, err, qt.IsNil)
+		ks, err := k.AsString()
+		qt.Assert(t, err, qt.IsNil)
+		keys = append(keys, ks)
+	}
+	qt.Check(t, keys, qt.HasLen, size)
+	sort.Strings(keys)
+	for i := 1; i < len(keys); i++ {
+		qt.Check(t, keys[i], qt.Not(qt.Equals), keys[i-1])
+	}
+}
+
+func TestRoundtrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		np   hamt.NodePrototype
+		size int
+	}{
+		{"defaults, small", hamt.NodePrototype{}, 10},
+		{"defaults, large", hamt.NodePrototype{}, 2000},
+		{"narrow shards", hamt.NodePrototype{BitWidth: 3, BucketSize: 2}, 500},
+		{"sha2-256", hamt.NodePrototype{BitWidth: 4, BucketSize: 1, HashAlg: 0x12}, 300},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lsys, store := newLinkSystem()
+			tc.np.LinkSystem = lsys
+			tc.np.LinkPrototype = linkProto
+			built, rootLnk := buildMap(t, tc.np, sequence(tc.size))
+			checkMap(t, built, tc.size)
+
+			// Reload from storage, with a fresh LinkSystem so nothing is cached.
+			lsys2 := cidlink.DefaultLinkSystem()
+			lsys2.SetReadStorage(store)
+			for _, proto := range []datamodel.NodePrototype{basicnode.Prototype.Any, hamt.Prototype.HashMapRoot.Representation()} {
+				substrate, err := lsys2.Load(linking.LinkContext{}, rootLnk, proto)
+				qt.Assert(t, err, qt.IsNil)
+				n, err := hamt.Reify(linking.LinkContext{}, substrate, &lsys2)
+				qt.Assert(t, err, qt.IsNil)
+				checkMap(t, n, tc.size)
+			}
+		})
+	}
+}
+
+func TestInsertionOrderIndependence(t *testing.T) {
+	lsys, _ := newLinkSystem()
+	np := hamt.NodePrototype{LinkSystem: lsys, LinkPrototype: linkProto, BitWidth: 3, BucketSize: 2}
+	order := sequence(200)
+	_, lnk1 := buildMap(t, np, order)
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	_, lnk2 := buildMap(t, np, order)
+	qt.Check(t, lnk2, qt.Equals, lnk1)
+}
+
+func TestLazyLoading(t *testing.T) {
+	lsys, store := newLinkSystem()
+	np := hamt.NodePrototype{LinkSystem: lsys, LinkPrototype: linkProto, BitWidth: 3, BucketSize: 1}
+	_, rootLnk := buildMap(t, np, sequence(300))
+
+	var loads int
+	lsys2 := cidlink.DefaultLinkSystem()
+	lsys2.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		loads++
+		return store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	substrate, err := lsys2.Load(linking.LinkContext{}, rootLnk, basicnode.Prototype.Any)
+	qt.Assert(t, err, qt.IsNil)
+	n, err := hamt.Reify(linking.LinkContext{}, substrate, &lsys2)
+	qt.Assert(t, err, qt.IsNil)
+
+	loads = 0
+	_, err = n.LookupByString("key-7")
+	qt.Assert(t, err, qt.IsNil)
+	firstLookup := loads
+	qt.Check(t, firstLookup > 0, qt.IsTrue)
+	qt.Check(t, firstLookup < 10, qt.IsTrue)
+
+	// Repeating the lookup is served from the shard cache.
+	_, err = n.LookupByString("key-7")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, loads, qt.Equals, firstLookup)
+
+	qt.Check(t, n.Length(), qt.Equals, int64(300))
+	qt.Check(t, loads > firstLookup, qt.IsTrue)
+}
+
+func TestBuilderErrors(t *testing.T) {
+	t.Run("repeated key", func(t *testing.T) {
+		nb := hamt.NodePrototype{}.NewBuilder()
+		ma, err := nb.BeginMap(2)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, ma.AssembleKey().AssignString("a"), qt.IsNil)
+		qt.Assert(t, ma.AssembleValue().AssignInt(1), qt.IsNil)
+		err = ma.AssembleKey().AssignString("a")
+		qt.Check(t, err, qt.ErrorAs, new(datamodel.ErrRepeatedMapKey))
+	})
+	t.Run("no link system", func(t *testing.T) {
+		_, err := qp.BuildMap(hamt.NodePrototype{BitWidth: 3, BucketSize: 1}, 100, func(ma datamodel.MapAssembler) {
+			for i := 0; i < 100; i++ {
+				qp.MapEntry(ma, fmt.Sprintf("key-%d", i), qp.Int(int64(i)))
+			}
+		})
+		qt.Check(t, err, qt.ErrorMatches, ".*no LinkSystem.*")
+	})
+	t.Run("bad bitWidth", func(t *testing.T) {
+		_, err := hamt.NodePrototype{BitWidth: 2}.NewBuilder().BeginMap(0)
+		qt.Check(t, err, qt.ErrorMatches, ".*invalid bitWidth.*")
+	})
+	t.Run("unknown hash", func(t *testing.T) {
+		_, err := hamt.NodePrototype{HashAlg: 0xdeadbeef}.NewBuilder().BeginMap(0)
+		qt.Check(t, err, qt.ErrorMatches, ".*no hasher registered.*")
+	})
+}
+
+func TestReifyRejectsInvalidRoots(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		root datamodel.Node
+	}{
+		{"not a map", basicnode.NewString("nope")},
+		{"bad bitfield", mustBuildRoot(t, 0x22, 3, []byte{0, 0, 0}, 0)},
+		{"bitfield and data disagree", mustBuildRoot(t, 0x22, 3, []byte{1}, 0)},
+		{"zero bucketSize", mustBuildRoot(t, 0x22, 0, []byte{0}, 0)},
+		{"unknown hash", mustBuildRoot(t, 0xdeadbeef, 3, []byte{0}, 0)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := hamt.Reify(linking.LinkContext{}, tc.root, nil)
+			qt.Check(t, err, qt.IsNotNil)
+		})
+	}
+}
+
+func mustBuildRoot(t *testing.T, hashAlg, bucketSize int64, bitfield []byte, elements int) datamodel.Node {
+	n, err := qp.BuildMap(basicnode.Prototype.Any, 3, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "hashAlg", qp.Int(hashAlg))
+		qp.MapEntry(ma, "bucketSize", qp.Int(bucketSize))
+		qp.MapEntry(ma, "hamt", qp.List(2, func(la datamodel.ListAssembler) {
+			qp.ListEntry(la, qp.Bytes(bitfield))
+			qp.ListEntry(la, qp.List(int64(elements), func(la datamodel.ListAssembler) {}))
+		}))
+	})
+	qt.Assert(t, err, qt.IsNil)
+	return n
+}
+
+func TestSelectorInterpretAs(t *testing.T) {
+	lsys, store := newLinkSystem()
+	np := hamt.NodePrototype{LinkSystem: lsys, LinkPrototype: linkProto, BitWidth: 3, BucketSize: 2}
+	_, rootLnk := buildMap(t, np, sequence(100))
+
+	lsys2 := cidlink.DefaultLinkSystem()
+	lsys2.SetReadStorage(store)
+	lsys2.KnownReifiers = map[string]linking.NodeReifier{"hamt": hamt.Reify}
+
+	sel, err := selectorparse.ParseAndCompileJSONSelector(`{"~":{"as":"hamt",">":{"f":{"f>":{"key-42":{".":{}}}}}}}`)
+	qt.Assert(t, err, qt.IsNil)
+	root, err := lsys2.Load(linking.LinkContext{}, rootLnk, basicnode.Prototype.Any)
+	qt.Assert(t, err, qt.IsNil)
+
+	var visited []datamodel.Node
+	err = traversal.Progress{Cfg: &traversal.Config{LinkSystem: lsys2}}.WalkMatching(root, sel, func(prog traversal.Progress, n datamodel.Node) error {
+		qt.Check(t, prog.Path.String(), qt.Equals, "key-42")
+		visited = append(visited, n)
+		return nil
+	})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, visited, qt.HasLen, 1)
+	v, err := visited[0].AsInt()
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, v, qt.Equals, int64(42))
+}
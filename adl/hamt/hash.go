@@ -0,0 +1,77 @@
+package hamt
+
+import (
+	"fmt"
+	"math/bits"
+
+	multihash "github.com/multiformats/go-multihash/core"
+	// Make sure murmur3-x64-64, the default hash function of the HashMap spec, is registered.
+	_ "github.com/multiformats/go-multihash/register/murmur3"
+)
+
+// hashKey hashes a key with the given multihash function.
+func hashKey(hashAlg uint64, key []byte) ([]byte, error) {
+	h, err := multihash.GetHasher(hashAlg)
+	if err != nil {
+		return nil, fmt.Errorf("hamt: no hasher registered for multihash indicator 0x%x: %w", hashAlg, err)
+	}
+	h.Write(key)
+	return h.Sum(nil), nil
+}
+
+// indexAt returns the index into a HashMapNode at the given depth that a hash belongs at.
+// Indexes are taken bitWidth bits at a time from the hash, starting at the most significant bit of its first byte.
+func indexAt(hash []byte, depth, bitWidth int) (int, error) {
+	offset := depth * bitWidth
+	if offset+bitWidth > len(hash)*8 {
+		return 0, fmt.Errorf("hamt: maximum depth reached: hash is only %d bits long", len(hash)*8)
+	}
+	var idx int
+	for i := offset; i < offset+bitWidth; i++ {
+		idx = idx<<1 | int(hash[i/8]>>(7-i%8)&1)
+	}
+	return idx, nil
+}
+
+// The bitfield in a HashMapNode's "map" is big-endian:
+// index 0 is the least significant bit of the last byte.
+
+func bitIsSet(bf []byte, i int) bool {
+	return bf[len(bf)-1-i/8]&(1<<(i%8)) != 0
+}
+
+func setBit(bf []byte, i int) {
+	bf[len(bf)-1-i/8] |= 1 << (i % 8)
+}
+
+// onesBefore counts the bits set at indexes below i, which is the position in Data of the element for index i.
+func onesBefore(bf []byte, i int) int {
+	var count int
+	for byteIdx := len(bf) - 1; i > 0; byteIdx-- {
+		b := bf[byteIdx]
+		if i < 8 {
+			b &= (1 << i) - 1
+		}
+		count += bits.OnesCount8(b)
+		i -= 8
+	}
+	return count
+}
+
+func popcount(bf []byte) int {
+	var count int
+	for _, b := range bf {
+		count += bits.OnesCount8(b)
+	}
+	return count
+}
+
+// bitWidthOf infers the bitWidth of a HAMT from the length of a "map" bitfield,
+// which must hold exactly 2^bitWidth bits.
+func bitWidthOf(bf []byte) (int, error) {
+	n := len(bf) * 8
+	if n < 8 || n&(n-1) != 0 {
+		return 0, fmt.Errorf("hamt: invalid map bitfield length %d: must be a power of two bytes", len(bf))
+	}
+	return bits.TrailingZeros(uint(n)), nil
+}
@@ -0,0 +1,282 @@
+package hamt
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ipld/go-ipld-prime/adl"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/node/mixins"
+)
+
+// -- Node -->
+
+var _ adl.ADL = (*Node)(nil)
+
+// Node is the synthesized view of a HAMT: a map, whose entries may be spread across many blocks.
+//
+// Shards are loaded lazily, through the LinkSystem given to Reify (or to the NodePrototype that built the Node),
+// and cached once loaded, so repeated lookups in the same region of the map don't repeat loads.
+// Errors encountered while loading shards are returned from lookups and from the map iterator.
+//
+// Keys in a HAMT are bytes; in the data model view given by Node, they're presented as strings
+// (with the same bytes -- which need not be UTF-8).
+type Node struct {
+	root     *HashMapRoot
+	bitWidth int
+	ctx      context.Context
+	lsys     *linking.LinkSystem
+	lp       datamodel.LinkPrototype // only used to configure the Prototype of this node; may be nil.
+
+	mu     sync.Mutex
+	shards map[datamodel.Link]*HashMapNode
+	length int64 // -1 until computed.
+}
+
+func newNode(ctx context.Context, root *HashMapRoot, bitWidth int, lsys *linking.LinkSystem, lp datamodel.LinkPrototype) *Node {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Node{
+		root:     root,
+		bitWidth: bitWidth,
+		ctx:      ctx,
+		lsys:     lsys,
+		lp:       lp,
+		shards:   make(map[datamodel.Link]*HashMapNode),
+		length:   -1,
+	}
+}
+
+// loadShard loads the shard at a link, or returns it from the cache if it's been loaded before.
+func (n *Node) loadShard(lnk datamodel.Link) (*HashMapNode, error) {
+	n.mu.Lock()
+	shard, ok := n.shards[lnk]
+	n.mu.Unlock()
+	if ok {
+		return shard, nil
+	}
+	if n.lsys == nil {
+		return nil, fmt.Errorf("hamt: cannot load shard %s: no LinkSystem configured", lnk)
+	}
+	nb := Prototype.HashMapNode.Representation().NewBuilder()
+	if err := n.lsys.Fill(linking.LinkContext{Ctx: n.ctx}, lnk, nb); err != nil {
+		return nil, fmt.Errorf("hamt: cannot load shard %s: %w", lnk, err)
+	}
+	shard = bindnode.Unwrap(nb.Build()).(*HashMapNode)
+	if err := n.checkShard(shard); err != nil {
+		return nil, fmt.Errorf("hamt: invalid shard %s: %w", lnk, err)
+	}
+	n.mu.Lock()
+	n.shards[lnk] = shard
+	n.mu.Unlock()
+	return shard, nil
+}
+
+// checkShard checks the consistency of a shard's bitfield with its data,
+// so that the rest of the implementation can index into it without further checks.
+func (n *Node) checkShard(shard *HashMapNode) error {
+	if len(shard.Map)*8 != 1<<n.bitWidth {
+		return fmt.Errorf("map bitfield is %d bytes, but the root has a bitWidth of %d", len(shard.Map), n.bitWidth)
+	}
+	if popcount(shard.Map) != len(shard.Data) {
+		return fmt.Errorf("map bitfield has %d bits set, but there are %d elements", popcount(shard.Map), len(shard.Data))
+	}
+	for _, elem := range shard.Data {
+		if elem.Bucket != nil && (len(*elem.Bucket) == 0 || len(*elem.Bucket) > int(n.root.BucketSize)) {
+			return fmt.Errorf("bucket has %d entries, but bucketSize is %d", len(*elem.Bucket), n.root.BucketSize)
+		}
+	}
+	return nil
+}
+
+func (n *Node) lookup(key string) (datamodel.Node, error) {
+	hash, err := hashKey(uint64(n.root.HashAlg), []byte(key))
+	if err != nil {
+		return nil, err
+	}
+	shard := &n.root.Hamt
+	for depth := 0; ; depth++ {
+		idx, err := indexAt(hash, depth, n.bitWidth)
+		if err != nil {
+			return nil, err
+		}
+		if !bitIsSet(shard.Map, idx) {
+			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(key)}
+		}
+		elem := shard.Data[onesBefore(shard.Map, idx)]
+		if elem.Bucket != nil {
+			for _, entry := range *elem.Bucket {
+				if bytes.Equal(entry.Key, []byte(key)) {
+					return entry.Value, nil
+				}
+			}
+			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(key)}
+		}
+		shard, err = n.loadShard(*elem.HashMapNode)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (*Node) Kind() datamodel.Kind {
+	return datamodel.Kind_Map
+}
+func (n *Node) LookupByString(key string) (datamodel.Node, error) {
+	return n.lookup(key)
+}
+func (n *Node) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+	ks, err := key.AsString()
+	if err != nil {
+		return nil, err
+	}
+	return n.lookup(ks)
+}
+func (*Node) LookupByIndex(idx int64) (datamodel.Node, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.LookupByIndex(idx)
+}
+func (n *Node) LookupBySegment(seg datamodel.PathSegment) (datamodel.Node, error) {
+	return n.lookup(seg.String())
+}
+func (n *Node) MapIterator() datamodel.MapIterator {
+	return &mapIterator{n: n, stack: []iterFrame{{shard: &n.root.Hamt}}}
+}
+func (*Node) ListIterator() datamodel.ListIterator {
+	return nil
+}
+
+// Length returns the number of entries in the map.
+// This requires loading every shard, the first time it's called;
+// if any shard can't be loaded, -1 is returned.
+func (n *Node) Length() int64 {
+	n.mu.Lock()
+	length := n.length
+	n.mu.Unlock()
+	if length >= 0 {
+		return length
+	}
+	length = 0
+	for itr := n.MapIterator(); !itr.Done(); {
+		if _, _, err := itr.Next(); err != nil {
+			return -1
+		}
+		length++
+	}
+	n.mu.Lock()
+	n.length = length
+	n.mu.Unlock()
+	return length
+}
+func (*Node) IsAbsent() bool {
+	return false
+}
+func (*Node) IsNull() bool {
+	return false
+}
+func (*Node) AsBool() (bool, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.AsBool()
+}
+func (*Node) AsInt() (int64, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.AsInt()
+}
+func (*Node) AsFloat() (float64, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.AsFloat()
+}
+func (*Node) AsString() (string, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.AsString()
+}
+func (*Node) AsBytes() ([]byte, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.AsBytes()
+}
+func (*Node) AsLink() (datamodel.Link, error) {
+	return mixins.Map{TypeName: "hamt.Node"}.AsLink()
+}
+
+// Prototype returns a NodePrototype which builds new HAMTs with the same parameters as this one.
+func (n *Node) Prototype() datamodel.NodePrototype {
+	return NodePrototype{
+		LinkSystem:    n.lsys,
+		LinkPrototype: n.lp,
+		BitWidth:      n.bitWidth,
+		BucketSize:    int(n.root.BucketSize),
+		HashAlg:       uint64(n.root.HashAlg),
+	}
+}
+
+// Substrate returns the root block of the HAMT's substrate, as a typed node.
+// Store the representation of this node (or just give it to LinkSystem.Store, which encodes typed nodes by their representation)
+// to persist a HAMT; the other shards are already stored by the time a Node exists.
+//
+// Note that the substrate contains only the root shard; the other shards are reachable by links from it.
+func (n *Node) Substrate() datamodel.Node {
+	return bindnode.Wrap(n.root, TypeSystem.TypeByName("HashMapRoot")).Representation()
+}
+
+// -- MapIterator -->
+
+type iterFrame struct {
+	shard *HashMapNode
+	pos   int
+}
+
+type mapIterator struct {
+	n         *Node
+	stack     []iterFrame
+	bucket    []BucketEntry
+	bucketPos int
+	err       error
+}
+
+// advance moves the iterator to the next entry, loading shards as necessary,
+// unless it is already on an entry, or there are none left.
+func (itr *mapIterator) advance() {
+	for itr.err == nil && itr.bucketPos >= len(itr.bucket) && len(itr.stack) > 0 {
+		top := &itr.stack[len(itr.stack)-1]
+		if top.pos >= len(top.shard.Data) {
+			itr.stack = itr.stack[:len(itr.stack)-1]
+			continue
+		}
+		elem := top.shard.Data[top.pos]
+		top.pos++
+		if elem.Bucket != nil {
+			itr.bucket = *elem.Bucket
+			itr.bucketPos = 0
+			continue
+		}
+		child, err := itr.n.loadShard(*elem.HashMapNode)
+		if err != nil {
+			itr.err = err
+			return
+		}
+		itr.stack = append(itr.stack, iterFrame{shard: child})
+	}
+}
+
+func (itr *mapIterator) Next() (datamodel.Node, datamodel.Node, error) {
+	itr.advance()
+	if itr.err != nil {
+		// Report the error once, then stop.
+		err := itr.err
+		itr.err = nil
+		itr.stack = nil
+		itr.bucket = nil
+		return nil, nil, err
+	}
+	if itr.bucketPos >= len(itr.bucket) {
+		return nil, nil, datamodel.ErrIteratorOverread{}
+	}
+	entry := itr.bucket[itr.bucketPos]
+	itr.bucketPos++
+	return basicnode.NewString(string(entry.Key)), entry.Value, nil
+}
+
+func (itr *mapIterator) Done() bool {
+	itr.advance()
+	return itr.err == nil && itr.bucketPos >= len(itr.bucket)
+}
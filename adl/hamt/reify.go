@@ -0,0 +1,58 @@
+package hamt
+
+import (
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+)
+
+var _ linking.NodeReifier = Reify
+
+// Reify examines data in a Node to see if it matches the shape of a HashMapRoot,
+// and if so, returns a Node which presents the HAMT as a map.
+//
+// Reify fits the linking.NodeReifier function interface,
+// so it can be registered in a LinkSystem's KnownReifiers, for use by selectors:
+//
+//	lsys.KnownReifiers = map[string]linking.NodeReifier{"hamt": hamt.Reify}
+//
+// which makes `ExploreInterpretAs{as: "hamt"}` clauses work in selectors.
+//
+// Only the root block is examined by Reify; the other shards are loaded lazily,
+// through the given LinkSystem, as lookups and iteration require them.
+// This means invalid data beyond the root will not be discovered until then.
+// The LinkSystem is retained by the returned Node for this purpose.
+//
+// If the data was loaded using Prototype.HashMapRoot.Representation(), Reify uses it directly;
+// otherwise it is copied into that form first.
+func Reify(lnkCtx linking.LinkContext, maybeRoot datamodel.Node, lsys *linking.LinkSystem) (datamodel.Node, error) {
+	root, ok := bindnode.Unwrap(maybeRoot).(*HashMapRoot)
+	if !ok {
+		nb := Prototype.HashMapRoot.Representation().NewBuilder()
+		if err := nb.AssignNode(maybeRoot); err != nil {
+			return nil, fmt.Errorf("hamt: data does not match expected shape for substrate: %w", err)
+		}
+		root = bindnode.Unwrap(nb.Build()).(*HashMapRoot)
+	}
+
+	bitWidth, err := bitWidthOf(root.Hamt.Map)
+	if err != nil {
+		return nil, err
+	}
+	if root.BucketSize < 1 {
+		return nil, fmt.Errorf("hamt: invalid bucketSize %d: must be at least 1", root.BucketSize)
+	}
+	if root.HashAlg < 0 {
+		return nil, fmt.Errorf("hamt: invalid hashAlg %d", root.HashAlg)
+	}
+	if _, err := hashKey(uint64(root.HashAlg), nil); err != nil {
+		return nil, err
+	}
+	n := newNode(lnkCtx.Ctx, root, bitWidth, lsys, nil)
+	if err := n.checkShard(&root.Hamt); err != nil {
+		return nil, fmt.Errorf("hamt: invalid root shard: %w", err)
+	}
+	return n, nil
+}
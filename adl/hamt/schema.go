@@ -0,0 +1,126 @@
+package hamt
+
+import (
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// HashMapRoot is the root block of a HAMT, as described by the IPLD HashMap spec.
+// This is the substrate of the ADL: the data that's actually encoded and stored.
+type HashMapRoot struct {
+	HashAlg    int64 // A multihash function code.
+	BucketSize int64
+	Hamt       HashMapNode
+}
+
+// HashMapNode is one shard of a HAMT.
+// The root shard is embedded in the HashMapRoot; all others are in blocks of their own.
+type HashMapNode struct {
+	Map  []byte // A bitfield with a bit set for each index present in Data.
+	Data []Element
+}
+
+// Element is one entry in a HashMapNode: either a link to a child shard, or a bucket of entries.
+// Exactly one of the fields is set.
+type Element struct {
+	HashMapNode *datamodel.Link
+	Bucket      *[]BucketEntry
+}
+
+// BucketEntry is a key and value stored in a HAMT.
+// Within a bucket, entries are sorted by key.
+type BucketEntry struct {
+	Key   []byte
+	Value datamodel.Node
+}
+
+// TypeSystem is the compiled form of the substrate schema of the HashMap spec:
+//
+//	type HashMapRoot struct {
+//		hashAlg Int
+//		bucketSize Int
+//		hamt HashMapNode
+//	}
+//
+//	type HashMapNode struct {
+//		map Bytes
+//		data [Element]
+//	} representation tuple
+//
+//	type Element union {
+//		| &HashMapNode link
+//		| Bucket list
+//	} representation kinded
+//
+//	type Bucket [BucketEntry]
+//
+//	type BucketEntry struct {
+//		key Bytes
+//		value Any
+//	} representation tuple
+var TypeSystem schema.TypeSystem
+
+// Prototype contains schema.TypedPrototype values for the substrate types of the HAMT ADL.
+//
+// Prototype.HashMapRoot.Representation() is the best choice of prototype to load a HAMT root block with,
+// since Reify can then use the loaded data without copying it.
+var Prototype struct {
+	HashMapRoot schema.TypedPrototype
+	HashMapNode schema.TypedPrototype
+}
+
+func init() {
+	var ts schema.TypeSystem
+	ts.Init()
+
+	ts.Accumulate(schema.SpawnInt("Int"))
+	ts.Accumulate(schema.SpawnBytes("Bytes"))
+	ts.Accumulate(schema.SpawnAny("Any"))
+
+	ts.Accumulate(schema.SpawnStruct("HashMapRoot",
+		[]schema.StructField{
+			schema.SpawnStructField("hashAlg", "Int", false, false),
+			schema.SpawnStructField("bucketSize", "Int", false, false),
+			schema.SpawnStructField("hamt", "HashMapNode", false, false),
+		},
+		schema.StructRepresentation_Map{},
+	))
+	ts.Accumulate(schema.SpawnStruct("HashMapNode",
+		[]schema.StructField{
+			schema.SpawnStructField("map", "Bytes", false, false),
+			schema.SpawnStructField("data", "List__Element", false, false),
+		},
+		schema.SpawnStructRepresentationTuple(),
+	))
+	ts.Accumulate(schema.SpawnList("List__Element", "Element", false))
+	ts.Accumulate(schema.SpawnUnion("Element",
+		[]schema.TypeName{
+			"Link__HashMapNode",
+			"Bucket",
+		},
+		schema.SpawnUnionRepresentationKinded(map[datamodel.Kind]schema.TypeName{
+			datamodel.Kind_Link: "Link__HashMapNode",
+			datamodel.Kind_List: "Bucket",
+		}),
+	))
+	ts.Accumulate(schema.SpawnLinkReference("Link__HashMapNode", "HashMapNode"))
+	ts.Accumulate(schema.SpawnList("Bucket", "BucketEntry", false))
+	ts.Accumulate(schema.SpawnStruct("BucketEntry",
+		[]schema.StructField{
+			schema.SpawnStructField("key", "Bytes", false, false),
+			schema.SpawnStructField("value", "Any", false, false),
+		},
+		schema.SpawnStructRepresentationTuple(),
+	))
+
+	if errs := ts.ValidateGraph(); errs != nil {
+		for _, err := range errs {
+			panic(err)
+		}
+	}
+	TypeSystem = ts
+
+	Prototype.HashMapRoot = bindnode.Prototype((*HashMapRoot)(nil), ts.TypeByName("HashMapRoot"))
+	Prototype.HashMapNode = bindnode.Prototype((*HashMapNode)(nil), ts.TypeByName("HashMapNode"))
+}
@@ -0,0 +1,247 @@
+package dagpb
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+	"github.com/ipld/go-ipld-prime/traversal"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+var (
+	rawCid = cid.MustParse("bafkreifzjut3te2nhyekklss27nh3k72ysco7y32koao5eei66wof36n5e")
+	pbCid  = cid.MustParse("QmWDtUQj38YLW8v3q4A6LwPn4vYKEbuKWpgSm6bjKW6Xfe")
+)
+
+func buildNode(t *testing.T, fn func(ma datamodel.MapAssembler)) datamodel.Node {
+	n, err := qp.BuildMap(basicnode.Prototype.Any, -1, fn)
+	qt.Assert(t, err, qt.IsNil)
+	return n
+}
+
+func encode(t *testing.T, n datamodel.Node) []byte {
+	var buf bytes.Buffer
+	qt.Assert(t, Encode(n, &buf), qt.IsNil)
+	return buf.Bytes()
+}
+
+func decode(t *testing.T, b []byte) datamodel.Node {
+	nb := basicnode.Prototype.Any.NewBuilder()
+	qt.Assert(t, Decode(nb, bytes.NewReader(b)), qt.IsNil)
+	return nb.Build()
+}
+
+func TestFixtures(t *testing.T) {
+	linkBytes := func(fields string) string {
+		return "12" + hex.EncodeToString([]byte{byte(len(fields) / 2)}) + fields
+	}
+	hashField := "0a" + hex.EncodeToString([]byte{byte(len(rawCid.Bytes()))}) + hex.EncodeToString(rawCid.Bytes())
+
+	for _, tc := range []struct {
+		name string
+		node datamodel.Node
+		hex  string
+	}{
+		{"Empty", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(0, func(datamodel.ListAssembler) {}))
+		}), ""},
+		{"EmptyData", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(0, func(datamodel.ListAssembler) {}))
+			qp.MapEntry(ma, "Data", qp.Bytes([]byte{}))
+		}), "0a00"},
+		{"Data", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(0, func(datamodel.ListAssembler) {}))
+			qp.MapEntry(ma, "Data", qp.Bytes([]byte{0, 1, 2, 3, 4}))
+		}), "0a050001020304"},
+		{"LinkHashOnly", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(1, func(la datamodel.ListAssembler) {
+				qp.ListEntry(la, qp.Map(1, func(ma datamodel.MapAssembler) {
+					qp.MapEntry(ma, "Hash", qp.Link(cidlink.Link{Cid: rawCid}))
+				}))
+			}))
+		}), linkBytes(hashField)},
+		{"LinkAllFields", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(1, func(la datamodel.ListAssembler) {
+				qp.ListEntry(la, qp.Map(3, func(ma datamodel.MapAssembler) {
+					qp.MapEntry(ma, "Hash", qp.Link(cidlink.Link{Cid: rawCid}))
+					qp.MapEntry(ma, "Name", qp.String("a"))
+					qp.MapEntry(ma, "Tsize", qp.Int(300))
+				}))
+			}))
+			qp.MapEntry(ma, "Data", qp.Bytes([]byte("x")))
+		}), linkBytes(hashField+"120161"+"18ac02") + "0a0178"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Check(t, hex.EncodeToString(encode(t, tc.node)), qt.Equals, tc.hex)
+
+			b, err := hex.DecodeString(tc.hex)
+			qt.Assert(t, err, qt.IsNil)
+			qt.Check(t, datamodel.DeepEqual(decode(t, b), tc.node), qt.IsTrue)
+		})
+	}
+}
+
+func TestEncodeStrictness(t *testing.T) {
+	link := func(name string) qp.Assemble {
+		return qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Hash", qp.Link(cidlink.Link{Cid: rawCid}))
+			qp.MapEntry(ma, "Name", qp.String(name))
+		})
+	}
+	for _, tc := range []struct {
+		name string
+		node datamodel.Node
+		err  string
+	}{
+		{"NotAMap", basicnode.NewString("nope"), "dagpb: cannot encode a node of kind string.*"},
+		{"MissingLinks", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Data", qp.Bytes(nil))
+		}), `dagpb: invalid PBNode: missing required field "Links"`},
+		{"ExtraField", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(0, func(datamodel.ListAssembler) {}))
+			qp.MapEntry(ma, "Extra", qp.Int(1))
+		}), `dagpb: invalid PBNode: unexpected field "Extra"`},
+		{"DataNotBytes", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(0, func(datamodel.ListAssembler) {}))
+			qp.MapEntry(ma, "Data", qp.String("x"))
+		}), `dagpb: invalid PBNode: Data must be bytes, not string`},
+		{"LinkMissingHash", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(1, func(la datamodel.ListAssembler) {
+				qp.ListEntry(la, qp.Map(1, func(ma datamodel.MapAssembler) {
+					qp.MapEntry(ma, "Name", qp.String("a"))
+				}))
+			}))
+		}), `dagpb: invalid PBLink at index 0: missing required field "Hash"`},
+		{"LinkNegativeTsize", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(1, func(la datamodel.ListAssembler) {
+				qp.ListEntry(la, qp.Map(2, func(ma datamodel.MapAssembler) {
+					qp.MapEntry(ma, "Hash", qp.Link(cidlink.Link{Cid: rawCid}))
+					qp.MapEntry(ma, "Tsize", qp.Int(-1))
+				}))
+			}))
+		}), `dagpb: invalid PBLink at index 0: Tsize must not be negative`},
+		{"LinksUnsorted", buildNode(t, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "Links", qp.List(2, func(la datamodel.ListAssembler) {
+				qp.ListEntry(la, link("b"))
+				qp.ListEntry(la, link("a"))
+			}))
+		}), `dagpb: invalid PBNode: Links must be sorted by Name; "a" at index 1 sorts before "b"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			qt.Check(t, Encode(tc.node, &buf), qt.ErrorMatches, tc.err)
+			qt.Check(t, buf.Len(), qt.Equals, 0)
+		})
+	}
+}
+
+func TestDecodeStrictness(t *testing.T) {
+	hashField := "0a" + hex.EncodeToString([]byte{byte(len(rawCid.Bytes()))}) + hex.EncodeToString(rawCid.Bytes())
+	wrapLink := func(fields string) string {
+		return "12" + hex.EncodeToString([]byte{byte(len(fields) / 2)}) + fields
+	}
+	for _, tc := range []struct {
+		name string
+		hex  string
+		err  string
+	}{
+		{"DuplicateData", "0a00" + "0a00", "dagpb: invalid PBNode: duplicate Data field"},
+		{"LinksAfterData", "0a00" + wrapLink(hashField), "dagpb: invalid PBNode: Links must come before Data"},
+		{"UnknownField", "1801", "dagpb: invalid PBNode: unexpected field number 3 with wire type 0"},
+		{"WrongWireType", "0801", "dagpb: invalid PBNode: unexpected field number 1 with wire type 0"},
+		{"TruncatedData", "0a05000102", "dagpb: invalid PBNode: Data: length 5 exceeds remaining input: unexpected EOF"},
+		{"NonMinimalVarint", "0a8000", "dagpb: invalid PBNode: Data: varint is not minimally encoded"},
+		{"LinkMissingHash", wrapLink("120161"), "dagpb: invalid PBLink at index 0: missing required field Hash"},
+		{"LinkNameBeforeHash", wrapLink("120161" + hashField), "dagpb: invalid PBLink at index 0: Hash must come before Name and Tsize"},
+		{"LinkTsizeBeforeName", wrapLink(hashField + "1801" + "120161"), "dagpb: invalid PBLink at index 0: Name must come before Tsize"},
+		{"LinkDuplicateName", wrapLink(hashField + "120161" + "120161"), "dagpb: invalid PBLink at index 0: duplicate Name field"},
+		{"LinkBadCid", wrapLink("0a03000102"), "dagpb: invalid PBLink at index 0: Hash: invalid CID: .*"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := hex.DecodeString(tc.hex)
+			qt.Assert(t, err, qt.IsNil)
+			nb := basicnode.Prototype.Any.NewBuilder()
+			qt.Check(t, Decode(nb, bytes.NewReader(b)), qt.ErrorMatches, tc.err)
+		})
+	}
+}
+
+func TestTypedPrototype(t *testing.T) {
+	name := "file.txt"
+	tsize := int64(42)
+	data := []byte("directory")
+	orig := &PBNode{
+		Links: []PBLink{
+			{Hash: cidlink.Link{Cid: rawCid}, Name: &name, Tsize: &tsize},
+			{Hash: cidlink.Link{Cid: pbCid}},
+		},
+		Data: &data,
+	}
+	// Links with no name sort as the empty string, so the order above is not valid.
+	var buf bytes.Buffer
+	qt.Check(t, Encode(bindnode.Wrap(orig, Type.PBNode), &buf), qt.ErrorMatches, "dagpb: invalid PBNode: Links must be sorted by Name.*")
+
+	orig.Links[0], orig.Links[1] = orig.Links[1], orig.Links[0]
+	buf.Reset()
+	qt.Assert(t, Encode(bindnode.Wrap(orig, Type.PBNode), &buf), qt.IsNil)
+
+	for _, np := range []datamodel.NodePrototype{Prototype.PBNode, Prototype.PBNode.Representation()} {
+		nb := np.NewBuilder()
+		qt.Assert(t, Decode(nb, bytes.NewReader(buf.Bytes())), qt.IsNil)
+		decoded := bindnode.Unwrap(nb.Build()).(*PBNode)
+		qt.Check(t, decoded.Links, qt.HasLen, 2)
+		qt.Check(t, decoded.Links[0].Hash, qt.Equals, datamodel.Link(cidlink.Link{Cid: pbCid}))
+		qt.Check(t, decoded.Links[0].Name, qt.IsNil)
+		qt.Check(t, *decoded.Links[1].Name, qt.Equals, name)
+		qt.Check(t, *decoded.Links[1].Tsize, qt.Equals, tsize)
+		qt.Check(t, *decoded.Data, qt.DeepEquals, data)
+	}
+}
+
+func TestTraversalWithChooser(t *testing.T) {
+	lsys := cidlink.DefaultLinkSystem()
+	store := &memstore.Store{}
+	lsys.SetReadStorage(store)
+	lsys.SetWriteStorage(store)
+	lctx := linking.LinkContext{Ctx: context.Background()}
+	pblp := cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: dagpbMulticodec, MhType: 0x12, MhLength: 32}}
+
+	leafData := []byte("leaf")
+	leaf := lsys.MustStore(lctx, pblp, bindnode.Wrap(&PBNode{Links: []PBLink{}, Data: &leafData}, Type.PBNode))
+	name := "child"
+	root := lsys.MustStore(lctx, pblp, bindnode.Wrap(&PBNode{Links: []PBLink{{Hash: leaf, Name: &name}}}, Type.PBNode))
+
+	rootNode, err := lsys.Load(lctx, root, Prototype.PBNode)
+	qt.Assert(t, err, qt.IsNil)
+
+	sel, err := selectorparse.ParseAndCompileJSONSelector(`{"f":{"f>":{"Links":{"i":{"i":0,">":{"f":{"f>":{"Hash":{"f":{"f>":{"Data":{".":{}}}}}}}}}}}}}`)
+	qt.Assert(t, err, qt.IsNil)
+
+	var visited []string
+	prog := traversal.Progress{Cfg: &traversal.Config{
+		LinkSystem: lsys,
+		LinkTargetNodePrototypeChooser: AddSupportToChooser(func(datamodel.Link, linking.LinkContext) (datamodel.NodePrototype, error) {
+			return basicnode.Prototype.Any, nil
+		}),
+	}}
+	err = prog.WalkMatching(rootNode, sel, func(p traversal.Progress, n datamodel.Node) error {
+		b, err := n.AsBytes()
+		qt.Assert(t, err, qt.IsNil)
+		visited = append(visited, p.Path.String()+"="+string(b))
+		return nil
+	})
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, visited, qt.DeepEquals, []string{"Links/0/Hash/Data=leaf"})
+}
@@ -0,0 +1,45 @@
+/*
+The dagpb package provides a DAG-PB codec implementation.
+
+DAG-PB is the codec used for UnixFS data (files and directories in IPFS),
+and is identified by the multicodec indicator 0x70.
+It is a restricted subset of Protocol Buffers, which can only express one shape of data:
+
+	type PBLink struct {
+		Hash Link
+		Name optional String
+		Tsize optional Int
+	}
+
+	type PBNode struct {
+		Links [PBLink]
+		Data optional Bytes
+	}
+
+The Encode and Decode functions match the codec.Encoder and codec.Decoder function interfaces,
+and can be registered with the go-ipld-prime/multicodec package for easy usage with systems such as CIDs.
+
+Importing this package will automatically have the side-effect of registering Encode and Decode
+with the go-ipld-prime/multicodec registry, associating them with the standard multicodec indicator number for DAG-PB.
+
+This implementation follows the strict rules of the DAG-PB spec (https://ipld.io/specs/codecs/dag-pb/spec/), namely:
+
+- Encode accepts any Node that has the data model shape above,
+and nothing else: no extra map keys, no missing Links list, no Links without a Hash;
+
+- Links must be sorted by Name (bytewise, with an absent Name sorting as if it were the empty string),
+and Encode will reject a Node whose Links are not sorted (it does not sort them for you,
+since that would silently change the data);
+
+- Encode emits the Links before the Data, and fields within each link in the order Hash, Name, Tsize,
+as is required for compatibility with the byte order produced by all historical implementations;
+
+- Decode accepts only that same canonical byte order, and rejects unknown fields,
+duplicate fields, and wire types other than those the schema above implies.
+
+Decode will assemble data into any NodeAssembler which can accept the data model shape above.
+For a typed view of the data, use the prototypes in the Prototype variable,
+which are backed by the PBNode and PBLink Go types in this package (via bindnode).
+AddSupportToChooser can be used to make a traversal load DAG-PB blocks using those prototypes.
+*/
+package dagpb
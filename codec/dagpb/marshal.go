@@ -0,0 +1,179 @@
+package dagpb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// Protobuf field tags (field number and wire type) used by DAG-PB.
+const (
+	tagNodeData  = 1<<3 | 2 // PBNode.Data: bytes.
+	tagNodeLinks = 2<<3 | 2 // PBNode.Links: repeated embedded message.
+	tagLinkHash  = 1<<3 | 2 // PBLink.Hash: bytes.
+	tagLinkName  = 2<<3 | 2 // PBLink.Name: string.
+	tagLinkTsize = 3<<3 | 0 // PBLink.Tsize: varint.
+)
+
+// Encode walks the given datamodel.Node and serializes it to the given io.Writer.
+// Encode fits the codec.Encoder function interface.
+//
+// The node must have the data model shape of a PBNode (see the package docs),
+// and its Links must already be sorted; otherwise an error is returned and nothing is written.
+// Typed nodes are encoded according to their representation.
+//
+// This is the function that will be registered in the default multicodec registry during package init time.
+func Encode(n datamodel.Node, w io.Writer) error {
+	if tn, ok := n.(schema.TypedNode); ok {
+		n = tn.Representation()
+	}
+	if n.Kind() != datamodel.Kind_Map {
+		return fmt.Errorf("dagpb: cannot encode a node of kind %s; a PBNode must be a map", n.Kind())
+	}
+
+	var links, data datamodel.Node
+	for itr := n.MapIterator(); !itr.Done(); {
+		k, v, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			return err
+		}
+		if v.IsAbsent() {
+			continue
+		}
+		switch ks {
+		case "Links":
+			links = v
+		case "Data":
+			data = v
+		default:
+			return fmt.Errorf("dagpb: invalid PBNode: unexpected field %q", ks)
+		}
+	}
+	if links == nil {
+		return fmt.Errorf("dagpb: invalid PBNode: missing required field \"Links\"")
+	}
+	if links.Kind() != datamodel.Kind_List {
+		return fmt.Errorf("dagpb: invalid PBNode: Links must be a list, not %s", links.Kind())
+	}
+
+	var buf bytes.Buffer
+	var linkBuf bytes.Buffer
+	var prevName string
+	for itr := links.ListIterator(); !itr.Done(); {
+		idx, link, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		linkBuf.Reset()
+		name, err := encodeLink(link, &linkBuf)
+		if err != nil {
+			return fmt.Errorf("dagpb: invalid PBLink at index %d: %w", idx, err)
+		}
+		if idx > 0 && name < prevName {
+			return fmt.Errorf("dagpb: invalid PBNode: Links must be sorted by Name; %q at index %d sorts before %q", name, idx, prevName)
+		}
+		prevName = name
+		appendVarint(&buf, tagNodeLinks)
+		appendVarint(&buf, uint64(linkBuf.Len()))
+		buf.Write(linkBuf.Bytes())
+	}
+	if data != nil {
+		b, err := data.AsBytes()
+		if err != nil {
+			return fmt.Errorf("dagpb: invalid PBNode: Data must be bytes, not %s", data.Kind())
+		}
+		appendVarint(&buf, tagNodeData)
+		appendVarint(&buf, uint64(len(b)))
+		buf.Write(b)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// encodeLink writes the protobuf form of a single PBLink,
+// returning the link's name (or the empty string if it has none) so that the caller can check sort order.
+func encodeLink(n datamodel.Node, buf *bytes.Buffer) (string, error) {
+	if n.Kind() != datamodel.Kind_Map {
+		return "", fmt.Errorf("must be a map, not %s", n.Kind())
+	}
+	var hash, name, tsize datamodel.Node
+	for itr := n.MapIterator(); !itr.Done(); {
+		k, v, err := itr.Next()
+		if err != nil {
+			return "", err
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			return "", err
+		}
+		if v.IsAbsent() {
+			continue
+		}
+		switch ks {
+		case "Hash":
+			hash = v
+		case "Name":
+			name = v
+		case "Tsize":
+			tsize = v
+		default:
+			return "", fmt.Errorf("unexpected field %q", ks)
+		}
+	}
+
+	if hash == nil {
+		return "", fmt.Errorf("missing required field \"Hash\"")
+	}
+	lnk, err := hash.AsLink()
+	if err != nil {
+		return "", fmt.Errorf("Hash must be a link, not %s", hash.Kind())
+	}
+	cl, ok := lnk.(cidlink.Link)
+	if !ok {
+		return "", fmt.Errorf("Hash must be a cidlink.Link, not %T", lnk)
+	}
+	hashBytes := cl.Cid.Bytes()
+	appendVarint(buf, tagLinkHash)
+	appendVarint(buf, uint64(len(hashBytes)))
+	buf.Write(hashBytes)
+
+	var nameStr string
+	if name != nil {
+		nameStr, err = name.AsString()
+		if err != nil {
+			return "", fmt.Errorf("Name must be a string, not %s", name.Kind())
+		}
+		appendVarint(buf, tagLinkName)
+		appendVarint(buf, uint64(len(nameStr)))
+		buf.WriteString(nameStr)
+	}
+
+	if tsize != nil {
+		ts, err := tsize.AsInt()
+		if err != nil {
+			return "", fmt.Errorf("Tsize must be an int, not %s", tsize.Kind())
+		}
+		if ts < 0 {
+			return "", fmt.Errorf("Tsize must not be negative")
+		}
+		appendVarint(buf, tagLinkTsize)
+		appendVarint(buf, uint64(ts))
+	}
+
+	return nameStr, nil
+}
+
+func appendVarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	buf.Write(tmp[:binary.PutUvarint(tmp[:], v)])
+}
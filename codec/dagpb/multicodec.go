@@ -0,0 +1,18 @@
+package dagpb
+
+import (
+	"github.com/ipld/go-ipld-prime/codec"
+	"github.com/ipld/go-ipld-prime/multicodec"
+)
+
+const dagpbMulticodec = 0x70
+
+var (
+	_ codec.Decoder = Decode
+	_ codec.Encoder = Encode
+)
+
+func init() {
+	multicodec.RegisterEncoder(dagpbMulticodec, Encode)
+	multicodec.RegisterDecoder(dagpbMulticodec, Decode)
+}
@@ -0,0 +1,100 @@
+package dagpb
+
+import (
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/traversal"
+)
+
+// PBNode is the Go type which Prototype.PBNode binds to.
+// It can be used directly with bindnode.Wrap and bindnode.Unwrap.
+type PBNode struct {
+	Links []PBLink
+	Data  *[]byte
+}
+
+// PBLink is the Go type which Prototype.PBLink binds to.
+type PBLink struct {
+	Hash  datamodel.Link
+	Name  *string
+	Tsize *int64
+}
+
+// TypeSystem is the compiled form of the DAG-PB schema (see the package docs).
+var TypeSystem schema.TypeSystem
+
+// Type contains the schema types of the DAG-PB schema.
+var Type struct {
+	PBNode  schema.Type
+	PBLinks schema.Type
+	PBLink  schema.Type
+}
+
+// Prototype contains schema.TypedPrototype values for the DAG-PB schema.
+// They create nodes backed by the PBNode and PBLink Go types in this package.
+//
+// Decode works well with these prototypes:
+// the typed nodes built by them (or by their Representation) can be given straight to Encode, too.
+var Prototype struct {
+	PBNode schema.TypedPrototype
+	PBLink schema.TypedPrototype
+}
+
+func init() {
+	var ts schema.TypeSystem
+	ts.Init()
+
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnInt("Int"))
+	ts.Accumulate(schema.SpawnBytes("Bytes"))
+	ts.Accumulate(schema.SpawnLink("Link"))
+
+	ts.Accumulate(schema.SpawnStruct("PBLink",
+		[]schema.StructField{
+			schema.SpawnStructField("Hash", "Link", false, false),
+			schema.SpawnStructField("Name", "String", true, false),
+			schema.SpawnStructField("Tsize", "Int", true, false),
+		},
+		schema.StructRepresentation_Map{},
+	))
+	ts.Accumulate(schema.SpawnList("PBLinks", "PBLink", false))
+	ts.Accumulate(schema.SpawnStruct("PBNode",
+		[]schema.StructField{
+			schema.SpawnStructField("Links", "PBLinks", false, false),
+			schema.SpawnStructField("Data", "Bytes", true, false),
+		},
+		schema.StructRepresentation_Map{},
+	))
+
+	if errs := ts.ValidateGraph(); errs != nil {
+		for _, err := range errs {
+			panic(err)
+		}
+	}
+	TypeSystem = ts
+
+	Type.PBNode = ts.TypeByName("PBNode")
+	Type.PBLinks = ts.TypeByName("PBLinks")
+	Type.PBLink = ts.TypeByName("PBLink")
+
+	Prototype.PBNode = bindnode.Prototype((*PBNode)(nil), Type.PBNode)
+	Prototype.PBLink = bindnode.Prototype((*PBLink)(nil), Type.PBLink)
+}
+
+// AddSupportToChooser takes an existing LinkTargetNodePrototypeChooser and returns one which
+// answers with Prototype.PBNode for any link with the DAG-PB multicodec,
+// and defers to the existing chooser for all other links.
+//
+// This lets a traversal over a mixed graph load DAG-PB blocks as typed nodes,
+// so that e.g. selectors can address the fields of a PBNode by name.
+func AddSupportToChooser(existing traversal.LinkTargetNodePrototypeChooser) traversal.LinkTargetNodePrototypeChooser {
+	return func(lnk datamodel.Link, lnkCtx linking.LinkContext) (datamodel.NodePrototype, error) {
+		if cl, ok := lnk.(cidlink.Link); ok && cl.Cid.Prefix().Codec == dagpbMulticodec {
+			return Prototype.PBNode, nil
+		}
+		return existing(lnk, lnkCtx)
+	}
+}
@@ -0,0 +1,261 @@
+package dagpb
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+)
+
+// pbLink and pbNode are the decoded forms of the protobuf messages, before they're fed into an assembler.
+type pbLink struct {
+	hash     cid.Cid
+	name     string
+	hasName  bool
+	tsize    uint64
+	hasTsize bool
+}
+
+type pbNode struct {
+	links   []pbLink
+	data    []byte
+	hasData bool
+}
+
+// Decode deserializes data from the given io.Reader and feeds it into the given datamodel.NodeAssembler.
+// Decode fits the codec.Decoder function interface.
+//
+// The data is assembled as a map with a "Links" list, followed by a "Data" entry if present;
+// each link is assembled as a map with a "Hash" entry, followed by "Name" and "Tsize" entries if present.
+// Bytes which are not in the canonical form described by the DAG-PB spec are rejected.
+//
+// This is the function that will be registered in the default multicodec registry during package init time.
+func Decode(na datamodel.NodeAssembler, r io.Reader) error {
+	var src []byte
+	if buf, ok := r.(interface{ Bytes() []byte }); ok {
+		src = buf.Bytes()
+	} else {
+		var err error
+		src, err = io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+	}
+	node, err := unmarshalNode(src)
+	if err != nil {
+		return err
+	}
+	return assembleNode(na, node)
+}
+
+func unmarshalNode(src []byte) (pbNode, error) {
+	var node pbNode
+	for len(src) > 0 {
+		tag, n, err := readVarint(src)
+		if err != nil {
+			return node, fmt.Errorf("dagpb: invalid PBNode: %w", err)
+		}
+		src = src[n:]
+		switch tag {
+		case tagNodeData:
+			if node.hasData {
+				return node, fmt.Errorf("dagpb: invalid PBNode: duplicate Data field")
+			}
+			chunk, n, err := readLengthDelimited(src)
+			if err != nil {
+				return node, fmt.Errorf("dagpb: invalid PBNode: Data: %w", err)
+			}
+			src = src[n:]
+			node.data = chunk
+			node.hasData = true
+		case tagNodeLinks:
+			if node.hasData {
+				return node, fmt.Errorf("dagpb: invalid PBNode: Links must come before Data")
+			}
+			chunk, n, err := readLengthDelimited(src)
+			if err != nil {
+				return node, fmt.Errorf("dagpb: invalid PBNode: Links: %w", err)
+			}
+			src = src[n:]
+			link, err := unmarshalLink(chunk)
+			if err != nil {
+				return node, fmt.Errorf("dagpb: invalid PBLink at index %d: %w", len(node.links), err)
+			}
+			node.links = append(node.links, link)
+		default:
+			return node, fmt.Errorf("dagpb: invalid PBNode: unexpected field number %d with wire type %d", tag>>3, tag&7)
+		}
+	}
+	return node, nil
+}
+
+func unmarshalLink(src []byte) (pbLink, error) {
+	var link pbLink
+	var hasHash bool
+	for len(src) > 0 {
+		tag, n, err := readVarint(src)
+		if err != nil {
+			return link, err
+		}
+		src = src[n:]
+		switch tag {
+		case tagLinkHash:
+			if hasHash {
+				return link, fmt.Errorf("duplicate Hash field")
+			}
+			if link.hasName || link.hasTsize {
+				return link, fmt.Errorf("Hash must come before Name and Tsize")
+			}
+			chunk, n, err := readLengthDelimited(src)
+			if err != nil {
+				return link, fmt.Errorf("Hash: %w", err)
+			}
+			src = src[n:]
+			c, err := cid.Cast(chunk)
+			if err != nil {
+				return link, fmt.Errorf("Hash: invalid CID: %w", err)
+			}
+			link.hash = c
+			hasHash = true
+		case tagLinkName:
+			if link.hasName {
+				return link, fmt.Errorf("duplicate Name field")
+			}
+			if link.hasTsize {
+				return link, fmt.Errorf("Name must come before Tsize")
+			}
+			chunk, n, err := readLengthDelimited(src)
+			if err != nil {
+				return link, fmt.Errorf("Name: %w", err)
+			}
+			src = src[n:]
+			link.name = string(chunk)
+			link.hasName = true
+		case tagLinkTsize:
+			if link.hasTsize {
+				return link, fmt.Errorf("duplicate Tsize field")
+			}
+			v, n, err := readVarint(src)
+			if err != nil {
+				return link, fmt.Errorf("Tsize: %w", err)
+			}
+			if v > math.MaxInt64 {
+				return link, fmt.Errorf("Tsize: value %d is too large", v)
+			}
+			src = src[n:]
+			link.tsize = v
+			link.hasTsize = true
+		default:
+			return link, fmt.Errorf("unexpected field number %d with wire type %d", tag>>3, tag&7)
+		}
+	}
+	if !hasHash {
+		return link, fmt.Errorf("missing required field Hash")
+	}
+	return link, nil
+}
+
+func readVarint(src []byte) (uint64, int, error) {
+	v, n := binary.Uvarint(src)
+	if n <= 0 {
+		return 0, 0, fmt.Errorf("invalid varint")
+	}
+	if n > 1 && src[n-1] == 0 {
+		return 0, 0, fmt.Errorf("varint is not minimally encoded")
+	}
+	return v, n, nil
+}
+
+func readLengthDelimited(src []byte) ([]byte, int, error) {
+	length, n, err := readVarint(src)
+	if err != nil {
+		return nil, 0, err
+	}
+	if length > uint64(len(src)-n) {
+		return nil, 0, fmt.Errorf("length %d exceeds remaining input: %w", length, io.ErrUnexpectedEOF)
+	}
+	end := n + int(length)
+	return src[n:end:end], end, nil
+}
+
+func assembleNode(na datamodel.NodeAssembler, node pbNode) error {
+	size := int64(1)
+	if node.hasData {
+		size++
+	}
+	ma, err := na.BeginMap(size)
+	if err != nil {
+		return err
+	}
+	va, err := ma.AssembleEntry("Links")
+	if err != nil {
+		return err
+	}
+	la, err := va.BeginList(int64(len(node.links)))
+	if err != nil {
+		return err
+	}
+	for _, link := range node.links {
+		if err := assembleLink(la.AssembleValue(), link); err != nil {
+			return err
+		}
+	}
+	if err := la.Finish(); err != nil {
+		return err
+	}
+	if node.hasData {
+		va, err := ma.AssembleEntry("Data")
+		if err != nil {
+			return err
+		}
+		if err := va.AssignBytes(node.data); err != nil {
+			return err
+		}
+	}
+	return ma.Finish()
+}
+
+func assembleLink(na datamodel.NodeAssembler, link pbLink) error {
+	size := int64(1)
+	if link.hasName {
+		size++
+	}
+	if link.hasTsize {
+		size++
+	}
+	ma, err := na.BeginMap(size)
+	if err != nil {
+		return err
+	}
+	va, err := ma.AssembleEntry("Hash")
+	if err != nil {
+		return err
+	}
+	if err := va.AssignLink(cidlink.Link{Cid: link.hash}); err != nil {
+		return err
+	}
+	if link.hasName {
+		va, err := ma.AssembleEntry("Name")
+		if err != nil {
+			return err
+		}
+		if err := va.AssignString(link.name); err != nil {
+			return err
+		}
+	}
+	if link.hasTsize {
+		va, err := ma.AssembleEntry("Tsize")
+		if err != nil {
+			return err
+		}
+		if err := va.AssignInt(int64(link.tsize)); err != nil {
+			return err
+		}
+	}
+	return ma.Finish()
+}
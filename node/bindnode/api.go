@@ -5,7 +5,9 @@
 package bindnode
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime/datamodel"
@@ -15,14 +17,37 @@ import (
 // Prototype implements a schema.TypedPrototype given a Go pointer type and an
 // IPLD schema type. Note that the result is also a datamodel.NodePrototype.
 //
-// If both the Go type and schema type are supplied, it is assumed that they are
-// compatible with one another.
+// If both the Go type and schema type are supplied, they must be compatible
+// with one another; otherwise Prototype panics with the IncompatibilityError
+// which CheckCompatibility would return.
 //
 // If either the Go type or schema type are nil, we infer the missing type from
 // the other provided type. For example, we can infer an unnamed Go struct type
 // for a schema struct type, and we can infer a schema Int type for a Go int64
 // type. The inferring logic is still a work in progress and subject to change.
-// At this time, inferring IPLD Unions and Enums from Go types is not supported.
+//
+// When inferring a schema from Go types, struct fields may be tagged much like
+// with encoding/json. A tag such as `ipld:"name,optional,nullable"` renames the
+// field in its map representation, and makes it optional or nullable.
+// Optional or nullable fields must be nilable, such as pointers,
+// and fields which are both must be double pointers.
+// A blank field, declared as "_ struct{}", holds the tags which apply to the
+// whole struct type: `ipld:"tuple"` gives it a tuple representation, and
+// `ipld:"union,keyed"` or `ipld:"union,kinded"` make it a union
+// whose members are the types its pointer fields point to.
+// The key of a keyed union member is its field's tag name,
+// or the name of the member type if there is none.
+// Enums are inferred from the Go types registered via TypedEnum.
+// Schema types are named after the Go types they're inferred from, so
+// inferring from two different Go types of the same name, such as types
+// declared in different packages or functions, panics.
+//
+// Values of schema types with an advanced representation, such as
+// `type Big {String:Int} representation advanced HAMT`, are held as a
+// datamodel.Node in Go, just like Any: bindnode keeps the ADL's substrate
+// as it is found in the data, leaving reification to whatever implements the ADL.
+// The traversal package does so when loading links to such types,
+// using the NodeReifiers registered in the LinkSystem's KnownReifiers.
 //
 // When supplying a non-nil ptrType, Prototype only obtains the Go pointer type
 // from it, so its underlying value will typically be nil. For example:
@@ -35,8 +60,6 @@ func Prototype(ptrType interface{}, schemaType schema.Type, options ...Option) s
 
 	cfg := applyOptions(options...)
 
-	// TODO: if both are supplied, verify that they are compatible
-
 	var goType reflect.Type
 	if ptrType == nil {
 		goType = inferGoType(schemaType, make(map[schema.TypeName]inferredStatus), 0)
@@ -51,15 +74,80 @@ func Prototype(ptrType interface{}, schemaType schema.Type, options ...Option) s
 		}
 
 		if schemaType == nil {
-			schemaType = inferSchema(goType, 0)
-		} else {
-			verifyCompatibility(cfg, make(map[seenEntry]bool), goType, schemaType)
+			schemaType = inferSchema(cfg, goType)
+		} else if err := checkCompatibility(cfg, goType, schemaType); err != nil {
+			panic(err)
 		}
 	}
 
 	return &_prototype{cfg: cfg, schemaType: schemaType, goType: goType}
 }
 
+// CheckCompatibility reports whether the Go type of ptrType, which is supplied
+// just like with Prototype, can be used with the schema type and options.
+// Prototype and Wrap panic when the two are not compatible;
+// CheckCompatibility returns an IncompatibilityError instead,
+// listing every mismatch between them rather than just the first.
+func CheckCompatibility(ptrType interface{}, schemaType schema.Type, options ...Option) error {
+	if ptrType == nil || schemaType == nil {
+		return fmt.Errorf("bindnode: both ptrType and schemaType must not be nil")
+	}
+	goPtrType := reflect.TypeOf(ptrType)
+	if goPtrType.Kind() != reflect.Ptr {
+		return fmt.Errorf("bindnode: ptrType must be a pointer")
+	}
+	goType := goPtrType.Elem()
+	if goType.Kind() == reflect.Ptr {
+		return fmt.Errorf("bindnode: ptrType must not be a pointer to a pointer")
+	}
+	return checkCompatibility(applyOptions(options...), goType, schemaType)
+}
+
+// Incompatibility is a mismatch between a Go type and a schema type,
+// as found by CheckCompatibility.
+//
+// GoPath and SchemaPath lead to the mismatching types from the ones which were
+// checked, and are empty when those mismatch. ".Name" steps into a struct field
+// or union member, "[]" into a list's values, and "[key]" or "[value]" into a
+// map's keys or values. For example, a Go path ".Values[value].Name" can go
+// along with a schema path "[value].name".
+type Incompatibility struct {
+	GoPath     string
+	GoType     reflect.Type
+	SchemaPath string
+	SchemaType schema.Type
+
+	// Reason describes the mismatch, such as "kind mismatch; need string".
+	Reason string
+}
+
+func (inc Incompatibility) String() string {
+	msg := fmt.Sprintf("schema type %s is not compatible with Go type %s: %s", inc.SchemaType.Name(), inc.GoType, inc.Reason)
+	if inc.GoPath == "" && inc.SchemaPath == "" {
+		return msg
+	}
+	return fmt.Sprintf("at %s (Go %s): %s", inc.SchemaPath, inc.GoPath, msg)
+}
+
+// IncompatibilityError is returned by CheckCompatibility
+// when a Go type cannot be used with a schema type.
+type IncompatibilityError struct {
+	Incompatibilities []Incompatibility
+}
+
+func (e IncompatibilityError) Error() string {
+	if len(e.Incompatibilities) == 1 {
+		return "bindnode: " + e.Incompatibilities[0].String()
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "bindnode: found %d incompatibilities between the schema and Go types:", len(e.Incompatibilities))
+	for _, inc := range e.Incompatibilities {
+		sb.WriteString("\n\t")
+		sb.WriteString(inc.String())
+	}
+	return sb.String()
+}
+
 type converter struct {
 	kind schema.TypeKind
 
@@ -88,6 +176,14 @@ type converter struct {
 type config struct {
 	namedConverters map[schema.TypeName]*converter
 	typeConverters  map[reflect.Type]*converter
+	enums           map[reflect.Type]*enum
+}
+
+// enum holds what TypedEnum registered for a Go type,
+// ready to spawn a schema enum with when inferring a schema.
+type enum struct {
+	members []string
+	repr    schema.EnumRepresentation
 }
 
 // this mainly exists to short-circuit the nonPtrType() call; the `Type()` variant
@@ -115,6 +211,13 @@ func (c *config) converterForType(typeName schema.TypeName, typ reflect.Type) *c
 	return c.typeConverters[typ]
 }
 
+func (c *config) enumForType(typ reflect.Type) *enum {
+	if c == nil {
+		return nil
+	}
+	return c.enums[typ]
+}
+
 // Option is able to apply custom options to the bindnode API
 type Option func(*config)
 
@@ -405,6 +508,57 @@ func NamedAnyConverter(typeName schema.TypeName, from func(datamodel.Node) (inte
 	}
 }
 
+// TypedEnum registers the members of an enum for a Go type,
+// as identified by a pointer in the first argument,
+// so that inferring a schema from Go types produces an enum for it.
+// The members must be values of that type; typically, its constants.
+// Go reflection cannot list the constants of a type, hence the registration.
+//
+// A Go string type results in an enum whose members are named by their values.
+// A Go integer type results in an enum with an int representation,
+// where each member is named by its String method, such as one generated by
+// the stringer tool, and is represented by its value.
+//
+// TypedEnum is an EXPERIMENTAL API and may be removed or
+// changed in a future release.
+func TypedEnum(ptrVal interface{}, members ...interface{}) Option {
+	customType := nonPtrType(reflect.ValueOf(ptrVal))
+	e := &enum{}
+	switch kind := customType.Kind(); {
+	case kind == reflect.String:
+		e.repr = schema.EnumRepresentation_String{}
+	case kindInt[kind], kindUint[kind]:
+		e.repr = schema.EnumRepresentation_Int{}
+	default:
+		panic(fmt.Sprintf("bindnode: enum type %s must be a string or integer type", customType))
+	}
+	for _, member := range members {
+		val := reflect.ValueOf(member)
+		if val.Type() != customType {
+			panic(fmt.Sprintf("bindnode: enum member %v must be of type %s, not %s", member, customType, val.Type()))
+		}
+		switch repr := e.repr.(type) {
+		case schema.EnumRepresentation_String:
+			e.members = append(e.members, val.String())
+		case schema.EnumRepresentation_Int:
+			stringer, ok := member.(fmt.Stringer)
+			if !ok {
+				panic(fmt.Sprintf("bindnode: integer enum type %s must implement fmt.Stringer to name its members", customType))
+			}
+			name := stringer.String()
+			if kindInt[customType.Kind()] {
+				repr[name] = int(val.Int())
+			} else {
+				repr[name] = int(val.Uint())
+			}
+			e.members = append(e.members, name)
+		}
+	}
+	return func(cfg *config) {
+		cfg.enums[customType] = e
+	}
+}
+
 func applyOptions(opt ...Option) *config {
 	if len(opt) == 0 {
 		// no need to allocate, we access it via converterFor and converterForType
@@ -414,6 +568,7 @@ func applyOptions(opt ...Option) *config {
 	cfg := &config{
 		namedConverters: make(map[string]*converter),
 		typeConverters:  make(map[reflect.Type]*converter),
+		enums:           make(map[reflect.Type]*enum),
 	}
 
 	for _, o := range opt {
@@ -428,7 +583,7 @@ func applyOptions(opt ...Option) *config {
 // Wrap is meant to be used when one already has a Go value with data.
 // As such, ptrVal must not be nil.
 //
-// Similar to Prototype, if schemaType is non-nil it is assumed to be compatible
+// Similar to Prototype, if schemaType is non-nil it must be compatible
 // with the Go type, and otherwise it's inferred from the Go type.
 func Wrap(ptrVal interface{}, schemaType schema.Type, options ...Option) schema.TypedNode {
 	if ptrVal == nil {
@@ -448,14 +603,16 @@ func Wrap(ptrVal interface{}, schemaType schema.Type, options ...Option) schema.
 		panic("bindnode: ptrVal must not be a pointer to a pointer")
 	}
 	if schemaType == nil {
-		schemaType = inferSchema(goVal.Type(), 0)
+		schemaType = inferSchema(cfg, goVal.Type())
 	} else {
 		// TODO(rvagg): explore ways to make this skippable by caching in the schema.Type
 		// passed in to this function; e.g. if you call Prototype(), then you've gone through
 		// this already, then calling .Type() on that could return a bindnode version of
 		// schema.Type that has the config cached and can be assumed to have been checked or
 		// inferred.
-		verifyCompatibility(cfg, make(map[seenEntry]bool), goVal.Type(), schemaType)
+		if err := checkCompatibility(cfg, goVal.Type(), schemaType); err != nil {
+			panic(err)
+		}
 	}
 	return newNode(cfg, schemaType, goVal)
 }
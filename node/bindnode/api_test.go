@@ -3,14 +3,19 @@ package bindnode_test
 import (
 	"encoding/hex"
 	"math"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec"
 	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
 	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/must"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
 	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/schema"
 )
 
 func TestEnumError(t *testing.T) {
@@ -272,3 +277,237 @@ func TestUint64Struct(t *testing.T) {
 		qt.Assert(t, hex.EncodeToString(byt), qt.Equals, maxExpectedHex)
 	})
 }
+
+func TestAdvancedLayoutHeldAsNode(t *testing.T) {
+	typeSystem, err := ipld.LoadSchemaBytes([]byte(`
+		advanced Chunked
+		type Blob bytes representation advanced Chunked
+		type S struct {
+			name String
+			blob Blob
+			blobs [Blob]
+		}
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	schemaType := typeSystem.TypeByName("S")
+
+	// The data for a Blob is whatever the ADL's substrate is; here, a map of chunks, and plain bytes.
+	const encoded = `{"blob":{"chunks":["YQ","Yg"]},"blobs":[{"/":{"bytes":"Yw"}},{"chunks":[]}],"name":"x"}`
+
+	type S struct {
+		Name  string
+		Blob  datamodel.Node
+		Blobs []datamodel.Node
+	}
+	node, err := ipld.DecodeUsingPrototype([]byte(encoded), dagjson.Decode, bindnode.Prototype((*S)(nil), schemaType).Representation())
+	qt.Assert(t, err, qt.IsNil)
+	s := bindnode.Unwrap(node).(*S)
+	qt.Check(t, s.Name, qt.Equals, "x")
+	qt.Check(t, s.Blob.Kind(), qt.Equals, datamodel.Kind_Map)
+	qt.Assert(t, s.Blobs, qt.HasLen, 2)
+	qt.Check(t, s.Blobs[0].Kind(), qt.Equals, datamodel.Kind_Bytes)
+	enc, err := ipld.Encode(node, dagjson.Encode)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, string(enc), qt.Equals, encoded)
+
+	// The same Go type is inferred from the schema.
+	inferred := bindnode.Prototype(nil, schemaType).NewBuilder()
+	qt.Assert(t, dagjson.Decode(inferred, strings.NewReader(encoded)), qt.IsNil)
+
+	// And no other Go type is accepted.
+	type Bad struct {
+		Name  string
+		Blob  []byte
+		Blobs [][]byte
+	}
+	qt.Check(t, func() { bindnode.Prototype((*Bad)(nil), schemaType) }, qt.PanicMatches,
+		`.*schema type Blob is not compatible with Go type \[\]uint8: types with an advanced representation must be datamodel.Node in Go`)
+}
+
+func TestInlineUnion(t *testing.T) {
+	typeSystem, err := ipld.LoadSchemaBytes([]byte(`
+		type Pet union {
+			| Cat "cat"
+			| Dog "dog"
+		} representation inline {
+			discriminantKey "kind"
+		}
+		type Cat struct {
+			name String (rename "n")
+			lives optional Int
+		}
+		type Dog struct {
+			name String
+		}
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	schemaType := typeSystem.TypeByName("Pet")
+
+	type Cat struct {
+		Name  string
+		Lives *int64
+	}
+	type Dog struct{ Name string }
+	type Pet struct {
+		Cat *Cat
+		Dog *Dog
+	}
+	proto := bindnode.Prototype((*Pet)(nil), schemaType)
+
+	// The discriminant may come first, last, or anywhere in between;
+	// it's always encoded first.
+	for _, encoded := range []string{
+		`{"kind":"cat","n":"Tom","lives":9}`,
+		`{"n":"Tom","lives":9,"kind":"cat"}`,
+		`{"n":"Tom","kind":"cat","lives":9}`,
+	} {
+		node, err := ipld.DecodeUsingPrototype([]byte(encoded), dagjson.Decode, proto.Representation())
+		qt.Assert(t, err, qt.IsNil, qt.Commentf("%s", encoded))
+		pet := bindnode.Unwrap(node).(*Pet)
+		qt.Assert(t, pet.Cat, qt.Not(qt.IsNil))
+		qt.Check(t, pet.Cat.Name, qt.Equals, "Tom")
+		qt.Check(t, *pet.Cat.Lives, qt.Equals, int64(9))
+
+		enc, err := ipld.Encode(node.(schema.TypedNode).Representation(), dagjson.EncodeOptions{MapSortMode: codec.MapSortMode_None}.Encode)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, string(enc), qt.Equals, `{"kind":"cat","n":"Tom","lives":9}`)
+	}
+
+	node := bindnode.Wrap(&Pet{Dog: &Dog{Name: "Rex"}}, schemaType).Representation()
+	qt.Check(t, node.Length(), qt.Equals, int64(2))
+	kind, err := node.LookupByString("kind")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, must.String(kind), qt.Equals, "dog")
+	name, err := node.LookupByString("name")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, must.String(name), qt.Equals, "Rex")
+
+	for _, tc := range []struct{ encoded, err string }{
+		{`{"n":"Tom"}`, `.*an inline union needs the "kind" key`},
+		{`{"kind":"cow","name":"Daisy"}`, `.*no member with discriminant "cow"`},
+		{`{"kind":"dog","kind":"dog","name":"Rex"}`, `cannot repeat map key .*`},
+		{`{"kind":"dog","name":"Rex","color":"brown"}`, `.*invalid key.*"color".*`},
+	} {
+		_, err := ipld.DecodeUsingPrototype([]byte(tc.encoded), dagjson.Decode, proto.Representation())
+		qt.Check(t, err, qt.ErrorMatches, tc.err, qt.Commentf("%s", tc.encoded))
+	}
+
+	// After an unknown discriminant, finishing fails rather than panicking.
+	ma, err := proto.Representation().NewBuilder().BeginMap(1)
+	qt.Assert(t, err, qt.IsNil)
+	asm, err := ma.AssembleEntry("kind")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, asm.AssignString("cow"), qt.ErrorMatches, `.*no member with discriminant "cow"`)
+	qt.Check(t, ma.Finish(), qt.ErrorMatches, `.*an inline union needs the "kind" key`)
+
+	// Only structs with a map representation can be members of inline unions.
+	typeSystem, err = ipld.LoadSchemaBytes([]byte(`
+		type Bad union {
+			| Name "name"
+		} representation inline {
+			discriminantKey "kind"
+		}
+		type Name string
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	schemaType = typeSystem.TypeByName("Bad")
+	const badErr = `.*member Name of an inline union must be a struct with a map representation, but its representation is of kind string`
+	_, err = ipld.DecodeUsingPrototype([]byte(`{"kind":"name"}`), dagjson.Decode, bindnode.Prototype(nil, schemaType).Representation())
+	qt.Check(t, err, qt.ErrorMatches, badErr)
+
+	name2 := "x"
+	type Bad struct{ Name *string }
+	_, err = ipld.Encode(bindnode.Wrap(&Bad{Name: &name2}, schemaType).Representation(), dagjson.Encode)
+	qt.Check(t, err, qt.ErrorMatches, badErr)
+}
+
+func TestBytesPrefixUnionOverlapping(t *testing.T) {
+	// One prefix may be the start of another.
+	var ts schema.TypeSystem
+	ts.Init()
+	ts.Accumulate(schema.SpawnBytes("Short"))
+	ts.Accumulate(schema.SpawnBytes("Long"))
+	ts.Accumulate(schema.SpawnUnion("Prefixed",
+		[]schema.TypeName{"Short", "Long"},
+		schema.SpawnUnionRepresentationBytesPrefix(map[string]schema.TypeName{
+			"01":   "Short",
+			"0102": "Long",
+		}),
+	))
+	schemaType := ts.TypeByName("Prefixed")
+
+	type Prefixed struct {
+		Short *[]byte
+		Long  *[]byte
+	}
+	proto := bindnode.Prototype((*Prefixed)(nil), schemaType)
+
+	// Just like schema.Validate, the longest matching prefix wins.
+	for _, tc := range []struct {
+		data        []byte
+		short, long []byte
+	}{
+		{[]byte{0x01, 0x02, 0x03}, nil, []byte{0x03}},
+		{[]byte{0x01, 0x03}, []byte{0x03}, nil},
+	} {
+		nb := proto.Representation().NewBuilder()
+		qt.Assert(t, nb.AssignBytes(tc.data), qt.IsNil)
+		node := nb.Build()
+		p := bindnode.Unwrap(node).(*Prefixed)
+		if tc.long != nil {
+			qt.Assert(t, p.Long, qt.Not(qt.IsNil), qt.Commentf("%x", tc.data))
+			qt.Check(t, *p.Long, qt.DeepEquals, tc.long)
+			qt.Check(t, p.Short, qt.IsNil)
+		} else {
+			qt.Assert(t, p.Short, qt.Not(qt.IsNil), qt.Commentf("%x", tc.data))
+			qt.Check(t, *p.Short, qt.DeepEquals, tc.short)
+			qt.Check(t, p.Long, qt.IsNil)
+		}
+		qt.Check(t, schema.Validate(&ts, schemaType, basicnode.NewBytes(tc.data)), qt.HasLen, 0)
+		encoded, err := node.(schema.TypedNode).Representation().AsBytes()
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, encoded, qt.DeepEquals, tc.data)
+	}
+}
+
+func TestTupleFieldOrder(t *testing.T) {
+	type S struct {
+		A string
+		B int64
+		C *string
+	}
+	typeSystem, err := ipld.LoadSchemaBytes([]byte(`
+		type S struct {
+			a String
+			b Int
+			c optional String
+		} representation tuple {
+			fieldOrder ["b", "a", "c"]
+		}
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	schemaType := typeSystem.TypeByName("S")
+
+	c := "z"
+	for _, tc := range []struct {
+		encoded string
+		value   S
+	}{
+		{`[1,"x"]`, S{A: "x", B: 1}},
+		{`[2,"y","z"]`, S{A: "y", B: 2, C: &c}},
+	} {
+		var s S
+		node, err := ipld.Unmarshal([]byte(tc.encoded), dagjson.Decode, &s, schemaType)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, s, qt.DeepEquals, tc.value)
+		qt.Check(t, schema.Validate(typeSystem, schemaType, node.(schema.TypedNode).Representation()), qt.HasLen, 0)
+
+		first, err := node.(schema.TypedNode).Representation().LookupByIndex(0)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, must.Int(first), qt.Equals, tc.value.B)
+
+		encoded, err := ipld.Marshal(dagjson.Encode, &s, schemaType)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, string(encoded), qt.Equals, tc.encoded)
+	}
+}
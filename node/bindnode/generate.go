@@ -21,6 +21,9 @@ func produceGoType(goTypes map[string]string, typ schema.Type) (name, src string
 
 	name = string(typ.Name())
 
+	if heldAsNode(typ) {
+		return goTypeNode.String(), "" // datamodel.Node
+	}
 	switch typ.(type) {
 	case *schema.TypeBool:
 		return goTypeBool.String(), ""
@@ -34,8 +37,6 @@ func produceGoType(goTypes map[string]string, typ schema.Type) (name, src string
 		return goTypeBytes.String(), ""
 	case *schema.TypeLink:
 		return goTypeLink.String(), "" // datamodel.Link
-	case *schema.TypeAny:
-		return goTypeNode.String(), "" // datamodel.Node
 	}
 
 	// Results are cached in goTypes.
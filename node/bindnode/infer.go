@@ -5,6 +5,7 @@ import (
 	"go/token"
 	"reflect"
 	"strings"
+	"sync"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime/datamodel"
@@ -39,219 +40,264 @@ type seenEntry struct {
 	schemaType schema.Type
 }
 
-// verifyCompatibility is the primary way we check that the schema type(s)
-// matches the Go type(s); so we do this before we can proceed operating on it.
-// verifyCompatibility doesn't return an error, it panics—the errors here are
-// not runtime errors, they're programmer errors because your schema doesn't
-// match your Go type
-func verifyCompatibility(cfg *config, seen map[seenEntry]bool, goType reflect.Type, schemaType schema.Type) {
+// compatChecker is the primary way we check that the schema type(s) match the
+// Go type(s); so we do this before we can proceed operating on them.
+// Rather than stopping at the first mismatch, it records every one it finds,
+// along with where it was found, so that they can all be fixed in one go.
+// The errors here are not runtime errors, they're programmer errors because
+// your schema doesn't match your Go type.
+type compatChecker struct {
+	cfg  *config
+	seen map[seenEntry]bool
+
+	incompatibilities []Incompatibility
+}
+
+// checkCompatibility returns an IncompatibilityError listing every mismatch
+// between the Go type and the schema type, or nil if they are compatible.
+func checkCompatibility(cfg *config, goType reflect.Type, schemaType schema.Type) error {
+	c := &compatChecker{cfg: cfg, seen: make(map[seenEntry]bool)}
+	c.check(goType, schemaType, "", "")
+	if len(c.incompatibilities) > 0 {
+		return IncompatibilityError{Incompatibilities: c.incompatibilities}
+	}
+	return nil
+}
+
+func (c *compatChecker) report(goPath string, goType reflect.Type, schemaPath string, schemaType schema.Type, format string, args ...interface{}) {
+	c.incompatibilities = append(c.incompatibilities, Incompatibility{
+		GoPath:     goPath,
+		GoType:     goType,
+		SchemaPath: schemaPath,
+		SchemaType: schemaType,
+		Reason:     fmt.Sprintf(format, args...),
+	})
+}
+
+// check compares a Go type with a schema type, found at goPath and schemaPath
+// respectively; see Incompatibility for the syntax of the paths.
+func (c *compatChecker) check(goType reflect.Type, schemaType schema.Type, goPath, schemaPath string) {
 	// TODO(mvdan): support **T as well?
 	if goType.Kind() == reflect.Ptr {
 		goType = goType.Elem()
 	}
 
 	// Avoid endless loops.
+	// This also means that each pair of types is only reported once,
+	// even if it appears in multiple places.
 	//
 	// TODO(mvdan): this is easy but fairly allocation-happy.
-	// Plus, one map per call means we don't reuse work.
-	if seen[seenEntry{goType, schemaType}] {
+	if c.seen[seenEntry{goType, schemaType}] {
 		return
 	}
-	seen[seenEntry{goType, schemaType}] = true
+	c.seen[seenEntry{goType, schemaType}] = true
 
-	doPanic := func(format string, args ...interface{}) {
-		panicFormat := "bindnode: schema type %s is not compatible with Go type %s"
-		panicArgs := []interface{}{schemaType.Name(), goType.String()}
-
-		if format != "" {
-			panicFormat += ": " + format
+	report := func(format string, args ...interface{}) {
+		c.report(goPath, goType, schemaPath, schemaType, format, args...)
+	}
+	cfg := c.cfg
+	if heldAsNode(schemaType) {
+		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
+			if customConverter.kind != schema.TypeKind_Any {
+				report("kind mismatch; custom converter for type is not for Any")
+			}
+		} else if goType != goTypeNode {
+			if _, ok := schemaType.(*schema.TypeAny); ok {
+				report("Any in Go must be datamodel.Node")
+			} else {
+				report("types with an advanced representation must be datamodel.Node in Go")
+			}
 		}
-		panicArgs = append(panicArgs, args...)
-		panic(fmt.Sprintf(panicFormat, panicArgs...))
+		return
 	}
 	switch schemaType := schemaType.(type) {
 	case *schema.TypeBool:
 		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
 			if customConverter.kind != schema.TypeKind_Bool {
-				doPanic("kind mismatch; custom converter for type is not for Bool")
+				report("kind mismatch; custom converter for type is not for Bool")
 			}
 		} else if goType.Kind() != reflect.Bool {
-			doPanic("kind mismatch; need boolean")
+			report("kind mismatch; need boolean")
 		}
 	case *schema.TypeInt:
 		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
 			if customConverter.kind != schema.TypeKind_Int {
-				doPanic("kind mismatch; custom converter for type is not for Int")
+				report("kind mismatch; custom converter for type is not for Int")
 			}
 		} else if kind := goType.Kind(); !kindInt[kind] && !kindUint[kind] {
-			doPanic("kind mismatch; need integer")
+			report("kind mismatch; need integer")
 		}
 	case *schema.TypeFloat:
 		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
 			if customConverter.kind != schema.TypeKind_Float {
-				doPanic("kind mismatch; custom converter for type is not for Float")
+				report("kind mismatch; custom converter for type is not for Float")
 			}
 		} else {
 			switch goType.Kind() {
 			case reflect.Float32, reflect.Float64:
 			default:
-				doPanic("kind mismatch; need float")
+				report("kind mismatch; need float")
 			}
 		}
 	case *schema.TypeString:
 		// TODO: allow []byte?
 		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
 			if customConverter.kind != schema.TypeKind_String {
-				doPanic("kind mismatch; custom converter for type is not for String")
+				report("kind mismatch; custom converter for type is not for String")
 			}
 		} else if goType.Kind() != reflect.String {
-			doPanic("kind mismatch; need string")
+			report("kind mismatch; need string")
 		}
 	case *schema.TypeBytes:
 		// TODO: allow string?
 		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
 			if customConverter.kind != schema.TypeKind_Bytes {
-				doPanic("kind mismatch; custom converter for type is not for Bytes")
+				report("kind mismatch; custom converter for type is not for Bytes")
 			}
-		} else if goType.Kind() != reflect.Slice {
-			doPanic("kind mismatch; need slice of bytes")
-		} else if goType.Elem().Kind() != reflect.Uint8 {
-			doPanic("kind mismatch; need slice of bytes")
+		} else if goType.Kind() != reflect.Slice || goType.Elem().Kind() != reflect.Uint8 {
+			report("kind mismatch; need slice of bytes")
 		}
 	case *schema.TypeEnum:
 		if _, ok := schemaType.RepresentationStrategy().(schema.EnumRepresentation_Int); ok {
 			if kind := goType.Kind(); kind != reflect.String && !kindInt[kind] && !kindUint[kind] {
-				doPanic("kind mismatch; need string or integer")
+				report("kind mismatch; need string or integer")
 			}
 		} else {
 			if goType.Kind() != reflect.String {
-				doPanic("kind mismatch; need string")
+				report("kind mismatch; need string")
 			}
 		}
 	case *schema.TypeList:
 		if goType.Kind() != reflect.Slice {
-			doPanic("kind mismatch; need slice")
+			report("kind mismatch; need slice")
+			return
 		}
-		goType = goType.Elem()
+		goType := goType.Elem()
 		if schemaType.ValueIsNullable() {
 			if ptr, nilable := ptrOrNilable(goType.Kind()); !nilable {
-				doPanic("nullable types must be nilable")
+				report("nullable types must be nilable")
+				return
 			} else if ptr {
 				goType = goType.Elem()
 			}
 		}
-		verifyCompatibility(cfg, seen, goType, schemaType.ValueType())
+		c.check(goType, schemaType.ValueType(), goPath+"[]", schemaPath+"[]")
 	case *schema.TypeMap:
 		//	struct {
 		//		Keys   []K
 		//		Values map[K]V
 		//	}
 		if goType.Kind() != reflect.Struct {
-			doPanic("kind mismatch; need struct{Keys []K; Values map[K]V}")
+			report("kind mismatch; need struct{Keys []K; Values map[K]V}")
+			return
 		}
 		if goType.NumField() != 2 {
-			doPanic("%d vs 2 fields", goType.NumField())
+			report("%d vs 2 fields", goType.NumField())
+			return
 		}
 
 		fieldKeys := goType.Field(0)
-		if fieldKeys.Type.Kind() != reflect.Slice {
-			doPanic("kind mismatch; need struct{Keys []K; Values map[K]V}")
-		}
-		verifyCompatibility(cfg, seen, fieldKeys.Type.Elem(), schemaType.KeyType())
-
 		fieldValues := goType.Field(1)
-		if fieldValues.Type.Kind() != reflect.Map {
-			doPanic("kind mismatch; need struct{Keys []K; Values map[K]V}")
+		if fieldKeys.Type.Kind() != reflect.Slice || fieldValues.Type.Kind() != reflect.Map {
+			report("kind mismatch; need struct{Keys []K; Values map[K]V}")
+			return
 		}
+		c.check(fieldKeys.Type.Elem(), schemaType.KeyType(), goPath+"."+fieldKeys.Name+"[]", schemaPath+"[key]")
+
 		keyType := fieldValues.Type.Key()
-		verifyCompatibility(cfg, seen, keyType, schemaType.KeyType())
+		c.check(keyType, schemaType.KeyType(), goPath+"."+fieldValues.Name+"[key]", schemaPath+"[key]")
 
 		elemType := fieldValues.Type.Elem()
 		if schemaType.ValueIsNullable() {
 			if ptr, nilable := ptrOrNilable(elemType.Kind()); !nilable {
-				doPanic("nullable types must be nilable")
+				report("nullable types must be nilable")
+				return
 			} else if ptr {
 				elemType = elemType.Elem()
 			}
 		}
-		verifyCompatibility(cfg, seen, elemType, schemaType.ValueType())
+		c.check(elemType, schemaType.ValueType(), goPath+"."+fieldValues.Name+"[value]", schemaPath+"[value]")
 	case *schema.TypeStruct:
 		if goType.Kind() != reflect.Struct {
-			doPanic("kind mismatch; need struct")
+			report("kind mismatch; need struct")
+			return
 		}
 
 		schemaFields := schemaType.Fields()
-		if goType.NumField() != len(schemaFields) {
-			doPanic("%d vs %d fields", goType.NumField(), len(schemaFields))
+		goFields := goFieldIndexes(goType)
+		if len(goFields) != len(schemaFields) {
+			report("%d vs %d fields", len(goFields), len(schemaFields))
+			return
 		}
 		for i, schemaField := range schemaFields {
 			schemaType := schemaField.Type()
-			goType := goType.Field(i).Type
+			goField := goType.Field(goFields[i])
+			goType := goField.Type
+			goPath := goPath + "." + goField.Name
+			schemaPath := schemaPath + "." + schemaField.Name()
 			switch {
 			case schemaField.IsOptional() && schemaField.IsNullable():
 				// TODO: https://github.com/ipld/go-ipld-prime/issues/340 will
 				// help here, to avoid the double pointer. We can't use nilable
 				// but non-pointer types because that's just one "nil" state.
 				// TODO: deal with custom converters in this case
-				if goType.Kind() != reflect.Ptr {
-					doPanic("optional and nullable fields must use double pointers (**)")
+				if goType.Kind() != reflect.Ptr || goType.Elem().Kind() != reflect.Ptr {
+					c.report(goPath, goType, schemaPath, schemaType, "optional and nullable fields must use double pointers (**)")
+					continue
 				}
-				goType = goType.Elem()
-				if goType.Kind() != reflect.Ptr {
-					doPanic("optional and nullable fields must use double pointers (**)")
-				}
-				goType = goType.Elem()
+				goType = goType.Elem().Elem()
 			case schemaField.IsOptional():
 				if ptr, nilable := ptrOrNilable(goType.Kind()); !nilable {
-					doPanic("optional fields must be nilable")
+					c.report(goPath, goType, schemaPath, schemaType, "optional fields must be nilable")
+					continue
 				} else if ptr {
 					goType = goType.Elem()
 				}
 			case schemaField.IsNullable():
 				if ptr, nilable := ptrOrNilable(goType.Kind()); !nilable {
 					if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter == nil {
-						doPanic("nullable fields must be nilable")
+						c.report(goPath, goType, schemaPath, schemaType, "nullable fields must be nilable")
+						continue
 					}
 				} else if ptr {
 					goType = goType.Elem()
 				}
 			}
-			verifyCompatibility(cfg, seen, goType, schemaType)
+			c.check(goType, schemaType, goPath, schemaPath)
 		}
 	case *schema.TypeUnion:
 		if goType.Kind() != reflect.Struct {
-			doPanic("kind mismatch; need struct for an union")
+			report("kind mismatch; need struct for an union")
+			return
 		}
 
 		schemaMembers := schemaType.Members()
-		if goType.NumField() != len(schemaMembers) {
-			doPanic("%d vs %d members", goType.NumField(), len(schemaMembers))
+		goFields := goFieldIndexes(goType)
+		if len(goFields) != len(schemaMembers) {
+			report("%d vs %d members", len(goFields), len(schemaMembers))
+			return
 		}
 
 		for i, schemaType := range schemaMembers {
-			goType := goType.Field(i).Type
+			goField := goType.Field(goFields[i])
+			goType := goField.Type
+			goPath := goPath + "." + goField.Name
+			schemaPath := schemaPath + "." + schemaType.Name()
 			if ptr, nilable := ptrOrNilable(goType.Kind()); !nilable {
-				doPanic("union members must be nilable")
+				c.report(goPath, goType, schemaPath, schemaType, "union members must be nilable")
+				continue
 			} else if ptr {
 				goType = goType.Elem()
 			}
-			verifyCompatibility(cfg, seen, goType, schemaType)
+			c.check(goType, schemaType, goPath, schemaPath)
 		}
 	case *schema.TypeLink:
 		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
 			if customConverter.kind != schema.TypeKind_Link {
-				doPanic("kind mismatch; custom converter for type is not for Link")
+				report("kind mismatch; custom converter for type is not for Link")
 			}
 		} else if goType != goTypeLink && goType != goTypeCidLink && goType != goTypeCid {
-			doPanic("links in Go must be datamodel.Link, cidlink.Link, or cid.Cid")
-		}
-	case *schema.TypeAny:
-		if customConverter := cfg.converterForType(schemaType.Name(), goType); customConverter != nil {
-			if customConverter.kind != schema.TypeKind_Any {
-				doPanic("kind mismatch; custom converter for type is not for Any")
-			}
-		} else if goType != goTypeNode {
-			doPanic("Any in Go must be datamodel.Node")
+			report("links in Go must be datamodel.Link, cidlink.Link, or cid.Cid")
 		}
 	default:
 		panic(fmt.Sprintf("%T", schemaType))
@@ -294,6 +340,9 @@ func inferGoType(typ schema.Type, status map[schema.TypeName]inferredStatus, lev
 	}
 	status[name] = inferringInProcess
 	defer func() { status[name] = inferringDone }()
+	if heldAsNode(typ) {
+		return goTypeNode
+	}
 	switch typ := typ.(type) {
 	case *schema.TypeBool:
 		return goTypeBool
@@ -375,8 +424,6 @@ func inferGoType(typ schema.Type, status map[schema.TypeName]inferredStatus, lev
 	case *schema.TypeEnum:
 		// TODO: generate int for int reprs by default?
 		return goTypeString
-	case *schema.TypeAny:
-		return goTypeNode
 	case nil:
 		panic("bindnode: unexpected nil schema.Type")
 	}
@@ -392,32 +439,85 @@ func fieldNameFromSchema(name string) string {
 	return fieldName
 }
 
-var defaultTypeSystem schema.TypeSystem
+// TODO: support IPLD maps in inferSchema
 
-func init() {
-	defaultTypeSystem.Init()
+// TODO: support bringing your own TypeSystem?
 
-	defaultTypeSystem.Accumulate(schemaTypeBool)
-	defaultTypeSystem.Accumulate(schemaTypeInt)
-	defaultTypeSystem.Accumulate(schemaTypeFloat)
-	defaultTypeSystem.Accumulate(schemaTypeString)
-	defaultTypeSystem.Accumulate(schemaTypeBytes)
-	defaultTypeSystem.Accumulate(schemaTypeLink)
-	defaultTypeSystem.Accumulate(schemaTypeAny)
+// inferredSystem holds the schema types inferred from Go types, in a type system
+// of their own. A Go type used more than once, or inferred by more than one call
+// to Prototype or Wrap, results in a single schema type.
+//
+// Options which change what gets inferred (for now, only TypedEnum) mean the
+// same Go types can result in different schema types of the same name,
+// so each set of them gets its own inferredSystem.
+type inferredSystem struct {
+	enums   map[reflect.Type]*enum
+	ts      schema.TypeSystem
+	goTypes map[schema.TypeName]reflect.Type // the Go types that named types were inferred from
 }
 
-// TODO: support IPLD maps and unions in inferSchema
+var (
+	inferredSystemsMu sync.Mutex
+	inferredSystems   []*inferredSystem
+)
 
-// TODO: support bringing your own TypeSystem?
+// inferSchema can build a schema from a Go type
+func inferSchema(cfg *config, typ reflect.Type) schema.Type {
+	inferredSystemsMu.Lock()
+	defer inferredSystemsMu.Unlock()
+
+	var enums map[reflect.Type]*enum
+	if cfg != nil && len(cfg.enums) > 0 {
+		enums = cfg.enums
+	}
+	var sys *inferredSystem
+	for _, s := range inferredSystems {
+		if reflect.DeepEqual(s.enums, enums) {
+			sys = s
+			break
+		}
+	}
+	if sys == nil {
+		sys = &inferredSystem{enums: enums, goTypes: make(map[schema.TypeName]reflect.Type)}
+		sys.ts.Init()
+		sys.ts.Accumulate(schemaTypeBool)
+		sys.ts.Accumulate(schemaTypeInt)
+		sys.ts.Accumulate(schemaTypeFloat)
+		sys.ts.Accumulate(schemaTypeString)
+		sys.ts.Accumulate(schemaTypeBytes)
+		sys.ts.Accumulate(schemaTypeLink)
+		sys.ts.Accumulate(schemaTypeAny)
+		inferredSystems = append(inferredSystems, sys)
+	}
+	return sys.infer(cfg, typ, 0)
+}
 
-// TODO: we should probably avoid re-spawning the same types if the TypeSystem
-// has them, and test that that works as expected
+// accumulate adds a type inferred from goType, unless it was already inferred,
+// in which case that one is returned.
+// Inferring a named type from two different Go types of the same name, such as
+// ones from different packages, is an error; unnamed Go types such as []string
+// don't count, as their schema types are named after their contents.
+func (s *inferredSystem) accumulate(goType reflect.Type, typ schema.Type) schema.Type {
+	existing := s.ts.TypeByName(typ.Name())
+	if existing == nil {
+		s.ts.Accumulate(typ)
+		s.goTypes[typ.Name()] = goType
+		return typ
+	}
+	if prev := s.goTypes[typ.Name()]; goType.Name() != "" && prev != goType {
+		panic(fmt.Sprintf("bindnode: cannot infer schema type %s from Go type %s (in %q): it was already inferred from a different Go type of the same name (in %q)",
+			typ.Name(), goType, goType.PkgPath(), prev.PkgPath()))
+	}
+	return existing
+}
 
-// inferSchema can build a schema from a Go type
-func inferSchema(typ reflect.Type, level int) schema.Type {
+func (s *inferredSystem) infer(cfg *config, typ reflect.Type, level int) schema.Type {
 	if level > maxRecursionLevel {
 		panic(fmt.Sprintf("inferSchema: refusing to recurse past %d levels", maxRecursionLevel))
 	}
+	if enum := cfg.enumForType(typ); enum != nil {
+		return s.accumulate(typ, schema.SpawnEnum(typ.Name(), enum.members, enum.repr))
+	}
 	switch typ.Kind() {
 	case reflect.Bool:
 		return schemaTypeBool
@@ -434,27 +534,75 @@ func inferSchema(typ reflect.Type, level int) schema.Type {
 			return schemaTypeLink
 		}
 
-		fieldsSchema := make([]schema.StructField, typ.NumField())
-		for i := range fieldsSchema {
+		name := typ.Name()
+		if name == "" {
+			panic("TODO: anonymous composite types")
+		}
+		var typeOpts tagOptions
+		var fields []reflect.StructField
+		for i := 0; i < typ.NumField(); i++ {
 			field := typ.Field(i)
+			if field.Name == "_" {
+				// The tag of a blank field has no name, only options.
+				if tag := field.Tag.Get("ipld"); tag != "" {
+					typeOpts = strings.Split(tag, ",")
+				}
+				continue
+			}
+			fields = append(fields, field)
+		}
+		if typeOpts.has("union") {
+			return s.accumulate(typ, s.inferUnion(cfg, name, fields, typeOpts, level))
+		}
+		typeOpts.check(name, "tuple")
+
+		fieldsSchema := make([]schema.StructField, len(fields))
+		renames := make(map[string]string)
+		for i, field := range fields {
+			key, opts := parseTag(field.Tag)
+			opts.check(name+"."+field.Name, "optional", "nullable")
+			optional, nullable := opts.has("optional"), opts.has("nullable")
+
+			// Mirror compatChecker, which tells what Go types are
+			// accepted for optional and nullable fields.
 			ftyp := field.Type
-			ftypSchema := inferSchema(ftyp, level+1)
+			switch {
+			case optional && nullable:
+				if ftyp.Kind() != reflect.Ptr || ftyp.Elem().Kind() != reflect.Ptr {
+					panic(fmt.Sprintf("bindnode: optional and nullable field %s.%s must use a double pointer (**)", name, field.Name))
+				}
+				ftyp = ftyp.Elem().Elem()
+			case optional, nullable:
+				if ptr, nilable := ptrOrNilable(ftyp.Kind()); !nilable {
+					panic(fmt.Sprintf("bindnode: optional or nullable field %s.%s must be nilable", name, field.Name))
+				} else if ptr {
+					ftyp = ftyp.Elem()
+				}
+			}
+			if ftyp.Kind() == reflect.Ptr {
+				panic(fmt.Sprintf("bindnode: pointer field %s.%s must be tagged as optional or nullable", name, field.Name))
+			}
+			ftypSchema := s.infer(cfg, ftyp, level+1)
 			fieldsSchema[i] = schema.SpawnStructField(
-				field.Name, // TODO: allow configuring the name with tags
+				field.Name,
 				ftypSchema.Name(),
-
-				// TODO: support nullable/optional with tags
-				false,
-				false,
+				optional,
+				nullable,
 			)
+			if key != "" && key != field.Name {
+				renames[field.Name] = key
+			}
 		}
-		name := typ.Name()
-		if name == "" {
-			panic("TODO: anonymous composite types")
+		var repr schema.StructRepresentation
+		if typeOpts.has("tuple") {
+			if len(renames) > 0 {
+				panic(fmt.Sprintf("bindnode: fields of %s cannot be renamed, as it has a tuple representation", name))
+			}
+			repr = schema.SpawnStructRepresentationTuple()
+		} else if len(renames) > 0 {
+			repr = schema.SpawnStructRepresentationMap(renames)
 		}
-		typSchema := schema.SpawnStruct(name, fieldsSchema, nil)
-		defaultTypeSystem.Accumulate(typSchema)
-		return typSchema
+		return s.accumulate(typ, schema.SpawnStruct(name, fieldsSchema, repr))
 	case reflect.Slice:
 		if typ.Elem().Kind() == reflect.Uint8 {
 			// Special case for []byte.
@@ -462,17 +610,21 @@ func inferSchema(typ reflect.Type, level int) schema.Type {
 		}
 
 		nullable := false
-		if typ.Elem().Kind() == reflect.Ptr {
+		etyp := typ.Elem()
+		if etyp.Kind() == reflect.Ptr {
 			nullable = true
+			etyp = etyp.Elem()
 		}
-		etypSchema := inferSchema(typ.Elem(), level+1)
+		etypSchema := s.infer(cfg, etyp, level+1)
 		name := typ.Name()
 		if name == "" {
 			name = "List_" + etypSchema.Name()
+			if nullable {
+				// Don't clash with the non-nullable list, which may also be inferred.
+				name = "List_Nullable_" + etypSchema.Name()
+			}
 		}
-		typSchema := schema.SpawnList(name, etypSchema.Name(), nullable)
-		defaultTypeSystem.Accumulate(typSchema)
-		return typSchema
+		return s.accumulate(typ, schema.SpawnList(name, etypSchema.Name(), nullable))
 	case reflect.Interface:
 		// these types must match exactly since we need symmetry of being able to
 		// get the values an also assign values to them
@@ -487,6 +639,92 @@ func inferSchema(typ reflect.Type, level int) schema.Type {
 	panic(fmt.Sprintf("bindnode: unable to infer from type %s", typ.Kind().String()))
 }
 
+// inferUnion builds a union from the fields of a Go struct type, which must all
+// be pointers, as per the options in the tag of its blank field.
+func (s *inferredSystem) inferUnion(cfg *config, name string, fields []reflect.StructField, typeOpts tagOptions, level int) *schema.TypeUnion {
+	typeOpts.check(name, "union", "keyed", "kinded")
+	keyed, kinded := typeOpts.has("keyed"), typeOpts.has("kinded")
+	if keyed == kinded {
+		panic(fmt.Sprintf("bindnode: union %s must be tagged as either keyed or kinded", name))
+	}
+
+	members := make([]schema.TypeName, len(fields))
+	keyedTable := make(map[string]schema.TypeName)
+	kindedTable := make(map[datamodel.Kind]schema.TypeName)
+	for i, field := range fields {
+		key, opts := parseTag(field.Tag)
+		opts.check(name + "." + field.Name)
+		if field.Type.Kind() != reflect.Ptr {
+			panic(fmt.Sprintf("bindnode: union member %s.%s must be a pointer", name, field.Name))
+		}
+		member := s.infer(cfg, field.Type.Elem(), level+1)
+		for _, other := range members[:i] {
+			if other == member.Name() {
+				panic(fmt.Sprintf("bindnode: union %s has more than one member of type %s", name, other))
+			}
+		}
+		members[i] = member.Name()
+
+		if keyed {
+			if key == "" {
+				key = member.Name()
+			}
+			if _, ok := keyedTable[key]; ok {
+				panic(fmt.Sprintf("bindnode: union %s has more than one member with key %q", name, key))
+			}
+			keyedTable[key] = member.Name()
+			continue
+		}
+		if key != "" {
+			panic(fmt.Sprintf("bindnode: member %s.%s of a kinded union cannot have a key", name, field.Name))
+		}
+		kind := member.RepresentationBehavior()
+		if kind == datamodel.Kind_Invalid {
+			panic(fmt.Sprintf("bindnode: type %s cannot be a member of kinded union %s, as its representation kind is not fixed", member.Name(), name))
+		}
+		if _, ok := kindedTable[kind]; ok {
+			panic(fmt.Sprintf("bindnode: union %s has more than one member of kind %s", name, kind))
+		}
+		kindedTable[kind] = member.Name()
+	}
+	if keyed {
+		return schema.SpawnUnion(name, members, schema.SpawnUnionRepresentationKeyed(keyedTable))
+	}
+	return schema.SpawnUnion(name, members, schema.SpawnUnionRepresentationKinded(kindedTable))
+}
+
+// tagOptions holds the comma-separated options following the name in a struct tag.
+type tagOptions []string
+
+// parseTag splits an `ipld:"name,opt1,opt2"` struct tag,
+// in a similar fashion to encoding/json.
+func parseTag(tag reflect.StructTag) (name string, opts tagOptions) {
+	name, rest, found := strings.Cut(tag.Get("ipld"), ",")
+	if found {
+		opts = strings.Split(rest, ",")
+	}
+	return name, opts
+}
+
+func (opts tagOptions) has(opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// check panics if any of the options isn't one of the allowed ones,
+// as a typo in a struct tag would otherwise be silently ignored.
+func (opts tagOptions) check(where string, allowed ...string) {
+	for _, o := range opts {
+		if !tagOptions(allowed).has(o) {
+			panic(fmt.Sprintf("bindnode: unknown ipld tag option %q on %s", o, where))
+		}
+	}
+}
+
 // There are currently 27 reflect.Kind iota values,
 // so 32 should be plenty to ensure we don't panic in practice.
 
@@ -9,18 +9,22 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"runtime/debug"
 	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/ipfs/go-cid"
 
 	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec"
 	"github.com/ipld/go-ipld-prime/codec/dagjson"
 	"github.com/ipld/go-ipld-prime/datamodel"
 	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/must"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
 	"github.com/ipld/go-ipld-prime/node/bindnode"
 	"github.com/ipld/go-ipld-prime/schema"
@@ -976,7 +980,7 @@ var verifyTests = []struct {
 			{(*struct {
 				List   []string
 				String string
-			})(nil), `.*type Root .*: union members must be nilable`},
+			})(nil), `.*at \.String \(Go \.String\): schema type String .*: union members must be nilable`},
 			{(*struct {
 				List   *[]string
 				String *int
@@ -1020,6 +1024,75 @@ func TestSchemaVerify(t *testing.T) {
 	}
 }
 
+func TestCheckCompatibility(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes([]byte(`
+		type Root struct {
+			name   String
+			tags   [String]
+			counts {String:Int}
+			shape  Shape
+			note   optional String
+		}
+		type Shape union {
+			| String string
+			| Int int
+		} representation kinded
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	schemaType := ts.TypeByName("Root")
+
+	type goodRoot struct {
+		Name   string
+		Tags   []string
+		Counts struct {
+			Keys   []string
+			Values map[string]int64
+		}
+		Shape struct {
+			String *string
+			Int    *int64
+		}
+		Note *string
+	}
+	qt.Check(t, bindnode.CheckCompatibility((*goodRoot)(nil), schemaType), qt.IsNil)
+
+	type badRoot struct {
+		Name   int
+		Tags   []bool
+		Counts struct {
+			Keys   []string
+			Values map[string]string
+		}
+		Shape struct {
+			String *string
+			Int    float64
+		}
+		Note string
+	}
+	err = bindnode.CheckCompatibility((*badRoot)(nil), schemaType)
+	var incompatible bindnode.IncompatibilityError
+	qt.Assert(t, err, qt.ErrorAs, &incompatible)
+
+	type location struct{ goPath, schemaPath, reason string }
+	var got []location
+	for _, inc := range incompatible.Incompatibilities {
+		got = append(got, location{inc.GoPath, inc.SchemaPath, inc.Reason})
+	}
+	qt.Check(t, got, qt.CmpEquals(cmp.AllowUnexported(location{})), []location{
+		{".Name", ".name", "kind mismatch; need string"},
+		{".Tags[]", ".tags[]", "kind mismatch; need string"},
+		{".Counts.Values[value]", ".counts[value]", "kind mismatch; need integer"},
+		{".Shape.Int", ".shape.Int", "union members must be nilable"},
+		{".Note", ".note", "optional fields must be nilable"},
+	})
+	qt.Check(t, err, qt.ErrorMatches, `bindnode: found 5 incompatibilities between the schema and Go types:
+	at \.name \(Go \.Name\): schema type String is not compatible with Go type int: kind mismatch; need string
+(?s).*`)
+
+	// Prototype reports every incompatibility too, as a panic.
+	qt.Check(t, func() { bindnode.Prototype((*badRoot)(nil), schemaType) }, qt.PanicMatches, regexp.QuoteMeta(err.Error()))
+}
+
 func TestProduceGoTypes(t *testing.T) {
 	t.Parallel()
 
@@ -1249,3 +1322,216 @@ func TestInferLinksAndAny(t *testing.T) {
 		t.Error("expected *Nd from Unwrap")
 	}
 }
+
+type InferNote struct {
+	Title  string       `ipld:"title"`
+	Body   *string      `ipld:"body,optional"`
+	Author *string      `ipld:",nullable"`
+	Origin **InferPoint `ipld:"origin,optional,nullable"`
+	Points []InferPoint `ipld:"points"`
+	Tags   []*string    `ipld:"tags"`
+	Extra  *InferPoint  `ipld:"extra,optional"`
+	Shapes []InferShape `ipld:"shapes"`
+	Values []InferValue `ipld:"values"`
+	Level  InferLevel   `ipld:"level"`
+}
+
+type InferPoint struct {
+	_ struct{} `ipld:"tuple"`
+	X int64
+	Y int64
+}
+
+type InferShape struct {
+	_      struct{}     `ipld:"union,keyed"`
+	Circle *InferCircle `ipld:"circle"`
+	Square *InferSquare
+}
+
+type InferCircle struct {
+	Radius int64 `ipld:"r"`
+}
+
+type InferSquare struct {
+	Side  int64
+	Color InferColor
+}
+
+type InferValue struct {
+	_      struct{} `ipld:"union,kinded"`
+	Level  *InferLevel
+	String *string
+	Shape  *InferShape
+}
+
+type InferColor string
+
+const (
+	InferRed  InferColor = "Red"
+	InferBlue InferColor = "Blue"
+)
+
+type InferLevel int
+
+const (
+	InferLow  InferLevel = 1
+	InferHigh InferLevel = 10
+)
+
+func (l InferLevel) String() string {
+	switch l {
+	case InferLow:
+		return "Low"
+	case InferHigh:
+		return "High"
+	}
+	return fmt.Sprintf("InferLevel(%d)", int(l))
+}
+
+func TestInferTags(t *testing.T) {
+	options := []bindnode.Option{
+		bindnode.TypedEnum((*InferColor)(nil), InferRed, InferBlue),
+		bindnode.TypedEnum((*InferLevel)(nil), InferLow, InferHigh),
+	}
+	proto := bindnode.Prototype((*InferNote)(nil), nil, options...)
+
+	typ := proto.Type().(*schema.TypeStruct)
+	qt.Check(t, typ.Field("Body").IsOptional(), qt.IsTrue)
+	qt.Check(t, typ.Field("Author").IsNullable(), qt.IsTrue)
+	qt.Check(t, typ.Field("Origin").IsOptional() && typ.Field("Origin").IsNullable(), qt.IsTrue)
+	qt.Check(t, typ.Field("Origin").Type().RepresentationBehavior(), qt.Equals, datamodel.Kind_List)
+	qt.Check(t, typ.Field("Level").Type().TypeKind(), qt.Equals, schema.TypeKind_Enum)
+	qt.Check(t, typ.Field("Level").Type().RepresentationBehavior(), qt.Equals, datamodel.Kind_Int)
+	qt.Check(t, typ.Field("Shapes").Type().(*schema.TypeList).ValueType().TypeKind(), qt.Equals, schema.TypeKind_Union)
+
+	// Inferring again reuses the same schema types.
+	qt.Check(t, bindnode.Prototype((*InferNote)(nil), nil, options...).Type(), qt.Equals, proto.Type())
+
+	const reprJSON = `{"title":"t","body":"b","Author":null,"origin":[1,2],"points":[[3,4]],"tags":["a",null],` +
+		`"shapes":[{"circle":{"r":5}},{"InferSquare":{"Side":6,"Color":"Blue"}}],` +
+		`"values":[10,"s",{"circle":{"r":7}}],"level":1}`
+	node, err := ipld.DecodeUsingPrototype([]byte(reprJSON), dagjson.Decode, proto.Representation())
+	qt.Assert(t, err, qt.IsNil)
+
+	body, a, s, high := "b", "a", "s", InferHigh
+	origin := &InferPoint{X: 1, Y: 2}
+	expected := &InferNote{
+		Title:  "t",
+		Body:   &body,
+		Origin: &origin,
+		Points: []InferPoint{{X: 3, Y: 4}},
+		Tags:   []*string{&a, nil},
+		Shapes: []InferShape{
+			{Circle: &InferCircle{Radius: 5}},
+			{Square: &InferSquare{Side: 6, Color: InferBlue}},
+		},
+		Values: []InferValue{
+			{Level: &high},
+			{String: &s},
+			{Shape: &InferShape{Circle: &InferCircle{Radius: 7}}},
+		},
+		Level: InferLow,
+	}
+	qt.Assert(t, bindnode.Unwrap(node), qt.CmpEquals(cmpopts.IgnoreUnexported(InferPoint{}, InferShape{}, InferValue{})), expected)
+
+	wrapped := bindnode.Wrap(expected, nil, options...)
+	level, err := wrapped.LookupByString("Level")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, must.String(level), qt.Equals, "Low")
+
+	var buf bytes.Buffer
+	err = dagjson.EncodeOptions{MapSortMode: codec.MapSortMode_None}.Encode(wrapped.Representation(), &buf)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, buf.String(), qt.Equals, reprJSON)
+
+	// Enums held as Go integers are assembled by member name at the type level.
+	levelProto := bindnode.Prototype((*InferLevel)(nil), nil, options...)
+	nb := levelProto.NewBuilder()
+	qt.Assert(t, nb.AssignString("High"), qt.IsNil)
+	qt.Check(t, bindnode.Unwrap(nb.Build()), qt.DeepEquals, &high)
+	qt.Check(t, levelProto.NewBuilder().AssignString("Medium"), qt.ErrorMatches, `.*"Medium" is not a valid member of enum InferLevel`)
+}
+
+type InferNoStringer int
+
+func TestInferTagErrors(t *testing.T) {
+	type InferTypo struct {
+		X int64 `ipld:"x,optinal"`
+	}
+	type InferUntaggedPtr struct {
+		X *int64
+	}
+	type InferOptionalNullable struct {
+		X *int64 `ipld:",optional,nullable"`
+	}
+	type InferTupleRename struct {
+		_ struct{} `ipld:"tuple"`
+		X int64    `ipld:"x"`
+	}
+	type InferUnionNoStrategy struct {
+		_ struct{} `ipld:"union"`
+		X *int64
+	}
+	type InferUnionNonPtr struct {
+		_ struct{} `ipld:"union,keyed"`
+		X int64
+	}
+	type InferUnionSameKey struct {
+		_ struct{} `ipld:"union,keyed"`
+		X *int64   `ipld:"n"`
+		Y *string  `ipld:"n"`
+	}
+	type InferUnionSameKind struct {
+		_     struct{} `ipld:"union,kinded"`
+		X     *string
+		Color *InferColor
+	}
+
+	for _, tc := range []struct {
+		name    string
+		ptrType interface{}
+		panic   string
+	}{
+		{"UnknownOption", (*InferTypo)(nil), `bindnode: unknown ipld tag option "optinal" on InferTypo.X`},
+		{"UntaggedPointer", (*InferUntaggedPtr)(nil), `bindnode: pointer field InferUntaggedPtr.X must be tagged as optional or nullable`},
+		{"OptionalNullableSinglePointer", (*InferOptionalNullable)(nil), `bindnode: optional and nullable field InferOptionalNullable.X must use a double pointer \(\*\*\)`},
+		{"TupleRename", (*InferTupleRename)(nil), `bindnode: fields of InferTupleRename cannot be renamed, as it has a tuple representation`},
+		{"UnionNoStrategy", (*InferUnionNoStrategy)(nil), `bindnode: union InferUnionNoStrategy must be tagged as either keyed or kinded`},
+		{"UnionNonPointer", (*InferUnionNonPtr)(nil), `bindnode: union member InferUnionNonPtr.X must be a pointer`},
+		{"UnionSameKey", (*InferUnionSameKey)(nil), `bindnode: union InferUnionSameKey has more than one member with key "n"`},
+		{"UnionSameKind", (*InferUnionSameKind)(nil), `bindnode: union InferUnionSameKind has more than one member of kind string`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			qt.Check(t, func() {
+				bindnode.Prototype(tc.ptrType, nil, bindnode.TypedEnum((*InferColor)(nil), InferRed, InferBlue))
+			}, qt.PanicMatches, tc.panic)
+		})
+	}
+
+	qt.Check(t, func() { bindnode.TypedEnum((*InferNoStringer)(nil), InferNoStringer(1)) },
+		qt.PanicMatches, `bindnode: integer enum type bindnode_test.InferNoStringer must implement fmt.Stringer to name its members`)
+	qt.Check(t, func() { bindnode.TypedEnum((*InferColor)(nil), "Red") },
+		qt.PanicMatches, `bindnode: enum member Red must be of type bindnode_test.InferColor, not string`)
+}
+
+func TestInferSameName(t *testing.T) {
+	// Two different Go types of the same name can't both be inferred.
+	first := func() interface{} {
+		type InferItem struct{ A string }
+		return (*InferItem)(nil)
+	}()
+	second := func() interface{} {
+		type InferItem struct{ B int64 }
+		return (*InferItem)(nil)
+	}()
+	qt.Check(t, bindnode.Prototype(first, nil).Type().(*schema.TypeStruct).Field("A"), qt.IsNotNil)
+	qt.Check(t, func() { bindnode.Prototype(second, nil) }, qt.PanicMatches,
+		`bindnode: cannot infer schema type InferItem from Go type bindnode_test.InferItem .*: it was already inferred from a different Go type of the same name .*`)
+
+	// The same Go type inferred with different options gets different schema types.
+	plain := bindnode.Prototype((*InferSquare)(nil), nil)
+	qt.Check(t, plain.Type().(*schema.TypeStruct).Field("Color").Type().TypeKind(), qt.Equals, schema.TypeKind_String)
+	withEnum := bindnode.Prototype((*InferSquare)(nil), nil, bindnode.TypedEnum((*InferColor)(nil), InferRed, InferBlue))
+	qt.Check(t, withEnum.Type().(*schema.TypeStruct).Field("Color").Type().TypeKind(), qt.Equals, schema.TypeKind_Enum)
+	qt.Check(t, bindnode.Prototype((*InferSquare)(nil), nil).Type(), qt.Equals, plain.Type())
+}
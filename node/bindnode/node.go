@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipld/go-ipld-prime/datamodel"
@@ -48,14 +49,18 @@ var (
 	_ datamodel.ListAssembler = (*_listAssemblerRepr)(nil)
 	_ datamodel.ListAssembler = (*_listStructAssemblerRepr)(nil)
 	_ datamodel.ListAssembler = (*_listpairsFieldListAssemblerRepr)(nil)
+	_ datamodel.ListAssembler = (*_listpairsMapAssemblerRepr)(nil)
 	_ datamodel.ListIterator  = (*_listIterator)(nil)
 	_ datamodel.ListIterator  = (*_tupleIteratorRepr)(nil)
 	_ datamodel.ListIterator  = (*_listpairsIteratorRepr)(nil)
+	_ datamodel.ListIterator  = (*_mapListpairsIteratorRepr)(nil)
 
 	_ datamodel.MapAssembler = (*_unionAssembler)(nil)
 	_ datamodel.MapAssembler = (*_unionAssemblerRepr)(nil)
+	_ datamodel.MapAssembler = (*_unionEnvelopeAssemblerRepr)(nil)
 	_ datamodel.MapIterator  = (*_unionIterator)(nil)
 	_ datamodel.MapIterator  = (*_unionIteratorRepr)(nil)
+	_ datamodel.MapIterator  = (*_unionEnvelopeIteratorRepr)(nil)
 )
 
 type _prototype struct {
@@ -121,9 +126,10 @@ func (w *_node) Kind() datamodel.Kind {
 // matching schema level types to data model kinds, since our Node and Builder
 // interfaces operate on kinds
 func compatibleKind(schemaType schema.Type, kind datamodel.Kind) error {
-	switch sch := schemaType.(type) {
-	case *schema.TypeAny:
+	if heldAsNode(schemaType) {
 		return nil
+	}
+	switch sch := schemaType.(type) {
 	default:
 		actual := actualKind(sch) // ActsLike data model
 		if actual == kind {
@@ -148,6 +154,17 @@ func compatibleKind(schemaType schema.Type, kind datamodel.Kind) error {
 	}
 }
 
+// heldAsNode reports whether the Go values for a schema type are datamodel.Nodes.
+// This is the case for Any, and for types with an advanced representation:
+// the data for those is the ADL's substrate, which the schema says nothing about,
+// so it's kept as it is, to be reified by whatever implements the ADL.
+func heldAsNode(schemaType schema.Type) bool {
+	if _, ok := schemaType.(*schema.TypeAny); ok {
+		return true
+	}
+	return schema.AdvancedLayoutOf(schemaType) != ""
+}
+
 func actualKind(schemaType schema.Type) datamodel.Kind {
 	return schemaType.TypeKind().ActsLike()
 }
@@ -219,7 +236,7 @@ func (w *_node) LookupByString(key string) (datamodel.Node, error) {
 				fval = fval.Elem()
 			}
 		}
-		if _, ok := field.Type().(*schema.TypeAny); ok {
+		if heldAsNode(field.Type()) {
 			if customConverter := w.cfg.converterFor(field.Type().Name(), fval); customConverter != nil {
 				// field is an Any and we have a custom type converter for the type
 				return customConverter.customToAny(ptrVal(fval).Interface())
@@ -264,7 +281,7 @@ func (w *_node) LookupByString(key string) (datamodel.Node, error) {
 			}
 			fval = fval.Elem()
 		}
-		if _, ok := typ.ValueType().(*schema.TypeAny); ok {
+		if heldAsNode(typ.ValueType()) {
 			if customConverter := w.cfg.converterFor(typ.ValueType().Name(), fval); customConverter != nil {
 				// value is an Any and we have a custom type converter for the type
 				return customConverter.customToAny(ptrVal(fval).Interface())
@@ -305,11 +322,36 @@ func (w *_node) LookupByString(key string) (datamodel.Node, error) {
 
 var invalidValue reflect.Value
 
+// goFieldIndexes returns the index of the Go struct field holding each schema
+// struct field or union member, in order. Blank fields, such as a "_ struct{}"
+// tagged `ipld:"union,keyed"`, only carry tags for inferSchema; they are skipped.
+func goFieldIndexes(typ reflect.Type) []int {
+	if cached, ok := goFieldIndexCache.Load(typ); ok {
+		return cached.([]int)
+	}
+	indexes := make([]int, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Name == "_" {
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	goFieldIndexCache.Store(typ, indexes)
+	return indexes
+}
+
+var goFieldIndexCache sync.Map // map[reflect.Type][]int
+
+// goField returns the Go struct field holding the i-th schema struct field or union member.
+func goField(val reflect.Value, i int) reflect.Value {
+	return val.Field(goFieldIndexes(val.Type())[i])
+}
+
 // unionMember finds which union member is set in the corresponding Go struct.
 func unionMember(val reflect.Value) (int, reflect.Value) {
 	// The first non-nil field is a match.
-	for i := 0; i < val.NumField(); i++ {
-		elemVal := val.Field(i)
+	for i, goIdx := range goFieldIndexes(val.Type()) {
+		elemVal := val.Field(goIdx)
 		if elemVal.Kind() != reflect.Ptr {
 			panic("bindnode bug: found unexpected non-pointer in a union field")
 		}
@@ -326,7 +368,7 @@ func unionSetMember(val reflect.Value, memberIdx int, memberPtr reflect.Value) {
 	val.Set(reflect.Zero(val.Type()))
 
 	// Set the index pointer to the given value.
-	val.Field(memberIdx).Set(memberPtr)
+	goField(val, memberIdx).Set(memberPtr)
 }
 
 func (w *_node) LookupByIndex(idx int64) (datamodel.Node, error) {
@@ -338,7 +380,7 @@ func (w *_node) LookupByIndex(idx int64) (datamodel.Node, error) {
 			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
 		}
 		val = val.Index(int(idx))
-		_, isAny := typ.ValueType().(*schema.TypeAny)
+		isAny := heldAsNode(typ.ValueType())
 		if isAny {
 			if customConverter := w.cfg.converterFor(typ.ValueType().Name(), val); customConverter != nil {
 				// values are Any and we have a converter for this type that will give us
@@ -531,7 +573,23 @@ func (w *_node) AsString() (string, error) {
 		// user has registered a converter that takes the underlying type and returns a string
 		return customConverter.customToString(ptrVal(w.val).Interface())
 	}
-	return nonPtrVal(w.val).String(), nil
+	val := nonPtrVal(w.val)
+	if stg, ok := reprStrategy(w.schemaType).(schema.EnumRepresentation_Int); ok && val.Kind() != reflect.String {
+		// Enums held as Go integers store the representation of their member.
+		var i int
+		if kindInt[val.Kind()] {
+			i = int(val.Int())
+		} else {
+			i = int(val.Uint())
+		}
+		for member, reprInt := range stg {
+			if reprInt == i {
+				return member, nil
+			}
+		}
+		return "", fmt.Errorf("AsString: %d is not the representation of any member of enum %s", i, w.schemaType.Name())
+	}
+	return val.String(), nil
 }
 
 func (w *_node) AsBytes() ([]byte, error) {
@@ -657,8 +715,7 @@ func (w *basicMapAssembler) Finish() error {
 }
 
 func (w *_assembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
-	switch typ := w.schemaType.(type) {
-	case *schema.TypeAny:
+	if heldAsNode(w.schemaType) {
 		basicBuilder := basicnode.Prototype.Any.NewBuilder()
 		mapAsm, err := basicBuilder.BeginMap(sizeHint)
 		if err != nil {
@@ -666,10 +723,12 @@ func (w *_assembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
 		}
 		converter := w.cfg.converterFor(w.schemaType.Name(), w.val)
 		return &basicMapAssembler{MapAssembler: mapAsm, builder: basicBuilder, parent: w, converter: converter}, nil
+	}
+	switch typ := w.schemaType.(type) {
 	case *schema.TypeStruct:
 		val := w.createNonPtrVal()
 		// _structAssembler walks through the fields in order as the entries are
-		// assembled, verifyCompatibility() should mean it's safe to assume that
+		// assembled, checkCompatibility() should mean it's safe to assume that
 		// they match the schema, but we need to keep track of the fields that are
 		// set in case of premature Finish()
 		doneFields := make([]bool, val.NumField())
@@ -751,8 +810,7 @@ func (w *basicListAssembler) Finish() error {
 }
 
 func (w *_assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
-	switch typ := w.schemaType.(type) {
-	case *schema.TypeAny:
+	if heldAsNode(w.schemaType) {
 		basicBuilder := basicnode.Prototype.Any.NewBuilder()
 		listAsm, err := basicBuilder.BeginList(sizeHint)
 		if err != nil {
@@ -760,6 +818,8 @@ func (w *_assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error)
 		}
 		converter := w.cfg.converterFor(w.schemaType.Name(), w.val)
 		return &basicListAssembler{ListAssembler: listAsm, builder: basicBuilder, parent: w, converter: converter}, nil
+	}
+	switch typ := w.schemaType.(type) {
 	case *schema.TypeList:
 		// we should be able to safely assume we're dealing with a Go slice here,
 		// so _listAssembler can append to that
@@ -780,7 +840,7 @@ func (w *_assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error)
 }
 
 func (w *_assembler) AssignNull() error {
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	if customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val); customConverter != nil && isAny {
 		// an Any field that is being assigned a Null, we pass the Null directly to
 		// the converter, regardless of whether this field is nullable or not
@@ -813,7 +873,7 @@ func (w *_assembler) AssignBool(b bool) error {
 		return err
 	}
 	customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val)
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	if customConverter != nil {
 		var typ interface{}
 		var err error
@@ -851,7 +911,7 @@ func (w *_assembler) assignUInt(uin datamodel.UintNode) error {
 	if err := compatibleKind(w.schemaType, datamodel.Kind_Int); err != nil {
 		return err
 	}
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	// TODO: customConverter for uint??
 	if isAny {
 		// Any means the Go type must receive a datamodel.Node
@@ -882,7 +942,7 @@ func (w *_assembler) AssignInt(i int64) error {
 	}
 	// TODO: check for overflow
 	customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val)
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	if customConverter != nil {
 		var typ interface{}
 		var err error
@@ -927,7 +987,7 @@ func (w *_assembler) AssignFloat(f float64) error {
 		return err
 	}
 	customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val)
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	if customConverter != nil {
 		var typ interface{}
 		var err error
@@ -966,7 +1026,7 @@ func (w *_assembler) AssignString(s string) error {
 		return err
 	}
 	customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val)
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	if customConverter != nil {
 		var typ interface{}
 		var err error
@@ -985,9 +1045,21 @@ func (w *_assembler) AssignString(s string) error {
 		}
 		w.createNonPtrVal().Set(matchSettable(typ, w.val))
 	} else {
+		stg, isEnumInt := reprStrategy(w.schemaType).(schema.EnumRepresentation_Int)
 		if isAny {
 			// Any means the Go type must receive a datamodel.Node
 			w.createNonPtrVal().Set(reflect.ValueOf(basicnode.NewString(s)))
+		} else if kind := nonPtrType(w.val).Kind(); isEnumInt && kind != reflect.String {
+			// Enums held as Go integers store the representation of their member.
+			reprInt, ok := stg[s]
+			if !ok {
+				return fmt.Errorf("AssignString: %q is not a valid member of enum %s", s, w.schemaType.Name())
+			}
+			if kindInt[kind] {
+				w.createNonPtrVal().SetInt(int64(reprInt))
+			} else {
+				w.createNonPtrVal().SetUint(uint64(reprInt))
+			}
 		} else {
 			w.createNonPtrVal().SetString(s)
 		}
@@ -1005,7 +1077,7 @@ func (w *_assembler) AssignBytes(p []byte) error {
 		return err
 	}
 	customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val)
-	_, isAny := w.schemaType.(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType)
 	if customConverter != nil {
 		var typ interface{}
 		var err error
@@ -1043,7 +1115,7 @@ func (w *_assembler) AssignLink(link datamodel.Link) error {
 	val := w.createNonPtrVal()
 	// TODO: newVal.Type() panics if link==nil; add a test and fix.
 	customConverter := w.cfg.converterFor(w.schemaType.Name(), w.val)
-	if _, ok := w.schemaType.(*schema.TypeAny); ok {
+	if heldAsNode(w.schemaType) {
 		if customConverter != nil {
 			// field is an Any, so the converter will be an Any converter that wants
 			// a datamodel.Node to convert to whatever the underlying Go type is
@@ -1199,9 +1271,10 @@ func (w *_structAssembler) AssembleEntry(k string) (datamodel.NodeAssembler, err
 
 func (w *_structAssembler) Finish() error {
 	fields := w.schemaType.Fields()
+	goIndexes := goFieldIndexes(w.val.Type())
 	var missing []string
 	for i, field := range fields {
-		if !field.IsOptional() && !w.doneFields[i] {
+		if !field.IsOptional() && !w.doneFields[goIndexes[i]] {
 			missing = append(missing, field.Name())
 		}
 	}
@@ -1384,7 +1457,7 @@ func (w *_unionAssembler) AssembleValue() datamodel.NodeAssembler {
 		}
 	}
 
-	goType := w.val.Field(idx).Type().Elem()
+	goType := goField(w.val, idx).Type().Elem()
 	valPtr := reflect.New(goType)
 	finish := func() error {
 		unionSetMember(w.val, idx, valPtr)
@@ -1450,7 +1523,7 @@ func (w *_structIterator) Next() (key, value datamodel.Node, _ error) {
 		return nil, nil, datamodel.ErrIteratorOverread{}
 	}
 	field := w.fields[w.nextIndex]
-	val := w.val.Field(w.nextIndex)
+	val := goField(w.val, w.nextIndex)
 	w.nextIndex++
 	key = basicnode.NewString(field.Name())
 	if field.IsOptional() {
@@ -1461,7 +1534,7 @@ func (w *_structIterator) Next() (key, value datamodel.Node, _ error) {
 			val = val.Elem()
 		}
 	}
-	_, isAny := field.Type().(*schema.TypeAny)
+	isAny := heldAsNode(field.Type())
 	if isAny {
 		if customConverter := w.cfg.converterFor(field.Type().Name(), val); customConverter != nil {
 			// field is an Any and we have an Any converter which takes the underlying
@@ -1511,7 +1584,7 @@ func (w *_mapIterator) Next() (key, value datamodel.Node, _ error) {
 	w.nextIndex++
 
 	key = newNode(w.cfg, w.schemaType.KeyType(), goKey)
-	_, isAny := w.schemaType.ValueType().(*schema.TypeAny)
+	isAny := heldAsNode(w.schemaType.ValueType())
 	if isAny {
 		if customConverter := w.cfg.converterFor(w.schemaType.ValueType().Name(), val); customConverter != nil {
 			// values of this map are Any and we have an Any converter which takes the
@@ -1562,7 +1635,7 @@ func (w *_listIterator) Next() (index int64, value datamodel.Node, _ error) {
 		}
 		val = val.Elem() // nullable values are pointers
 	}
-	if _, ok := w.schemaType.ValueType().(*schema.TypeAny); ok {
+	if heldAsNode(w.schemaType.ValueType()) {
 		if customConverter := w.cfg.converterFor(w.schemaType.ValueType().Name(), val); customConverter != nil {
 			// values are Any and we have an Any converter which can take whatever
 			// the underlying Go type in this slice is and return a datamodel.Node
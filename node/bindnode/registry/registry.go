@@ -50,8 +50,9 @@ func (br BindnodeRegistry) prototypeDataFor(ptrType interface{}) prototypeData {
 // is defined in the schema for the type being registered.
 // Registering the same type twice on this registry will cause an error.
 // This call may also error if the schema is invalid or the type doesn't match
-// the schema. Additionally, panics from within bindnode's initial prototype
-// checks will be captured and returned as errors from this function.
+// the schema, in which case the error is a bindnode.IncompatibilityError
+// listing every mismatch. Additionally, panics from within bindnode's initial
+// prototype checks will be captured and returned as errors from this function.
 func (br BindnodeRegistry) RegisterType(ptrType interface{}, schema string, typeName string, options ...bindnode.Option) (err error) {
 	typ := typeOf(ptrType)
 	if _, ok := br[typ]; ok {
@@ -65,6 +66,9 @@ func (br BindnodeRegistry) RegisterType(ptrType interface{}, schema string, type
 	if schemaType == nil {
 		return fmt.Errorf("bindnode utils: schema for [%T] does not contain that named type [%s]", ptrType, typ.Name())
 	}
+	if err := bindnode.CheckCompatibility(ptrType, schemaType, options...); err != nil {
+		return err
+	}
 
 	// focusing on bindnode setup panics
 	defer func() {
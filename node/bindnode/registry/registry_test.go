@@ -96,5 +96,7 @@ func TestRegistryErrors(t *testing.T) {
 			NotInt String
 			NotBool Float
 		}`, "Foo")
-	qt.Assert(t, err, qt.ErrorMatches, `.*kind mismatch.*`)
+	qt.Assert(t, err, qt.ErrorMatches, `bindnode: found 2 incompatibilities between the schema and Go types:
+	at \.NotInt \(Go \.Int\): .* kind mismatch; need string
+	at \.NotBool \(Go \.Bool\): .* kind mismatch; need float`)
 }
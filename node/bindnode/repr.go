@@ -1,12 +1,15 @@
 package bindnode
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
 	"reflect"
 	"strings"
 
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/mixins"
 	"github.com/ipld/go-ipld-prime/schema"
 )
 
@@ -31,6 +34,8 @@ func reprStrategy(typ schema.Type) interface{} {
 		return typ.RepresentationStrategy()
 	case *schema.TypeEnum:
 		return typ.RepresentationStrategy()
+	case *schema.TypeMap:
+		return typ.RepresentationStrategy()
 	}
 	return nil
 }
@@ -49,7 +54,7 @@ type _nodeRepr _node
 
 func (w *_nodeRepr) Kind() datamodel.Kind {
 	switch reprStrategy(w.schemaType).(type) {
-	case schema.StructRepresentation_Stringjoin:
+	case schema.StructRepresentation_Stringjoin, schema.StructRepresentation_StringPairs:
 		return datamodel.Kind_String
 	case schema.StructRepresentation_Map:
 		return datamodel.Kind_Map
@@ -66,10 +71,18 @@ func (w *_nodeRepr) Kind() datamodel.Kind {
 		return mtyp.RepresentationBehavior()
 	case schema.UnionRepresentation_Stringprefix:
 		return datamodel.Kind_String
+	case schema.UnionRepresentation_Envelope, schema.UnionRepresentation_Inline:
+		return datamodel.Kind_Map
+	case schema.UnionRepresentation_BytesPrefix:
+		return datamodel.Kind_Bytes
 	case schema.EnumRepresentation_Int:
 		return datamodel.Kind_Int
 	case schema.EnumRepresentation_String:
 		return datamodel.Kind_String
+	case schema.MapRepresentation_StringPairs:
+		return datamodel.Kind_String
+	case schema.MapRepresentation_ListPairs:
+		return datamodel.Kind_List
 	default:
 		return (*_node)(w).Kind()
 	}
@@ -124,13 +137,27 @@ func (w *_nodeRepr) asKinded(stg schema.UnionRepresentation_Kinded, kind datamod
 			continue
 		}
 		w2 := *w
-		w2.val = w.val.Field(i).Elem()
+		w2.val = goField(w.val, i).Elem()
 		w2.schemaType = member
 		return &w2
 	}
 	panic("bindnode TODO: GetMember result is missing?")
 }
 
+// unionMember returns a node representing the member which is set in a union node,
+// along with the member's type.
+func (w *_nodeRepr) unionMember() (*_nodeRepr, schema.Type) {
+	haveIdx, mval := unionMember(w.val)
+	if haveIdx < 0 {
+		panic(fmt.Sprintf("bindnode: union %s has no member", w.val.Type()))
+	}
+	mtyp := w.schemaType.(*schema.TypeUnion).Members()[haveIdx]
+	w2 := *w
+	w2.val = mval
+	w2.schemaType = mtyp
+	return &w2, mtyp
+}
+
 func (w *_nodeRepr) LookupByString(key string) (datamodel.Node, error) {
 	if stg, ok := reprStrategy(w.schemaType).(schema.UnionRepresentation_Kinded); ok {
 		w = w.asKinded(stg, datamodel.Kind_Map)
@@ -150,6 +177,24 @@ func (w *_nodeRepr) LookupByString(key string) (datamodel.Node, error) {
 			return nil, err
 		}
 		return reprNode(v), nil
+	case schema.UnionRepresentation_Envelope:
+		member, mtyp := w.unionMember()
+		switch key {
+		case stg.GetDiscriminantKey():
+			return basicnode.NewString(stg.GetDiscriminant(mtyp)), nil
+		case stg.GetContentKey():
+			return reprNode(newNode(member.cfg, mtyp, member.val)), nil
+		}
+		return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfString(key)}
+	case schema.UnionRepresentation_Inline:
+		member, mtyp := w.unionMember()
+		if key == stg.GetDiscriminantKey() {
+			return basicnode.NewString(stg.GetDiscriminant(mtyp)), nil
+		}
+		if err := checkInlineUnionMember(w.schemaType.(*schema.TypeUnion), mtyp); err != nil {
+			return nil, err
+		}
+		return member.LookupByString(key)
 	default:
 		v, err := (*_node)(w).LookupByString(key)
 		if err != nil {
@@ -164,11 +209,12 @@ func (w *_nodeRepr) LookupByIndex(idx int64) (datamodel.Node, error) {
 	case schema.UnionRepresentation_Kinded:
 		return w.asKinded(stg, datamodel.Kind_List).LookupByIndex(idx)
 	case schema.StructRepresentation_Tuple:
-		fields := w.schemaType.(*schema.TypeStruct).Fields()
-		if idx < 0 || int(idx) >= len(fields) {
+		typ := w.schemaType.(*schema.TypeStruct)
+		indexes := typ.TupleFieldIndexes()
+		if idx < 0 || int(idx) >= len(indexes) {
 			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
 		}
-		field := fields[idx]
+		field := typ.Fields()[indexes[idx]]
 		v, err := (*_node)(w).LookupByString(field.Name())
 		if err != nil {
 			return nil, err
@@ -194,6 +240,17 @@ func (w *_nodeRepr) LookupByIndex(idx int64) (datamodel.Node, error) {
 			curField++
 		}
 		return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
+	case schema.MapRepresentation_ListPairs:
+		if idx < 0 || idx >= (*_node)(w).Length() {
+			return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
+		}
+		iter := (*_node)(w).MapIterator().(*_mapIterator)
+		iter.nextIndex = int(idx)
+		key, value, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		return buildListpairsField(reprNode(key), reprNode(value))
 	default:
 		v, err := (*_node)(w).LookupByIndex(idx)
 		if err != nil {
@@ -250,7 +307,7 @@ func (w *_nodeRepr) MapIterator() datamodel.MapIterator {
 	if stg, ok := reprStrategy(w.schemaType).(schema.UnionRepresentation_Kinded); ok {
 		w = w.asKinded(stg, datamodel.Kind_Map)
 	}
-	switch reprStrategy(w.schemaType).(type) {
+	switch stg := reprStrategy(w.schemaType).(type) {
 	case schema.StructRepresentation_Map:
 		itr := (*_node)(w).MapIterator().(*_structIterator)
 		// When we reach the last non-absent field, we should stop.
@@ -259,6 +316,12 @@ func (w *_nodeRepr) MapIterator() datamodel.MapIterator {
 	case schema.UnionRepresentation_Keyed:
 		itr := (*_node)(w).MapIterator().(*_unionIterator)
 		return (*_unionIteratorRepr)(itr)
+	case schema.UnionRepresentation_Envelope:
+		return &_unionEnvelopeIteratorRepr{node: w, stg: stg}
+	case schema.UnionRepresentation_Inline:
+		return &_unionInlineIteratorRepr{node: w, stg: stg}
+	case schema.MapRepresentation_StringPairs, schema.MapRepresentation_ListPairs:
+		return nil
 	default:
 		iter, _ := (*_node)(w).MapIterator().(*_mapIterator)
 		if iter == nil {
@@ -288,8 +351,7 @@ func (w *_nodeRepr) ListIterator() datamodel.ListIterator {
 	}
 	switch reprStrategy(w.schemaType).(type) {
 	case schema.StructRepresentation_Tuple:
-		typ := w.schemaType.(*schema.TypeStruct)
-		iter := _tupleIteratorRepr{cfg: w.cfg, schemaType: typ, fields: typ.Fields(), val: w.val}
+		iter := _tupleIteratorRepr{node: (*_node)(w), schemaType: w.schemaType.(*schema.TypeStruct)}
 		iter.reprEnd = int(w.lengthMinusTrailingAbsents())
 		return &iter
 	case schema.StructRepresentation_ListPairs:
@@ -297,6 +359,8 @@ func (w *_nodeRepr) ListIterator() datamodel.ListIterator {
 		iter := _listpairsIteratorRepr{cfg: w.cfg, schemaType: typ, fields: typ.Fields(), val: w.val}
 		iter.reprEnd = int(w.lengthMinusTrailingAbsents())
 		return &iter
+	case schema.MapRepresentation_ListPairs:
+		return &_mapListpairsIteratorRepr{(*_node)(w).MapIterator().(*_mapIterator)}
 	default:
 		iter, _ := (*_node)(w).ListIterator().(*_listIterator)
 		if iter == nil {
@@ -324,7 +388,7 @@ func (w *_nodeRepr) lengthMinusAbsents() int64 {
 	fields := w.schemaType.(*schema.TypeStruct).Fields()
 	n := int64(len(fields))
 	for i, field := range fields {
-		if field.IsOptional() && w.val.Field(i).IsNil() {
+		if field.IsOptional() && goField(w.val, i).IsNil() {
 			n--
 		}
 	}
@@ -332,28 +396,24 @@ func (w *_nodeRepr) lengthMinusAbsents() int64 {
 }
 
 type _tupleIteratorRepr struct {
-	// TODO: support embedded fields?
-	cfg        *config
+	node       *_node
 	schemaType *schema.TypeStruct
-	fields     []schema.StructField
-	val        reflect.Value // non-pointer
 	nextIndex  int
-
-	// these are only used in repr.go
-	reprEnd int
+	reprEnd    int
 }
 
 func (w *_tupleIteratorRepr) Next() (index int64, value datamodel.Node, _ error) {
-	for {
-		idx := w.nextIndex
-		_, value, err := (*_structIterator)(w).Next()
-		if err != nil {
-			return 0, nil, err
-		}
-		if w.nextIndex <= w.reprEnd {
-			return int64(idx), reprNode(value), nil
-		}
+	if w.Done() {
+		return 0, nil, datamodel.ErrIteratorOverread{}
+	}
+	idx := w.nextIndex
+	w.nextIndex++
+	field := w.schemaType.Fields()[w.schemaType.TupleFieldIndexes()[idx]]
+	value, err := w.node.LookupByString(field.Name())
+	if err != nil {
+		return 0, nil, err
 	}
+	return int64(idx), reprNode(value), nil
 }
 
 func (w *_tupleIteratorRepr) Done() bool {
@@ -396,6 +456,28 @@ func (w *_listpairsIteratorRepr) Done() bool {
 	return w.nextIndex >= w.reprEnd
 }
 
+// _mapListpairsIteratorRepr yields each entry of a map as a two-element list of key and value.
+type _mapListpairsIteratorRepr struct {
+	iter *_mapIterator
+}
+
+func (w *_mapListpairsIteratorRepr) Next() (index int64, value datamodel.Node, _ error) {
+	idx := w.iter.nextIndex
+	key, value, err := w.iter.Next()
+	if err != nil {
+		return 0, nil, err
+	}
+	field, err := buildListpairsField(reprNode(key), reprNode(value))
+	if err != nil {
+		return 0, nil, err
+	}
+	return int64(idx), field, nil
+}
+
+func (w *_mapListpairsIteratorRepr) Done() bool {
+	return w.iter.Done()
+}
+
 func buildListpairsField(key, value datamodel.Node) (datamodel.Node, error) {
 	nb := basicnode.Prototype.List.NewBuilder()
 	la, err := nb.BeginList(2)
@@ -415,10 +497,16 @@ func buildListpairsField(key, value datamodel.Node) (datamodel.Node, error) {
 }
 
 func (w *_nodeRepr) lengthMinusTrailingAbsents() int64 {
-	fields := w.schemaType.(*schema.TypeStruct).Fields()
+	typ := w.schemaType.(*schema.TypeStruct)
+	fields := typ.Fields()
+	// For a tuple, the trailing fields are the last ones in its field order.
+	indexes := typ.TupleFieldIndexes()
 	for i := len(fields) - 1; i >= 0; i-- {
-		field := fields[i]
-		if !field.IsOptional() || !w.val.Field(i).IsNil() {
+		fieldIdx := i
+		if indexes != nil {
+			fieldIdx = indexes[i]
+		}
+		if !fields[fieldIdx].IsOptional() || !goField(w.val, fieldIdx).IsNil() {
 			return int64(i + 1)
 		}
 	}
@@ -427,7 +515,8 @@ func (w *_nodeRepr) lengthMinusTrailingAbsents() int64 {
 
 func (w *_nodeRepr) Length() int64 {
 	switch stg := reprStrategy(w.schemaType).(type) {
-	case schema.StructRepresentation_Stringjoin:
+	case schema.StructRepresentation_Stringjoin, schema.StructRepresentation_StringPairs,
+		schema.MapRepresentation_StringPairs:
 		return -1
 	case schema.StructRepresentation_Map:
 		return w.lengthMinusAbsents()
@@ -437,6 +526,14 @@ func (w *_nodeRepr) Length() int64 {
 		return w.lengthMinusAbsents()
 	case schema.UnionRepresentation_Keyed:
 		return (*_node)(w).Length()
+	case schema.UnionRepresentation_Envelope:
+		return 2
+	case schema.UnionRepresentation_Inline:
+		member, mtyp := w.unionMember()
+		if checkInlineUnionMember(w.schemaType.(*schema.TypeUnion), mtyp) != nil {
+			return 1 // just the discriminant
+		}
+		return 1 + member.Length()
 	case schema.UnionRepresentation_Kinded:
 		w = w.asKinded(stg, w.Kind())
 		return (*_node)(w).Length()
@@ -542,14 +639,13 @@ func (w *_nodeRepr) AsString() (string, error) {
 			b.WriteString(s)
 		}
 		return b.String(), nil
+	case schema.StructRepresentation_StringPairs:
+		return stringpairsAsString((*_node)(w).MapIterator(), stg.GetInnerDelim(), stg.GetEntryDelim())
+	case schema.MapRepresentation_StringPairs:
+		return stringpairsAsString((*_node)(w).MapIterator(), stg.GetInnerDelim(), stg.GetEntryDelim())
 	case schema.UnionRepresentation_Stringprefix:
-		haveIdx, mval := unionMember(w.val)
-		mtyp := w.schemaType.(*schema.TypeUnion).Members()[haveIdx]
-
-		w2 := *w
-		w2.val = mval
-		w2.schemaType = mtyp
-		s, err := w2.AsString()
+		member, mtyp := w.unionMember()
+		s, err := member.AsString()
 		if err != nil {
 			return "", err
 		}
@@ -584,10 +680,48 @@ func (w *_nodeRepr) AsString() (string, error) {
 	}
 }
 
+// stringpairsAsString joins each key and value from a type-level map iterator
+// with the inner delimiter, and each resulting pair with the entry delimiter.
+func stringpairsAsString(itr datamodel.MapIterator, innerDelim, entryDelim string) (string, error) {
+	var b strings.Builder
+	for first := true; !itr.Done(); first = false {
+		k, v, err := itr.Next()
+		if err != nil {
+			return "", err
+		}
+		ks, err := reprNode(k).AsString()
+		if err != nil {
+			return "", err
+		}
+		vs, err := reprNode(v).AsString()
+		if err != nil {
+			return "", err
+		}
+		if !first {
+			b.WriteString(entryDelim)
+		}
+		b.WriteString(ks)
+		b.WriteString(innerDelim)
+		b.WriteString(vs)
+	}
+	return b.String(), nil
+}
+
 func (w *_nodeRepr) AsBytes() ([]byte, error) {
 	switch stg := reprStrategy(w.schemaType).(type) {
 	case schema.UnionRepresentation_Kinded:
 		return w.asKinded(stg, datamodel.Kind_Bytes).AsBytes()
+	case schema.UnionRepresentation_BytesPrefix:
+		member, mtyp := w.unionMember()
+		b, err := member.AsBytes()
+		if err != nil {
+			return nil, err
+		}
+		prefix, err := hex.DecodeString(stg.GetDiscriminant(mtyp))
+		if err != nil {
+			return nil, err
+		}
+		return append(prefix, b...), nil
 	default:
 		return (*_node)(w).AsBytes()
 	}
@@ -646,6 +780,28 @@ func assemblerRepr(am datamodel.NodeAssembler) datamodel.NodeAssembler {
 	}
 }
 
+// asMember can be called on a union assembler to obtain an assembler
+// for the member at the given index, which becomes the union's value once finished.
+func (w *_assemblerRepr) asMember(idx int) *_assemblerRepr {
+	w2 := *w
+	goType := goField(w.val, idx).Type().Elem()
+	valPtr := reflect.New(goType)
+	w2.val = valPtr.Elem()
+	w2.schemaType = w.schemaType.(*schema.TypeUnion).Members()[idx]
+
+	// Layer a new finish func on top, to set Index/Value.
+	w2.finish = func() error {
+		unionSetMember(w.val, idx, valPtr)
+		if w.finish != nil {
+			if err := w.finish(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return &w2
+}
+
 func (w *_assemblerRepr) asKinded(stg schema.UnionRepresentation_Kinded, kind datamodel.Kind) datamodel.NodeAssembler {
 	name := stg.GetMember(kind)
 	members := w.schemaType.(*schema.TypeUnion).Members()
@@ -655,23 +811,7 @@ func (w *_assemblerRepr) asKinded(stg schema.UnionRepresentation_Kinded, kind da
 			kindSet = append(kindSet, member.RepresentationBehavior())
 			continue
 		}
-		w2 := *w
-		goType := w.val.Field(idx).Type().Elem()
-		valPtr := reflect.New(goType)
-		w2.val = valPtr.Elem()
-		w2.schemaType = member
-
-		// Layer a new finish func on top, to set Index/Value.
-		w2.finish = func() error {
-			unionSetMember(w.val, idx, valPtr)
-			if w.finish != nil {
-				if err := w.finish(); err != nil {
-					return err
-				}
-			}
-			return nil
-		}
-		return &w2
+		return w.asMember(idx)
 	}
 	return _errorAssembler{datamodel.ErrWrongKind{
 		TypeName:        w.schemaType.Name() + ".Repr",
@@ -682,8 +822,23 @@ func (w *_assemblerRepr) asKinded(stg schema.UnionRepresentation_Kinded, kind da
 }
 
 func (w *_assemblerRepr) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
-	if stg, ok := reprStrategy(w.schemaType).(schema.UnionRepresentation_Kinded); ok {
+	switch stg := reprStrategy(w.schemaType).(type) {
+	case schema.UnionRepresentation_Kinded:
 		return w.asKinded(stg, datamodel.Kind_Map).BeginMap(sizeHint)
+	case schema.MapRepresentation_StringPairs:
+		return nil, datamodel.ErrWrongKind{
+			TypeName:        w.schemaType.Name() + ".Repr",
+			MethodName:      "BeginMap",
+			AppropriateKind: datamodel.KindSet_JustString,
+			ActualKind:      datamodel.Kind_Map,
+		}
+	case schema.MapRepresentation_ListPairs:
+		return nil, datamodel.ErrWrongKind{
+			TypeName:        w.schemaType.Name() + ".Repr",
+			MethodName:      "BeginMap",
+			AppropriateKind: datamodel.KindSet_JustList,
+			ActualKind:      datamodel.Kind_Map,
+		}
 	}
 	asm, err := (*_assembler)(w).BeginMap(sizeHint)
 	if err != nil {
@@ -695,6 +850,12 @@ func (w *_assemblerRepr) BeginMap(sizeHint int64) (datamodel.MapAssembler, error
 	case *_mapAssembler:
 		return (*_mapAssemblerRepr)(asm), nil
 	case *_unionAssembler:
+		switch stg := reprStrategy(w.schemaType).(type) {
+		case schema.UnionRepresentation_Envelope:
+			return &_unionEnvelopeAssemblerRepr{union: asm, stg: stg}, nil
+		case schema.UnionRepresentation_Inline:
+			return &_unionInlineAssemblerRepr{union: asm, stg: stg}, nil
+		}
 		return (*_unionAssemblerRepr)(asm), nil
 	case *basicMapAssembler:
 		return asm, nil
@@ -713,6 +874,12 @@ func (w *_assemblerRepr) BeginList(sizeHint int64) (datamodel.ListAssembler, err
 			return nil, err
 		}
 		return (*_listStructAssemblerRepr)(asm.(*_structAssembler)), nil
+	case schema.MapRepresentation_ListPairs:
+		asm, err := (*_assembler)(w).BeginMap(sizeHint)
+		if err != nil {
+			return nil, err
+		}
+		return (*_listpairsMapAssemblerRepr)(asm.(*_mapAssembler)), nil
 	default:
 		asm, err := (*_assembler)(w).BeginList(sizeHint)
 		if err != nil {
@@ -825,6 +992,10 @@ func (w *_assemblerRepr) AssignString(s string) error {
 			}
 		}
 		return mapAsm.Finish()
+	case schema.StructRepresentation_StringPairs:
+		return w.assignStringpairs(s, stg.GetInnerDelim(), stg.GetEntryDelim())
+	case schema.MapRepresentation_StringPairs:
+		return w.assignStringpairs(s, stg.GetInnerDelim(), stg.GetEntryDelim())
 	case schema.UnionRepresentation_Kinded:
 		return w.asKinded(stg, datamodel.Kind_String).AssignString(s)
 	case schema.UnionRepresentation_Stringprefix:
@@ -853,22 +1024,7 @@ func (w *_assemblerRepr) AssignString(s string) error {
 				remainder = s[len(descrm):]
 			}
 
-			// TODO: DRY: this has much in common with the asKinded method; it differs only in that we picked idx already in a different way.
-			w2 := *w
-			goType := w.val.Field(idx).Type().Elem()
-			valPtr := reflect.New(goType)
-			w2.val = valPtr.Elem()
-			w2.schemaType = member
-			w2.finish = func() error {
-				unionSetMember(w.val, idx, valPtr)
-				if w.finish != nil {
-					if err := w.finish(); err != nil {
-						return err
-					}
-				}
-				return nil
-			}
-			return w2.AssignString(remainder)
+			return w.asMember(idx).AssignString(remainder)
 		}
 		return fmt.Errorf("schema rejects data: the union type %s requires a known prefix, and it was not found in the data %q", w.schemaType.Name(), s)
 	case schema.EnumRepresentation_String:
@@ -878,9 +1034,10 @@ func (w *_assemblerRepr) AssignString(s string) error {
 				return (*_assembler)(w).AssignString(member)
 			}
 		}
+		// Members which the representation doesn't mention are represented by their own name.
 		members := w.schemaType.(*schema.TypeEnum).Members()
 		for _, member := range members {
-			if s == member {
+			if _, mapped := stg[member]; s == member && !mapped {
 				return (*_assembler)(w).AssignString(member)
 			}
 		}
@@ -897,10 +1054,53 @@ func (w *_assemblerRepr) AssignString(s string) error {
 	}
 }
 
+// assignStringpairs splits a stringpairs string into its entries,
+// and assembles each of them into a struct or map via the type-level map assembler.
+// The empty string is an empty set of entries.
+func (w *_assemblerRepr) assignStringpairs(s, innerDelim, entryDelim string) error {
+	pairs, err := mixins.SplitPairs(s, innerDelim, entryDelim)
+	if err != nil {
+		return fmt.Errorf("schema rejects data: the type %s expects stringpairs: %w", w.schemaType.Name(), err)
+	}
+	mapAsm, err := (*_assembler)(w).BeginMap(int64(len(pairs)))
+	if err != nil {
+		return err
+	}
+	for _, kv := range pairs {
+		keyAsm := assemblerRepr(mapAsm.AssembleKey())
+		if err := keyAsm.AssignString(kv[0]); err != nil {
+			return err
+		}
+		valAsm := assemblerRepr(mapAsm.AssembleValue())
+		if err := valAsm.AssignString(kv[1]); err != nil {
+			return err
+		}
+	}
+	return mapAsm.Finish()
+}
+
 func (w *_assemblerRepr) AssignBytes(p []byte) error {
 	switch stg := reprStrategy(w.schemaType).(type) {
 	case schema.UnionRepresentation_Kinded:
 		return w.asKinded(stg, datamodel.Kind_Bytes).AssignBytes(p)
+	case schema.UnionRepresentation_BytesPrefix:
+		// Prefixes may overlap, in which case the longest one wins, just like with schema.Validate.
+		members := w.schemaType.(*schema.TypeUnion).Members()
+		matched := -1
+		var matchedPrefix []byte
+		for idx, member := range members {
+			prefix, err := hex.DecodeString(stg.GetDiscriminant(member))
+			if err != nil {
+				return err
+			}
+			if bytes.HasPrefix(p, prefix) && (matched < 0 || len(prefix) > len(matchedPrefix)) {
+				matched, matchedPrefix = idx, prefix
+			}
+		}
+		if matched >= 0 {
+			return w.asMember(matched).AssignBytes(p[len(matchedPrefix):])
+		}
+		return fmt.Errorf("schema rejects data: the union type %s requires a known prefix, and it was not found in the data %x", w.schemaType.Name(), p)
 	default:
 		return (*_assembler)(w).AssignBytes(p)
 	}
@@ -1040,14 +1240,13 @@ type _listStructAssemblerRepr _structAssembler
 func (w *_listStructAssemblerRepr) AssembleValue() datamodel.NodeAssembler {
 	switch stg := reprStrategy(w.schemaType).(type) {
 	case schema.StructRepresentation_Tuple:
-		fields := w.schemaType.Fields()
-		if w.nextIndex >= len(fields) {
+		indexes := w.schemaType.TupleFieldIndexes()
+		if w.nextIndex >= len(indexes) {
 			return _errorAssembler{datamodel.ErrNotExists{
 				Segment: datamodel.PathSegmentOfInt(int64(w.nextIndex)),
 			}}
 		}
-		field := fields[w.nextIndex]
-		w.doneFields[w.nextIndex] = true
+		field := w.schemaType.Fields()[indexes[w.nextIndex]]
 		w.nextIndex++
 
 		entryAsm, err := (*_structAssembler)(w).AssembleEntry(field.Name())
@@ -1057,7 +1256,7 @@ func (w *_listStructAssemblerRepr) AssembleValue() datamodel.NodeAssembler {
 		entryAsm = assemblerRepr(entryAsm)
 		return entryAsm
 	case schema.StructRepresentation_ListPairs:
-		return &_listpairsFieldAssemblerRepr{parent: (*_structAssembler)(w)}
+		return &_listpairsFieldAssemblerRepr{parent: (*_structAssembler)(w), schemaType: w.schemaType}
 	default:
 		return _errorAssembler{fmt.Errorf("bindnode AssembleValue TODO: %T", stg)}
 	}
@@ -1076,13 +1275,16 @@ func (w *_listStructAssemblerRepr) ValuePrototype(idx int64) datamodel.NodeProto
 	panic("bindnode TODO: list ValuePrototype")
 }
 
+// _listpairsFieldAssemblerRepr assembles a single [key, value] pair
+// into a struct or map with a listpairs representation.
 type _listpairsFieldAssemblerRepr struct {
-	parent *_structAssembler
+	parent     datamodel.MapAssembler // a *_structAssembler or *_mapAssembler
+	schemaType schema.Type
 }
 
 func (w _listpairsFieldAssemblerRepr) BeginMap(int64) (datamodel.MapAssembler, error) {
 	return nil, datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "BeginMap",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1093,7 +1295,7 @@ func (w *_listpairsFieldAssemblerRepr) BeginList(int64) (datamodel.ListAssembler
 }
 func (w _listpairsFieldAssemblerRepr) AssignNull() error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignNull",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1101,7 +1303,7 @@ func (w _listpairsFieldAssemblerRepr) AssignNull() error {
 }
 func (w _listpairsFieldAssemblerRepr) AssignBool(bool) error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignBool",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1109,7 +1311,7 @@ func (w _listpairsFieldAssemblerRepr) AssignBool(bool) error {
 }
 func (w _listpairsFieldAssemblerRepr) AssignInt(int64) error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignInt",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1117,7 +1319,7 @@ func (w _listpairsFieldAssemblerRepr) AssignInt(int64) error {
 }
 func (w _listpairsFieldAssemblerRepr) AssignFloat(float64) error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignFloat",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1125,7 +1327,7 @@ func (w _listpairsFieldAssemblerRepr) AssignFloat(float64) error {
 }
 func (w _listpairsFieldAssemblerRepr) AssignString(string) error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignString",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1133,7 +1335,7 @@ func (w _listpairsFieldAssemblerRepr) AssignString(string) error {
 }
 func (w _listpairsFieldAssemblerRepr) AssignBytes([]byte) error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignBytes",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1141,7 +1343,7 @@ func (w _listpairsFieldAssemblerRepr) AssignBytes([]byte) error {
 }
 func (w _listpairsFieldAssemblerRepr) AssignLink(datamodel.Link) error {
 	return datamodel.ErrWrongKind{
-		TypeName:        w.parent.schemaType.Name(),
+		TypeName:        w.schemaType.Name(),
 		MethodName:      "AssignLink",
 		AppropriateKind: datamodel.KindSet_JustList,
 		ActualKind:      datamodel.Kind_Map,
@@ -1157,7 +1359,7 @@ func (w _listpairsFieldAssemblerRepr) Prototype() datamodel.NodePrototype {
 }
 
 type _listpairsFieldListAssemblerRepr struct {
-	parent *_structAssembler
+	parent datamodel.MapAssembler
 	idx    int
 }
 
@@ -1165,10 +1367,9 @@ func (w *_listpairsFieldListAssemblerRepr) AssembleValue() datamodel.NodeAssembl
 	w.idx++
 	switch w.idx {
 	case 1:
-		return w.parent.AssembleKey()
+		return assemblerRepr(w.parent.AssembleKey())
 	case 2:
-		asm := w.parent.AssembleValue()
-		return assemblerRepr(asm.(*_assembler))
+		return assemblerRepr(w.parent.AssembleValue())
 	default:
 		return _errorAssembler{fmt.Errorf("bindnode: too many values in listpairs field")}
 	}
@@ -1182,6 +1383,22 @@ func (w *_listpairsFieldListAssemblerRepr) ValuePrototype(idx int64) datamodel.N
 	panic("bindnode TODO: listpairs field ValuePrototype")
 }
 
+// _listpairsMapAssemblerRepr assembles a map with a listpairs representation,
+// where each list element is a [key, value] pair.
+type _listpairsMapAssemblerRepr _mapAssembler
+
+func (w *_listpairsMapAssemblerRepr) AssembleValue() datamodel.NodeAssembler {
+	return &_listpairsFieldAssemblerRepr{parent: (*_mapAssembler)(w), schemaType: w.schemaType}
+}
+
+func (w *_listpairsMapAssemblerRepr) Finish() error {
+	return (*_mapAssembler)(w).Finish()
+}
+
+func (w *_listpairsMapAssemblerRepr) ValuePrototype(idx int64) datamodel.NodePrototype {
+	panic("bindnode TODO: listpairs map ValuePrototype")
+}
+
 // Note that lists do not have any representation strategy right now.
 type _listAssemblerRepr _listAssembler
 
@@ -1249,6 +1466,280 @@ func (w *_unionAssemblerRepr) ValuePrototype(k string) datamodel.NodePrototype {
 	panic("bindnode TODO: union ValuePrototype")
 }
 
+// _unionEnvelopeAssemblerRepr assembles an envelope union from a map with two entries.
+// The entries may come in either order; if the content comes before the discriminant,
+// it's assembled as a basic node first, and copied into the member once the discriminant is known.
+type _unionEnvelopeAssemblerRepr struct {
+	union *_unionAssembler
+	stg   schema.UnionRepresentation_Envelope
+
+	discriminant    _assembler
+	hasDiscriminant bool
+	hasContent      bool
+	buffered        datamodel.NodeBuilder
+}
+
+func (w *_unionEnvelopeAssemblerRepr) AssembleKey() datamodel.NodeAssembler {
+	return w.union.AssembleKey()
+}
+
+func (w *_unionEnvelopeAssemblerRepr) AssembleValue() datamodel.NodeAssembler {
+	key := w.union.curKey.val.String()
+	switch key {
+	case w.stg.GetDiscriminantKey():
+		if w.hasDiscriminant {
+			return _errorAssembler{datamodel.ErrRepeatedMapKey{Key: basicnode.NewString(key)}}
+		}
+		w.discriminant = _assembler{
+			cfg:        w.union.cfg,
+			schemaType: schemaTypeString,
+			val:        reflect.New(goTypeString).Elem(),
+			finish: func() error {
+				w.hasDiscriminant = true
+				return nil
+			},
+		}
+		return &w.discriminant
+	case w.stg.GetContentKey():
+		if w.hasContent {
+			return _errorAssembler{datamodel.ErrRepeatedMapKey{Key: basicnode.NewString(key)}}
+		}
+		w.hasContent = true
+		if !w.hasDiscriminant {
+			w.buffered = basicnode.Prototype.Any.NewBuilder()
+			return w.buffered
+		}
+		asm, err := w.member()
+		if err != nil {
+			return _errorAssembler{err}
+		}
+		return asm
+	default:
+		return _errorAssembler{schema.ErrInvalidKey{
+			TypeName: w.union.schemaType.Name() + ".Repr",
+			Key:      basicnode.NewString(key),
+		}}
+	}
+}
+
+// member returns an assembler for the member named by the discriminant.
+func (w *_unionEnvelopeAssemblerRepr) member() (*_assemblerRepr, error) {
+	discriminant := w.discriminant.val.String()
+	name := w.stg.GetMember(discriminant)
+	for idx, member := range w.union.schemaType.Members() {
+		if member.Name() == name {
+			union := &_assemblerRepr{cfg: w.union.cfg, schemaType: w.union.schemaType, val: w.union.val}
+			return union.asMember(idx), nil
+		}
+	}
+	return nil, schema.ErrNotUnionStructure{
+		TypeName: w.union.schemaType.Name(),
+		Detail:   fmt.Sprintf("no member with discriminant %q", discriminant),
+	}
+}
+
+func (w *_unionEnvelopeAssemblerRepr) AssembleEntry(k string) (datamodel.NodeAssembler, error) {
+	if err := w.AssembleKey().AssignString(k); err != nil {
+		return nil, err
+	}
+	am := w.AssembleValue()
+	return am, nil
+}
+
+func (w *_unionEnvelopeAssemblerRepr) Finish() error {
+	if !w.hasDiscriminant || !w.hasContent {
+		return schema.ErrNotUnionStructure{
+			TypeName: w.union.schemaType.Name(),
+			Detail:   fmt.Sprintf("an envelope union needs both the %q and %q keys", w.stg.GetDiscriminantKey(), w.stg.GetContentKey()),
+		}
+	}
+	if w.buffered != nil {
+		asm, err := w.member()
+		if err != nil {
+			return err
+		}
+		if err := asm.AssignNode(w.buffered.Build()); err != nil {
+			return err
+		}
+	}
+	return w.union.Finish()
+}
+
+func (w *_unionEnvelopeAssemblerRepr) KeyPrototype() datamodel.NodePrototype {
+	return w.union.KeyPrototype()
+}
+
+func (w *_unionEnvelopeAssemblerRepr) ValuePrototype(k string) datamodel.NodePrototype {
+	panic("bindnode TODO: union ValuePrototype")
+}
+
+// inlineUnionMember returns the index of the member of an inline union with the given discriminant.
+func inlineUnionMember(typ *schema.TypeUnion, stg schema.UnionRepresentation_Inline, discriminant string) (int, error) {
+	name := stg.GetMember(discriminant)
+	for idx, member := range typ.Members() {
+		if member.Name() == name {
+			return idx, checkInlineUnionMember(typ, member)
+		}
+	}
+	return -1, schema.ErrNotUnionStructure{
+		TypeName: typ.Name(),
+		Detail:   fmt.Sprintf("no member with discriminant %q", discriminant),
+	}
+}
+
+// checkInlineUnionMember returns an error if a member of an inline union isn't a struct with a map representation.
+// Inline unions put the discriminant alongside the member's own map entries, so no other member would work.
+func checkInlineUnionMember(typ *schema.TypeUnion, member schema.Type) error {
+	if member, ok := member.(*schema.TypeStruct); ok {
+		if _, ok := member.RepresentationStrategy().(schema.StructRepresentation_Map); ok {
+			return nil
+		}
+	}
+	return schema.ErrNotUnionStructure{
+		TypeName: typ.Name(),
+		Detail: fmt.Sprintf("member %s of an inline union must be a struct with a map representation, but its representation is of kind %s",
+			member.Name(), member.RepresentationBehavior()),
+	}
+}
+
+// _unionInlineAssemblerRepr assembles an inline union from a map with the discriminant among the member's entries.
+// Entries after the discriminant go straight to the member; any before it are assembled as a basic map first,
+// and copied into the member once the discriminant is known.
+type _unionInlineAssemblerRepr struct {
+	union *_unionAssembler
+	stg   schema.UnionRepresentation_Inline
+
+	key             _assembler
+	discriminant    _assembler
+	hasDiscriminant bool
+	member          datamodel.MapAssembler // set once the discriminant is known.
+
+	buffered    datamodel.NodeBuilder
+	bufferedMap datamodel.MapAssembler
+}
+
+func (w *_unionInlineAssemblerRepr) AssembleKey() datamodel.NodeAssembler {
+	w.key = _assembler{
+		cfg:        w.union.cfg,
+		schemaType: schemaTypeString,
+		val:        reflect.New(goTypeString).Elem(),
+	}
+	return &w.key
+}
+
+func (w *_unionInlineAssemblerRepr) AssembleValue() datamodel.NodeAssembler {
+	key := w.key.val.String()
+	if key == w.stg.GetDiscriminantKey() {
+		if w.hasDiscriminant {
+			return _errorAssembler{datamodel.ErrRepeatedMapKey{Key: basicnode.NewString(key)}}
+		}
+		w.discriminant = _assembler{
+			cfg:        w.union.cfg,
+			schemaType: schemaTypeString,
+			val:        reflect.New(goTypeString).Elem(),
+			finish: func() error {
+				// Only once the member has begun, so that Finish can't find a discriminant without a member.
+				if err := w.beginMember(); err != nil {
+					return err
+				}
+				w.hasDiscriminant = true
+				return nil
+			},
+		}
+		return &w.discriminant
+	}
+	if w.member != nil {
+		asm, err := w.member.AssembleEntry(key)
+		if err != nil {
+			return _errorAssembler{err}
+		}
+		return asm
+	}
+	if w.buffered == nil {
+		w.buffered = basicnode.Prototype.Map.NewBuilder()
+		ma, err := w.buffered.BeginMap(0)
+		if err != nil {
+			return _errorAssembler{err}
+		}
+		w.bufferedMap = ma
+	}
+	asm, err := w.bufferedMap.AssembleEntry(key)
+	if err != nil {
+		return _errorAssembler{err}
+	}
+	return asm
+}
+
+// beginMember starts assembling the member named by the discriminant,
+// along with any entries which came before the discriminant.
+func (w *_unionInlineAssemblerRepr) beginMember() error {
+	idx, err := inlineUnionMember(w.union.schemaType, w.stg, w.discriminant.val.String())
+	if err != nil {
+		return err
+	}
+	union := &_assemblerRepr{cfg: w.union.cfg, schemaType: w.union.schemaType, val: w.union.val}
+	ma, err := union.asMember(idx).BeginMap(0)
+	if err != nil {
+		return err
+	}
+	w.member = ma
+	if w.buffered == nil {
+		return nil
+	}
+	if err := w.bufferedMap.Finish(); err != nil {
+		return err
+	}
+	itr := w.buffered.Build().MapIterator()
+	w.buffered, w.bufferedMap = nil, nil
+	for !itr.Done() {
+		k, v, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			return err
+		}
+		asm, err := ma.AssembleEntry(ks)
+		if err != nil {
+			return err
+		}
+		if err := asm.AssignNode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *_unionInlineAssemblerRepr) AssembleEntry(k string) (datamodel.NodeAssembler, error) {
+	if err := w.AssembleKey().AssignString(k); err != nil {
+		return nil, err
+	}
+	am := w.AssembleValue()
+	return am, nil
+}
+
+func (w *_unionInlineAssemblerRepr) Finish() error {
+	if !w.hasDiscriminant {
+		return schema.ErrNotUnionStructure{
+			TypeName: w.union.schemaType.Name(),
+			Detail:   fmt.Sprintf("an inline union needs the %q key", w.stg.GetDiscriminantKey()),
+		}
+	}
+	if err := w.member.Finish(); err != nil {
+		return err
+	}
+	return w.union.Finish()
+}
+
+func (w *_unionInlineAssemblerRepr) KeyPrototype() datamodel.NodePrototype {
+	return w.union.KeyPrototype()
+}
+
+func (w *_unionInlineAssemblerRepr) ValuePrototype(k string) datamodel.NodePrototype {
+	panic("bindnode TODO: union ValuePrototype")
+}
+
 type _structIteratorRepr _structIterator
 
 func (w *_structIteratorRepr) Next() (key, value datamodel.Node, _ error) {
@@ -1313,3 +1804,58 @@ func (w *_unionIteratorRepr) Done() bool {
 		panic(fmt.Sprintf("bindnode Done TODO: %T", stg))
 	}
 }
+
+// _unionEnvelopeIteratorRepr iterates over the two entries of an envelope union:
+// the discriminant, followed by the content.
+type _unionEnvelopeIteratorRepr struct {
+	node      *_nodeRepr
+	stg       schema.UnionRepresentation_Envelope
+	nextIndex int
+}
+
+func (w *_unionEnvelopeIteratorRepr) Next() (key, value datamodel.Node, _ error) {
+	if w.Done() {
+		return nil, nil, datamodel.ErrIteratorOverread{}
+	}
+	w.nextIndex++
+	keyStr := w.stg.GetDiscriminantKey()
+	if w.nextIndex == 2 {
+		keyStr = w.stg.GetContentKey()
+	}
+	value, err := w.node.LookupByString(keyStr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return basicnode.NewString(keyStr), value, nil
+}
+
+func (w *_unionEnvelopeIteratorRepr) Done() bool {
+	return w.nextIndex >= 2
+}
+
+// _unionInlineIteratorRepr iterates over an inline union:
+// the discriminant, followed by the member's own entries.
+type _unionInlineIteratorRepr struct {
+	node   *_nodeRepr
+	stg    schema.UnionRepresentation_Inline
+	member datamodel.MapIterator // set once the discriminant has been yielded.
+}
+
+func (w *_unionInlineIteratorRepr) Next() (key, value datamodel.Node, _ error) {
+	if w.Done() {
+		return nil, nil, datamodel.ErrIteratorOverread{}
+	}
+	member, mtyp := w.node.unionMember()
+	if w.member == nil {
+		if err := checkInlineUnionMember(w.node.schemaType.(*schema.TypeUnion), mtyp); err != nil {
+			return nil, nil, err
+		}
+		w.member = member.MapIterator()
+		return basicnode.NewString(w.stg.GetDiscriminantKey()), basicnode.NewString(w.stg.GetDiscriminant(mtyp)), nil
+	}
+	return w.member.Next()
+}
+
+func (w *_unionInlineIteratorRepr) Done() bool {
+	return w.member != nil && w.member.Done()
+}
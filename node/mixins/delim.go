@@ -38,3 +38,25 @@ func SplitExact(s string, sep string, count int) ([]string, error) {
 func SplitN(s, sep string, n int) []string {
 	return strings.SplitN(s, sep, n)
 }
+
+// SplitPairs splits a string into key and value pairs.
+// Entries are separated by 'entrySep', and each entry is split into its key
+// and value at the first instance of 'innerSep'; an entry without 'innerSep'
+// is an error.  The empty string yields no pairs.
+//
+// SplitPairs is used by the 'stringpairs' representation for structs and maps.
+func SplitPairs(s string, innerSep string, entrySep string) ([][2]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	entries := strings.Split(s, entrySep)
+	pairs := make([][2]string, len(entries))
+	for i, entry := range entries {
+		kv := strings.SplitN(entry, innerSep, 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("expected entry %d to contain the delimiter %q, found %q", i, innerSep, entry)
+		}
+		pairs[i] = [2]string{kv[0], kv[1]}
+	}
+	return pairs, nil
+}
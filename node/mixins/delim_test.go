@@ -35,3 +35,28 @@ func TestSplitExact(t *testing.T) {
 		qt.Check(t, ent2, qt.CmpEquals(cmp.Exporter(func(reflect.Type) bool { return true })), ent)
 	}
 }
+
+func TestSplitPairs(t *testing.T) {
+	type expect struct {
+		value [][2]string
+		err   error
+	}
+	type tcase struct {
+		s        string
+		innerSep string
+		entrySep string
+		expect   expect
+	}
+	for _, ent := range []tcase{
+		{"", "=", ",", expect{nil, nil}},
+		{"x=y", "=", ",", expect{[][2]string{{"x", "y"}}, nil}},
+		{"x=y,z=", "=", ",", expect{[][2]string{{"x", "y"}, {"z", ""}}, nil}},
+		{"x=y=z", "=", ",", expect{[][2]string{{"x", "y=z"}}, nil}},
+		{"x=y,z", "=", ",", expect{nil, fmt.Errorf("expected entry 1 to contain the delimiter \"=\", found \"z\"")}},
+		{"x=y,", "=", ",", expect{nil, fmt.Errorf("expected entry 1 to contain the delimiter \"=\", found \"\"")}},
+	} {
+		value, err := SplitPairs(ent.s, ent.innerSep, ent.entrySep)
+		ent2 := tcase{ent.s, ent.innerSep, ent.entrySep, expect{value, err}}
+		qt.Check(t, ent2, qt.CmpEquals(cmp.Exporter(func(reflect.Type) bool { return true })), ent)
+	}
+}
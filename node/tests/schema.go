@@ -14,18 +14,26 @@ var allSchemaTests = []struct {
 	{"MapsContainingMaybe", SchemaTestMapsContainingMaybe},
 	{"MapsContainingMaps", SchemaTestMapsContainingMaps},
 	{"MapsWithComplexKeys", SchemaTestMapsWithComplexKeys},
+	{"MapReprStringpairs", SchemaTestMapReprStringpairs},
+	{"MapReprListpairs", SchemaTestMapReprListpairs},
 	{"Scalars", SchemaTestScalars},
 	{"RequiredFields", SchemaTestRequiredFields},
 	{"StructNesting", SchemaTestStructNesting},
 	{"StructReprStringjoin", SchemaTestStructReprStringjoin},
 	{"StructReprTuple", SchemaTestStructReprTuple},
 	{"StructReprListPairs", SchemaTestStructReprListPairs},
+	{"StructReprStringpairs", SchemaTestStructReprStringpairs},
 	{"StructsContainingMaybe", SchemaTestStructsContainingMaybe},
 	{"UnionKeyed", SchemaTestUnionKeyed},
 	{"UnionKeyedComplexChildren", SchemaTestUnionKeyedComplexChildren},
 	{"UnionKeyedReset", SchemaTestUnionKeyedReset},
 	{"UnionKinded", SchemaTestUnionKinded},
 	{"UnionStringprefix", SchemaTestUnionStringprefix},
+	{"UnionEnvelope", SchemaTestUnionEnvelope},
+	{"UnionBytesprefix", SchemaTestUnionBytesprefix},
+	{"UnionInline", SchemaTestUnionInline},
+	{"Enums", SchemaTestEnums},
+	{"Any", SchemaTestAny},
 }
 
 type EngineSubtest struct {
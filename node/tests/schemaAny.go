@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestAny(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnAny("Any"))
+	ts.Accumulate(schema.SpawnStruct("Holder",
+		[]schema.StructField{
+			schema.SpawnStructField("name", "String", false, false),
+			schema.SpawnStructField("value", "Any", false, false),
+			schema.SpawnStructField("extra", "Any", true, false),
+		},
+		schema.SpawnStructRepresentationMap(map[string]string{"value": "v"}),
+	))
+	ts.Accumulate(schema.SpawnList("List__Any", "Any", false))
+	engine.Init(t, ts)
+
+	t.Run("in a struct", func(t *testing.T) {
+		np := engine.PrototypeByName("Holder")
+		nrp := engine.PrototypeByName("Holder.Repr")
+		for _, tcase := range []testcase{
+			{
+				name:     "Scalar",
+				typeJson: `{"extra":"x","name":"a","value":12}`,
+				reprJson: `{"extra":"x","name":"a","v":12}`,
+				typePoints: []testcasePoint{
+					{"value", datamodel.Kind_Int},
+					{"value", 12},
+					{"extra", "x"},
+				},
+				reprPoints: []testcasePoint{
+					{"v", 12},
+				},
+			},
+			{
+				name:     "Recursive",
+				typeJson: `{"extra":[],"name":"b","value":{"list":[1,"two",{"three":null}],"map":{}}}`,
+				reprJson: `{"extra":[],"name":"b","v":{"list":[1,"two",{"three":null}],"map":{}}}`,
+				typePoints: []testcasePoint{
+					{"value", datamodel.Kind_Map},
+					{"value/list/1", "two"},
+					{"value/list/2/three", datamodel.Kind_Null},
+					{"extra", datamodel.Kind_List},
+				},
+				reprPoints: []testcasePoint{
+					{"v/list/0", 1},
+				},
+			},
+			{
+				name:     "AbsentField",
+				reprJson: `{"name":"c","v":"y"}`,
+				typePoints: []testcasePoint{
+					{"value", "y"},
+					{"extra", datamodel.Absent},
+				},
+			},
+		} {
+			tcase.Test(t, np, nrp)
+		}
+	})
+
+	t.Run("in a list", func(t *testing.T) {
+		np := engine.PrototypeByName("List__Any")
+		nrp := engine.PrototypeByName("List__Any.Repr")
+		testcase{
+			name:     "Mixed",
+			typeJson: `[true,1.5,"x",[{"y":null}]]`,
+			reprJson: `[true,1.5,"x",[{"y":null}]]`,
+			typePoints: []testcasePoint{
+				{"0", datamodel.Kind_Bool},
+				{"1", datamodel.Kind_Float},
+				{"2", "x"},
+				{"3/0", datamodel.Kind_Map},
+				{"3/0/y", datamodel.Kind_Null},
+			},
+		}.Test(t, np, nrp)
+	})
+}
@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestEnums(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnEnum("Color",
+		[]string{"Red", "Green", "Blue"},
+		schema.EnumRepresentation_String{"Red": "r", "Blue": "b"}, // Green is represented by its own name.
+	))
+	ts.Accumulate(schema.SpawnEnum("Level",
+		[]string{"Low", "High"},
+		schema.EnumRepresentation_Int{"Low": 1, "High": 10},
+	))
+	ts.Accumulate(schema.SpawnStruct("Setting",
+		[]schema.StructField{
+			schema.SpawnStructField("color", "Color", false, false),
+			schema.SpawnStructField("level", "Level", true, false),
+		},
+		schema.SpawnStructRepresentationMap(nil),
+	))
+	ts.Accumulate(schema.SpawnStruct("Shade",
+		[]schema.StructField{
+			schema.SpawnStructField("color", "Color", false, false),
+			schema.SpawnStructField("name", "String", false, false),
+		},
+		schema.SpawnStructRepresentationStringjoin(":"),
+	))
+	engine.Init(t, ts)
+
+	t.Run("string representation", func(t *testing.T) {
+		np := engine.PrototypeByName("Color")
+		nrp := engine.PrototypeByName("Color.Repr")
+		for _, tcase := range []testcase{
+			{
+				name:       "Mapped",
+				typeJson:   `"Red"`,
+				reprJson:   `"r"`,
+				typePoints: []testcasePoint{{"", "Red"}},
+				reprPoints: []testcasePoint{{"", "r"}},
+			},
+			{
+				name:       "Unmapped",
+				typeJson:   `"Green"`,
+				reprJson:   `"Green"`,
+				typePoints: []testcasePoint{{"", "Green"}},
+				reprPoints: []testcasePoint{{"", "Green"}},
+			},
+		} {
+			tcase.Test(t, np, nrp)
+		}
+
+		// The name of a member isn't its representation, if it's been given another one.
+		for _, s := range []string{"Red", "g", "Purple"} {
+			qt.Check(t, nrp.NewBuilder().AssignString(s), qt.IsNotNil, qt.Commentf("%q", s))
+		}
+		var wrongKind datamodel.ErrWrongKind
+		qt.Check(t, nrp.NewBuilder().AssignInt(1), qt.ErrorAs, &wrongKind)
+	})
+
+	t.Run("int representation", func(t *testing.T) {
+		np := engine.PrototypeByName("Level")
+		nrp := engine.PrototypeByName("Level.Repr")
+		for _, tcase := range []testcase{
+			{
+				name:       "Low",
+				typeJson:   `"Low"`,
+				reprJson:   `1`,
+				typePoints: []testcasePoint{{"", "Low"}},
+				reprPoints: []testcasePoint{{"", datamodel.Kind_Int}, {"", 1}},
+			},
+			{
+				name:       "High",
+				typeJson:   `"High"`,
+				reprJson:   `10`,
+				typePoints: []testcasePoint{{"", "High"}},
+				reprPoints: []testcasePoint{{"", 10}},
+			},
+		} {
+			tcase.Test(t, np, nrp)
+		}
+
+		qt.Check(t, nrp.NewBuilder().AssignInt(2), qt.IsNotNil)
+		var wrongKind datamodel.ErrWrongKind
+		qt.Check(t, nrp.NewBuilder().AssignString("Low"), qt.ErrorAs, &wrongKind)
+	})
+
+	t.Run("in a struct", func(t *testing.T) {
+		np := engine.PrototypeByName("Setting")
+		nrp := engine.PrototypeByName("Setting.Repr")
+		for _, tcase := range []testcase{
+			{
+				name:     "AllFields",
+				typeJson: `{"color":"Blue","level":"High"}`,
+				reprJson: `{"color":"b","level":10}`,
+				typePoints: []testcasePoint{
+					{"color", "Blue"},
+					{"level", "High"},
+				},
+				reprPoints: []testcasePoint{
+					{"color", "b"},
+					{"level", 10},
+				},
+			},
+			{
+				name:     "AbsentField",
+				reprJson: `{"color":"Green"}`,
+				typePoints: []testcasePoint{
+					{"color", "Green"},
+				},
+				typeItr: []entry{
+					{"color", "Green"},
+					{"level", datamodel.Absent},
+				},
+			},
+		} {
+			tcase.Test(t, np, nrp)
+		}
+	})
+
+	t.Run("in a stringjoin struct", func(t *testing.T) {
+		np := engine.PrototypeByName("Shade")
+		nrp := engine.PrototypeByName("Shade.Repr")
+		testcase{
+			name:     "Joined",
+			typeJson: `{"color":"Red","name":"crimson"}`,
+			reprJson: `"r:crimson"`,
+			typePoints: []testcasePoint{
+				{"color", "Red"},
+				{"name", "crimson"},
+			},
+			reprPoints: []testcasePoint{
+				{"", "r:crimson"},
+			},
+		}.Test(t, np, nrp)
+
+		qt.Check(t, nrp.NewBuilder().AssignString("Red:crimson"), qt.IsNotNil)
+	})
+}
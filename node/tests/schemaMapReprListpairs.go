@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestMapReprListpairs(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnStruct("SmolStruct",
+		[]schema.StructField{
+			schema.SpawnStructField("s", "String", false, false),
+		},
+		schema.SpawnStructRepresentationMap(map[string]string{
+			"s": "q",
+		}),
+	))
+	ts.Accumulate(schema.SpawnMapWithRepresentation("Map__String__String",
+		"String", "String", false,
+		schema.SpawnMapRepresentationListPairs(),
+	))
+	ts.Accumulate(schema.SpawnMapWithRepresentation("Map__String__nullable__String",
+		"String", "String", true,
+		schema.SpawnMapRepresentationListPairs(),
+	))
+	ts.Accumulate(schema.SpawnMapWithRepresentation("Map__String__SmolStruct",
+		"String", "SmolStruct", false,
+		schema.SpawnMapRepresentationListPairs(),
+	))
+	engine.Init(t, ts)
+
+	t.Run("plain values", func(t *testing.T) {
+		specs := []testcase{
+			{
+				name:     "several entries",
+				typeJson: `{"a":"1","b":"2"}`,
+				reprJson: `[["a","1"],["b","2"]]`,
+				typePoints: []testcasePoint{
+					{"", datamodel.Kind_Map},
+					{"a", "1"},
+					{"b", "2"},
+				},
+				reprPoints: []testcasePoint{
+					{"", datamodel.Kind_List},
+					{"0", datamodel.Kind_List},
+					{"0/0", "a"},
+					{"0/1", "1"},
+					{"1/0", "b"},
+					{"1/1", "2"},
+				},
+			},
+			{
+				name:     "empty",
+				typeJson: `{}`,
+				reprJson: `[]`,
+			},
+		}
+		for _, tcase := range specs {
+			tcase.Test(t, engine.PrototypeByName("Map__String__String"), engine.PrototypeByName("Map__String__String.Repr"))
+		}
+	})
+
+	t.Run("nullable values", func(t *testing.T) {
+		specs := []testcase{
+			{
+				name:     "null and non-null",
+				typeJson: `{"a":null,"b":"2"}`,
+				reprJson: `[["a",null],["b","2"]]`,
+				typePoints: []testcasePoint{
+					{"a", datamodel.Kind_Null},
+					{"b", "2"},
+				},
+				reprPoints: []testcasePoint{
+					{"0/1", datamodel.Kind_Null},
+					{"1/1", "2"},
+				},
+			},
+		}
+		for _, tcase := range specs {
+			tcase.Test(t, engine.PrototypeByName("Map__String__nullable__String"), engine.PrototypeByName("Map__String__nullable__String.Repr"))
+		}
+	})
+
+	t.Run("values with their own representation", func(t *testing.T) {
+		specs := []testcase{
+			{
+				name:     "struct values",
+				typeJson: `{"a":{"s":"whee"}}`,
+				reprJson: `[["a",{"q":"whee"}]]`,
+				typePoints: []testcasePoint{
+					{"a/s", "whee"},
+				},
+				reprPoints: []testcasePoint{
+					{"0/0", "a"},
+					{"0/1/q", "whee"},
+				},
+			},
+		}
+		for _, tcase := range specs {
+			tcase.Test(t, engine.PrototypeByName("Map__String__SmolStruct"), engine.PrototypeByName("Map__String__SmolStruct.Repr"))
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestMapReprStringpairs(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnMapWithRepresentation("Map__String__String",
+		"String", "String", false,
+		schema.SpawnMapRepresentationStringPairs("=", "&"),
+	))
+	engine.Init(t, ts)
+
+	np := engine.PrototypeByName("Map__String__String")
+	nrp := engine.PrototypeByName("Map__String__String.Repr")
+	specs := []testcase{
+		{
+			name:     "several entries",
+			typeJson: `{"a":"1","b":"2","c":"3"}`,
+			reprJson: `"a=1&b=2&c=3"`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"a", "1"},
+				{"b", "2"},
+				{"c", "3"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_String},
+				{"", "a=1&b=2&c=3"},
+			},
+		},
+		{
+			name:     "values containing the inner delimiter",
+			typeJson: `{"a":"x=y"}`,
+			reprJson: `"a=x=y"`,
+			typePoints: []testcasePoint{
+				{"a", "x=y"},
+			},
+		},
+		{
+			name:     "empty",
+			typeJson: `{}`,
+			reprJson: `""`,
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_String},
+				{"", ""},
+			},
+		},
+	}
+	for _, tcase := range specs {
+		tcase.Test(t, np, nrp)
+	}
+
+	t.Run("rejects entries without delimiter", func(t *testing.T) {
+		nb := nrp.NewBuilder()
+		qt.Check(t, nb.AssignString("a=1&b"), qt.IsNotNil)
+	})
+}
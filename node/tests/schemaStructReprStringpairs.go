@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// SchemaTestStructReprStringpairs is much like the stringjoin tests,
+// except that each field is written out together with its name.
+//
+// No maybes are exercised, since stringpairs doesn't permit them.
+func SchemaTestStructReprStringpairs(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnStruct("StringyStruct",
+		[]schema.StructField{
+			schema.SpawnStructField("field", "String", false, false),
+		},
+		schema.SpawnStructRepresentationStringPairs("=", ","),
+	))
+	ts.Accumulate(schema.SpawnStruct("ManystringStruct",
+		[]schema.StructField{
+			schema.SpawnStructField("bar", "String", false, false),
+			schema.SpawnStructField("foo", "String", false, false),
+		},
+		schema.SpawnStructRepresentationStringPairs("=", ","),
+	))
+	ts.Accumulate(schema.SpawnStruct("Recurzorator",
+		[]schema.StructField{
+			schema.SpawnStructField("alpha", "String", false, false),
+			schema.SpawnStructField("zap", "ManystringStruct", false, false),
+			schema.SpawnStructField("zulu", "String", false, false),
+		},
+		schema.SpawnStructRepresentationStringPairs(":", ";"),
+	))
+	engine.Init(t, ts)
+
+	specs := []testcase{
+		{
+			name:     "single field",
+			typeJson: `{"field":"valoo"}`,
+			reprJson: `"field=valoo"`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"field", "valoo"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_String},
+				{"", "field=valoo"},
+			},
+		},
+	}
+	for _, tcase := range specs {
+		tcase.Test(t, engine.PrototypeByName("StringyStruct"), engine.PrototypeByName("StringyStruct.Repr"))
+	}
+
+	specs = []testcase{
+		{
+			name:     "several fields",
+			typeJson: `{"bar":"v1","foo":"v2"}`,
+			reprJson: `"bar=v1,foo=v2"`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"bar", "v1"},
+				{"foo", "v2"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_String},
+				{"", "bar=v1,foo=v2"},
+			},
+		},
+	}
+	for _, tcase := range specs {
+		tcase.Test(t, engine.PrototypeByName("ManystringStruct"), engine.PrototypeByName("ManystringStruct.Repr"))
+	}
+
+	specs = []testcase{
+		{
+			name:     "nested",
+			typeJson: `{"alpha":"v1","zap":{"bar":"v2","foo":"v3"},"zulu":"v4"}`,
+			reprJson: `"alpha:v1;zap:bar=v2,foo=v3;zulu:v4"`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"alpha", "v1"},
+				{"zap", datamodel.Kind_Map},
+				{"zap/bar", "v2"},
+				{"zap/foo", "v3"},
+				{"zulu", "v4"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_String},
+				{"", "alpha:v1;zap:bar=v2,foo=v3;zulu:v4"},
+			},
+		},
+	}
+	for _, tcase := range specs {
+		tcase.Test(t, engine.PrototypeByName("Recurzorator"), engine.PrototypeByName("Recurzorator.Repr"))
+	}
+
+	t.Run("fields in any order", func(t *testing.T) {
+		n := fluent.MustBuildMap(engine.PrototypeByName("ManystringStruct"), 2, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("foo").AssignString("v1")
+			ma.AssembleEntry("bar").AssignString("v2")
+		})
+		nr := fluent.MustBuild(engine.PrototypeByName("ManystringStruct.Repr"), func(na fluent.NodeAssembler) {
+			na.AssignString("foo=v1,bar=v2")
+		})
+		qt.Check(t, n, NodeContentEquals, nr)
+	})
+
+	t.Run("rejects bad data", func(t *testing.T) {
+		for _, s := range []string{
+			"bar=v1",               // missing field
+			"bar=v1,foo=v2,baz=v3", // unknown field
+			"bar=v1,foo",           // missing delimiter
+		} {
+			nb := engine.PrototypeByName("ManystringStruct.Repr").NewBuilder()
+			qt.Check(t, nb.AssignString(s), qt.IsNotNil, qt.Commentf("%q", s))
+		}
+	})
+}
@@ -30,6 +30,14 @@ func SchemaTestStructReprTuple(t *testing.T, engine Engine) {
 		},
 		schema.SpawnStructRepresentationTuple(),
 	))
+	ts.Accumulate(schema.SpawnStruct("ReorderedTuple",
+		[]schema.StructField{
+			schema.SpawnStructField("foo", "String", false, false),
+			schema.SpawnStructField("bar", "String", false, false),
+			schema.SpawnStructField("baz", "String", true, false),
+		},
+		schema.SpawnStructRepresentationTupleWithFieldOrder([]string{"bar", "foo", "baz"}),
+	))
 	engine.Init(t, ts)
 
 	t.Run("onetuple works", func(t *testing.T) {
@@ -133,4 +141,40 @@ func SchemaTestStructReprTuple(t *testing.T, engine Engine) {
 			qt.Check(t, n, NodeContentEquals, nr)
 		})
 	})
+	t.Run("reordered tuple works", func(t *testing.T) {
+		np := engine.PrototypeByName("ReorderedTuple")
+		nrp := engine.PrototypeByName("ReorderedTuple.Repr")
+		var n schema.TypedNode
+		t.Run("typed-create", func(t *testing.T) {
+			n = fluent.MustBuildMap(np, 3, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("foo").AssignString("0")
+				ma.AssembleEntry("bar").AssignString("1")
+				ma.AssembleEntry("baz").AssignString("2")
+			}).(schema.TypedNode)
+			t.Run("repr-read", func(t *testing.T) {
+				nr := n.Representation()
+				qt.Assert(t, nr.Kind(), qt.Equals, datamodel.Kind_List)
+				qt.Check(t, nr.Length(), qt.Equals, int64(3))
+				qt.Check(t, must.String(must.Node(nr.LookupByIndex(0))), qt.Equals, "1")
+				qt.Check(t, must.String(must.Node(nr.LookupByIndex(1))), qt.Equals, "0")
+				qt.Check(t, must.String(must.Node(nr.LookupByIndex(2))), qt.Equals, "2")
+				itr := nr.ListIterator()
+				var values []string
+				for !itr.Done() {
+					_, v, err := itr.Next()
+					qt.Assert(t, err, qt.IsNil)
+					values = append(values, must.String(v))
+				}
+				qt.Check(t, values, qt.DeepEquals, []string{"1", "0", "2"})
+			})
+		})
+		t.Run("repr-create", func(t *testing.T) {
+			nr := fluent.MustBuildList(nrp, 3, func(la fluent.ListAssembler) {
+				la.AssembleValue().AssignString("1")
+				la.AssembleValue().AssignString("0")
+				la.AssembleValue().AssignString("2")
+			})
+			qt.Check(t, n, NodeContentEquals, nr)
+		})
+	})
 }
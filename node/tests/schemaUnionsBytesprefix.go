@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestUnionBytesprefix(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnBytes("Bytes"))
+	ts.Accumulate(schema.SpawnBytes("OtherBytes"))
+	ts.Accumulate(schema.SpawnBytes("ShortBytes"))
+	ts.Accumulate(schema.SpawnUnion("WheeUnion",
+		[]schema.TypeName{
+			"Bytes",
+			"ShortBytes",
+			"OtherBytes",
+		},
+		schema.SpawnUnionRepresentationBytesPrefix(
+			map[string]schema.TypeName{
+				"00":   "Bytes",
+				"FF":   "ShortBytes", // overlaps with FF01, which wins when both match, as it's longer.
+				"FF01": "OtherBytes",
+			},
+		),
+	))
+	engine.Init(t, ts)
+
+	specs := []testcase{
+		{
+			name:     "InhabitantA",
+			typeJson: `{"Bytes":{"/":{"bytes":"AQI"}}}`, // 0x0102
+			reprJson: `{"/":{"bytes":"AAEC"}}`,          // 0x00 0x0102
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"Bytes", datamodel.Kind_Bytes},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Bytes},
+			},
+		},
+		{
+			name:     "InhabitantB",
+			typeJson: `{"OtherBytes":{"/":{"bytes":"AQI"}}}`, // 0x0102
+			reprJson: `{"/":{"bytes":"/wEBAg"}}`,             // 0xff01 0x0102
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"OtherBytes", datamodel.Kind_Bytes},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Bytes},
+			},
+		},
+		{
+			name:     "InhabitantShortPrefix",
+			typeJson: `{"ShortBytes":{"/":{"bytes":"AgM"}}}`, // 0x0203
+			reprJson: `{"/":{"bytes":"/wID"}}`,               // 0xff 0x0203
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"ShortBytes", datamodel.Kind_Bytes},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Bytes},
+			},
+		},
+		{
+			name:     "EmptyMember",
+			typeJson: `{"Bytes":{"/":{"bytes":""}}}`,
+			reprJson: `{"/":{"bytes":"AA"}}`, // 0x00
+		},
+	}
+
+	np := engine.PrototypeByName("WheeUnion")
+	nrp := engine.PrototypeByName("WheeUnion.Repr")
+	for _, tcase := range specs {
+		tcase.Test(t, np, nrp)
+	}
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestUnionEnvelope(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnStruct("SmolStruct",
+		[]schema.StructField{
+			schema.SpawnStructField("s", "String", false, false),
+		},
+		schema.SpawnStructRepresentationMap(map[string]string{
+			"s": "q",
+		}),
+	))
+	ts.Accumulate(schema.SpawnUnion("WheeUnion",
+		[]schema.TypeName{
+			"String",
+			"SmolStruct",
+		},
+		schema.SpawnUnionRepresentationEnvelope(
+			"tag",
+			"msg",
+			map[string]schema.TypeName{
+				"a": "String",
+				"b": "SmolStruct",
+			},
+		),
+	))
+	engine.Init(t, ts)
+
+	// These are the same *type-level* as in TestUnionKinded,
+	//  but (of course) have very different representations.
+	// Note that the content key sorts before the discriminant key,
+	//  so creating from the json fixtures gets the content before it knows which member it's for.
+	specs := []testcase{
+		{
+			name:     "InhabitantA",
+			typeJson: `{"String":"whee"}`,
+			reprJson: `{"msg":"whee","tag":"a"}`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"String", "whee"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"tag", "a"},
+				{"msg", "whee"},
+			},
+		},
+		{
+			name:     "InhabitantB",
+			typeJson: `{"SmolStruct":{"s":"whee"}}`,
+			reprJson: `{"msg":{"q":"whee"},"tag":"b"}`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"SmolStruct", datamodel.Kind_Map},
+				{"SmolStruct/s", "whee"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"tag", "b"},
+				{"msg", datamodel.Kind_Map},
+				{"msg/q", "whee"},
+			},
+		},
+	}
+
+	np := engine.PrototypeByName("WheeUnion")
+	nrp := engine.PrototypeByName("WheeUnion.Repr")
+	for _, tcase := range specs {
+		tcase.Test(t, np, nrp)
+	}
+}
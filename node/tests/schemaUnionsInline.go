@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func SchemaTestUnionInline(t *testing.T, engine Engine) {
+	ts := schema.TypeSystem{}
+	ts.Init()
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnStruct("SmolStruct",
+		[]schema.StructField{
+			schema.SpawnStructField("s", "String", false, false),
+		},
+		schema.SpawnStructRepresentationMap(map[string]string{
+			"s": "q",
+		}),
+	))
+	ts.Accumulate(schema.SpawnStruct("PairStruct",
+		[]schema.StructField{
+			schema.SpawnStructField("a", "String", false, false),
+			schema.SpawnStructField("b", "String", true, false),
+		},
+		schema.SpawnStructRepresentationMap(nil),
+	))
+	ts.Accumulate(schema.SpawnUnion("WheeUnion",
+		[]schema.TypeName{
+			"SmolStruct",
+			"PairStruct",
+		},
+		schema.SpawnUnionRepresentationInline(
+			"tag",
+			map[string]schema.TypeName{
+				"smol": "SmolStruct",
+				"pair": "PairStruct",
+			},
+		),
+	))
+	engine.Init(t, ts)
+
+	// Note that the json fixtures are sorted (as marshalling sorts map keys),
+	//  so creating from them gets the discriminant after the member's fields.
+	specs := []testcase{
+		{
+			name:     "InhabitantA",
+			typeJson: `{"SmolStruct":{"s":"whee"}}`,
+			reprJson: `{"q":"whee","tag":"smol"}`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"SmolStruct", datamodel.Kind_Map},
+				{"SmolStruct/s", "whee"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"tag", "smol"},
+				{"q", "whee"},
+			},
+		},
+		{
+			name:     "InhabitantB",
+			typeJson: `{"PairStruct":{"a":"one","b":"two"}}`,
+			reprJson: `{"a":"one","b":"two","tag":"pair"}`,
+			typePoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"PairStruct", datamodel.Kind_Map},
+				{"PairStruct/a", "one"},
+				{"PairStruct/b", "two"},
+			},
+			reprPoints: []testcasePoint{
+				{"", datamodel.Kind_Map},
+				{"tag", "pair"},
+				{"a", "one"},
+				{"b", "two"},
+			},
+		},
+	}
+
+	np := engine.PrototypeByName("WheeUnion")
+	nrp := engine.PrototypeByName("WheeUnion.Repr")
+	for _, tcase := range specs {
+		tcase.Test(t, np, nrp)
+	}
+
+	t.Run("discriminant before fields", func(t *testing.T) {
+		n := fluent.MustBuildMap(np, 1, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("PairStruct").CreateMap(2, func(ma fluent.MapAssembler) {
+				ma.AssembleEntry("a").AssignString("one")
+				ma.AssembleEntry("b").AssignString("two")
+			})
+		})
+		nr := fluent.MustBuildMap(nrp, 3, func(ma fluent.MapAssembler) {
+			ma.AssembleEntry("tag").AssignString("pair")
+			ma.AssembleEntry("b").AssignString("two")
+			ma.AssembleEntry("a").AssignString("one")
+		})
+		qt.Check(t, n, NodeContentEquals, nr)
+	})
+
+	t.Run("missing discriminant", func(t *testing.T) {
+		nb := nrp.NewBuilder()
+		ma, err := nb.BeginMap(1)
+		qt.Assert(t, err, qt.IsNil)
+		va, err := ma.AssembleEntry("q")
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, va.AssignString("whee"), qt.IsNil)
+		qt.Check(t, ma.Finish(), qt.IsNotNil)
+	})
+
+	t.Run("unknown discriminant", func(t *testing.T) {
+		nb := nrp.NewBuilder()
+		ma, err := nb.BeginMap(1)
+		qt.Assert(t, err, qt.IsNil)
+		va, err := ma.AssembleEntry("tag")
+		if err == nil {
+			err = va.AssignString("nope")
+		}
+		qt.Check(t, err, qt.IsNotNil)
+	})
+}
@@ -323,7 +323,7 @@ func (c *closeEnoughChecker) Check(actual interface{}, args []interface{}, note
 			return fmt.Errorf("expected something with kind int, got kind %s", a.Kind())
 		}
 		x, _ := a.AsInt()
-		return qt.Equals.Check(x, args, note)
+		return qt.Equals.Check(x, []interface{}{int64(expected.(int))}, note)
 	case datamodel.Node:
 		return qt.Equals.Check(actual, args, note)
 	default:
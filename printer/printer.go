@@ -268,7 +268,7 @@ func (z *printBuf) doString(indentLevel int, printState uint8, n datamodel.Node)
 			z.writeString("}")
 			return
 		case schema.TypeKind_Enum:
-			panic("TODO")
+			// continue -- enums behave as strings at the type level, so the data-model driven behavior is sufficient.
 		default:
 			panic("unreachable")
 		}
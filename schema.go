@@ -6,7 +6,6 @@ import (
 	"os"
 
 	"github.com/ipld/go-ipld-prime/schema"
-	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
 	schemadsl "github.com/ipld/go-ipld-prime/schema/dsl"
 )
 
@@ -29,14 +28,13 @@ func LoadSchemaFile(path string) (*schema.TypeSystem, error) {
 
 // LoadSchema parses an IPLD Schema in its DSL form
 // and compiles its types into a standalone TypeSystem.
+//
+// Errors in the schema are returned as a schemadsl.ErrorList,
+// which says where in the DSL each error was found.
 func LoadSchema(name string, r io.Reader) (*schema.TypeSystem, error) {
-	sch, err := schemadsl.Parse(name, r)
-	if err != nil {
-		return nil, err
-	}
 	ts := new(schema.TypeSystem)
 	ts.Init()
-	if err := schemadmt.Compile(ts, sch); err != nil {
+	if err := schemadsl.Compile(ts, name, r); err != nil {
 		return nil, err
 	}
 	return ts, nil
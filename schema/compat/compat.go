@@ -0,0 +1,482 @@
+/*
+Package schema/compat compares two versions of a schema,
+and reports the changes which could stop data written with one version from being read with the other.
+
+Compatibility goes two ways, so every Issue says which way (or ways) it breaks:
+
+  - a change breaks Backward compatibility if data written with the old schema might not be readable with the new one;
+  - a change breaks Forward compatibility if data written with the new schema might not be readable with the old one.
+
+For example, adding an optional field to a struct breaks only forward compatibility:
+old data will still load, but old readers will reject new data which contains the field.
+
+The comparison is by type name: a type in the old schema is compared with the type of the same name in the new schema,
+and types only found in the new schema aren't reported at all.
+References between types are compared by name too, so renaming a type which a field uses is reported as a change to that field's type,
+even if the two types are identical in every other respect.
+
+A typical use is a test that fails when a schema changes in a way that would break existing data:
+
+	issues := compat.Breaking(compat.Compare(oldTS, newTS), compat.Backward)
+	if len(issues) > 0 {
+		t.Fatalf("schema change breaks existing data: %v", issues)
+	}
+*/
+package compat
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// Direction says which way a change breaks compatibility.
+// It's a bitfield: a change may break compatibility both ways.
+type Direction uint8
+
+const (
+	// Backward compatibility is broken if data written with the old schema might not be readable with the new one.
+	Backward Direction = 1 << iota
+	// Forward compatibility is broken if data written with the new schema might not be readable with the old one.
+	Forward
+
+	Both = Backward | Forward
+)
+
+func (d Direction) String() string {
+	switch d {
+	case Backward:
+		return "backward"
+	case Forward:
+		return "forward"
+	case Both:
+		return "backward and forward"
+	default:
+		return fmt.Sprintf("Direction(%d)", uint8(d))
+	}
+}
+
+// Change identifies the kind of change an Issue is about.
+// The values are short, stable strings, so they're fine to match on or to store.
+type Change string
+
+const (
+	TypeRemoved           Change = "type-removed"           // The type isn't in the new schema.
+	TypeKindChanged       Change = "type-kind-changed"      // The type is of a different kind, e.g. it was a struct and now it's a union.
+	RepresentationChanged Change = "representation-changed" // The representation strategy, or one of its parameters (e.g. a delimiter), is different.
+
+	FieldRemoved      Change = "field-removed"       // A struct field is gone.
+	FieldAdded        Change = "field-added"         // A struct field is new.
+	FieldTypeChanged  Change = "field-type-changed"  // A struct field has a different type.
+	FieldKeyChanged   Change = "field-key-changed"   // A struct field is serialized under a different key (e.g. it gained a rename).
+	FieldOrderChanged Change = "field-order-changed" // A struct field is in a different position, in a representation where that matters.
+	ImplicitChanged   Change = "implicit-changed"    // A struct field gained, lost, or has a different implicit value, which is what its absence means.
+
+	OptionalChanged Change = "optional-changed" // A struct field became optional, or stopped being optional.
+	NullableChanged Change = "nullable-changed" // A struct field, or the values of a map or list, became nullable, or stopped being nullable.
+
+	KeyTypeChanged   Change = "key-type-changed"   // The keys of a map are of a different type.
+	ValueTypeChanged Change = "value-type-changed" // The values of a map or list are of a different type.
+
+	MemberRemoved       Change = "member-removed"        // A member of a union or enum is gone.
+	MemberAdded         Change = "member-added"          // A member of a union or enum is new.
+	MemberSerialChanged Change = "member-serial-changed" // A member of a union or enum is serialized differently (e.g. its discriminant changed).
+)
+
+// Issue describes one change between two versions of a type.
+type Issue struct {
+	Type   schema.TypeName // The name of the type that changed.
+	Name   string          // The field or member that changed, if the change is about one; empty otherwise.
+	Change Change
+	Breaks Direction
+	Detail string // A human-readable description of the change.
+}
+
+func (i Issue) String() string {
+	if i.Name != "" {
+		return fmt.Sprintf("%s.%s: %s (breaks %s compatibility): %s", i.Type, i.Name, i.Change, i.Breaks, i.Detail)
+	}
+	return fmt.Sprintf("%s: %s (breaks %s compatibility): %s", i.Type, i.Change, i.Breaks, i.Detail)
+}
+
+// Compare reports every change between the types of the old schema and those of the new one
+// which could affect whether data can be read.
+// The issues are ordered by type name, and then in the order the changes were found.
+func Compare(old, new *schema.TypeSystem) []Issue {
+	names := make([]schema.TypeName, 0, len(old.GetTypes()))
+	for name := range old.GetTypes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var c comparison
+	for _, name := range names {
+		t2 := new.TypeByName(name)
+		if t2 == nil {
+			c.issue(name, "", TypeRemoved, Backward, "type is not in the new schema")
+			continue
+		}
+		c.compareType(old.TypeByName(name), t2)
+	}
+	return c.issues
+}
+
+// Breaking returns just the issues which break compatibility in the given direction.
+// With Both, issues which break compatibility either way are returned.
+func Breaking(issues []Issue, d Direction) []Issue {
+	var result []Issue
+	for _, i := range issues {
+		if i.Breaks&d != 0 {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+type comparison struct {
+	issues []Issue
+}
+
+func (c *comparison) issue(typ schema.TypeName, name string, change Change, breaks Direction, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{
+		Type:   typ,
+		Name:   name,
+		Change: change,
+		Breaks: breaks,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *comparison) compareType(t1, t2 schema.Type) {
+	name := t1.Name()
+	if t1.TypeKind() != t2.TypeKind() {
+		c.issue(name, "", TypeKindChanged, Both, "was a %s, now a %s", t1.TypeKind(), t2.TypeKind())
+		return
+	}
+	if adl1, adl2 := schema.AdvancedLayoutOf(t1), schema.AdvancedLayoutOf(t2); adl1 != adl2 {
+		c.issue(name, "", RepresentationChanged, Both, "advanced layout was %q, now %q", adl1, adl2)
+		return
+	}
+	switch t1 := t1.(type) {
+	case *schema.TypeStruct:
+		c.compareStruct(t1, t2.(*schema.TypeStruct))
+	case *schema.TypeUnion:
+		c.compareUnion(t1, t2.(*schema.TypeUnion))
+	case *schema.TypeEnum:
+		c.compareEnum(t1, t2.(*schema.TypeEnum))
+	case *schema.TypeMap:
+		c.compareMap(t1, t2.(*schema.TypeMap))
+	case *schema.TypeList:
+		t2 := t2.(*schema.TypeList)
+		if t1.ValueType().Name() != t2.ValueType().Name() {
+			c.issue(name, "", ValueTypeChanged, Both, "values were %s, now %s", t1.ValueType().Name(), t2.ValueType().Name())
+		}
+		c.compareNullable(name, "", t1.ValueIsNullable(), t2.ValueIsNullable())
+	default:
+		// Scalars, links, and any have nothing more to compare.
+		// (The type a link refers to is only a hint, and doesn't affect what data is acceptable.)
+	}
+}
+
+// compareNullable reports a change of nullability; for the values of a map or list, name is empty.
+func (c *comparison) compareNullable(typ schema.TypeName, name string, n1, n2 bool) {
+	switch {
+	case n1 && !n2:
+		c.issue(typ, name, NullableChanged, Backward, "is no longer nullable")
+	case !n1 && n2:
+		c.issue(typ, name, NullableChanged, Forward, "is now nullable")
+	}
+}
+
+// reprParams describes a representation strategy and its parameters as a string,
+// so that any difference between two strategies shows up as a difference between two strings.
+// Struct map representations and union and enum tables are left out: those are compared field by field and member by member.
+func reprParams(stg interface{}) string {
+	switch stg := stg.(type) {
+	case schema.StructRepresentation_Map:
+		return "map"
+	case schema.StructRepresentation_Tuple:
+		return "tuple"
+	case schema.StructRepresentation_ListPairs:
+		return "listpairs"
+	case schema.StructRepresentation_StringPairs:
+		return fmt.Sprintf("stringpairs (innerDelim %q, entryDelim %q)", stg.GetInnerDelim(), stg.GetEntryDelim())
+	case schema.StructRepresentation_Stringjoin:
+		return fmt.Sprintf("stringjoin (join %q)", stg.GetDelim())
+	case schema.MapRepresentation_Map:
+		return "map"
+	case schema.MapRepresentation_StringPairs:
+		return fmt.Sprintf("stringpairs (innerDelim %q, entryDelim %q)", stg.GetInnerDelim(), stg.GetEntryDelim())
+	case schema.MapRepresentation_ListPairs:
+		return "listpairs"
+	case schema.UnionRepresentation_Keyed:
+		return "keyed"
+	case schema.UnionRepresentation_Kinded:
+		return "kinded"
+	case schema.UnionRepresentation_Envelope:
+		return fmt.Sprintf("envelope (discriminantKey %q, contentKey %q)", stg.GetDiscriminantKey(), stg.GetContentKey())
+	case schema.UnionRepresentation_Inline:
+		return fmt.Sprintf("inline (discriminantKey %q)", stg.GetDiscriminantKey())
+	case schema.UnionRepresentation_Stringprefix:
+		return fmt.Sprintf("stringprefix (delim %q)", stg.GetDelim())
+	case schema.UnionRepresentation_BytesPrefix:
+		return "bytesprefix"
+	case schema.EnumRepresentation_String:
+		return "string"
+	case schema.EnumRepresentation_Int:
+		return "int"
+	default:
+		panic(fmt.Sprintf("unknown representation strategy %T", stg))
+	}
+}
+
+// compareRepr reports a change of representation, and returns false if there was one,
+// since nothing else about the two types can be usefully compared after that.
+func (c *comparison) compareRepr(typ schema.TypeName, stg1, stg2 interface{}) bool {
+	if p1, p2 := reprParams(stg1), reprParams(stg2); p1 != p2 {
+		c.issue(typ, "", RepresentationChanged, Both, "representation was %s, now %s", p1, p2)
+		return false
+	}
+	return true
+}
+
+// fieldPositions returns where each field of a struct appears in its representation,
+// which for a tuple with a fieldOrder isn't the order the fields are declared in.
+func fieldPositions(t *schema.TypeStruct) map[string]int {
+	fields := t.TupleFields()
+	if fields == nil {
+		fields = t.Fields()
+	}
+	positions := make(map[string]int, len(fields))
+	for i, f := range fields {
+		positions[f.Name()] = i
+	}
+	return positions
+}
+
+func (c *comparison) compareStruct(t1, t2 *schema.TypeStruct) {
+	name := t1.Name()
+	stg1, stg2 := t1.RepresentationStrategy(), t2.RepresentationStrategy()
+	if !c.compareRepr(name, stg1, stg2) {
+		return
+	}
+	_, positional := stg1.(schema.StructRepresentation_Tuple)
+	_, stringjoin := stg1.(schema.StructRepresentation_Stringjoin)
+	positional = positional || stringjoin
+
+	// In the representations which identify fields by key, an unknown key is rejected;
+	// so adding a field, even an optional one, means old readers may reject new data.
+	// In a tuple, adding an optional field to the end has the same effect: old readers reject the longer list.
+	// Either way, a field that's absent in the data breaks whichever side requires it.
+	fields2 := make(map[string]int, len(t2.Fields()))
+	for i, f2 := range t2.Fields() {
+		fields2[f2.Name()] = i
+	}
+	positions1, positions2 := fieldPositions(t1), fieldPositions(t2)
+	for _, f1 := range t1.Fields() {
+		j, ok := fields2[f1.Name()]
+		if !ok {
+			switch {
+			case f1.IsOptional():
+				c.issue(name, f1.Name(), FieldRemoved, Backward, "optional field was removed")
+			case hasImplicit(stg1, f1):
+				// Old readers take the field's absence to mean its implicit value.
+				c.issue(name, f1.Name(), FieldRemoved, Backward, "required field with an implicit value was removed")
+			default:
+				c.issue(name, f1.Name(), FieldRemoved, Both, "required field was removed")
+			}
+			continue
+		}
+		f2 := t2.Fields()[j]
+		if positional {
+			if p1, p2 := positions1[f1.Name()], positions2[f1.Name()]; p1 != p2 {
+				c.issue(name, f1.Name(), FieldOrderChanged, Both, "field was at position %d, now at %d", p1, p2)
+			}
+		}
+		if f1.Type().Name() != f2.Type().Name() {
+			c.issue(name, f1.Name(), FieldTypeChanged, Both, "field was of type %s, now %s", f1.Type().Name(), f2.Type().Name())
+		}
+		if m1, ok := stg1.(schema.StructRepresentation_Map); ok {
+			m2 := stg2.(schema.StructRepresentation_Map)
+			if k1, k2 := m1.GetFieldKey(f1), m2.GetFieldKey(f2); k1 != k2 {
+				c.issue(name, f1.Name(), FieldKeyChanged, Both, "field was serialized with key %q, now %q", k1, k2)
+			}
+			c.compareImplicit(name, f1.Name(), m1.FieldImplicit(f1), m2.FieldImplicit(f2))
+		}
+		switch {
+		case f1.IsOptional() && !f2.IsOptional():
+			c.issue(name, f1.Name(), OptionalChanged, Backward, "field is no longer optional")
+		case !f1.IsOptional() && f2.IsOptional():
+			c.issue(name, f1.Name(), OptionalChanged, Forward, "field is now optional")
+		}
+		c.compareNullable(name, f1.Name(), f1.IsNullable(), f2.IsNullable())
+	}
+	fields1 := make(map[string]struct{}, len(t1.Fields()))
+	for _, f1 := range t1.Fields() {
+		fields1[f1.Name()] = struct{}{}
+	}
+	for _, f2 := range t2.Fields() {
+		if _, ok := fields1[f2.Name()]; ok {
+			continue
+		}
+		switch {
+		case f2.IsOptional():
+			c.issue(name, f2.Name(), FieldAdded, Forward, "optional field was added")
+		case hasImplicit(stg2, f2):
+			// New readers take the field's absence in old data to mean its implicit value.
+			c.issue(name, f2.Name(), FieldAdded, Forward, "required field with an implicit value was added")
+		default:
+			c.issue(name, f2.Name(), FieldAdded, Both, "required field was added")
+		}
+	}
+}
+
+// compareImplicit reports a change to a field's implicit value.
+// Data omits a field whose value is the implicit one, so a reader without the implicit
+// may reject that data, and a reader with a different implicit reads a different value.
+func (c *comparison) compareImplicit(typ schema.TypeName, name string, i1, i2 schema.ImplicitValue) {
+	switch {
+	case i1 == i2:
+	case i2 == nil:
+		c.issue(typ, name, ImplicitChanged, Backward, "field no longer has an implicit value (was %s)", implicitString(i1))
+	case i1 == nil:
+		c.issue(typ, name, ImplicitChanged, Forward, "field now has an implicit value (%s)", implicitString(i2))
+	default:
+		c.issue(typ, name, ImplicitChanged, Both, "field's implicit value was %s, now %s", implicitString(i1), implicitString(i2))
+	}
+}
+
+// hasImplicit reports whether a field has an implicit value in the given struct representation.
+func hasImplicit(stg schema.StructRepresentation, f schema.StructField) bool {
+	m, ok := stg.(schema.StructRepresentation_Map)
+	return ok && m.FieldImplicit(f) != nil
+}
+
+func implicitString(v schema.ImplicitValue) string {
+	switch v := v.(type) {
+	case schema.ImplicitValue_EmptyList:
+		return "[]"
+	case schema.ImplicitValue_EmptyMap:
+		return "{}"
+	case schema.ImplicitValue_String:
+		return fmt.Sprintf("%q", string(v))
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// discriminant returns how a union member is identified in the union's representation.
+func discriminant(stg schema.UnionRepresentation, member schema.Type) string {
+	switch stg := stg.(type) {
+	case schema.UnionRepresentation_Keyed:
+		return stg.GetDiscriminant(member)
+	case schema.UnionRepresentation_Envelope:
+		return stg.GetDiscriminant(member)
+	case schema.UnionRepresentation_Inline:
+		return stg.GetDiscriminant(member)
+	case schema.UnionRepresentation_Stringprefix:
+		return stg.GetDiscriminant(member)
+	case schema.UnionRepresentation_BytesPrefix:
+		return stg.GetDiscriminant(member)
+	case schema.UnionRepresentation_Kinded:
+		for _, k := range []datamodel.Kind{
+			datamodel.Kind_Map, datamodel.Kind_List, datamodel.Kind_Null, datamodel.Kind_Bool, datamodel.Kind_Int,
+			datamodel.Kind_Float, datamodel.Kind_String, datamodel.Kind_Bytes, datamodel.Kind_Link,
+		} {
+			if stg.GetMember(k) == member.Name() {
+				return k.String()
+			}
+		}
+		panic("that type isn't a member of this union")
+	default:
+		panic(fmt.Sprintf("unknown representation strategy %T", stg))
+	}
+}
+
+func (c *comparison) compareUnion(t1, t2 *schema.TypeUnion) {
+	name := t1.Name()
+	stg1, stg2 := t1.RepresentationStrategy(), t2.RepresentationStrategy()
+	if !c.compareRepr(name, stg1, stg2) {
+		return
+	}
+	members2 := make(map[schema.TypeName]schema.Type, len(t2.Members()))
+	for _, m2 := range t2.Members() {
+		members2[m2.Name()] = m2
+	}
+	members1 := make(map[schema.TypeName]struct{}, len(t1.Members()))
+	for _, m1 := range t1.Members() {
+		members1[m1.Name()] = struct{}{}
+		m2, ok := members2[m1.Name()]
+		if !ok {
+			c.issue(name, m1.Name(), MemberRemoved, Backward, "union member was removed")
+			continue
+		}
+		if d1, d2 := discriminant(stg1, m1), discriminant(stg2, m2); d1 != d2 {
+			c.issue(name, m1.Name(), MemberSerialChanged, Both, "union member discriminant was %q, now %q", d1, d2)
+		}
+	}
+	for _, m2 := range t2.Members() {
+		if _, ok := members1[m2.Name()]; !ok {
+			c.issue(name, m2.Name(), MemberAdded, Forward, "union member was added")
+		}
+	}
+}
+
+// enumSerial returns how an enum member is represented, as a string.
+func enumSerial(stg schema.EnumRepresentation, member string) string {
+	switch stg := stg.(type) {
+	case schema.EnumRepresentation_String:
+		if s, ok := stg[member]; ok {
+			return fmt.Sprintf("%q", s)
+		}
+		return fmt.Sprintf("%q", member)
+	case schema.EnumRepresentation_Int:
+		return fmt.Sprintf("%d", stg[member])
+	default:
+		panic(fmt.Sprintf("unknown representation strategy %T", stg))
+	}
+}
+
+func (c *comparison) compareEnum(t1, t2 *schema.TypeEnum) {
+	name := t1.Name()
+	stg1, stg2 := t1.RepresentationStrategy(), t2.RepresentationStrategy()
+	if !c.compareRepr(name, stg1, stg2) {
+		return
+	}
+	members2 := make(map[string]struct{}, len(t2.Members()))
+	for _, m2 := range t2.Members() {
+		members2[m2] = struct{}{}
+	}
+	members1 := make(map[string]struct{}, len(t1.Members()))
+	for _, m1 := range t1.Members() {
+		members1[m1] = struct{}{}
+		if _, ok := members2[m1]; !ok {
+			c.issue(name, m1, MemberRemoved, Backward, "enum member was removed")
+			continue
+		}
+		if s1, s2 := enumSerial(stg1, m1), enumSerial(stg2, m1); s1 != s2 {
+			c.issue(name, m1, MemberSerialChanged, Both, "enum member was represented as %s, now %s", s1, s2)
+		}
+	}
+	for _, m2 := range t2.Members() {
+		if _, ok := members1[m2]; !ok {
+			c.issue(name, m2, MemberAdded, Forward, "enum member was added")
+		}
+	}
+}
+
+func (c *comparison) compareMap(t1, t2 *schema.TypeMap) {
+	name := t1.Name()
+	if !c.compareRepr(name, t1.RepresentationStrategy(), t2.RepresentationStrategy()) {
+		return
+	}
+	if t1.KeyType().Name() != t2.KeyType().Name() {
+		c.issue(name, "", KeyTypeChanged, Both, "keys were %s, now %s", t1.KeyType().Name(), t2.KeyType().Name())
+	}
+	if t1.ValueType().Name() != t2.ValueType().Name() {
+		c.issue(name, "", ValueTypeChanged, Both, "values were %s, now %s", t1.ValueType().Name(), t2.ValueType().Name())
+	}
+	c.compareNullable(name, "", t1.ValueIsNullable(), t2.ValueIsNullable())
+}
@@ -0,0 +1,248 @@
+package compat_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/compat"
+)
+
+func load(t *testing.T, src string) *schema.TypeSystem {
+	t.Helper()
+	ts, err := ipld.LoadSchemaBytes([]byte(src))
+	qt.Assert(t, err, qt.IsNil)
+	return ts
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	src := `
+		type Person struct {
+			name String
+			age optional Int
+		}
+		type Color enum {
+			| Red
+			| Blue
+		}
+	`
+	qt.Check(t, compat.Compare(load(t, src), load(t, src)), qt.HasLen, 0)
+}
+
+func TestCompare(t *testing.T) {
+	old := load(t, `
+		type Person struct {
+			name String
+			email String
+			nick optional String
+			age optional Int
+			height Int
+			role Role (rename "r")
+			tags [String]
+		}
+		type Role enum {
+			| Admin ("admin")
+			| User ("user")
+			| Guest ("guest")
+		}
+		type Pet union {
+			| Cat "cat"
+			| Dog "dog"
+		} representation keyed
+		type Cat struct {
+			lives Int
+		}
+		type Dog struct {
+			good Bool
+		}
+		type Point struct {
+			x Int
+			y Int
+		} representation tuple
+		type Gone string
+		type Scores {String:Int}
+		type Pair struct {
+			a String
+			b String
+		} representation stringjoin {
+			join ":"
+		}
+	`)
+	new := load(t, `
+		type Person struct {
+			name String
+			nick String
+			age Int
+			height Float
+			role Role (rename "role")
+			tags [nullable String]
+			bio optional String
+			id String
+		}
+		type Role enum {
+			| Admin ("admin")
+			| User ("member")
+			| Owner ("owner")
+		}
+		type Pet union {
+			| Cat "kitty"
+			| Bird "bird"
+		} representation keyed
+		type Cat struct {
+			lives Int
+		}
+		type Bird struct {
+			wings Int
+		}
+		type Point struct {
+			y Int
+			x Int
+		} representation tuple
+		type Scores [Int]
+		type Pair struct {
+			a String
+			b String
+		} representation stringjoin {
+			join "-"
+		}
+	`)
+
+	type issue struct {
+		Type   string
+		Name   string
+		Change compat.Change
+		Breaks compat.Direction
+	}
+	var got []issue
+	for _, i := range compat.Compare(old, new) {
+		got = append(got, issue{i.Type, i.Name, i.Change, i.Breaks})
+	}
+	qt.Check(t, got, qt.DeepEquals, []issue{
+		{"Dog", "", compat.TypeRemoved, compat.Backward},
+		{"Gone", "", compat.TypeRemoved, compat.Backward},
+		{"List__String", "", compat.NullableChanged, compat.Forward}, // the anonymous type for Person.tags.
+		{"Pair", "", compat.RepresentationChanged, compat.Both},
+		{"Person", "email", compat.FieldRemoved, compat.Both},
+		{"Person", "nick", compat.OptionalChanged, compat.Backward},
+		{"Person", "age", compat.OptionalChanged, compat.Backward},
+		{"Person", "height", compat.FieldTypeChanged, compat.Both},
+		{"Person", "role", compat.FieldKeyChanged, compat.Both},
+		{"Person", "bio", compat.FieldAdded, compat.Forward},
+		{"Person", "id", compat.FieldAdded, compat.Both},
+		{"Pet", "Cat", compat.MemberSerialChanged, compat.Both},
+		{"Pet", "Dog", compat.MemberRemoved, compat.Backward},
+		{"Pet", "Bird", compat.MemberAdded, compat.Forward},
+		{"Point", "x", compat.FieldOrderChanged, compat.Both},
+		{"Point", "y", compat.FieldOrderChanged, compat.Both},
+		{"Role", "User", compat.MemberSerialChanged, compat.Both},
+		{"Role", "Guest", compat.MemberRemoved, compat.Backward},
+		{"Role", "Owner", compat.MemberAdded, compat.Forward},
+		{"Scores", "", compat.TypeKindChanged, compat.Both},
+	})
+}
+
+func TestCompareTupleFieldOrder(t *testing.T) {
+	// What matters is where fields appear in the list, not the order they're declared in.
+	old := load(t, `
+		type Point struct {
+			x Int
+			y Int
+		} representation tuple
+		type Pair struct {
+			a String
+			b String
+		} representation tuple
+	`)
+	new := load(t, `
+		type Point struct {
+			y Int
+			x Int
+		} representation tuple {
+			fieldOrder ["x", "y"]
+		}
+		type Pair struct {
+			a String
+			b String
+		} representation tuple {
+			fieldOrder ["b", "a"]
+		}
+	`)
+	var got []string
+	for _, i := range compat.Compare(old, new) {
+		qt.Check(t, i.Change, qt.Equals, compat.FieldOrderChanged)
+		got = append(got, i.Type+"."+i.Name)
+	}
+	qt.Check(t, got, qt.DeepEquals, []string{"Pair.a", "Pair.b"})
+}
+
+func TestCompareImplicit(t *testing.T) {
+	old := load(t, `
+		type Config struct {
+			lost Bool (implicit false)
+			gained Int
+			changed String (implicit "a")
+			same Int (implicit 1)
+			removed Bool (implicit true)
+		}
+	`)
+	new := load(t, `
+		type Config struct {
+			lost Bool
+			gained Int (implicit 0)
+			changed String (implicit "b")
+			same Int (implicit 1)
+			added Float (implicit 1.5)
+		}
+	`)
+
+	type issue struct {
+		Name   string
+		Change compat.Change
+		Breaks compat.Direction
+	}
+	var got []issue
+	for _, i := range compat.Compare(old, new) {
+		got = append(got, issue{i.Name, i.Change, i.Breaks})
+	}
+	qt.Check(t, got, qt.DeepEquals, []issue{
+		{"lost", compat.ImplicitChanged, compat.Backward},
+		{"gained", compat.ImplicitChanged, compat.Forward},
+		{"changed", compat.ImplicitChanged, compat.Both},
+		{"removed", compat.FieldRemoved, compat.Backward},
+		{"added", compat.FieldAdded, compat.Forward},
+	})
+	qt.Check(t, compat.Compare(old, new)[2].Detail, qt.Equals, `field's implicit value was "a", now "b"`)
+}
+
+func TestCompareNullable(t *testing.T) {
+	old := load(t, `
+		type Names [nullable String]
+		type Thing struct {
+			a nullable String
+			b String
+		}
+	`)
+	new := load(t, `
+		type Names [String]
+		type Thing struct {
+			a String
+			b nullable String
+		}
+	`)
+	issues := compat.Compare(old, new)
+	qt.Assert(t, issues, qt.HasLen, 3)
+	qt.Check(t, issues[0].String(), qt.Equals, "Names: nullable-changed (breaks backward compatibility): is no longer nullable")
+	qt.Check(t, issues[1].String(), qt.Equals, "Thing.a: nullable-changed (breaks backward compatibility): is no longer nullable")
+	qt.Check(t, issues[2].String(), qt.Equals, "Thing.b: nullable-changed (breaks forward compatibility): is now nullable")
+
+	qt.Check(t, compat.Breaking(issues, compat.Backward), qt.HasLen, 2)
+	qt.Check(t, compat.Breaking(issues, compat.Forward), qt.HasLen, 1)
+	qt.Check(t, compat.Breaking(issues, compat.Both), qt.HasLen, 3)
+
+	// Reversing the comparison reverses the direction of every issue.
+	issues = compat.Compare(new, old)
+	qt.Check(t, compat.Breaking(issues, compat.Backward), qt.HasLen, 1)
+	qt.Check(t, compat.Breaking(issues, compat.Forward), qt.HasLen, 2)
+}
@@ -1,7 +1,10 @@
 package schemadmt
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/schema"
@@ -27,44 +30,212 @@ import (
 // It supports several validations for logical coherency of schemas,
 // but may not yet successfully reject all invalid schemas.
 func Compile(ts *schema.TypeSystem, node *Schema) error {
+	if errs := CompileAll(ts, node); len(errs) > 0 {
+		// Return the first error.
+		return errs[0]
+	}
+	return nil
+}
+
+// CompileAll is like Compile, but rather than stopping at the first error,
+// it carries on to return every error it can find.
+//
+// Errors about a particular type definition are returned as a CompileError,
+// including errors from TypeSystem.ValidateGraph about the types it defines,
+// so that tools can point at the definition responsible.
+// A type which fails to compile is left out of the TypeSystem,
+// but references to it aren't reported again as references to a missing type.
+//
+// The same caveat about the state of the TypeSystem applies as for Compile:
+// it should not be used if there were any errors.
+func CompileAll(ts *schema.TypeSystem, node *Schema) []error {
 	// Add basic types
 	schema.SpawnDefaultBasicTypes(ts)
 
 	// Add the schema types
-	err := SpawnSchemaTypes(ts, node)
-	if err != nil {
-		return err
-	}
+	c := spawnSchemaTypes(ts, node, true)
+	errs := c.errs
+
 	// TODO: if this fails and the user forgot to check Compile's returned error,
 	// we can leave the TypeSystem in an unfortunate broken state:
 	// they can obtain types out of the TypeSystem and they are non-nil,
 	// but trying to use them in any way may result in panics.
 	// Consider making that less prone to misuse, such as making it illegal to
 	// call TypeByName until ValidateGraph is happy.
-	if errs := ts.ValidateGraph(); errs != nil {
-		// Return the first error.
-		for _, err := range errs {
-			return err
+	for _, err := range ts.ValidateGraph() {
+		var missing schema.ErrMissingType
+		if errors.As(err, &missing) {
+			if c.failed[missing.Missing] {
+				continue // already reported
+			}
+			if owner, ok := c.owners[missing.TypeName]; ok {
+				err = CompileError{TypeName: owner, Err: err}
+			}
 		}
+		errs = append(errs, err)
 	}
-	return nil
+	return errs
+}
+
+// CompileError is the type of the errors returned by Compile and CompileAll
+// which are about a particular type definition in the schema.
+type CompileError struct {
+	// TypeName is the name of the type in the schema whose definition is at fault.
+	// It may be the type that an anonymous type was defined inline within,
+	// such as a struct with a field of type [Foo], where Foo is missing.
+	TypeName string
+
+	// Err is the reason.
+	Err error
+}
+
+func (e CompileError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns Err, so that errors.Is and errors.As can look at it.
+func (e CompileError) Unwrap() error {
+	return e.Err
 }
 
 // SpawnSchemaTypes is a lighter verion of compile that doesn't add basic types and doesn't validate the graph --
 // for use when you want to build a type system from multiple sources and validate later
 func SpawnSchemaTypes(ts *schema.TypeSystem, node *Schema) error {
+	if errs := spawnSchemaTypes(ts, node, false).errs; len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// spawned is what spawnSchemaTypes found out along the way.
+type spawned struct {
+	errs []error
+
+	// owners maps the name of each type spawned to the name of the type in the schema it came from,
+	// which differs for anonymous types.
+	owners map[schema.TypeName]string
+
+	// failed holds the names of the types in the schema which failed to spawn.
+	failed map[string]bool
+}
+
+func spawnSchemaTypes(ts *schema.TypeSystem, node *Schema, all bool) spawned {
+	c := spawned{
+		owners: make(map[schema.TypeName]string),
+		failed: make(map[string]bool),
+	}
+	if node.Advanced != nil {
+		for _, name := range node.Advanced.Keys {
+			if ts.HasAdvancedLayout(name) {
+				c.errs = append(c.errs, fmt.Errorf("duplicate advanced layout: %q", name))
+				if !all {
+					return c
+				}
+				continue
+			}
+			ts.AccumulateAdvancedLayout(name)
+		}
+	}
 
 	for _, name := range node.Types.Keys {
-		defn := node.Types.Values[name]
+		if err := spawnSchemaType(ts, node, name, c.owners); err != nil {
+			c.errs = append(c.errs, CompileError{TypeName: name, Err: err})
+			c.failed[name] = true
+			if !all {
+				return c
+			}
+		}
+	}
+	return c
+}
+
+// spawnSchemaType spawns the named type from the schema and accumulates it in ts,
+// along with any anonymous types it defines, recording where they all came from in owners.
+func spawnSchemaType(ts *schema.TypeSystem, node *Schema, name string, owners map[schema.TypeName]string) error {
+	if ts.TypeByName(name) != nil {
+		return fmt.Errorf("type %q is already defined", name)
+	}
+	defn, err := resolveCopy(node, name)
+	if err != nil {
+		return err
+	}
+
+	// Anonymous types are accumulated as they're spawned, so note what's new afterwards.
+	before := len(ts.Names())
+	defer func() {
+		for _, spawned := range ts.Names()[before:] {
+			owners[spawned] = name
+		}
+	}()
+
+	// TODO: once ./schema supports anonymous/inline types, remove the ts argument.
+	typ, err := spawnType(ts, name, defn)
+	if err != nil {
+		return err
+	}
+	ts.Accumulate(typ)
+	return nil
+}
+
+// resolveCopy returns the definition of the named type,
+// following copies such as `type B = A` to the definition they copy.
+// Only types within the same schema can be copied.
+func resolveCopy(node *Schema, name string) (TypeDefn, error) {
+	defn := node.Types.Values[name]
+	seen := map[string]bool{name: true}
+	for defn.TypeDefnCopy != nil {
+		from := defn.TypeDefnCopy.FromType
+		if seen[from] {
+			return defn, fmt.Errorf("type %q is a copy of itself", name)
+		}
+		seen[from] = true
+		var ok bool
+		defn, ok = node.Types.Values[from]
+		if !ok {
+			return defn, fmt.Errorf("type %q copies undefined type %q", name, from)
+		}
+	}
+	return defn, nil
+}
 
-		// TODO: once ./schema supports anonymous/inline types, remove the ts argument.
-		typ, err := spawnType(ts, name, defn)
-		if err != nil {
-			return err
+// checkFieldOrder returns an error if a stringjoin struct's fieldOrder differs from the order its fields are declared in,
+// since the schema package can't yet represent any other order for stringjoin (only for tuples).
+func checkFieldOrder(defn *TypeDefnStruct, order *List__FieldName) error {
+	if order == nil {
+		return nil
+	}
+	if len(*order) == len(defn.Fields.Keys) {
+		same := true
+		for i, name := range *order {
+			if defn.Fields.Keys[i] != name {
+				same = false
+				break
+			}
+		}
+		if same {
+			return nil
 		}
-		ts.Accumulate(typ)
 	}
+	return fmt.Errorf("TODO: support for fieldOrder differing from the order of the fields in schema package")
+}
 
+// checkFieldOrderComplete returns an error unless a struct's fieldOrder lists each of its fields exactly once.
+func checkFieldOrderComplete(defn *TypeDefnStruct, order List__FieldName) error {
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if _, ok := defn.Fields.Values[name]; !ok {
+			return fmt.Errorf("fieldOrder lists %q, which is not a field", name)
+		}
+		if seen[name] {
+			return fmt.Errorf("fieldOrder lists %q more than once", name)
+		}
+		seen[name] = true
+	}
+	for _, name := range defn.Fields.Keys {
+		if !seen[name] {
+			return fmt.Errorf("fieldOrder is missing field %q", name)
+		}
+	}
 	return nil
 }
 
@@ -135,7 +306,20 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 	case defn.TypeDefnString != nil:
 		return schema.SpawnString(name), nil
 	case defn.TypeDefnBytes != nil:
-		return schema.SpawnBytes(name), nil
+		typ := defn.TypeDefnBytes
+		switch {
+		case typ.Representation == nil ||
+			typ.Representation.BytesRepresentation_Bytes != nil:
+			return schema.SpawnBytes(name), nil
+		case typ.Representation.AdvancedDataLayoutName != nil:
+			adl := *typ.Representation.AdvancedDataLayoutName
+			if !ts.HasAdvancedLayout(adl) {
+				return nil, fmt.Errorf("type %q refers to undeclared advanced layout %q", name, adl)
+			}
+			return schema.SpawnBytesAdvanced(name, adl), nil
+		default:
+			return nil, fmt.Errorf("TODO: support other bytes repr in schema package")
+		}
 	case defn.TypeDefnInt != nil:
 		return schema.SpawnInt(name), nil
 	case defn.TypeDefnFloat != nil:
@@ -162,6 +346,16 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 		case typ.Representation == nil ||
 			typ.Representation.ListRepresentation_List != nil:
 			// default behavior
+		case typ.Representation.AdvancedDataLayoutName != nil:
+			adl := *typ.Representation.AdvancedDataLayoutName
+			if !ts.HasAdvancedLayout(adl) {
+				return nil, fmt.Errorf("type %q refers to undeclared advanced layout %q", name, adl)
+			}
+			return schema.SpawnListAdvanced(name,
+				tname,
+				todoFromImplicitlyFalseBool(typ.ValueNullable),
+				adl,
+			), nil
 		default:
 			return nil, fmt.Errorf("TODO: support other list repr in schema package")
 		}
@@ -191,7 +385,37 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 			typ.Representation.MapRepresentation_Map != nil:
 			// default behavior
 		case typ.Representation.MapRepresentation_Stringpairs != nil:
-			return nil, fmt.Errorf("TODO: support stringpairs map repr in schema package")
+			rp := typ.Representation.MapRepresentation_Stringpairs
+			if rp.InnerDelim == "" || rp.EntryDelim == "" {
+				return nil, fmt.Errorf("stringpairs has empty innerDelim or entryDelim value")
+			}
+			if todoFromImplicitlyFalseBool(typ.ValueNullable) {
+				return nil, fmt.Errorf("stringpairs map %q cannot have nullable values", name)
+			}
+			return schema.SpawnMapWithRepresentation(name,
+				typ.KeyType,
+				tname,
+				false,
+				schema.SpawnMapRepresentationStringPairs(rp.InnerDelim, rp.EntryDelim),
+			), nil
+		case typ.Representation.MapRepresentation_Listpairs != nil:
+			return schema.SpawnMapWithRepresentation(name,
+				typ.KeyType,
+				tname,
+				todoFromImplicitlyFalseBool(typ.ValueNullable),
+				schema.SpawnMapRepresentationListPairs(),
+			), nil
+		case typ.Representation.AdvancedDataLayoutName != nil:
+			adl := *typ.Representation.AdvancedDataLayoutName
+			if !ts.HasAdvancedLayout(adl) {
+				return nil, fmt.Errorf("type %q refers to undeclared advanced layout %q", name, adl)
+			}
+			return schema.SpawnMapAdvanced(name,
+				typ.KeyType,
+				tname,
+				todoFromImplicitlyFalseBool(typ.ValueNullable),
+				adl,
+			), nil
 		default:
 			return nil, fmt.Errorf("TODO: support other map repr in schema package")
 		}
@@ -251,8 +475,10 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 						sumVal = schema.ImplicitValue_String(*imp.String)
 					case imp.Int != nil:
 						sumVal = schema.ImplicitValue_Int(*imp.Int)
+					case imp.Float != nil:
+						sumVal = schema.ImplicitValue_Float(*imp.Float)
 					default:
-						panic("TODO: implicit value kind")
+						return nil, fmt.Errorf("TODO: support implicit values of kinds other than bool, string, int, and float in schema package")
 					}
 					implicits[name] = sumVal
 				}
@@ -265,15 +491,33 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 				repr = schema.SpawnStructRepresentationTuple()
 				break
 			}
-			return nil, fmt.Errorf("TODO: support for tuples with field orders in the schema package")
+			if err := checkFieldOrderComplete(typ, *rp.FieldOrder); err != nil {
+				return nil, err
+			}
+			repr = schema.SpawnStructRepresentationTupleWithFieldOrder(*rp.FieldOrder)
 		case typ.Representation.StructRepresentation_Stringjoin != nil:
-			join := typ.Representation.StructRepresentation_Stringjoin.Join
+			rp := typ.Representation.StructRepresentation_Stringjoin
+			join := rp.Join
 			if join == "" {
 				return nil, fmt.Errorf("stringjoin has empty join value")
 			}
+			if err := checkFieldOrder(typ, rp.FieldOrder); err != nil {
+				return nil, err
+			}
 			repr = schema.SpawnStructRepresentationStringjoin(join)
 		case typ.Representation.StructRepresentation_Listpairs != nil:
 			repr = schema.SpawnStructRepresentationListPairs()
+		case typ.Representation.StructRepresentation_Stringpairs != nil:
+			rp := typ.Representation.StructRepresentation_Stringpairs
+			if rp.InnerDelim == "" || rp.EntryDelim == "" {
+				return nil, fmt.Errorf("stringpairs has empty innerDelim or entryDelim value")
+			}
+			for _, field := range fields {
+				if field.IsMaybe() {
+					return nil, fmt.Errorf("stringpairs struct %q cannot have optional or nullable field %q", name, field.Name())
+				}
+			}
+			repr = schema.SpawnStructRepresentationStringPairs(rp.InnerDelim, rp.EntryDelim)
 		default:
 			return nil, fmt.Errorf("TODO: support other struct repr in schema package")
 		}
@@ -385,6 +629,54 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 				}
 			}
 			repr = schema.SpawnUnionRepresentationInline(rp.DiscriminantKey, rp.DiscriminantTable.Values)
+		case typ.Representation.UnionRepresentation_Envelope != nil:
+			rp := typ.Representation.UnionRepresentation_Envelope
+			if rp.DiscriminantKey == "" {
+				return nil, fmt.Errorf("envelope union has empty discriminantKey value")
+			}
+			if rp.ContentKey == "" {
+				return nil, fmt.Errorf("envelope union has empty contentKey value")
+			}
+			if rp.DiscriminantKey == rp.ContentKey {
+				return nil, fmt.Errorf("envelope union has the same discriminantKey and contentKey: %q", rp.ContentKey)
+			}
+			table := make(map[string]schema.TypeName, len(rp.DiscriminantTable.Keys))
+			for _, key := range rp.DiscriminantTable.Keys {
+				member := rp.DiscriminantTable.Values[key]
+				switch {
+				case member.TypeName != nil:
+					memberName := *member.TypeName
+					if err := validMember(memberName); err != nil {
+						return nil, err
+					}
+					table[key] = memberName
+				case member.UnionMemberInlineDefn != nil:
+					tname := anonLinkName(*member.UnionMemberInlineDefn.TypeDefnLink)
+					if err := validMember(tname); err != nil {
+						return nil, err
+					}
+					table[key] = tname
+				}
+			}
+			repr = schema.SpawnUnionRepresentationEnvelope(rp.DiscriminantKey, rp.ContentKey, table)
+		case typ.Representation.UnionRepresentation_BytesPrefix != nil:
+			prefixes := typ.Representation.UnionRepresentation_BytesPrefix.Prefixes
+			for i, key := range prefixes.Keys {
+				if _, err := hex.DecodeString(key); err != nil || key == "" {
+					return nil, fmt.Errorf("bytesprefix union %q has an invalid hex prefix %q", name, key)
+				}
+				// One prefix may be the start of another, as the longest match wins;
+				// but prefixes are case-insensitive, so two may not differ only in case.
+				for _, other := range prefixes.Keys[:i] {
+					if strings.EqualFold(key, other) {
+						return nil, fmt.Errorf("bytesprefix union %q has the same prefix twice: %q and %q", name, other, key)
+					}
+				}
+				if err := validMember(prefixes.Values[key]); err != nil {
+					return nil, err
+				}
+			}
+			repr = schema.SpawnUnionRepresentationBytesPrefix(prefixes.Values)
 		default:
 			return nil, fmt.Errorf("TODO: support other union repr in schema package")
 		}
@@ -442,6 +734,8 @@ func spawnType(ts *schema.TypeSystem, name schema.TypeName, defn TypeDefn) (sche
 		return schema.SpawnLinkReference(name, *typ.ExpectedType), nil
 	case defn.TypeDefnAny != nil:
 		return schema.SpawnAny(name), nil
+	case defn.TypeDefnUnit != nil:
+		return nil, fmt.Errorf("TODO: support unit types in schema package")
 	default:
 		panic(fmt.Errorf("%#v", defn))
 	}
@@ -0,0 +1,458 @@
+package schemadmt
+
+import (
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// ConvertTypeSystem produces the DMT form of a compiled type system,
+// such as one built programmatically with the schema.Spawn functions.
+// It's roughly the inverse of Compile:
+// compiling the result into a fresh TypeSystem gives back equivalent types.
+//
+// Types which Compile defines anyway (the ones from schema.SpawnDefaultBasicTypes) are left out,
+// as long as they're the same as the defaults; if they're not, that's an error,
+// because compiling the result would define them twice.
+//
+// Types named the way Compile names the types it creates for inline definitions,
+// like "List__String" or "Map__String__Int", are written as inline definitions wherever they're used,
+// rather than declared, if that's possible everywhere they're used.
+// This is what makes a schema parsed from the DSL and compiled convert back to something close to the original DMT.
+func ConvertTypeSystem(ts *schema.TypeSystem) (*Schema, error) {
+	c := converter{inline: inlinableTypes(ts)}
+	sch := &Schema{Types: Map__TypeName__TypeDefn{Values: make(map[string]TypeDefn)}}
+	if names := ts.AdvancedLayoutNames(); len(names) > 0 {
+		sch.Advanced = &AdvancedDataLayoutMap{Values: make(map[string]AdvancedDataLayout)}
+		for _, name := range names {
+			sch.Advanced.Keys = append(sch.Advanced.Keys, name)
+			sch.Advanced.Values[name] = AdvancedDataLayout{}
+		}
+	}
+	for _, name := range ts.Names() {
+		t := ts.TypeByName(name)
+		if isDefaultBasicType(t) {
+			continue
+		}
+		if prelude.TypeByName(name) != nil {
+			return nil, fmt.Errorf("type %q differs from the type of the same name which Compile always defines", name)
+		}
+		if c.inline[name] {
+			continue
+		}
+		defn, err := c.typeDefn(t)
+		if err != nil {
+			return nil, fmt.Errorf("type %q: %w", name, err)
+		}
+		sch.Types.Keys = append(sch.Types.Keys, name)
+		sch.Types.Values[name] = defn
+	}
+	return sch, nil
+}
+
+// prelude holds the types which Compile always defines.
+var prelude schema.TypeSystem
+
+func init() {
+	prelude.Init()
+	schema.SpawnDefaultBasicTypes(&prelude)
+}
+
+func isDefaultBasicType(t schema.Type) bool {
+	p := prelude.TypeByName(t.Name())
+	if p == nil || p.TypeKind() != t.TypeKind() || schema.AdvancedLayoutOf(t) != "" {
+		return false
+	}
+	switch t := t.(type) {
+	case *schema.TypeMap:
+		p := p.(*schema.TypeMap)
+		_, isMapRepr := t.RepresentationStrategy().(schema.MapRepresentation_Map)
+		return isMapRepr && t.KeyType().Name() == p.KeyType().Name() &&
+			t.ValueType().Name() == p.ValueType().Name() && !t.ValueIsNullable()
+	case *schema.TypeList:
+		p := p.(*schema.TypeList)
+		return t.ValueType().Name() == p.ValueType().Name() && !t.ValueIsNullable()
+	case *schema.TypeLink:
+		return !t.HasReferencedType()
+	}
+	return true
+}
+
+// inlinableTypes finds the types which can be written as inline definitions everywhere they're used.
+// That's the types which have the name Compile would give their inline definition,
+// and which are only used as the types of struct fields or of list or map values.
+func inlinableTypes(ts *schema.TypeSystem) map[schema.TypeName]bool {
+	inline := make(map[schema.TypeName]bool)
+	for _, name := range ts.Names() {
+		if name == inlineName(ts.TypeByName(name)) {
+			inline[name] = false // until we find a use of it.
+		}
+	}
+	used := func(t schema.Type) {
+		if t == nil {
+			return
+		}
+		if _, ok := inline[t.Name()]; ok {
+			inline[t.Name()] = true
+		}
+	}
+	// Uses which can't be inline definitions rule the type out for good.
+	ruledOut := make(map[schema.TypeName]bool)
+	for _, name := range ts.Names() {
+		switch t := ts.TypeByName(name).(type) {
+		case *schema.TypeStruct:
+			for _, f := range t.Fields() {
+				used(f.Type())
+			}
+		case *schema.TypeList:
+			used(t.ValueType())
+		case *schema.TypeMap:
+			used(t.ValueType())
+			ruledOut[t.KeyType().Name()] = true
+		case *schema.TypeUnion:
+			for _, member := range t.Members() {
+				// Only links can be written inline as union members.
+				if _, ok := member.(*schema.TypeLink); ok {
+					used(member)
+				} else {
+					ruledOut[member.Name()] = true
+				}
+			}
+		case *schema.TypeLink:
+			if t.HasReferencedType() && t.ReferencedType() != nil {
+				ruledOut[t.ReferencedType().Name()] = true
+			}
+		}
+	}
+	for name, isUsed := range inline {
+		if !isUsed || ruledOut[name] {
+			delete(inline, name)
+		}
+	}
+	return inline
+}
+
+// inlineName returns the name Compile gives the type an inline definition describes,
+// if the type could be described by an inline definition at all.
+func inlineName(t schema.Type) schema.TypeName {
+	if schema.AdvancedLayoutOf(t) != "" {
+		return ""
+	}
+	switch t := t.(type) {
+	case *schema.TypeList:
+		return "List__" + t.ValueType().Name()
+	case *schema.TypeMap:
+		if _, ok := t.RepresentationStrategy().(schema.MapRepresentation_Map); !ok {
+			return ""
+		}
+		return "Map__" + t.KeyType().Name() + "__" + t.ValueType().Name()
+	case *schema.TypeLink:
+		if !t.HasReferencedType() || t.ReferencedType() == nil {
+			return ""
+		}
+		return anonLinkName(TypeDefnLink{ExpectedType: strPtr(t.ReferencedType().Name())})
+	}
+	return ""
+}
+
+type converter struct {
+	inline map[schema.TypeName]bool
+}
+
+func strPtr(s string) *string { return &s }
+
+func boolPtr(b bool) *bool {
+	if !b {
+		return nil
+	}
+	return &b
+}
+
+func (c converter) typeNameOrInlineDefn(t schema.Type) (TypeNameOrInlineDefn, error) {
+	if !c.inline[t.Name()] {
+		return TypeNameOrInlineDefn{TypeName: strPtr(t.Name())}, nil
+	}
+	defn, err := c.typeDefn(t)
+	if err != nil {
+		return TypeNameOrInlineDefn{}, err
+	}
+	return TypeNameOrInlineDefn{InlineDefn: &InlineDefn{
+		TypeDefnMap:  defn.TypeDefnMap,
+		TypeDefnList: defn.TypeDefnList,
+		TypeDefnLink: defn.TypeDefnLink,
+	}}, nil
+}
+
+func (c converter) typeDefn(t schema.Type) (TypeDefn, error) {
+	adl := schema.AdvancedLayoutOf(t)
+	switch t := t.(type) {
+	case *schema.TypeBool:
+		return TypeDefn{TypeDefnBool: &TypeDefnBool{}}, nil
+	case *schema.TypeString:
+		return TypeDefn{TypeDefnString: &TypeDefnString{}}, nil
+	case *schema.TypeInt:
+		return TypeDefn{TypeDefnInt: &TypeDefnInt{}}, nil
+	case *schema.TypeFloat:
+		return TypeDefn{TypeDefnFloat: &TypeDefnFloat{}}, nil
+	case *schema.TypeAny:
+		return TypeDefn{TypeDefnAny: &TypeDefnAny{}}, nil
+	case *schema.TypeBytes:
+		defn := &TypeDefnBytes{}
+		if adl != "" {
+			defn.Representation = &BytesRepresentation{AdvancedDataLayoutName: &adl}
+		}
+		return TypeDefn{TypeDefnBytes: defn}, nil
+	case *schema.TypeLink:
+		defn := &TypeDefnLink{}
+		if t.HasReferencedType() {
+			if t.ReferencedType() == nil {
+				return TypeDefn{}, fmt.Errorf("link refers to an undefined type")
+			}
+			defn.ExpectedType = strPtr(t.ReferencedType().Name())
+		}
+		return TypeDefn{TypeDefnLink: defn}, nil
+	case *schema.TypeList:
+		value, err := c.typeNameOrInlineDefn(t.ValueType())
+		if err != nil {
+			return TypeDefn{}, err
+		}
+		defn := &TypeDefnList{ValueType: value, ValueNullable: boolPtr(t.ValueIsNullable())}
+		if adl != "" {
+			defn.Representation = &ListRepresentation{AdvancedDataLayoutName: &adl}
+		}
+		return TypeDefn{TypeDefnList: defn}, nil
+	case *schema.TypeMap:
+		value, err := c.typeNameOrInlineDefn(t.ValueType())
+		if err != nil {
+			return TypeDefn{}, err
+		}
+		defn := &TypeDefnMap{KeyType: t.KeyType().Name(), ValueType: value, ValueNullable: boolPtr(t.ValueIsNullable())}
+		switch stg := t.RepresentationStrategy().(type) {
+		case schema.MapRepresentation_Map:
+			if adl != "" {
+				defn.Representation = &MapRepresentation{AdvancedDataLayoutName: &adl}
+			}
+		case schema.MapRepresentation_StringPairs:
+			defn.Representation = &MapRepresentation{MapRepresentation_Stringpairs: &MapRepresentation_Stringpairs{
+				InnerDelim: stg.GetInnerDelim(),
+				EntryDelim: stg.GetEntryDelim(),
+			}}
+		case schema.MapRepresentation_ListPairs:
+			defn.Representation = &MapRepresentation{MapRepresentation_Listpairs: &MapRepresentation_Listpairs{}}
+		default:
+			return TypeDefn{}, fmt.Errorf("unknown map representation %T", stg)
+		}
+		return TypeDefn{TypeDefnMap: defn}, nil
+	case *schema.TypeStruct:
+		return c.structDefn(t)
+	case *schema.TypeUnion:
+		return c.unionDefn(t)
+	case *schema.TypeEnum:
+		return enumDefn(t)
+	default:
+		return TypeDefn{}, fmt.Errorf("unknown type kind %s", t.TypeKind())
+	}
+}
+
+func (c converter) structDefn(t *schema.TypeStruct) (TypeDefn, error) {
+	defn := &TypeDefnStruct{}
+	if len(t.Fields()) > 0 {
+		defn.Fields.Values = make(map[string]StructField, len(t.Fields()))
+	}
+	for _, f := range t.Fields() {
+		typ, err := c.typeNameOrInlineDefn(f.Type())
+		if err != nil {
+			return TypeDefn{}, err
+		}
+		defn.Fields.Keys = append(defn.Fields.Keys, f.Name())
+		defn.Fields.Values[f.Name()] = StructField{
+			Type:     typ,
+			Optional: boolPtr(f.IsOptional()),
+			Nullable: boolPtr(f.IsNullable()),
+		}
+	}
+	switch stg := t.RepresentationStrategy().(type) {
+	case schema.StructRepresentation_Map:
+		rp := &StructRepresentation_Map{}
+		for _, f := range t.Fields() {
+			var details StructRepresentation_Map_FieldDetails
+			if stg.FieldHasRename(f) {
+				details.Rename = strPtr(stg.GetFieldKey(f))
+			}
+			if implicit := stg.FieldImplicit(f); implicit != nil {
+				var scalar AnyScalar
+				switch implicit := implicit.(type) {
+				case schema.ImplicitValue_String:
+					s := string(implicit)
+					scalar.String = &s
+				case schema.ImplicitValue_Int:
+					i := int(implicit)
+					scalar.Int = &i
+				case schema.ImplicitValue_Bool:
+					b := bool(implicit)
+					scalar.Bool = &b
+				case schema.ImplicitValue_Float:
+					f := float64(implicit)
+					scalar.Float = &f
+				default:
+					return TypeDefn{}, fmt.Errorf("field %q: implicit value %T has no DMT form", f.Name(), implicit)
+				}
+				details.Implicit = &scalar
+			}
+			if details.Rename == nil && details.Implicit == nil {
+				continue
+			}
+			if rp.Fields == nil {
+				rp.Fields = &Map__FieldName__StructRepresentation_Map_FieldDetails{
+					Values: make(map[string]StructRepresentation_Map_FieldDetails),
+				}
+			}
+			rp.Fields.Keys = append(rp.Fields.Keys, f.Name())
+			rp.Fields.Values[f.Name()] = details
+		}
+		defn.Representation.StructRepresentation_Map = rp
+	case schema.StructRepresentation_Tuple:
+		rp := &StructRepresentation_Tuple{}
+		if order := stg.FieldOrder(); order != nil {
+			fieldOrder := List__FieldName(append([]string(nil), order...))
+			rp.FieldOrder = &fieldOrder
+		}
+		defn.Representation.StructRepresentation_Tuple = rp
+	case schema.StructRepresentation_Stringjoin:
+		defn.Representation.StructRepresentation_Stringjoin = &StructRepresentation_Stringjoin{Join: stg.GetDelim()}
+	case schema.StructRepresentation_StringPairs:
+		defn.Representation.StructRepresentation_Stringpairs = &StructRepresentation_Stringpairs{
+			InnerDelim: stg.GetInnerDelim(),
+			EntryDelim: stg.GetEntryDelim(),
+		}
+	case schema.StructRepresentation_ListPairs:
+		defn.Representation.StructRepresentation_Listpairs = &StructRepresentation_Listpairs{}
+	default:
+		return TypeDefn{}, fmt.Errorf("unknown struct representation %T", stg)
+	}
+	return TypeDefn{TypeDefnStruct: defn}, nil
+}
+
+// kinds lists the kinds a kinded union can have members for, in the order they're written out in.
+var kinds = []datamodel.Kind{
+	datamodel.Kind_Map,
+	datamodel.Kind_List,
+	datamodel.Kind_Null,
+	datamodel.Kind_Bool,
+	datamodel.Kind_Int,
+	datamodel.Kind_Float,
+	datamodel.Kind_String,
+	datamodel.Kind_Bytes,
+	datamodel.Kind_Link,
+}
+
+func (c converter) unionDefn(t *schema.TypeUnion) (TypeDefn, error) {
+	defn := &TypeDefnUnion{}
+	for _, member := range t.Members() {
+		defn.Members = append(defn.Members, c.unionMember(member))
+	}
+	switch stg := t.RepresentationStrategy().(type) {
+	case schema.UnionRepresentation_Kinded:
+		rp := &UnionRepresentation_Kinded{Values: make(map[string]UnionMember)}
+		for i, member := range t.Members() {
+			for _, k := range kinds {
+				if stg.GetMember(k) == member.Name() {
+					rp.Keys = append(rp.Keys, k.String())
+					rp.Values[k.String()] = defn.Members[i]
+					break
+				}
+			}
+		}
+		defn.Representation.UnionRepresentation_Kinded = rp
+	case schema.UnionRepresentation_Keyed:
+		rp := &UnionRepresentation_Keyed{Values: make(map[string]UnionMember)}
+		for i, member := range t.Members() {
+			key := stg.GetDiscriminant(member)
+			rp.Keys = append(rp.Keys, key)
+			rp.Values[key] = defn.Members[i]
+		}
+		defn.Representation.UnionRepresentation_Keyed = rp
+	case schema.UnionRepresentation_Envelope:
+		rp := &UnionRepresentation_Envelope{
+			DiscriminantKey:   stg.GetDiscriminantKey(),
+			ContentKey:        stg.GetContentKey(),
+			DiscriminantTable: Map__String__UnionMember{Values: make(map[string]UnionMember)},
+		}
+		for i, member := range t.Members() {
+			key := stg.GetDiscriminant(member)
+			rp.DiscriminantTable.Keys = append(rp.DiscriminantTable.Keys, key)
+			rp.DiscriminantTable.Values[key] = defn.Members[i]
+		}
+		defn.Representation.UnionRepresentation_Envelope = rp
+	case schema.UnionRepresentation_Inline:
+		rp := &UnionRepresentation_Inline{
+			DiscriminantKey:   stg.GetDiscriminantKey(),
+			DiscriminantTable: Map__String__TypeName{Values: make(map[string]string)},
+		}
+		for _, member := range t.Members() {
+			key := stg.GetDiscriminant(member)
+			rp.DiscriminantTable.Keys = append(rp.DiscriminantTable.Keys, key)
+			rp.DiscriminantTable.Values[key] = member.Name()
+		}
+		defn.Representation.UnionRepresentation_Inline = rp
+	case schema.UnionRepresentation_Stringprefix:
+		rp := &UnionRepresentation_StringPrefix{Prefixes: Map__String__TypeName{Values: make(map[string]string)}}
+		for _, member := range t.Members() {
+			// The DMT has no separate delimiter; it's part of each prefix.
+			key := stg.GetDiscriminant(member) + stg.GetDelim()
+			rp.Prefixes.Keys = append(rp.Prefixes.Keys, key)
+			rp.Prefixes.Values[key] = member.Name()
+		}
+		defn.Representation.UnionRepresentation_StringPrefix = rp
+	case schema.UnionRepresentation_BytesPrefix:
+		rp := &UnionRepresentation_BytesPrefix{Prefixes: Map__HexString__TypeName{Values: make(map[string]string)}}
+		for _, member := range t.Members() {
+			key := stg.GetDiscriminant(member)
+			rp.Prefixes.Keys = append(rp.Prefixes.Keys, key)
+			rp.Prefixes.Values[key] = member.Name()
+		}
+		defn.Representation.UnionRepresentation_BytesPrefix = rp
+	default:
+		return TypeDefn{}, fmt.Errorf("unknown union representation %T", stg)
+	}
+	return TypeDefn{TypeDefnUnion: defn}, nil
+}
+
+func (c converter) unionMember(member schema.Type) UnionMember {
+	if link, ok := member.(*schema.TypeLink); ok && c.inline[member.Name()] {
+		return UnionMember{UnionMemberInlineDefn: &UnionMemberInlineDefn{
+			TypeDefnLink: &TypeDefnLink{ExpectedType: strPtr(link.ReferencedType().Name())},
+		}}
+	}
+	return UnionMember{TypeName: strPtr(member.Name())}
+}
+
+func enumDefn(t *schema.TypeEnum) (TypeDefn, error) {
+	defn := &TypeDefnEnum{Members: append(List__EnumMember(nil), t.Members()...)}
+	switch stg := t.RepresentationStrategy().(type) {
+	case schema.EnumRepresentation_String:
+		// Only the members with a serial other than their own name are listed, as in the DSL.
+		rp := &EnumRepresentation_String{}
+		for _, member := range t.Members() {
+			if s, ok := stg[member]; ok {
+				if rp.Values == nil {
+					rp.Values = make(map[string]string)
+				}
+				rp.Keys = append(rp.Keys, member)
+				rp.Values[member] = s
+			}
+		}
+		defn.Representation.EnumRepresentation_String = rp
+	case schema.EnumRepresentation_Int:
+		rp := &EnumRepresentation_Int{Values: make(map[string]int)}
+		for _, member := range t.Members() {
+			rp.Keys = append(rp.Keys, member)
+			rp.Values[member] = stg[member]
+		}
+		defn.Representation.EnumRepresentation_Int = rp
+	default:
+		return TypeDefn{}, fmt.Errorf("unknown enum representation %T", stg)
+	}
+	return TypeDefn{TypeDefnEnum: defn}, nil
+}
@@ -0,0 +1,105 @@
+package schemadmt_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
+)
+
+func TestConvertTypeSystem(t *testing.T) {
+	t.Parallel()
+
+	var ts schema.TypeSystem
+	ts.Init()
+	schema.SpawnDefaultBasicTypes(&ts)
+	ts.Accumulate(schema.SpawnList("List__String", "String", false))
+	ts.Accumulate(schema.SpawnStruct("Person",
+		[]schema.StructField{
+			schema.SpawnStructField("name", "String", false, false),
+			schema.SpawnStructField("tags", "List__String", true, false),
+		},
+		schema.SpawnStructRepresentationMap2(map[string]string{"name": "n"}, nil),
+	))
+	ts.Accumulate(schema.SpawnList("List__Int", "Int", false)) // used as a union member, so can't be inline.
+	ts.Accumulate(schema.SpawnUnion("Thing",
+		[]schema.TypeName{"Person", "List__Int"},
+		schema.SpawnUnionRepresentationKinded(map[datamodel.Kind]schema.TypeName{
+			datamodel.Kind_Map:  "Person",
+			datamodel.Kind_List: "List__Int",
+		}),
+	))
+	ts.Accumulate(schema.SpawnEnum("Color", []string{"Red", "Blue"}, schema.EnumRepresentation_Int{"Red": 1, "Blue": 2}))
+	qt.Assert(t, ts.ValidateGraph(), qt.IsNil)
+
+	sch, err := schemadmt.ConvertTypeSystem(&ts)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, sch.Types.Keys, qt.DeepEquals, []string{"Person", "List__Int", "Thing", "Color"})
+	tags := sch.Types.Values["Person"].TypeDefnStruct.Fields.Values["tags"]
+	qt.Check(t, tags.Type.InlineDefn.TypeDefnList.ValueType.TypeName, qt.DeepEquals, ptr("String"))
+
+	var ts2 schema.TypeSystem
+	ts2.Init()
+	qt.Assert(t, schemadmt.Compile(&ts2, sch), qt.IsNil)
+	for _, name := range ts.Names() {
+		qt.Assert(t, ts2.TypeByName(name), qt.Not(qt.IsNil), qt.Commentf("%s", name))
+		qt.Check(t, ts2.TypeByName(name).TypeKind(), qt.Equals, ts.TypeByName(name).TypeKind())
+	}
+	person := ts2.TypeByName("Person").(*schema.TypeStruct)
+	qt.Check(t, person.RepresentationStrategy().(schema.StructRepresentation_Map).GetFieldKey(*person.Field("name")), qt.Equals, "n")
+	qt.Check(t, ts2.TypeByName("Color").(*schema.TypeEnum).RepresentationStrategy(), qt.DeepEquals,
+		schema.EnumRepresentation_Int{"Red": 1, "Blue": 2})
+
+	// A type with the name of a default type, but a different definition, can't be expressed.
+	var ts3 schema.TypeSystem
+	ts3.Init()
+	ts3.Accumulate(schema.SpawnInt("String"))
+	_, err = schemadmt.ConvertTypeSystem(&ts3)
+	qt.Check(t, err, qt.ErrorMatches, `type "String" differs from .*`)
+}
+
+func TestCompileTupleFieldOrder(t *testing.T) {
+	t.Parallel()
+
+	// The DSL parser checks fieldOrder, but a Schema built in Go might not have been through it.
+	for _, tc := range []struct {
+		order schemadmt.List__FieldName
+		err   string
+	}{
+		{schemadmt.List__FieldName{"y", "x"}, ""},
+		{schemadmt.List__FieldName{"y"}, `.*fieldOrder is missing field "x"`},
+		{schemadmt.List__FieldName{"y", "x", "z"}, `.*fieldOrder lists "z", which is not a field`},
+		{schemadmt.List__FieldName{"y", "y"}, `.*fieldOrder lists "y" more than once`},
+	} {
+		order := tc.order
+		sch := &schemadmt.Schema{Types: schemadmt.Map__TypeName__TypeDefn{
+			Keys: []string{"Point"},
+			Values: map[string]schemadmt.TypeDefn{"Point": {TypeDefnStruct: &schemadmt.TypeDefnStruct{
+				Fields: schemadmt.Map__FieldName__StructField{
+					Keys: []string{"x", "y"},
+					Values: map[string]schemadmt.StructField{
+						"x": {Type: schemadmt.TypeNameOrInlineDefn{TypeName: ptr("Int")}},
+						"y": {Type: schemadmt.TypeNameOrInlineDefn{TypeName: ptr("Int")}},
+					},
+				},
+				Representation: schemadmt.StructRepresentation{
+					StructRepresentation_Tuple: &schemadmt.StructRepresentation_Tuple{FieldOrder: &order},
+				},
+			}}},
+		}}
+		var ts schema.TypeSystem
+		ts.Init()
+		err := schemadmt.Compile(&ts, sch)
+		if tc.err != "" {
+			qt.Check(t, err, qt.ErrorMatches, tc.err)
+			continue
+		}
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, ts.TypeByName("Point").(*schema.TypeStruct).TupleFieldIndexes(), qt.DeepEquals, []int{1, 0})
+	}
+}
+
+func ptr(s string) *string { return &s }
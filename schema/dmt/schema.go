@@ -32,9 +32,6 @@ func init() {
 	var ts schema.TypeSystem
 	ts.Init()
 
-	// I've elided all references to Advancedlayouts stuff for the moment.
-	// (Not because it's particularly hard or problematic; I just want to draw a slightly smaller circle first.)
-
 	// Prelude
 	ts.Accumulate(schema.SpawnString("String"))
 	ts.Accumulate(schema.SpawnBool("Bool"))
@@ -44,12 +41,11 @@ func init() {
 
 	// Schema-schema!
 	// In the same order as the spec's ipldsch file.
-	// Note that ADL stuff is excluded for now, as per above.
 	ts.Accumulate(schema.SpawnString("TypeName"))
 	ts.Accumulate(schema.SpawnStruct("Schema",
 		[]schema.StructField{
 			schema.SpawnStructField("types", "Map__TypeName__TypeDefn", false, false),
-			// also: `advanced AdvancedDataLayoutMap`, but as commented above, we'll pursue this later.
+			schema.SpawnStructField("advanced", "AdvancedDataLayoutMap", true, false), // optional here, so that schemas which declare no ADLs may omit it.
 		},
 		schema.StructRepresentation_Map{},
 	))
@@ -122,8 +118,23 @@ func init() {
 		schema.StructRepresentation_Map{},
 	))
 	ts.Accumulate(schema.SpawnStruct("TypeDefnBytes",
+		[]schema.StructField{
+			schema.SpawnStructField("representation", "BytesRepresentation", true, false),
+		},
+		schema.StructRepresentation_Map{},
+	))
+	ts.Accumulate(schema.SpawnUnion("BytesRepresentation",
+		[]schema.TypeName{
+			"BytesRepresentation_Bytes",
+			"AdvancedDataLayoutName",
+		},
+		schema.SpawnUnionRepresentationKeyed(map[string]schema.TypeName{
+			"bytes":    "BytesRepresentation_Bytes",
+			"advanced": "AdvancedDataLayoutName",
+		}),
+	))
+	ts.Accumulate(schema.SpawnStruct("BytesRepresentation_Bytes",
 		[]schema.StructField{},
-		// No BytesRepresentation, since we omit ADL stuff.
 		schema.StructRepresentation_Map{},
 	))
 	ts.Accumulate(schema.SpawnStruct("TypeDefnInt",
@@ -148,11 +159,13 @@ func init() {
 			"MapRepresentation_Map",
 			"MapRepresentation_Stringpairs",
 			"MapRepresentation_Listpairs",
+			"AdvancedDataLayoutName",
 		},
 		schema.SpawnUnionRepresentationKeyed(map[string]schema.TypeName{
 			"map":         "MapRepresentation_Map",
 			"stringpairs": "MapRepresentation_Stringpairs",
 			"listpairs":   "MapRepresentation_Listpairs",
+			"advanced":    "AdvancedDataLayoutName",
 		}),
 	))
 	ts.Accumulate(schema.SpawnStruct("MapRepresentation_Map",
@@ -181,9 +194,11 @@ func init() {
 	ts.Accumulate(schema.SpawnUnion("ListRepresentation",
 		[]schema.TypeName{
 			"ListRepresentation_List",
+			"AdvancedDataLayoutName",
 		},
 		schema.SpawnUnionRepresentationKeyed(map[string]schema.TypeName{
-			"list": "ListRepresentation_List",
+			"list":     "ListRepresentation_List",
+			"advanced": "AdvancedDataLayoutName",
 		}),
 	))
 	ts.Accumulate(schema.SpawnStruct("ListRepresentation_List",
@@ -243,7 +258,7 @@ func init() {
 			"envelope":     "UnionRepresentation_Envelope",
 			"inline":       "UnionRepresentation_Inline",
 			"stringprefix": "UnionRepresentation_StringPrefix",
-			"byteprefix":   "UnionRepresentation_BytesPrefix",
+			"bytesprefix":  "UnionRepresentation_BytesPrefix",
 		}),
 	))
 	ts.Accumulate(schema.SpawnMap("UnionRepresentation_Kinded",
@@ -253,7 +268,7 @@ func init() {
 		"String", "UnionMember", false,
 	))
 	ts.Accumulate(schema.SpawnMap("Map__String__UnionMember",
-		"TypeName", "TypeDefn", false,
+		"String", "UnionMember", false,
 	))
 	ts.Accumulate(schema.SpawnStruct("UnionRepresentation_Envelope",
 		[]schema.StructField{
@@ -419,6 +434,14 @@ func init() {
 		},
 		schema.StructRepresentation_Map{},
 	))
+	ts.Accumulate(schema.SpawnString("AdvancedDataLayoutName"))
+	ts.Accumulate(schema.SpawnMap("AdvancedDataLayoutMap",
+		"AdvancedDataLayoutName", "AdvancedDataLayout", false,
+	))
+	ts.Accumulate(schema.SpawnStruct("AdvancedDataLayout",
+		[]schema.StructField{},
+		schema.StructRepresentation_Map{},
+	))
 	ts.Accumulate(schema.SpawnUnion("AnyScalar",
 		[]schema.TypeName{
 			"Bool",
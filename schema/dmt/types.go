@@ -1,7 +1,8 @@
 package schemadmt
 
 type Schema struct {
-	Types Map__TypeName__TypeDefn
+	Types    Map__TypeName__TypeDefn
+	Advanced *AdvancedDataLayoutMap
 }
 type Map__TypeName__TypeDefn struct {
 	Keys   []string
@@ -37,6 +38,13 @@ type TypeDefnBool struct {
 type TypeDefnString struct {
 }
 type TypeDefnBytes struct {
+	Representation *BytesRepresentation
+}
+type BytesRepresentation struct {
+	BytesRepresentation_Bytes *BytesRepresentation_Bytes
+	AdvancedDataLayoutName    *string
+}
+type BytesRepresentation_Bytes struct {
 }
 type TypeDefnInt struct {
 }
@@ -52,6 +60,7 @@ type MapRepresentation struct {
 	MapRepresentation_Map         *MapRepresentation_Map
 	MapRepresentation_Stringpairs *MapRepresentation_Stringpairs
 	MapRepresentation_Listpairs   *MapRepresentation_Listpairs
+	AdvancedDataLayoutName        *string
 }
 type MapRepresentation_Map struct {
 }
@@ -68,6 +77,7 @@ type TypeDefnList struct {
 }
 type ListRepresentation struct {
 	ListRepresentation_List *ListRepresentation_List
+	AdvancedDataLayoutName  *string
 }
 type ListRepresentation_List struct {
 }
@@ -105,7 +115,7 @@ type UnionRepresentation_Keyed struct {
 }
 type Map__String__UnionMember struct {
 	Keys   []string
-	Values map[string]TypeDefn
+	Values map[string]UnionMember
 }
 type UnionRepresentation_Envelope struct {
 	DiscriminantKey   string
@@ -206,6 +216,12 @@ type TypeDefnAny struct {
 type TypeDefnCopy struct {
 	FromType string
 }
+type AdvancedDataLayoutMap struct {
+	Keys   []string
+	Values map[string]AdvancedDataLayout
+}
+type AdvancedDataLayout struct {
+}
 type AnyScalar struct {
 	Bool   *bool
 	String *string
@@ -0,0 +1,45 @@
+package schemadsl
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	dmt "github.com/ipld/go-ipld-prime/schema/dmt"
+)
+
+// Compile parses a schema in the DSL, as Parse does,
+// and then compiles it into ts, as schemadmt.CompileAll does.
+//
+// Any errors are returned as an ErrorList, sorted by position.
+// A schema with syntax errors isn't compiled,
+// so the errors are either all syntax errors, or all compile errors.
+// Compile errors about a type are positioned at the name in the type's definition;
+// errors which aren't about any one type, such as duplicate advanced layouts,
+// are positioned at the whole file.
+func Compile(ts *schema.TypeSystem, name string, r io.Reader) error {
+	sch, decl, err := parse(name, r)
+	if err != nil {
+		return err
+	}
+	return compile(ts, sch, decl, name)
+}
+
+// compile compiles sch into ts, positioning any errors using decl.
+// Errors which aren't about any one type are positioned at filename.
+func compile(ts *schema.TypeSystem, sch *dmt.Schema, decl *declared, filename string) error {
+	var errs ErrorList
+	for _, err := range dmt.CompileAll(ts, sch) {
+		e := &Error{Pos: Position{Filename: filename}, Err: err}
+		var cerr dmt.CompileError
+		if errors.As(err, &cerr) {
+			if pos, ok := decl.types[cerr.TypeName]; ok {
+				e.Pos = pos
+				e.Token = cerr.TypeName
+			}
+		}
+		errs = append(errs, e)
+	}
+	errs.Sort()
+	return errs.Err()
+}
@@ -0,0 +1,129 @@
+package schemadsl_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
+	schemadsl "github.com/ipld/go-ipld-prime/schema/dsl"
+)
+
+// errorSummaries returns each error in an ErrorList as "position token: message".
+func errorSummaries(t *testing.T, err error) []string {
+	t.Helper()
+	var list schemadsl.ErrorList
+	qt.Assert(t, errors.As(err, &list), qt.IsTrue, qt.Commentf("%v", err))
+	var summaries []string
+	for _, e := range list {
+		summaries = append(summaries, e.Pos.String()+" "+e.Token+": "+e.Err.Error())
+	}
+	return summaries
+}
+
+func TestParseRecovery(t *testing.T) {
+	t.Parallel()
+
+	_, err := schemadsl.Parse("bad.ipldsch", strings.NewReader(`
+type Good string
+
+type Foo struct {
+	a Int (renamed "x")
+	b String
+}
+
+type Bar strukt
+
+type Baz struct {
+	c $
+}
+
+type Missing struct {
+	d [String
+type Qux union {
+	| Foo "foo"
+	| Bar "foo"
+} representation keyed
+type Last string
+`))
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		`bad.ipldsch:5:9 renamed: unknown field option: "renamed"`,
+		`bad.ipldsch:9:10 strukt: unknown type keyword: "strukt"`,
+		`bad.ipldsch:12:4 $: unexpected character '$'`,
+		`bad.ipldsch:17:1 type: expected "]", got "type"`,
+		`bad.ipldsch:19:8 "foo": duplicate union discriminant: "foo"`,
+	})
+	qt.Check(t, err, qt.ErrorMatches, `bad.ipldsch:5:9: unknown field option: "renamed" \(and 4 more errors\)`)
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	var ts schema.TypeSystem
+	ts.Init()
+	err := schemadsl.Compile(&ts, "ok.ipldsch", strings.NewReader(`
+type Foo struct {
+	bars [Bar]
+}
+type Bar string
+`))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, ts.TypeByName("Foo"), qt.Not(qt.IsNil))
+
+	ts = schema.TypeSystem{}
+	ts.Init()
+	err = schemadsl.Compile(&ts, "bad.ipldsch", strings.NewReader(`advanced ADL
+
+type Foo struct {
+	a Missing
+	b [AlsoMissing]
+	c Unit
+}
+
+type Unit unit representation null
+
+type Baz {String:Int} representation advanced ADL
+`))
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		// Fields referring to missing types, including via an inline type.
+		// The field of type Unit isn't an error, as the problem is with Unit.
+		`bad.ipldsch:3:6 Foo: type List__AlsoMissing refers to missing type AlsoMissing (as value type)`,
+		`bad.ipldsch:3:6 Foo: type Foo refers to missing type Missing (in field "a")`,
+		`bad.ipldsch:9:6 Unit: TODO: support unit types in schema package`,
+	})
+	var cerr schemadmt.CompileError
+	qt.Assert(t, errors.As(err, &cerr), qt.IsTrue)
+	qt.Check(t, cerr.TypeName, qt.Equals, "Foo")
+	var missing schema.ErrMissingType
+	qt.Assert(t, errors.As(err, &missing), qt.IsTrue)
+	qt.Check(t, missing.Missing, qt.Equals, "AlsoMissing")
+
+	// Syntax errors stop compilation.
+	ts = schema.TypeSystem{}
+	ts.Init()
+	err = schemadsl.Compile(&ts, "", strings.NewReader("type Foo Bar\ntype Baz strukt"))
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		`1:10 Bar: unknown type keyword: "Bar"`,
+		`2:10 strukt: unknown type keyword: "strukt"`,
+	})
+
+	// Errors not about any one type are positioned at the file.
+	ts = schema.TypeSystem{}
+	ts.Init()
+	ts.AccumulateAdvancedLayout("ADL")
+	err = schemadsl.Compile(&ts, "adl.ipldsch", strings.NewReader("advanced ADL"))
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		`adl.ipldsch : duplicate advanced layout: "ADL"`,
+	})
+
+	// Types already in the TypeSystem can't be redefined.
+	ts = schema.TypeSystem{}
+	ts.Init()
+	err = schemadsl.Compile(&ts, "", strings.NewReader("type String int"))
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		`1:6 String: type "String" is already defined`,
+	})
+}
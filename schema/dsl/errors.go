@@ -0,0 +1,110 @@
+package schemadsl
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Position is a place in a schema's source, as given to Parse.
+type Position struct {
+	Filename string // the name given to Parse, if any.
+	Line     int    // counting from 1.
+	Column   int    // in bytes, counting from 1.
+}
+
+// IsValid reports whether the position has a line number,
+// which isn't the case for errors about a whole file.
+func (pos Position) IsValid() bool { return pos.Line > 0 }
+
+// String returns the position in one of these forms:
+//
+//	file:line:column
+//	line:column
+//	file
+//	-
+func (pos Position) String() string {
+	s := pos.Filename
+	if pos.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", pos.Line, pos.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// Error is an error found in a schema's source, along with where it was found.
+type Error struct {
+	Pos Position
+
+	// Token is the text of the token the error was found at, if any.
+	// For a compile error about a type, it is the name of the type.
+	Token string
+
+	// Err describes the problem.
+	// For compile errors, it is the error from schemadmt.CompileAll,
+	// so that errors.As can find a schemadmt.CompileError.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Pos.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns Err, so that errors.Is and errors.As can look at it.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList is the type of the errors returned by Parse and Compile,
+// which report every error they can find rather than stopping at the first one.
+// It is never empty when returned as an error.
+type ErrorList []*Error
+
+// Error returns the first error's message, followed by how many more there are.
+func (l ErrorList) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	case 2:
+		return fmt.Sprintf("%s (and 1 more error)", l[0])
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Unwrap returns the errors in the list, so that errors.Is and errors.As can look at all of them.
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// Err returns an error equivalent to the list, or nil if the list is empty.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// Sort sorts the list by position: by file name, then line, then column.
+// Errors found at the same position keep their order.
+func (l ErrorList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		a, b := l[i].Pos, l[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
@@ -0,0 +1,183 @@
+package schemadsl
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	dmt "github.com/ipld/go-ipld-prime/schema/dmt"
+)
+
+// Loaded is a TypeSystem compiled by Load from several files,
+// along with where each of its types came from.
+type Loaded struct {
+	TypeSystem *schema.TypeSystem
+
+	// Files are the names of the files loaded, in the order they were read.
+	Files []string
+
+	positions map[schema.TypeName]Position
+}
+
+// Position returns where the named type was defined,
+// at the type's name in its definition.
+//
+// Anonymous types, such as the List__String for a struct field of type [String],
+// are given the position of the definition they were first used in.
+// Types that weren't defined in any of the files, such as the prelude types like String,
+// have no position, and ok is false.
+func (l *Loaded) Position(name schema.TypeName) (pos Position, ok bool) {
+	pos, ok = l.positions[name]
+	return pos, ok
+}
+
+// Load reads the schema files in fsys whose names match any of the patterns,
+// as fs.Glob matches them, and compiles all of their types together into one TypeSystem.
+//
+// This allows a schema to be split across several files,
+// with the types in each file referring to those defined in any of the others.
+// Defining the same type or advanced layout in more than one file is an error,
+// which says where both definitions are, even if they're the same.
+// A pattern which matches no files is an error too, as it's likely a mistake.
+//
+// As with Compile, errors in the schema are returned as an ErrorList, sorted by position,
+// with file names as they are in fsys.
+// All of the files are parsed, to report all of their syntax errors,
+// but nothing is compiled if there are any.
+func Load(fsys fs.FS, patterns ...string) (*Loaded, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, pattern := range patterns {
+		matches, err := fs.Glob(fsys, pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("schema file pattern %q matches no files", pattern)
+		}
+		for _, name := range matches {
+			if !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+
+	// Parse every file, merging their declarations into one schema.
+	sch := &dmt.Schema{}
+	sch.Types.Values = make(map[string]dmt.TypeDefn)
+	all := &declared{
+		types:    make(map[string]Position),
+		advanced: make(map[string]Position),
+	}
+	var errs ErrorList
+	for _, name := range files {
+		fileSch, decl, err := parseFS(fsys, name)
+		if list, ok := err.(ErrorList); ok {
+			errs = append(errs, list...)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if fileSch.Advanced != nil {
+			if sch.Advanced == nil {
+				sch.Advanced = &dmt.AdvancedDataLayoutMap{}
+			}
+			for _, adl := range fileSch.Advanced.Keys {
+				pos := decl.advanced[adl]
+				if prev, ok := all.advanced[adl]; ok {
+					errs = append(errs, &Error{Pos: pos, Token: adl,
+						Err: fmt.Errorf("advanced layout %q is already declared at %s", adl, prev)})
+					continue
+				}
+				all.advanced[adl] = pos
+				mapAppend(sch.Advanced, adl, fileSch.Advanced.Values[adl])
+			}
+		}
+		for _, typeName := range fileSch.Types.Keys {
+			pos := decl.types[typeName]
+			if prev, ok := all.types[typeName]; ok {
+				errs = append(errs, &Error{Pos: pos, Token: typeName,
+					Err: fmt.Errorf("type %q is already defined at %s", typeName, prev)})
+				continue
+			}
+			all.types[typeName] = pos
+			mapAppend(&sch.Types, typeName, fileSch.Types.Values[typeName])
+		}
+	}
+	if len(errs) > 0 {
+		errs.Sort()
+		return nil, errs
+	}
+
+	ts := new(schema.TypeSystem)
+	ts.Init()
+	if err := compile(ts, sch, all, ""); err != nil {
+		return nil, err
+	}
+	return &Loaded{
+		TypeSystem: ts,
+		Files:      files,
+		positions:  typePositions(ts, sch.Types.Keys, all.types),
+	}, nil
+}
+
+func parseFS(fsys fs.FS, name string) (*dmt.Schema, *declared, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	return parse(name, f)
+}
+
+// typePositions returns the positions of all the types in ts which were defined in the schema,
+// including anonymous ones, which are found by following references from the types named in order.
+func typePositions(ts *schema.TypeSystem, order []string, defined map[string]Position) map[schema.TypeName]Position {
+	positions := make(map[schema.TypeName]Position, len(ts.Names()))
+	for name, pos := range defined {
+		positions[name] = pos
+	}
+	var prelude schema.TypeSystem
+	prelude.Init()
+	schema.SpawnDefaultBasicTypes(&prelude)
+
+	var visit func(typ schema.Type, pos Position)
+	visit = func(typ schema.Type, pos Position) {
+		if typ == nil {
+			return
+		}
+		if _, ok := positions[typ.Name()]; ok || prelude.TypeByName(typ.Name()) != nil {
+			return
+		}
+		positions[typ.Name()] = pos
+		visitReferences(typ, func(ref schema.Type) { visit(ref, pos) })
+	}
+	for _, name := range order {
+		visitReferences(ts.TypeByName(name), func(ref schema.Type) { visit(ref, defined[name]) })
+	}
+	return positions
+}
+
+// visitReferences calls fn with each type that typ refers to.
+func visitReferences(typ schema.Type, fn func(schema.Type)) {
+	switch typ := typ.(type) {
+	case *schema.TypeStruct:
+		for _, field := range typ.Fields() {
+			fn(field.Type())
+		}
+	case *schema.TypeMap:
+		fn(typ.KeyType())
+		fn(typ.ValueType())
+	case *schema.TypeList:
+		fn(typ.ValueType())
+	case *schema.TypeUnion:
+		for _, member := range typ.Members() {
+			fn(member)
+		}
+	case *schema.TypeLink:
+		if typ.HasReferencedType() {
+			fn(typ.ReferencedType())
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package schemadsl_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	schemadsl "github.com/ipld/go-ipld-prime/schema/dsl"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"people.ipldsch": {Data: []byte(`
+type Person struct {
+	name String
+	address Address
+	pets [Pet]
+}
+`)},
+		"places/address.ipldsch": {Data: []byte(`type Address struct {
+	street String
+	owners [&Person]
+}
+`)},
+		"places/pets.ipldsch": {Data: []byte(`advanced Big
+
+type Pet union {
+	| Cat "cat"
+} representation keyed
+
+type Cat struct {}
+`)},
+		"README.md": {Data: []byte(`not a schema`)},
+	}
+	loaded, err := schemadsl.Load(fsys, "*.ipldsch", "places/*.ipldsch", "people.ipldsch")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, loaded.Files, qt.DeepEquals, []string{
+		"people.ipldsch", "places/address.ipldsch", "places/pets.ipldsch",
+	})
+
+	ts := loaded.TypeSystem
+	person := ts.TypeByName("Person").(*schema.TypeStruct)
+	qt.Check(t, person.Field("address").Type().Name(), qt.Equals, "Address")
+	qt.Check(t, person.Field("pets").Type().(*schema.TypeList).ValueType().Name(), qt.Equals, "Pet")
+	qt.Check(t, ts.HasAdvancedLayout("Big"), qt.IsTrue)
+
+	for _, tc := range []struct {
+		name string
+		pos  string
+	}{
+		{"Person", "people.ipldsch:2:6"},
+		{"List__Pet", "people.ipldsch:2:6"},
+		{"Address", "places/address.ipldsch:1:6"},
+		{"List__Link__Person", "places/address.ipldsch:1:6"},
+		{"Link__Person", "places/address.ipldsch:1:6"},
+		{"Pet", "places/pets.ipldsch:3:6"},
+		{"Cat", "places/pets.ipldsch:7:6"},
+	} {
+		pos, ok := loaded.Position(tc.name)
+		qt.Check(t, ok, qt.IsTrue, qt.Commentf("%s", tc.name))
+		qt.Check(t, pos.String(), qt.Equals, tc.pos, qt.Commentf("%s", tc.name))
+	}
+	_, ok := loaded.Position("String")
+	qt.Check(t, ok, qt.IsFalse)
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"a.ipldsch": {Data: []byte("advanced ADL\ntype Foo string\ntype Bar struct { baz Baz }\n")},
+		"b.ipldsch": {Data: []byte("advanced ADL\n\ntype Foo string\n")},
+		"c.ipldsch": {Data: []byte("type Broken strukt\n")},
+	}
+
+	// Conflicting definitions are errors, even if they're the same; so are syntax errors in any file.
+	_, err := schemadsl.Load(fsys, "*.ipldsch")
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		`b.ipldsch:1:10 ADL: advanced layout "ADL" is already declared at a.ipldsch:1:10`,
+		`b.ipldsch:3:6 Foo: type "Foo" is already defined at a.ipldsch:2:6`,
+		`c.ipldsch:1:13 strukt: unknown type keyword: "strukt"`,
+	})
+
+	// Compile errors are positioned in the file the type is defined in.
+	_, err = schemadsl.Load(fsys, "a.ipldsch")
+	qt.Check(t, errorSummaries(t, err), qt.DeepEquals, []string{
+		`a.ipldsch:3:6 Bar: type Bar refers to missing type Baz (in field "baz")`,
+	})
+
+	_, err = schemadsl.Load(fsys, "a.ipldsch", "*.yml")
+	qt.Check(t, err, qt.ErrorMatches, `schema file pattern "\*.yml" matches no files`)
+
+	_, err = schemadsl.Load(fsys, "[")
+	qt.Check(t, err, qt.ErrorMatches, `syntax error in pattern`)
+}
@@ -8,7 +8,6 @@ import (
 	"os"
 	"reflect"
 	"strconv"
-	"strings"
 
 	dmt "github.com/ipld/go-ipld-prime/schema/dmt"
 )
@@ -30,7 +29,26 @@ func ParseFile(path string) (*dmt.Schema, error) {
 	return Parse(path, f)
 }
 
+// Parse reads a schema in the DSL, producing its DMT form.
+//
+// Any errors are returned as an ErrorList, each with the position in the input it was found at.
+// After an error, Parse skips to the next declaration and carries on, to find as many errors as it can.
+// Besides syntax errors, Parse rejects duplicate names: of types, fields, members, and so on.
+// Other checks, such as whether the types referred to exist, are left to schemadmt.Compile;
+// see Compile for a way to get their errors positioned too.
 func Parse(name string, r io.Reader) (*dmt.Schema, error) {
+	sch, _, err := parse(name, r)
+	return sch, err
+}
+
+// declared records where each name in a schema was declared.
+type declared struct {
+	types    map[string]Position
+	advanced map[string]Position
+}
+
+// parse is Parse, also returning where each type and advanced layout was declared.
+func parse(name string, r io.Reader) (*dmt.Schema, *declared, error) {
 	p := &parser{
 		path: name,
 		br:   bufio.NewReader(r),
@@ -40,36 +58,112 @@ func Parse(name string, r io.Reader) (*dmt.Schema, error) {
 
 	sch := &dmt.Schema{}
 	sch.Types.Values = make(map[string]dmt.TypeDefn)
+	decl := &declared{
+		types:    make(map[string]Position),
+		advanced: make(map[string]Position),
+	}
 
+	var errs ErrorList
 	for {
-		tok, err := p.consumeToken()
-		if err == io.EOF {
+		tok, err := p.peekToken()
+		if err == nil && tok == "" {
 			break
 		}
-
-		switch tok {
-		case "type":
-			name, err := p.consumeName()
-			if err != nil {
-				return nil, err
+		if err == nil {
+			p.consumePeeked()
+			err = p.declaration(sch, tok, decl)
+		}
+		if err != nil {
+			perr, ok := err.(*Error)
+			if !ok {
+				return nil, nil, err // not a problem with the input, such as an I/O error
 			}
-			defn, err := p.typeDefn()
-			if err != nil {
-				return nil, err
+			errs = append(errs, perr)
+			if err := p.skipDeclaration(&errs); err != nil {
+				return nil, nil, err
 			}
-			mapAppend(&sch.Types, name, defn)
-		case "advanced":
-			return nil, p.errf("TODO: advanced")
-		default:
-			return nil, p.errf("unexpected token: %q", tok)
 		}
 	}
-	return sch, nil
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return sch, decl, nil
+}
+
+func (p *parser) declaration(sch *dmt.Schema, keyword string, decl *declared) error {
+	switch keyword {
+	case "type":
+		name, err := p.consumeName()
+		if err != nil {
+			return err
+		}
+		if _, ok := sch.Types.Values[name]; ok {
+			return p.errf("duplicate type definition: %q", name)
+		}
+		pos := p.position(p.last)
+		defn, err := p.typeDefn()
+		if err != nil {
+			return err
+		}
+		mapAppend(&sch.Types, name, defn)
+		decl.types[name] = pos
+	case "advanced":
+		name, err := p.consumeName()
+		if err != nil {
+			return err
+		}
+		if sch.Advanced == nil {
+			sch.Advanced = &dmt.AdvancedDataLayoutMap{}
+		}
+		if _, ok := sch.Advanced.Values[name]; ok {
+			return p.errf("duplicate advanced layout declaration: %q", name)
+		}
+		mapAppend(sch.Advanced, name, dmt.AdvancedDataLayout{})
+		decl.advanced[name] = p.position(p.last)
+	default:
+		return p.errf("unexpected token: %q", keyword)
+	}
+	return nil
+}
+
+// skipDeclaration skips the rest of a declaration with an error in it,
+// so that parsing can carry on and find any more errors.
+// It stops at the next "type" or "advanced" keyword which is outside of any brackets,
+// or which is at the start of a line, in case the declaration was missing a closing bracket.
+// Any errors from the lexer along the way are added to errs.
+func (p *parser) skipDeclaration(errs *ErrorList) error {
+	// The token the error was found at may itself start the next declaration,
+	// such as when a declaration is cut short.
+	if t := p.last; (t.text == "type" || t.text == "advanced") && t.pos.col == 1 && p.peeked.text == "" {
+		p.peeked = t
+		p.depth = 0
+		return nil
+	}
+	for {
+		tok, err := p.peekToken()
+		if err != nil {
+			perr, ok := err.(*Error)
+			if !ok {
+				return err
+			}
+			*errs = append(*errs, perr)
+			continue
+		}
+		switch {
+		case tok == "":
+			return nil
+		case (tok == "type" || tok == "advanced") && (p.depth <= 0 || p.peeked.pos.col == 1):
+			p.depth = 0
+			return nil
+		}
+		p.consumePeeked()
+	}
 }
 
+// mapAppend appends an entry to one of the DMT's order-preserving maps.
+// Callers check for duplicate keys first, so they can report them where they were found.
 func mapAppend(mapPtr, k, v interface{}) {
 	// TODO: delete with generics
-	// TODO: error on dupes
 
 	mval := reflect.ValueOf(mapPtr).Elem()
 	kval := reflect.ValueOf(k)
@@ -85,120 +179,212 @@ func mapAppend(mapPtr, k, v interface{}) {
 	values.SetMapIndex(kval, vval)
 }
 
+// position is a place in the input, as a line and a column, both counting from 1.
+type position struct {
+	line, col int
+}
+
+// token is a token, and where it starts in the input.
+type token struct {
+	text string
+	pos  position
+}
+
 type parser struct {
 	path string
 	br   *bufio.Reader
 
-	peekedToken string
+	peeked token // the next token, if peekToken has read it already.
+	last   token // the last token consumed.
 
-	line, col int
+	line, col         int // the position of the next byte to be read.
+	prevLine, prevCol int // the position of the last byte read, so that it can be unread.
+
+	depth int // how many brackets are open, so that skipDeclaration knows when a declaration ends.
+}
+
+func (p *parser) position(t token) Position {
+	return Position{Filename: p.path, Line: t.pos.line, Column: t.pos.col}
+}
+
+func (p *parser) errorAt(t token, err error) error {
+	return &Error{Pos: p.position(t), Token: t.text, Err: err}
 }
 
+// forwardError positions an error at the last token consumed.
 func (p *parser) forwardError(err error) error {
-	var prefix string
-	if p.path != "" {
-		prefix = p.path + ":"
-	}
-	return fmt.Errorf("%s%d:%d: %s", prefix, p.line, p.col, err)
+	return p.errorAt(p.last, err)
 }
 
 func (p *parser) errf(format string, args ...interface{}) error {
 	return p.forwardError(fmt.Errorf(format, args...))
 }
 
-func (p *parser) consumeToken() (string, error) {
-	if tok := p.peekedToken; tok != "" {
-		p.peekedToken = ""
-		return tok, nil
+func (p *parser) errAtf(t token, format string, args ...interface{}) error {
+	return p.errorAt(t, fmt.Errorf(format, args...))
+}
+
+func (p *parser) readByte() (byte, error) {
+	b, err := p.br.ReadByte()
+	if err != nil {
+		return 0, err
 	}
+	p.prevLine, p.prevCol = p.line, p.col
+	if b == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return b, nil
+}
+
+func (p *parser) unreadByte() {
+	if err := p.br.UnreadByte(); err != nil {
+		panic(err) // should never happen
+	}
+	p.line, p.col = p.prevLine, p.prevCol
+}
+
+func isWordByte(b byte) bool {
+	// TODO: should probably allow unicode letters and numbers, like Go?
+	switch {
+	case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z':
+	case b >= '0' && b <= '9':
+	case b == '_':
+	default:
+		return false
+	}
+	return true
+}
+
+// lex reads the next token.
+// At the end of the input, it returns io.EOF.
+// Errors reading the input are returned as they are, rather than as an *Error.
+func (p *parser) lex() (token, error) {
 	for {
-		// TODO: use runes for better unicode support
-		b, err := p.br.ReadByte()
-		if err == io.EOF {
-			return "", err // TODO: ErrUnexpectedEOF?
-		}
+		start := position{p.line, p.col}
+		b, err := p.readByte()
 		if err != nil {
-			return "", p.forwardError(err)
+			return token{pos: start}, err
 		}
-		p.col++
 		switch b {
-		case ' ', '\t', '\r': // skip whitespace
-			continue
-		case '\n': // skip newline
+		case ' ', '\t', '\r', '\n': // skip whitespace
 			// TODO: should we require a newline after each type def, struct field, etc?
-			p.line++
-			p.col = 1
 			continue
-		case '"': // quoted string
-			quoted, err := p.br.ReadString('"')
-			if err != nil {
-				return "", p.forwardError(err)
+		case '#': // comment, until the end of the line
+			for b != '\n' {
+				if b, err = p.readByte(); err == io.EOF {
+					break
+				} else if err != nil {
+					return token{pos: start}, err
+				}
 			}
-			return "\"" + quoted, nil
-		case '{', '}', '[', ']', '(', ')', ':', '&': // simple token
-			return string(b), nil
-		case '#': // comment
-			_, err := p.br.ReadString('\n')
-			if err != nil {
-				return "", p.forwardError(err)
+			continue
+		case '"': // quoted string, with its quotes; escapes are as in Go.
+			tok := []byte{b}
+			for {
+				b, err := p.readByte()
+				if err == io.EOF || b == '\n' {
+					return token{pos: start}, p.errAtf(token{string(tok), start}, "unterminated string")
+				}
+				if err != nil {
+					return token{pos: start}, err
+				}
+				tok = append(tok, b)
+				switch b {
+				case '\\':
+					b, err := p.readByte()
+					if err == io.EOF {
+						return token{pos: start}, p.errAtf(token{string(tok), start}, "unterminated string")
+					}
+					tok = append(tok, b)
+				case '"':
+					return token{string(tok), start}, nil
+				}
 			}
-			// tokenize the newline
-			if err := p.br.UnreadByte(); err != nil {
-				panic(err) // should never happen
+		case '{', '[', '(':
+			p.depth++
+			return token{string(b), start}, nil
+		case '}', ']', ')':
+			p.depth--
+			return token{string(b), start}, nil
+		case ':', '&', '|', '=', ',': // other simple tokens
+			return token{string(b), start}, nil
+		default: // name, keyword, or number
+			if !isWordByte(b) && b != '-' {
+				return token{pos: start}, p.errAtf(token{string(b), start}, "unexpected character %q", b)
 			}
-			continue
-		default: // string token or name
-			var sb strings.Builder
-			sb.WriteByte(b)
+			// Numbers may also have signs, decimal points, and exponents.
+			number := b == '-' || (b >= '0' && b <= '9')
+			tok := []byte{b}
 			for {
-				b, err := p.br.ReadByte()
+				b, err := p.readByte()
 				if err == io.EOF {
 					// Token ends at the end of the whole input.
-					return sb.String(), nil
+					return token{string(tok), start}, nil
 				}
 				if err != nil {
-					return "", p.forwardError(err)
+					return token{pos: start}, err
 				}
-				// TODO: should probably allow unicode letters and numbers, like Go?
-				switch {
-				case b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z':
-				case b >= '0' && b <= '9':
-				case b == '_':
-				default:
-					if err := p.br.UnreadByte(); err != nil {
-						panic(err) // should never happen
-					}
-					return sb.String(), nil
+				if !isWordByte(b) && !(number && (b == '.' || b == '-' || b == '+')) {
+					p.unreadByte()
+					return token{string(tok), start}, nil
 				}
-				sb.WriteByte(b)
+				tok = append(tok, b)
 			}
 		}
 	}
 }
 
+func (p *parser) consumeToken() (string, error) {
+	if p.peeked.text != "" {
+		p.consumePeeked()
+		return p.last.text, nil
+	}
+	tok, err := p.lex()
+	p.last = tok
+	if err == io.EOF {
+		return "", p.errf("unexpected end of input")
+	}
+	return tok.text, err
+}
+
 func (p *parser) consumePeeked() {
-	if p.peekedToken == "" {
+	if p.peeked.text == "" {
 		panic("consumePeeked requires a peeked token to be present")
 	}
-	p.peekedToken = ""
+	p.last = p.peeked
+	p.peeked = token{}
 }
 
+// peekToken returns the next token without consuming it.
+// At the end of the input, it returns the empty string, since peekToken is often used when a token is optional.
 func (p *parser) peekToken() (string, error) {
-	if tok := p.peekedToken; tok != "" {
-		return tok, nil
+	if p.peeked.text != "" {
+		return p.peeked.text, nil
+	}
+	tok, err := p.lex()
+	if err == io.EOF {
+		return "", nil
 	}
-	tok, err := p.consumeToken()
 	if err != nil {
-		if err == io.EOF {
-			// peekToken is often used when a token is optional.
-			// If we hit io.EOF, that's not an error.
-			// TODO: consider making peekToken just not return an error?
-			return "", nil
-		}
 		return "", err
 	}
-	p.peekedToken = tok
-	return tok, nil
+	p.peeked = tok
+	return tok.text, nil
+}
+
+func isName(tok string) bool {
+	if tok == "" || (tok[0] >= '0' && tok[0] <= '9') || tok[0] == '-' {
+		return false
+	}
+	for i := 0; i < len(tok); i++ {
+		if !isWordByte(tok[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func (p *parser) consumeName() (string, error) {
@@ -206,66 +392,148 @@ func (p *parser) consumeName() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	switch tok {
-	case "\"", "{", "}", "[", "]", "(", ")", ":":
-		return "", p.errf("expected a name, got %q", tok)
-	}
 	if tok[0] == '"' {
 		return "", p.errf("expected a name, got string %s", tok)
 	}
+	if !isName(tok) {
+		return "", p.errf("expected a name, got %q", tok)
+	}
 	return tok, nil
 }
 
-func (p *parser) consumeString() (string, error) {
-	tok, err := p.consumeToken()
+// unquote returns the value of a quoted string token.
+func (p *parser) unquote(t token) (string, error) {
+	if t.text[0] != '"' {
+		return "", p.errAtf(t, "expected a string, got %q", t.text)
+	}
+	s, err := strconv.Unquote(t.text)
 	if err != nil {
+		return "", p.errAtf(t, "invalid string %s: %v", t.text, err)
+	}
+	return s, nil
+}
+
+func (p *parser) consumeString() (string, error) {
+	if _, err := p.consumeToken(); err != nil {
 		return "", err
 	}
-	if tok[0] != '"' {
-		return "", p.errf("expected a string, got %q", tok)
+	return p.unquote(p.last)
+}
+
+func (p *parser) consumeRequired(tok string) error {
+	got, err := p.consumeToken()
+	if err != nil {
+		return err
+	}
+	if got != tok {
+		return p.errf("expected %q, got %q", tok, got)
 	}
-	// Unquote, too.
-	return tok[1 : len(tok)-1], nil
+	return nil
 }
 
-func (p *parser) consumeStringMap() (map[string]string, error) {
-	result := map[string]string{}
-loop:
+// consumeOptions consumes the optional block of options some representations have,
+// such as `{ join ":" }` or `{ fieldOrder ["b", "a"] }`.
+// Options are strings, except those listed by name in lists, which are lists of strings.
+// Any option not named in either is an error.
+func (p *parser) consumeOptions(strs []string, lists []string) (map[string]string, map[string][]string, error) {
+	strValues := map[string]string{}
+	listValues := map[string][]string{}
+	if tok, err := p.peekToken(); err != nil || tok != "{" {
+		return strValues, listValues, err
+	}
+	p.consumePeeked()
+	contains := func(names []string, name string) bool {
+		for _, n := range names {
+			if n == name {
+				return true
+			}
+		}
+		return false
+	}
 	for {
-		tok, err := p.peekToken()
+		tok, err := p.consumeToken()
 		if err != nil {
-			return result, err
+			return nil, nil, err
 		}
-		switch tok {
-		case "{":
-			p.consumePeeked()
-		case "}":
-			p.consumePeeked()
-			break loop
-		default:
-			key, err := p.consumeName()
-			if err != nil {
-				return result, err
+		if tok == "}" {
+			break
+		}
+		name := tok
+		_, seenStr := strValues[name]
+		_, seenList := listValues[name]
+		switch {
+		case seenStr || seenList:
+			return nil, nil, p.errf("duplicate option: %q", name)
+		case contains(strs, name):
+			if strValues[name], err = p.consumeString(); err != nil {
+				return nil, nil, err
 			}
-			value, err := p.consumeString()
-			if err != nil {
-				return result, err
+		case contains(lists, name):
+			if listValues[name], err = p.consumeStringList(); err != nil {
+				return nil, nil, err
 			}
-			result[key] = value
+		default:
+			return nil, nil, p.errf("unknown option: %q", name)
 		}
 	}
-	return result, nil
+	return strValues, listValues, nil
 }
 
-func (p *parser) consumeRequired(tok string) error {
-	got, err := p.consumeToken()
+// consumeStringList consumes a list of strings like `["a", "b"]`; the commas are optional.
+func (p *parser) consumeStringList() ([]string, error) {
+	if err := p.consumeRequired("["); err != nil {
+		return nil, err
+	}
+	list := []string{}
+	for {
+		tok, err := p.consumeToken()
+		if err != nil {
+			return nil, err
+		}
+		switch tok {
+		case "]":
+			return list, nil
+		case ",":
+			continue
+		}
+		s, err := p.unquote(p.last)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+}
+
+// consumeScalar consumes a scalar value, as used for implicit values:
+// a string, an integer, a float, or a boolean.
+func (p *parser) consumeScalar() (dmt.AnyScalar, error) {
+	var scalar dmt.AnyScalar
+	tok, err := p.consumeToken()
 	if err != nil {
-		return err
+		return scalar, err
 	}
-	if got != tok {
-		return p.errf("expected %q, got %q", tok, got)
+	switch {
+	case tok[0] == '"':
+		s, err := p.unquote(p.last)
+		if err != nil {
+			return scalar, err
+		}
+		scalar.String = &s
+	case tok == "true", tok == "false":
+		b := tok == "true"
+		scalar.Bool = &b
+	case tok[0] == '-', tok[0] >= '0' && tok[0] <= '9':
+		if n, err := strconv.Atoi(tok); err == nil {
+			scalar.Int = &n
+		} else if f, err := strconv.ParseFloat(tok, 64); err == nil {
+			scalar.Float = &f
+		} else {
+			return scalar, p.errf("invalid number: %s", tok)
+		}
+	default:
+		return scalar, p.errf("unsupported implicit scalar: %s", tok)
 	}
-	return nil
+	return scalar, nil
 }
 
 func (p *parser) typeDefn() (dmt.TypeDefn, error) {
@@ -291,10 +559,13 @@ func (p *parser) typeDefn() (dmt.TypeDefn, error) {
 			return defn, err
 		}
 		defn.TypeDefnEnum, err = p.typeEnum()
+	case "unit":
+		defn.TypeDefnUnit, err = p.typeUnit()
 	case "bool":
 		defn.TypeDefnBool = &dmt.TypeDefnBool{}
 	case "bytes":
 		defn.TypeDefnBytes = &dmt.TypeDefnBytes{}
+		defn.TypeDefnBytes.Representation, err = p.bytesRepr()
 	case "float":
 		defn.TypeDefnFloat = &dmt.TypeDefnFloat{}
 	case "int":
@@ -313,8 +584,14 @@ func (p *parser) typeDefn() (dmt.TypeDefn, error) {
 		defn.TypeDefnString = &dmt.TypeDefnString{}
 	case "{":
 		defn.TypeDefnMap, err = p.typeMap()
+		if err == nil {
+			defn.TypeDefnMap.Representation, err = p.mapRepr()
+		}
 	case "[":
 		defn.TypeDefnList, err = p.typeList()
+		if err == nil {
+			defn.TypeDefnList.Representation, err = p.listRepr()
+		}
 	case "=":
 		from, err := p.consumeName()
 		if err != nil {
@@ -331,6 +608,7 @@ func (p *parser) typeDefn() (dmt.TypeDefn, error) {
 func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 	repr := &dmt.StructRepresentation_Map{}
 	repr.Fields = &dmt.Map__FieldName__StructRepresentation_Map_FieldDetails{}
+	var detailsTok token // where the first field details were, if any.
 
 	defn := &dmt.TypeDefnStruct{}
 	for {
@@ -342,7 +620,13 @@ func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 		if tok == "}" {
 			break
 		}
+		if !isName(tok) {
+			return nil, p.errf("expected a field name or %q, got %q", "}", tok)
+		}
 		name := tok
+		if _, ok := defn.Fields.Values[name]; ok {
+			return nil, p.errf("duplicate field: %q", name)
+		}
 
 		var field dmt.StructField
 	loop:
@@ -354,13 +638,13 @@ func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 			switch tok {
 			case "optional":
 				if field.Optional != nil {
-					return nil, p.errf("multiple optional keywords")
+					return nil, p.errAtf(p.peeked, "multiple optional keywords")
 				}
 				field.Optional = &globalTrue
 				p.consumePeeked()
 			case "nullable":
 				if field.Nullable != nil {
-					return nil, p.errf("multiple nullable keywords")
+					return nil, p.errAtf(p.peeked, "multiple nullable keywords")
 				}
 				field.Nullable = &globalTrue
 				p.consumePeeked()
@@ -380,6 +664,9 @@ func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 		if tok == "(" {
 			details := dmt.StructRepresentation_Map_FieldDetails{}
 			p.consumePeeked()
+			if len(repr.Fields.Keys) == 0 {
+				detailsTok = p.last
+			}
 		parenLoop:
 			for {
 				tok, err = p.consumeToken()
@@ -390,38 +677,25 @@ func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 				case ")":
 					break parenLoop
 				case "rename":
+					if details.Rename != nil {
+						return nil, p.errf("multiple rename options")
+					}
 					str, err := p.consumeString()
 					if err != nil {
 						return nil, err
 					}
 					details.Rename = &str
 				case "implicit":
-					scalar, err := p.consumeToken()
+					if details.Implicit != nil {
+						return nil, p.errf("multiple implicit options")
+					}
+					scalar, err := p.consumeScalar()
 					if err != nil {
 						return nil, err
 					}
-					var anyScalar dmt.AnyScalar
-					switch {
-					case scalar[0] == '"': // string
-						s, err := strconv.Unquote(scalar)
-						if err != nil {
-							return nil, p.forwardError(err)
-						}
-						anyScalar.String = &s
-					case scalar == "true", scalar == "false": // bool
-						t := scalar == "true"
-						anyScalar.Bool = &t
-					case scalar[0] >= '0' && scalar[0] <= '0':
-						n, err := strconv.Atoi(scalar)
-						if err != nil {
-							return nil, p.forwardError(err)
-						}
-						anyScalar.Int = &n
-					default:
-						return nil, p.errf("unsupported implicit scalar: %s", scalar)
-					}
-
-					details.Implicit = &anyScalar
+					details.Implicit = &scalar
+				default:
+					return nil, p.errf("unknown field option: %q", tok)
 				}
 			}
 			mapAppend(repr.Fields, name, details)
@@ -430,17 +704,15 @@ func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 		mapAppend(&defn.Fields, name, field)
 	}
 
-	reprName := "map" // default repr
-	if tok, err := p.peekToken(); err == nil && tok == "representation" {
-		p.consumePeeked()
-		name, err := p.consumeName()
-		if err != nil {
-			return nil, err
-		}
-		reprName = name
+	reprName, err := p.consumeRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	if reprName == "" {
+		reprName = "map" // default repr
 	}
 	if reprName != "map" && len(repr.Fields.Keys) > 0 {
-		return nil, p.errf("rename and implicit are only supported for struct map representations")
+		return nil, p.errAtf(detailsTok, "rename and implicit are only supported for struct map representations")
 	}
 	switch reprName {
 	case "map":
@@ -449,30 +721,73 @@ func (p *parser) typeStruct() (*dmt.TypeDefnStruct, error) {
 			repr.Fields = nil
 		}
 		defn.Representation.StructRepresentation_Map = repr
-		return defn, nil
 	case "tuple":
-		defn.Representation.StructRepresentation_Tuple = &dmt.StructRepresentation_Tuple{}
-		return defn, nil
-		// TODO: support custom fieldorder
+		_, lists, err := p.consumeOptions(nil, []string{"fieldOrder"})
+		if err != nil {
+			return nil, err
+		}
+		rp := &dmt.StructRepresentation_Tuple{}
+		if order, ok := lists["fieldOrder"]; ok {
+			if rp.FieldOrder, err = p.fieldOrder(defn, order); err != nil {
+				return nil, err
+			}
+		}
+		defn.Representation.StructRepresentation_Tuple = rp
 	case "stringjoin":
-		optMap, err := p.consumeStringMap()
+		strs, lists, err := p.consumeOptions([]string{"join"}, []string{"fieldOrder"})
 		if err != nil {
 			return nil, err
 		}
-		join, hasJoin := optMap["join"]
+		join, hasJoin := strs["join"]
 		if !hasJoin {
 			return nil, p.errf("no join value provided for stringjoin repr")
 		}
-		defn.Representation.StructRepresentation_Stringjoin = &dmt.StructRepresentation_Stringjoin{
-			Join: join,
+		rp := &dmt.StructRepresentation_Stringjoin{Join: join}
+		if order, ok := lists["fieldOrder"]; ok {
+			if rp.FieldOrder, err = p.fieldOrder(defn, order); err != nil {
+				return nil, err
+			}
+		}
+		defn.Representation.StructRepresentation_Stringjoin = rp
+	case "stringpairs":
+		strs, _, err := p.consumeOptions([]string{"innerDelim", "entryDelim"}, nil)
+		if err != nil {
+			return nil, err
+		}
+		innerDelim, hasInner := strs["innerDelim"]
+		entryDelim, hasEntry := strs["entryDelim"]
+		if !hasInner || !hasEntry {
+			return nil, p.errf("stringpairs repr requires both innerDelim and entryDelim")
+		}
+		defn.Representation.StructRepresentation_Stringpairs = &dmt.StructRepresentation_Stringpairs{
+			InnerDelim: innerDelim,
+			EntryDelim: entryDelim,
 		}
-		return defn, nil
 	case "listpairs":
 		defn.Representation.StructRepresentation_Listpairs = &dmt.StructRepresentation_Listpairs{}
-		return defn, nil
 	default:
 		return nil, p.errf("unknown struct repr: %q", reprName)
 	}
+	return defn, nil
+}
+
+// fieldOrder checks that a fieldOrder option lists each of a struct's fields exactly once.
+func (p *parser) fieldOrder(defn *dmt.TypeDefnStruct, order []string) (*dmt.List__FieldName, error) {
+	seen := make(map[string]bool, len(order))
+	for _, name := range order {
+		if _, ok := defn.Fields.Values[name]; !ok {
+			return nil, p.errf("fieldOrder lists %q, which is not a field", name)
+		}
+		if seen[name] {
+			return nil, p.errf("fieldOrder lists %q more than once", name)
+		}
+		seen[name] = true
+	}
+	if len(order) != len(defn.Fields.Keys) {
+		return nil, p.errf("fieldOrder must list all %d fields, but lists %d", len(defn.Fields.Keys), len(order))
+	}
+	list := dmt.List__FieldName(order)
+	return &list, nil
 }
 
 func (p *parser) typeNameOrInlineDefn() (dmt.TypeNameOrInlineDefn, error) {
@@ -502,6 +817,9 @@ func (p *parser) typeNameOrInlineDefn() (dmt.TypeNameOrInlineDefn, error) {
 		}
 		typ.InlineDefn = &dmt.InlineDefn{TypeDefnMap: tmap}
 	default:
+		if !isName(tok) {
+			return typ, p.errf("expected a type, got %q", tok)
+		}
 		typ.TypeName = &tok
 	}
 	return typ, nil
@@ -527,7 +845,6 @@ func (p *parser) typeList() (*dmt.TypeDefnList, error) {
 		return defn, err
 	}
 
-	// TODO: repr
 	return defn, nil
 }
 
@@ -564,9 +881,120 @@ func (p *parser) typeMap() (*dmt.TypeDefnMap, error) {
 	return defn, nil
 }
 
+// consumeRepresentation consumes an optional representation clause,
+// returning the name of the representation strategy, or an empty string if there was no such clause.
+func (p *parser) consumeRepresentation() (string, error) {
+	if tok, err := p.peekToken(); err != nil || tok != "representation" {
+		return "", err
+	}
+	p.consumePeeked()
+	return p.consumeName()
+}
+
+func (p *parser) mapRepr() (*dmt.MapRepresentation, error) {
+	reprName, err := p.consumeRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	switch reprName {
+	case "": // default repr
+		return nil, nil
+	case "map":
+		return &dmt.MapRepresentation{MapRepresentation_Map: &dmt.MapRepresentation_Map{}}, nil
+	case "stringpairs":
+		strs, _, err := p.consumeOptions([]string{"innerDelim", "entryDelim"}, nil)
+		if err != nil {
+			return nil, err
+		}
+		innerDelim, hasInner := strs["innerDelim"]
+		entryDelim, hasEntry := strs["entryDelim"]
+		if !hasInner || !hasEntry {
+			return nil, p.errf("stringpairs repr requires both innerDelim and entryDelim")
+		}
+		return &dmt.MapRepresentation{MapRepresentation_Stringpairs: &dmt.MapRepresentation_Stringpairs{
+			InnerDelim: innerDelim,
+			EntryDelim: entryDelim,
+		}}, nil
+	case "listpairs":
+		return &dmt.MapRepresentation{MapRepresentation_Listpairs: &dmt.MapRepresentation_Listpairs{}}, nil
+	case "advanced":
+		adl, err := p.consumeName()
+		if err != nil {
+			return nil, err
+		}
+		return &dmt.MapRepresentation{AdvancedDataLayoutName: &adl}, nil
+	default:
+		return nil, p.errf("unknown map repr: %q", reprName)
+	}
+}
+
+func (p *parser) listRepr() (*dmt.ListRepresentation, error) {
+	reprName, err := p.consumeRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	switch reprName {
+	case "": // default repr
+		return nil, nil
+	case "list":
+		return &dmt.ListRepresentation{ListRepresentation_List: &dmt.ListRepresentation_List{}}, nil
+	case "advanced":
+		adl, err := p.consumeName()
+		if err != nil {
+			return nil, err
+		}
+		return &dmt.ListRepresentation{AdvancedDataLayoutName: &adl}, nil
+	default:
+		return nil, p.errf("unknown list repr: %q", reprName)
+	}
+}
+
+func (p *parser) bytesRepr() (*dmt.BytesRepresentation, error) {
+	reprName, err := p.consumeRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	switch reprName {
+	case "": // default repr
+		return nil, nil
+	case "bytes":
+		return &dmt.BytesRepresentation{BytesRepresentation_Bytes: &dmt.BytesRepresentation_Bytes{}}, nil
+	case "advanced":
+		adl, err := p.consumeName()
+		if err != nil {
+			return nil, err
+		}
+		return &dmt.BytesRepresentation{AdvancedDataLayoutName: &adl}, nil
+	default:
+		return nil, p.errf("unknown bytes repr: %q", reprName)
+	}
+}
+
+func (p *parser) typeUnit() (*dmt.TypeDefnUnit, error) {
+	reprName, err := p.consumeRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	switch reprName {
+	case "":
+		return nil, p.errf("unit types require a representation: null, true, false, or emptymap")
+	case "null", "true", "false", "emptymap":
+		return &dmt.TypeDefnUnit{Representation: reprName}, nil
+	default:
+		return nil, p.errf("unknown unit repr: %q", reprName)
+	}
+}
+
+// kindNames are the names of the Data Model kinds, as used by kinded unions.
+var kindNames = map[string]bool{
+	"map": true, "list": true, "null": true, "bool": true, "int": true,
+	"float": true, "string": true, "bytes": true, "link": true,
+}
+
 func (p *parser) typeUnion() (*dmt.TypeDefnUnion, error) {
 	defn := &dmt.TypeDefnUnion{}
-	var reprKeys []string
+	var reprKeys []token
+	memberNames := make(map[string]bool)
 
 	for {
 		tok, err := p.consumeToken()
@@ -585,22 +1013,28 @@ func (p *parser) typeUnion() (*dmt.TypeDefnUnion, error) {
 			return nil, err
 		}
 
+		var memberName string
 		if nameOrInline.TypeName != nil {
 			member.TypeName = nameOrInline.TypeName
+			memberName = *member.TypeName
 		} else {
 			if nameOrInline.InlineDefn.TypeDefnLink != nil {
 				member.UnionMemberInlineDefn = &dmt.UnionMemberInlineDefn{TypeDefnLink: nameOrInline.InlineDefn.TypeDefnLink}
+				memberName = "&" + *nameOrInline.InlineDefn.TypeDefnLink.ExpectedType
 			} else {
 				return nil, p.errf("expected a name or inline link, got neither")
 			}
 		}
+		if memberNames[memberName] {
+			return nil, p.errf("duplicate union member: %q", memberName)
+		}
+		memberNames[memberName] = true
 		defn.Members = append(defn.Members, member)
 
-		key, err := p.consumeToken()
-		if err != nil {
+		if _, err := p.consumeToken(); err != nil {
 			return nil, err
 		}
-		reprKeys = append(reprKeys, key)
+		reprKeys = append(reprKeys, p.last)
 	}
 	if err := p.consumeRequired("representation"); err != nil {
 		return nil, err
@@ -609,48 +1043,73 @@ func (p *parser) typeUnion() (*dmt.TypeDefnUnion, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// Discriminants are quoted strings, except for kinded unions, where they're kinds.
+	// Either way, they must be unique.
+	discriminants := make([]string, len(reprKeys))
+	seen := make(map[string]bool, len(reprKeys))
+	for i, key := range reprKeys {
+		if reprName == "kinded" {
+			if !kindNames[key.text] {
+				return nil, p.errAtf(key, "expected a kind, got %q", key.text)
+			}
+			discriminants[i] = key.text
+		} else {
+			if discriminants[i], err = p.unquote(key); err != nil {
+				return nil, err
+			}
+		}
+		if seen[discriminants[i]] {
+			return nil, p.errAtf(key, "duplicate union discriminant: %s", key.text)
+		}
+		seen[discriminants[i]] = true
+	}
+	// Some representations only have room for the names of their members.
+	namedMembers := func() error {
+		for i, member := range defn.Members {
+			if member.TypeName == nil {
+				return p.errAtf(reprKeys[i], "%s union members must be named types", reprName)
+			}
+		}
+		return nil
+	}
+
 	switch reprName {
 	case "keyed":
 		repr := &dmt.UnionRepresentation_Keyed{}
-		for i, keyStr := range reprKeys {
-			key, err := strconv.Unquote(keyStr)
-			if err != nil {
-				return nil, p.forwardError(err)
-			}
+		for i, key := range discriminants {
 			mapAppend(repr, key, defn.Members[i])
 		}
 		defn.Representation.UnionRepresentation_Keyed = repr
 	case "kinded":
 		repr := &dmt.UnionRepresentation_Kinded{}
-		// TODO: verify keys are valid kinds? enum should do it for us?
-		for i, key := range reprKeys {
+		for i, key := range discriminants {
 			mapAppend(repr, key, defn.Members[i])
 		}
 		defn.Representation.UnionRepresentation_Kinded = repr
 	case "stringprefix":
+		if err := namedMembers(); err != nil {
+			return nil, err
+		}
 		repr := &dmt.UnionRepresentation_StringPrefix{
 			Prefixes: dmt.Map__String__TypeName{
 				Values: map[string]string{},
 			},
 		}
-		for i, key := range reprKeys {
-			// unquote prefix string
-			if len(key) < 2 || key[0] != '"' || key[len(key)-1] != '"' {
-				return nil, p.errf("invalid stringprefix %q", key)
-			}
-			key = key[1 : len(key)-1]
-
-			// add prefix to prefixes map
+		for i, key := range discriminants {
 			repr.Prefixes.Keys = append(repr.Prefixes.Keys, key)
 			repr.Prefixes.Values[key] = *defn.Members[i].TypeName
 		}
 		defn.Representation.UnionRepresentation_StringPrefix = repr
 	case "inline":
-		optMap, err := p.consumeStringMap()
+		if err := namedMembers(); err != nil {
+			return nil, err
+		}
+		strs, _, err := p.consumeOptions([]string{"discriminantKey"}, nil)
 		if err != nil {
 			return nil, err
 		}
-		discriminantKey, hasDiscriminantKey := optMap["discriminantKey"]
+		discriminantKey, hasDiscriminantKey := strs["discriminantKey"]
 		if !hasDiscriminantKey {
 			return nil, p.errf("no discriminantKey value provided for inline repr")
 		}
@@ -661,24 +1120,59 @@ func (p *parser) typeUnion() (*dmt.TypeDefnUnion, error) {
 			},
 		}
 		// TODO: verify member types all have map representation
-		for i, qkey := range reprKeys {
-			key, err := strconv.Unquote(qkey)
-			if err != nil {
-				return nil, fmt.Errorf("invalid discriminant key %q: %w", key, err)
-			}
+		for i, key := range discriminants {
 			repr.DiscriminantTable.Keys = append(repr.DiscriminantTable.Keys, key)
 			repr.DiscriminantTable.Values[key] = *defn.Members[i].TypeName
 		}
 		defn.Representation.UnionRepresentation_Inline = repr
+	case "envelope":
+		strs, _, err := p.consumeOptions([]string{"discriminantKey", "contentKey"}, nil)
+		if err != nil {
+			return nil, err
+		}
+		discriminantKey, hasDiscriminantKey := strs["discriminantKey"]
+		if !hasDiscriminantKey {
+			return nil, p.errf("no discriminantKey value provided for envelope repr")
+		}
+		contentKey, hasContentKey := strs["contentKey"]
+		if !hasContentKey {
+			return nil, p.errf("no contentKey value provided for envelope repr")
+		}
+		repr := &dmt.UnionRepresentation_Envelope{
+			DiscriminantKey: discriminantKey,
+			ContentKey:      contentKey,
+		}
+		for i, key := range discriminants {
+			mapAppend(&repr.DiscriminantTable, key, defn.Members[i])
+		}
+		defn.Representation.UnionRepresentation_Envelope = repr
+	case "bytesprefix":
+		if err := namedMembers(); err != nil {
+			return nil, err
+		}
+		repr := &dmt.UnionRepresentation_BytesPrefix{
+			Prefixes: dmt.Map__HexString__TypeName{
+				Values: map[string]string{},
+			},
+		}
+		for i, key := range discriminants {
+			repr.Prefixes.Keys = append(repr.Prefixes.Keys, key)
+			repr.Prefixes.Values[key] = *defn.Members[i].TypeName
+		}
+		defn.Representation.UnionRepresentation_BytesPrefix = repr
 	default:
-		return nil, p.errf("TODO: union repr %q", reprName)
+		return nil, p.errf("unknown union repr: %q", reprName)
 	}
 	return defn, nil
 }
 
 func (p *parser) typeEnum() (*dmt.TypeDefnEnum, error) {
 	defn := &dmt.TypeDefnEnum{}
-	var reprKeys []string
+	// reprKeys holds each member's representation, as given in parentheses,
+	// or the member's name token if it had none, in which case explicit is false.
+	var reprKeys []token
+	var explicit []bool
+	members := make(map[string]bool)
 
 	for {
 		tok, err := p.consumeToken()
@@ -691,67 +1185,85 @@ func (p *parser) typeEnum() (*dmt.TypeDefnEnum, error) {
 		if tok != "|" {
 			return nil, p.errf("expected %q or %q, got %q", "}", "|", tok)
 		}
-		name, err := p.consumeToken()
+		name, err := p.consumeName()
 		if err != nil {
 			return nil, err
 		}
+		if members[name] {
+			return nil, p.errf("duplicate enum member: %q", name)
+		}
+		members[name] = true
 		defn.Members = append(defn.Members, name)
+		nameTok := p.last
 
-		if tok, err := p.peekToken(); err == nil && tok == "(" {
+		if tok, err := p.peekToken(); err != nil {
+			return nil, err
+		} else if tok == "(" {
 			p.consumePeeked()
-			key, err := p.consumeToken()
-			if err != nil {
+			if _, err := p.consumeToken(); err != nil {
 				return nil, err
 			}
-			reprKeys = append(reprKeys, key)
+			reprKeys = append(reprKeys, p.last)
+			explicit = append(explicit, true)
 			if err := p.consumeRequired(")"); err != nil {
 				return defn, err
 			}
 		} else {
-			reprKeys = append(reprKeys, "")
+			reprKeys = append(reprKeys, nameTok)
+			explicit = append(explicit, false)
 		}
 	}
 
-	reprName := "string" // default repr
-	if tok, err := p.peekToken(); err == nil && tok == "representation" {
-		p.consumePeeked()
-		name, err := p.consumeName()
-		if err != nil {
-			return nil, err
-		}
-		reprName = name
+	reprName, err := p.consumeRepresentation()
+	if err != nil {
+		return nil, err
+	}
+	if reprName == "" {
+		reprName = "string" // default repr
 	}
 	switch reprName {
 	case "string":
 		repr := &dmt.EnumRepresentation_String{}
+		seen := make(map[string]bool)
 		for i, key := range reprKeys {
-			if key == "" {
-				continue // no key; defaults to the name
-			}
-			if key[0] != '"' {
-				return nil, p.errf("enum string representation used with non-string key: %s", key)
+			serial := defn.Members[i] // no key; defaults to the name
+			if explicit[i] {
+				if key.text[0] != '"' {
+					return nil, p.errAtf(key, "enum string representation used with non-string key: %s", key.text)
+				}
+				if serial, err = p.unquote(key); err != nil {
+					return nil, err
+				}
+				mapAppend(repr, defn.Members[i], serial)
 			}
-			unquoted, err := strconv.Unquote(key)
-			if err != nil {
-				return nil, p.forwardError(err)
+			if seen[serial] {
+				return nil, p.errAtf(key, "duplicate enum representation: %q", serial)
 			}
-			mapAppend(repr, defn.Members[i], unquoted)
+			seen[serial] = true
 		}
 		defn.Representation.EnumRepresentation_String = repr
 	case "int":
 		repr := &dmt.EnumRepresentation_Int{}
+		seen := make(map[int]bool)
 		for i, key := range reprKeys {
-			if key[0] != '"' {
-				return nil, p.errf("enum int representation used with non-string key: %s", key)
+			if !explicit[i] {
+				return nil, p.errAtf(key, "enum int representation requires a value for member %q", defn.Members[i])
+			}
+			if key.text[0] != '"' {
+				return nil, p.errAtf(key, "enum int representation used with non-string key: %s", key.text)
 			}
-			unquoted, err := strconv.Unquote(key)
+			unquoted, err := p.unquote(key)
 			if err != nil {
-				return nil, p.forwardError(err)
+				return nil, err
 			}
 			parsed, err := strconv.Atoi(unquoted)
 			if err != nil {
-				return nil, p.forwardError(err)
+				return nil, p.errorAt(key, err)
+			}
+			if seen[parsed] {
+				return nil, p.errAtf(key, "duplicate enum representation: %d", parsed)
 			}
+			seen[parsed] = true
 			mapAppend(repr, defn.Members[i], parsed)
 		}
 		defn.Representation.EnumRepresentation_Int = repr
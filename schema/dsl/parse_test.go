@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	ipldjson "github.com/ipld/go-ipld-prime/codec/json"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
 	"github.com/ipld/go-ipld-prime/node/bindnode"
 	"github.com/ipld/go-ipld-prime/schema"
 	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
@@ -113,6 +114,16 @@ func testParse(t *testing.T, inSchema, inJSON string, updateFn func(string)) {
 		qt.Assert(t, ts.Names(), qt.Not(qt.HasLen), 0)
 	}
 
+	// Ensure that printing the schema and parsing it again gives the same result.
+	{
+		var buf bytes.Buffer
+		err := schemadsl.Print(&buf, sch)
+		qt.Assert(t, err, qt.IsNil)
+		reparsed, err := schemadsl.ParseBytes(buf.Bytes())
+		qt.Assert(t, err, qt.IsNil)
+		qt.Assert(t, reparsed, qt.DeepEquals, sch)
+	}
+
 	// Ensure we can encode the schema as the json codec,
 	// and that it results in the same bytes as the ipldsch.json file.
 	{
@@ -136,3 +147,478 @@ func testParse(t *testing.T, inSchema, inJSON string, updateFn func(string)) {
 	// TODO: ensure that doing a json codec decode results in the same Schema Go
 	// value that we got by parsing the DSL.
 }
+
+func TestParseAdvanced(t *testing.T) {
+	t.Parallel()
+
+	testParse(t, `
+advanced HAMT
+advanced Rope
+
+type Big {String:Int} representation advanced HAMT
+type Text [String] representation advanced Rope
+type Blob bytes representation advanced Rope
+type Plain bytes
+`, `{
+	"types": {
+		"Big": {
+			"map": {
+				"keyType": "String",
+				"valueType": "Int",
+				"representation": {
+					"advanced": "HAMT"
+				}
+			}
+		},
+		"Text": {
+			"list": {
+				"valueType": "String",
+				"representation": {
+					"advanced": "Rope"
+				}
+			}
+		},
+		"Blob": {
+			"bytes": {
+				"representation": {
+					"advanced": "Rope"
+				}
+			}
+		},
+		"Plain": {
+			"bytes": {}
+		}
+	},
+	"advanced": {
+		"HAMT": {},
+		"Rope": {}
+	}
+}
+`, func(string) {})
+
+	// Referring to an advanced layout which isn't declared is an error.
+	sch, err := schemadsl.ParseBytes([]byte(`type Big {String:Int} representation advanced HAMT`))
+	qt.Assert(t, err, qt.IsNil)
+	var ts schema.TypeSystem
+	ts.Init()
+	err = schemadmt.Compile(&ts, sch)
+	qt.Assert(t, err, qt.ErrorMatches, `type "Big" refers to undeclared advanced layout "HAMT"`)
+}
+
+func TestParseUnionEnvelopeAndBytesPrefix(t *testing.T) {
+	t.Parallel()
+
+	testParse(t, `
+type Message union {
+	| Ping "ping"
+	| Pong "pong"
+} representation envelope {
+	discriminantKey "tag"
+	contentKey "msg"
+}
+
+type Ping struct {}
+type Pong struct {}
+
+type Key union {
+	| Ed25519 "ed"
+	| Secp256k1 "e7"
+} representation bytesprefix
+
+type Ed25519 bytes
+type Secp256k1 bytes
+`, `{
+	"types": {
+		"Message": {
+			"union": {
+				"members": [
+					"Ping",
+					"Pong"
+				],
+				"representation": {
+					"envelope": {
+						"discriminantKey": "tag",
+						"contentKey": "msg",
+						"discriminantTable": {
+							"ping": "Ping",
+							"pong": "Pong"
+						}
+					}
+				}
+			}
+		},
+		"Ping": {
+			"struct": {
+				"fields": {},
+				"representation": {
+					"map": {}
+				}
+			}
+		},
+		"Pong": {
+			"struct": {
+				"fields": {},
+				"representation": {
+					"map": {}
+				}
+			}
+		},
+		"Key": {
+			"union": {
+				"members": [
+					"Ed25519",
+					"Secp256k1"
+				],
+				"representation": {
+					"bytesprefix": {
+						"prefixes": {
+							"ed": "Ed25519",
+							"e7": "Secp256k1"
+						}
+					}
+				}
+			}
+		},
+		"Ed25519": {
+			"bytes": {}
+		},
+		"Secp256k1": {
+			"bytes": {}
+		}
+	}
+}
+`, func(string) {})
+
+	// Prefixes may overlap; the longest one which matches wins.
+	sch, err := schemadsl.ParseBytes([]byte(`type Key union { | A "00" | B "0001" } representation bytesprefix
+		type A bytes
+		type B bytes`))
+	qt.Assert(t, err, qt.IsNil)
+	var ts schema.TypeSystem
+	ts.Init()
+	qt.Assert(t, schemadmt.Compile(&ts, sch), qt.IsNil)
+	qt.Check(t, schema.Validate(&ts, ts.TypeByName("Key"), basicnode.NewBytes([]byte{0x00, 0x01, 0x02})), qt.HasLen, 0)
+
+	for _, tc := range []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{
+			"InvalidHex",
+			`type Key union { | Ed25519 "zz" } representation bytesprefix
+			type Ed25519 bytes`,
+			`bytesprefix union "Key" has an invalid hex prefix "zz"`,
+		},
+		{
+			"SamePrefixes",
+			`type Key union { | A "ab" | B "AB" } representation bytesprefix
+			type A bytes
+			type B bytes`,
+			`bytesprefix union "Key" has the same prefix twice: "ab" and "AB"`,
+		},
+		{
+			"SameKeys",
+			`type Message union { | A "a" } representation envelope { discriminantKey "k" contentKey "k" }
+			type A string`,
+			`envelope union has the same discriminantKey and contentKey: "k"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sch, err := schemadsl.ParseBytes([]byte(tc.schema))
+			qt.Assert(t, err, qt.IsNil)
+			var ts schema.TypeSystem
+			ts.Init()
+			err = schemadmt.Compile(&ts, sch)
+			qt.Assert(t, err, qt.ErrorMatches, tc.err)
+		})
+	}
+}
+
+func TestParseRepresentations(t *testing.T) {
+	t.Parallel()
+
+	testParse(t, `
+type Point struct {
+	x Int
+	y Int
+} representation tuple {
+	fieldOrder ["x", "y"]
+}
+
+type Pair struct {
+	left String
+	right String
+} representation stringjoin {
+	join ":"
+	fieldOrder ["left", "right"]
+}
+
+type Params struct {
+	a String
+	b String
+} representation stringpairs {
+	innerDelim "="
+	entryDelim "&"
+}
+
+type Query {String:String} representation stringpairs {
+	innerDelim "="
+	entryDelim "&"
+}
+
+type Entries {String:Int} representation listpairs
+
+type Defaults struct {
+	s String (implicit "a \"quoted\" value")
+	i Int (implicit -3)
+	b Bool (implicit false)
+}
+
+type Location = Point # trailing comment without a newline`, `{
+	"types": {
+		"Point": {
+			"struct": {
+				"fields": {
+					"x": {
+						"type": "Int"
+					},
+					"y": {
+						"type": "Int"
+					}
+				},
+				"representation": {
+					"tuple": {
+						"fieldOrder": [
+							"x",
+							"y"
+						]
+					}
+				}
+			}
+		},
+		"Pair": {
+			"struct": {
+				"fields": {
+					"left": {
+						"type": "String"
+					},
+					"right": {
+						"type": "String"
+					}
+				},
+				"representation": {
+					"stringjoin": {
+						"join": ":",
+						"fieldOrder": [
+							"left",
+							"right"
+						]
+					}
+				}
+			}
+		},
+		"Params": {
+			"struct": {
+				"fields": {
+					"a": {
+						"type": "String"
+					},
+					"b": {
+						"type": "String"
+					}
+				},
+				"representation": {
+					"stringpairs": {
+						"innerDelim": "=",
+						"entryDelim": "&"
+					}
+				}
+			}
+		},
+		"Query": {
+			"map": {
+				"keyType": "String",
+				"valueType": "String",
+				"representation": {
+					"stringpairs": {
+						"innerDelim": "=",
+						"entryDelim": "&"
+					}
+				}
+			}
+		},
+		"Entries": {
+			"map": {
+				"keyType": "String",
+				"valueType": "Int",
+				"representation": {
+					"listpairs": {}
+				}
+			}
+		},
+		"Defaults": {
+			"struct": {
+				"fields": {
+					"s": {
+						"type": "String"
+					},
+					"i": {
+						"type": "Int"
+					},
+					"b": {
+						"type": "Bool"
+					}
+				},
+				"representation": {
+					"map": {
+						"fields": {
+							"s": {
+								"implicit": "a \"quoted\" value"
+							},
+							"i": {
+								"implicit": -3
+							},
+							"b": {
+								"implicit": false
+							}
+						}
+					}
+				}
+			}
+		},
+		"Location": {
+			"copy": {
+				"fromType": "Point"
+			}
+		}
+	}
+}
+`, func(string) {})
+
+	// Copies are compiled as the type they copy.
+	sch, err := schemadsl.ParseBytes([]byte(`type A = B
+type B = C
+type C struct { x Int }`))
+	qt.Assert(t, err, qt.IsNil)
+	var ts schema.TypeSystem
+	ts.Init()
+	qt.Assert(t, schemadmt.Compile(&ts, sch), qt.IsNil)
+	qt.Check(t, ts.TypeByName("A").TypeKind(), qt.Equals, schema.TypeKind_Struct)
+	qt.Check(t, ts.TypeByName("A").(*schema.TypeStruct).Field("x"), qt.Not(qt.IsNil))
+
+	// A tuple's fieldOrder and float implicits survive being compiled and converted back.
+	for _, tc := range []struct {
+		name   string
+		schema string
+	}{
+		{"FieldOrder", `type P struct { x Int y Int } representation tuple { fieldOrder ["y", "x"] }`},
+		{"FloatImplicit", `type P struct { x Float (implicit 1.5) }`},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sch, err := schemadsl.ParseBytes([]byte(tc.schema))
+			qt.Assert(t, err, qt.IsNil)
+			var ts schema.TypeSystem
+			ts.Init()
+			qt.Assert(t, schemadmt.Compile(&ts, sch), qt.IsNil)
+			converted, err := schemadmt.ConvertTypeSystem(&ts)
+			qt.Assert(t, err, qt.IsNil)
+			qt.Check(t, converted.Types.Values["P"], qt.DeepEquals, sch.Types.Values["P"])
+		})
+	}
+	sch, err = schemadsl.ParseBytes([]byte(`type P struct { x Int y Int z Int } representation tuple { fieldOrder ["z", "x", "y"] }`))
+	qt.Assert(t, err, qt.IsNil)
+	ts = schema.TypeSystem{}
+	ts.Init()
+	qt.Assert(t, schemadmt.Compile(&ts, sch), qt.IsNil)
+	var order []string
+	for _, f := range ts.TypeByName("P").(*schema.TypeStruct).TupleFields() {
+		order = append(order, f.Name())
+	}
+	qt.Check(t, order, qt.DeepEquals, []string{"z", "x", "y"})
+
+	// Some of what can be parsed can't be compiled yet.
+	for _, tc := range []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{
+			"Unit",
+			`type U unit representation null`,
+			`TODO: support unit types .*`,
+		},
+		{
+			"CopyCycle",
+			`type A = B
+			type B = A`,
+			`type "A" is a copy of itself`,
+		},
+		{
+			"CopyUndefined",
+			`type A = B`,
+			`type "A" copies undefined type "B"`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sch, err := schemadsl.ParseBytes([]byte(tc.schema))
+			qt.Assert(t, err, qt.IsNil)
+			var ts schema.TypeSystem
+			ts.Init()
+			err = schemadmt.Compile(&ts, sch)
+			qt.Assert(t, err, qt.ErrorMatches, tc.err)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name   string
+		schema string
+		err    string
+	}{
+		{"UnexpectedEOF", "type Foo", `1:9: unexpected end of input`},
+		{"UnknownKeyword", "\n\ntype Foo strukt", `3:10: unknown type keyword: "strukt"`},
+		{"UnexpectedCharacter", "type Foo $", `1:10: unexpected character '\$'`},
+		{"UnterminatedString", "type Foo struct {\n\ta String (rename \"a\n}", `2:19: unterminated string`},
+		{"InvalidName", `type "Foo" string`, `1:6: expected a name, got string "Foo"`},
+		{"DuplicateType", "type Foo string\ntype Foo int", `2:6: duplicate type definition: "Foo"`},
+		{"DuplicateAdvanced", "advanced A\nadvanced A", `2:10: duplicate advanced layout declaration: "A"`},
+		{"DuplicateField", "type Foo struct {\n\ta Int\n\ta String\n}", `3:2: duplicate field: "a"`},
+		{"RepeatedFieldOption", `type Foo struct { a Int (rename "x" rename "y") }`, `1:37: multiple rename options`},
+		{"UnknownFieldOption", `type Foo struct { a Int (renamed "x") }`, `1:26: unknown field option: "renamed"`},
+		{"RenameNotMap", `type Foo struct { a Int (rename "x") } representation tuple`, `1:25: rename and implicit are only supported for struct map representations`},
+		{"UnsupportedImplicit", `type Foo struct { a Int (implicit Bar) }`, `1:35: unsupported implicit scalar: Bar`},
+		{"FieldOrderMissing", `type Foo struct { a Int b Int } representation tuple { fieldOrder ["a"] }`, `1:73: fieldOrder must list all 2 fields, but lists 1`},
+		{"FieldOrderUnknown", `type Foo struct { a Int } representation tuple { fieldOrder ["b"] }`, `1:67: fieldOrder lists "b", which is not a field`},
+		{"FieldOrderRepeated", `type Foo struct { a Int } representation tuple { fieldOrder ["a", "a"] }`, `1:72: fieldOrder lists "a" more than once`},
+		{"UnknownOption", `type Foo struct { a Int } representation stringjoin { joiner ":" }`, `1:55: unknown option: "joiner"`},
+		{"DuplicateOption", `type Foo struct { a Int } representation stringjoin { join ":" join "-" }`, `1:64: duplicate option: "join"`},
+		{"StringpairsDelims", `type Foo {String:String} representation stringpairs { innerDelim "=" }`, `1:70: stringpairs repr requires both innerDelim and entryDelim`},
+		{"DuplicateMember", "type Foo union {\n\t| A \"a\"\n\t| A \"b\"\n} representation keyed", `3:4: duplicate union member: "A"`},
+		{"DuplicateDiscriminant", "type Foo union {\n\t| A \"a\"\n\t| B \"a\"\n} representation keyed", `3:6: duplicate union discriminant: "a"`},
+		{"UnquotedDiscriminant", "type Foo union {\n\t| A a\n} representation keyed", `2:6: expected a string, got "a"`},
+		{"InvalidKind", "type Foo union {\n\t| A \"map\"\n} representation kinded", `2:6: expected a kind, got "\\"map\\""`},
+		{"InlineLinkMember", "type Foo union {\n\t| &A \"a\"\n} representation inline { discriminantKey \"k\" }", `2:7: inline union members must be named types`},
+		{"UnknownUnionRepr", `type Foo union { | A "a" } representation keyd`, `1:43: unknown union repr: "keyd"`},
+		{"DuplicateEnumMember", "type Foo enum {\n\t| A\n\t| A\n}", `3:4: duplicate enum member: "A"`},
+		{"DuplicateEnumValue", "type Foo enum {\n\t| A (\"x\")\n\t| x\n}", `3:4: duplicate enum representation: "x"`},
+		{"EnumIntMissingValue", "type Foo enum {\n\t| A (\"1\")\n\t| B\n} representation int", `3:4: enum int representation requires a value for member "B"`},
+		{"UnitWithoutRepr", `type Foo unit`, `1:10: unit types require a representation: null, true, false, or emptymap`},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := schemadsl.ParseBytes([]byte(tc.schema))
+			qt.Assert(t, err, qt.ErrorMatches, tc.err)
+		})
+	}
+
+	// The name given to Parse is included in errors.
+	_, err := schemadsl.Parse("foo.ipldsch", strings.NewReader("type Foo"))
+	qt.Assert(t, err, qt.ErrorMatches, `foo.ipldsch:1:9: unexpected end of input`)
+}
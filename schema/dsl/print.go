@@ -0,0 +1,372 @@
+package schemadsl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	dmt "github.com/ipld/go-ipld-prime/schema/dmt"
+)
+
+// Print writes a schema in the DSL, in canonical form:
+// declarations appear in the same order as in the schema (advanced data layouts first),
+// separated by blank lines and indented with tabs,
+// and representation details which are already the default are left out.
+//
+// Parsing the output gives back the same schema.
+// Comments aren't part of a schema's DMT form, so they're lost.
+//
+// To print a compiled schema.TypeSystem, use schemadmt.ConvertTypeSystem first.
+func Print(w io.Writer, sch *dmt.Schema) error {
+	p := &printer{}
+	if sch.Advanced != nil {
+		for _, name := range sch.Advanced.Keys {
+			p.printf("advanced %s\n\n", name)
+		}
+	}
+	for _, name := range sch.Types.Keys {
+		p.printf("type %s ", name)
+		p.typeDefn(sch.Types.Values[name])
+		p.printf("\n\n")
+		if p.err != nil {
+			return fmt.Errorf("type %s: %w", name, p.err)
+		}
+	}
+	out := bytes.TrimRight(p.buf.Bytes(), "\n")
+	if len(out) > 0 {
+		out = append(out, '\n')
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+type printer struct {
+	buf bytes.Buffer
+	err error // the first thing which couldn't be printed.
+}
+
+func (p *printer) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&p.buf, format, args...)
+}
+
+func (p *printer) fail(format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+}
+
+// options prints the block of options some representations have, such as `{ join ":" }`.
+// The options are given as name and already-formatted value pairs.
+func (p *printer) options(nameValues ...string) {
+	p.printf(" {\n")
+	for i := 0; i < len(nameValues); i += 2 {
+		p.printf("\t%s %s\n", nameValues[i], nameValues[i+1])
+	}
+	p.printf("}")
+}
+
+func quoteList(ss []string) string {
+	quoted := make([]string, len(ss))
+	for i, s := range ss {
+		quoted[i] = strconv.Quote(s)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+func (p *printer) typeDefn(defn dmt.TypeDefn) {
+	switch {
+	case defn.TypeDefnBool != nil:
+		p.printf("bool")
+	case defn.TypeDefnString != nil:
+		p.printf("string")
+	case defn.TypeDefnInt != nil:
+		p.printf("int")
+	case defn.TypeDefnFloat != nil:
+		p.printf("float")
+	case defn.TypeDefnAny != nil:
+		p.printf("any")
+	case defn.TypeDefnBytes != nil:
+		p.printf("bytes")
+		if repr := defn.TypeDefnBytes.Representation; repr != nil && repr.AdvancedDataLayoutName != nil {
+			p.printf(" representation advanced %s", *repr.AdvancedDataLayoutName)
+		}
+	case defn.TypeDefnLink != nil:
+		p.link(defn.TypeDefnLink)
+	case defn.TypeDefnList != nil:
+		p.list(defn.TypeDefnList)
+		if repr := defn.TypeDefnList.Representation; repr != nil && repr.AdvancedDataLayoutName != nil {
+			p.printf(" representation advanced %s", *repr.AdvancedDataLayoutName)
+		}
+	case defn.TypeDefnMap != nil:
+		p.mapDefn(defn.TypeDefnMap)
+		p.mapRepr(defn.TypeDefnMap.Representation)
+	case defn.TypeDefnStruct != nil:
+		p.structDefn(defn.TypeDefnStruct)
+	case defn.TypeDefnUnion != nil:
+		p.unionDefn(defn.TypeDefnUnion)
+	case defn.TypeDefnEnum != nil:
+		p.enumDefn(defn.TypeDefnEnum)
+	case defn.TypeDefnUnit != nil:
+		p.printf("unit representation %s", defn.TypeDefnUnit.Representation)
+	case defn.TypeDefnCopy != nil:
+		p.printf("= %s", defn.TypeDefnCopy.FromType)
+	default:
+		p.fail("empty type definition")
+	}
+}
+
+func (p *printer) typeNameOrInlineDefn(t dmt.TypeNameOrInlineDefn) {
+	switch {
+	case t.TypeName != nil:
+		p.printf("%s", *t.TypeName)
+	case t.InlineDefn != nil && t.InlineDefn.TypeDefnList != nil:
+		p.list(t.InlineDefn.TypeDefnList)
+	case t.InlineDefn != nil && t.InlineDefn.TypeDefnMap != nil:
+		p.mapDefn(t.InlineDefn.TypeDefnMap)
+	case t.InlineDefn != nil && t.InlineDefn.TypeDefnLink != nil:
+		p.link(t.InlineDefn.TypeDefnLink)
+	default:
+		p.fail("empty type reference")
+	}
+}
+
+func (p *printer) link(defn *dmt.TypeDefnLink) {
+	if defn.ExpectedType == nil {
+		p.printf("link")
+		return
+	}
+	p.printf("&%s", *defn.ExpectedType)
+}
+
+func nullable(b *bool) string {
+	if b != nil && *b {
+		return "nullable "
+	}
+	return ""
+}
+
+func (p *printer) list(defn *dmt.TypeDefnList) {
+	p.printf("[%s", nullable(defn.ValueNullable))
+	p.typeNameOrInlineDefn(defn.ValueType)
+	p.printf("]")
+}
+
+func (p *printer) mapDefn(defn *dmt.TypeDefnMap) {
+	p.printf("{%s:%s", defn.KeyType, nullable(defn.ValueNullable))
+	p.typeNameOrInlineDefn(defn.ValueType)
+	p.printf("}")
+}
+
+func (p *printer) mapRepr(repr *dmt.MapRepresentation) {
+	switch {
+	case repr == nil, repr.MapRepresentation_Map != nil:
+	case repr.MapRepresentation_Stringpairs != nil:
+		p.printf(" representation stringpairs")
+		p.options(
+			"innerDelim", strconv.Quote(repr.MapRepresentation_Stringpairs.InnerDelim),
+			"entryDelim", strconv.Quote(repr.MapRepresentation_Stringpairs.EntryDelim),
+		)
+	case repr.MapRepresentation_Listpairs != nil:
+		p.printf(" representation listpairs")
+	case repr.AdvancedDataLayoutName != nil:
+		p.printf(" representation advanced %s", *repr.AdvancedDataLayoutName)
+	}
+}
+
+func (p *printer) structDefn(defn *dmt.TypeDefnStruct) {
+	var details *dmt.Map__FieldName__StructRepresentation_Map_FieldDetails
+	if defn.Representation.StructRepresentation_Map != nil {
+		details = defn.Representation.StructRepresentation_Map.Fields
+	}
+	p.printf("struct {")
+	if len(defn.Fields.Keys) > 0 {
+		p.printf("\n")
+	}
+	for _, name := range defn.Fields.Keys {
+		field := defn.Fields.Values[name]
+		p.printf("\t%s ", name)
+		if field.Optional != nil && *field.Optional {
+			p.printf("optional ")
+		}
+		p.printf("%s", nullable(field.Nullable))
+		p.typeNameOrInlineDefn(field.Type)
+		if details != nil {
+			if d, ok := details.Values[name]; ok {
+				p.fieldDetails(d)
+			}
+		}
+		p.printf("\n")
+	}
+	p.printf("}")
+
+	repr := defn.Representation
+	switch {
+	case repr.StructRepresentation_Map != nil:
+	case repr.StructRepresentation_Tuple != nil:
+		p.printf(" representation tuple")
+		if order := repr.StructRepresentation_Tuple.FieldOrder; order != nil {
+			p.options("fieldOrder", quoteList(*order))
+		}
+	case repr.StructRepresentation_Stringjoin != nil:
+		p.printf(" representation stringjoin")
+		opts := []string{"join", strconv.Quote(repr.StructRepresentation_Stringjoin.Join)}
+		if order := repr.StructRepresentation_Stringjoin.FieldOrder; order != nil {
+			opts = append(opts, "fieldOrder", quoteList(*order))
+		}
+		p.options(opts...)
+	case repr.StructRepresentation_Stringpairs != nil:
+		p.printf(" representation stringpairs")
+		p.options(
+			"innerDelim", strconv.Quote(repr.StructRepresentation_Stringpairs.InnerDelim),
+			"entryDelim", strconv.Quote(repr.StructRepresentation_Stringpairs.EntryDelim),
+		)
+	case repr.StructRepresentation_Listpairs != nil:
+		p.printf(" representation listpairs")
+	default:
+		p.fail("struct has no representation")
+	}
+}
+
+func (p *printer) fieldDetails(d dmt.StructRepresentation_Map_FieldDetails) {
+	if d.Rename == nil && d.Implicit == nil {
+		return
+	}
+	var parts []string
+	if d.Rename != nil {
+		parts = append(parts, "rename "+strconv.Quote(*d.Rename))
+	}
+	if imp := d.Implicit; imp != nil {
+		switch {
+		case imp.String != nil:
+			parts = append(parts, "implicit "+strconv.Quote(*imp.String))
+		case imp.Int != nil:
+			parts = append(parts, "implicit "+strconv.Itoa(*imp.Int))
+		case imp.Bool != nil:
+			parts = append(parts, "implicit "+strconv.FormatBool(*imp.Bool))
+		case imp.Float != nil:
+			if math.IsInf(*imp.Float, 0) || math.IsNaN(*imp.Float) {
+				p.fail("implicit value %v can't be written in the DSL", *imp.Float)
+				break
+			}
+			f := strconv.FormatFloat(*imp.Float, 'g', -1, 64)
+			if !strings.ContainsAny(f, ".e") {
+				f += ".0" // so that it's not parsed back as an int
+			}
+			parts = append(parts, "implicit "+f)
+		default:
+			p.fail("implicit values other than strings, ints, floats and bools can't be written in the DSL")
+		}
+	}
+	p.printf(" (%s)", strings.Join(parts, " "))
+}
+
+// memberName returns the name a union member is known by in the union's representation.
+func memberName(member dmt.UnionMember) string {
+	switch {
+	case member.TypeName != nil:
+		return *member.TypeName
+	case member.UnionMemberInlineDefn != nil && member.UnionMemberInlineDefn.TypeDefnLink != nil:
+		if t := member.UnionMemberInlineDefn.TypeDefnLink.ExpectedType; t != nil {
+			return "Link__" + *t
+		}
+		return "Link__Link"
+	}
+	return ""
+}
+
+func (p *printer) unionDefn(defn *dmt.TypeDefnUnion) {
+	// Each representation has a table with the members' discriminants as keys.
+	// Index them by member, so they can be printed next to the member.
+	discriminants := make(map[string]string)
+	quote := true
+	var reprName string
+	var opts []string
+	addMembers := func(keys []string, values map[string]dmt.UnionMember) {
+		for _, k := range keys {
+			discriminants[memberName(values[k])] = k
+		}
+	}
+	addNames := func(keys []string, values map[string]string) {
+		for _, k := range keys {
+			discriminants[values[k]] = k
+		}
+	}
+	switch repr := defn.Representation; {
+	case repr.UnionRepresentation_Kinded != nil:
+		reprName, quote = "kinded", false
+		addMembers(repr.UnionRepresentation_Kinded.Keys, repr.UnionRepresentation_Kinded.Values)
+	case repr.UnionRepresentation_Keyed != nil:
+		reprName = "keyed"
+		addMembers(repr.UnionRepresentation_Keyed.Keys, repr.UnionRepresentation_Keyed.Values)
+	case repr.UnionRepresentation_Envelope != nil:
+		rp := repr.UnionRepresentation_Envelope
+		reprName = "envelope"
+		opts = []string{"discriminantKey", strconv.Quote(rp.DiscriminantKey), "contentKey", strconv.Quote(rp.ContentKey)}
+		addMembers(rp.DiscriminantTable.Keys, rp.DiscriminantTable.Values)
+	case repr.UnionRepresentation_Inline != nil:
+		rp := repr.UnionRepresentation_Inline
+		reprName = "inline"
+		opts = []string{"discriminantKey", strconv.Quote(rp.DiscriminantKey)}
+		addNames(rp.DiscriminantTable.Keys, rp.DiscriminantTable.Values)
+	case repr.UnionRepresentation_StringPrefix != nil:
+		reprName = "stringprefix"
+		addNames(repr.UnionRepresentation_StringPrefix.Prefixes.Keys, repr.UnionRepresentation_StringPrefix.Prefixes.Values)
+	case repr.UnionRepresentation_BytesPrefix != nil:
+		reprName = "bytesprefix"
+		addNames(repr.UnionRepresentation_BytesPrefix.Prefixes.Keys, repr.UnionRepresentation_BytesPrefix.Prefixes.Values)
+	default:
+		p.fail("union has no representation")
+		return
+	}
+
+	p.printf("union {\n")
+	for _, member := range defn.Members {
+		p.printf("\t| ")
+		switch {
+		case member.TypeName != nil:
+			p.printf("%s", *member.TypeName)
+		case member.UnionMemberInlineDefn != nil && member.UnionMemberInlineDefn.TypeDefnLink != nil:
+			p.link(member.UnionMemberInlineDefn.TypeDefnLink)
+		default:
+			p.fail("empty union member")
+		}
+		d, ok := discriminants[memberName(member)]
+		if !ok {
+			p.fail("union member %s is missing from the representation", memberName(member))
+		}
+		if quote {
+			d = strconv.Quote(d)
+		}
+		p.printf(" %s\n", d)
+	}
+	p.printf("} representation %s", reprName)
+	if opts != nil {
+		p.options(opts...)
+	}
+}
+
+func (p *printer) enumDefn(defn *dmt.TypeDefnEnum) {
+	repr := defn.Representation
+	p.printf("enum {\n")
+	for _, member := range defn.Members {
+		p.printf("\t| %s", member)
+		switch {
+		case repr.EnumRepresentation_String != nil:
+			if s, ok := repr.EnumRepresentation_String.Values[member]; ok {
+				p.printf(" (%s)", strconv.Quote(s))
+			}
+		case repr.EnumRepresentation_Int != nil:
+			if i, ok := repr.EnumRepresentation_Int.Values[member]; ok {
+				p.printf(" (%s)", strconv.Quote(strconv.Itoa(i)))
+			}
+		}
+		p.printf("\n")
+	}
+	p.printf("}")
+	if repr.EnumRepresentation_Int != nil {
+		p.printf(" representation int")
+	}
+}
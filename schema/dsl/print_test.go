@@ -0,0 +1,180 @@
+package schemadsl_test
+
+import (
+	"bytes"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
+	schemadsl "github.com/ipld/go-ipld-prime/schema/dsl"
+)
+
+func printSchema(t *testing.T, sch *schemadmt.Schema) string {
+	t.Helper()
+	var buf bytes.Buffer
+	qt.Assert(t, schemadsl.Print(&buf, sch), qt.IsNil)
+	return buf.String()
+}
+
+func TestPrint(t *testing.T) {
+	t.Parallel()
+
+	// Already canonical, so printing what's parsed should give back the same text.
+	src := `advanced Rot13
+
+type Name string
+
+type Flag bool
+
+type Data bytes
+
+type Secret bytes representation advanced Rot13
+
+type Anything any
+
+type Ref &Person
+
+type AnyLink link
+
+type Matrix [[nullable Float]]
+
+type Index {String:[&Person]}
+
+type Person struct {
+	name Name
+	age optional Int (rename "a")
+	nick optional nullable String
+	kind String (implicit "human")
+	friends [Ref]
+}
+
+type Point struct {
+	x Int
+	y Int
+} representation tuple
+
+type Pair struct {
+	left String
+	right String
+} representation stringjoin {
+	join ":"
+}
+
+type Entries struct {
+	one Int
+	two Int
+} representation listpairs
+
+type Pet union {
+	| Cat "cat"
+	| Dog "dog"
+} representation keyed
+
+type Scalar union {
+	| Name string
+	| Int int
+	| &Person link
+} representation kinded
+
+type Shape union {
+	| Cat "cat"
+	| Dog "dog"
+} representation inline {
+	discriminantKey "kind"
+}
+
+type Envelope union {
+	| Cat "cat"
+	| Dog "dog"
+} representation envelope {
+	discriminantKey "tag"
+	contentKey "content"
+}
+
+type Prefixed union {
+	| Cat "c:"
+	| Dog "d:"
+} representation stringprefix
+
+type Color enum {
+	| Red ("r")
+	| Green
+}
+
+type Level enum {
+	| Low ("1")
+	| High ("2")
+} representation int
+
+type Cat struct {
+	lives Int
+}
+
+type Dog struct {}
+`
+	sch, err := schemadsl.ParseBytes([]byte(src))
+	qt.Assert(t, err, qt.IsNil)
+	printed := printSchema(t, sch)
+	qt.Check(t, printed, qt.Equals, src)
+
+	reparsed, err := schemadsl.ParseBytes([]byte(printed))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, reparsed, qt.DeepEquals, sch)
+}
+
+func TestPrintFormats(t *testing.T) {
+	t.Parallel()
+
+	// Comments and layout aren't kept; declaration order is.
+	sch, err := schemadsl.ParseBytes([]byte(`
+		# Things.
+		type Zed struct { b Int  a  optional String }
+		type Alpha [ String ]
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, printSchema(t, sch), qt.Equals, `type Zed struct {
+	b Int
+	a optional String
+}
+
+type Alpha [String]
+`)
+
+	// Float implicits keep a decimal point, so that they're not read back as ints.
+	sch, err = schemadsl.ParseBytes([]byte(`type P struct { x Float (implicit 2.0) y Float (implicit -1.5e-9) }`))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, printSchema(t, sch), qt.Equals, `type P struct {
+	x Float (implicit 2.0)
+	y Float (implicit -1.5e-09)
+}
+`)
+
+	qt.Check(t, printSchema(t, &schemadmt.Schema{}), qt.Equals, "")
+}
+
+// TestPrintSchemaSchema round-trips the schema-schema, as compiled into schemadmt.TypeSystem,
+// through the DMT form and the DSL.
+func TestPrintSchemaSchema(t *testing.T) {
+	t.Parallel()
+
+	sch, err := schemadmt.ConvertTypeSystem(&schemadmt.TypeSystem)
+	qt.Assert(t, err, qt.IsNil)
+	printed := printSchema(t, sch)
+
+	reparsed, err := schemadsl.ParseBytes([]byte(printed))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, reparsed, qt.DeepEquals, sch)
+	qt.Check(t, printSchema(t, reparsed), qt.Equals, printed)
+
+	var ts schema.TypeSystem
+	ts.Init()
+	qt.Assert(t, schemadmt.Compile(&ts, reparsed), qt.IsNil)
+	for _, name := range schemadmt.TypeSystem.Names() {
+		want := schemadmt.TypeSystem.TypeByName(name)
+		got := ts.TypeByName(name)
+		qt.Assert(t, got, qt.Not(qt.IsNil), qt.Commentf("%s", name))
+		qt.Check(t, got.TypeKind(), qt.Equals, want.TypeKind(), qt.Commentf("%s", name))
+	}
+}
@@ -161,3 +161,64 @@ func (e ErrNotUnionStructure) Is(err error) bool {
 	_, ok := err.(ErrNotUnionStructure)
 	return ok
 }
+
+// ErrValidation is the type of the errors returned by Validate.
+// It says where in the data a mismatch with the schema was found,
+// and wraps another error describing the mismatch.
+type ErrValidation struct {
+	// Path is the path to the mismatched data, from the root of the node that was validated.
+	Path datamodel.Path
+
+	// Reason must always be present.  It is often another error from this package, or datamodel.ErrWrongKind.
+	Reason error
+}
+
+func (e ErrValidation) Error() string {
+	if e.Path.Len() == 0 {
+		return fmt.Sprintf("invalid data at the root: %s", e.Reason)
+	}
+	return fmt.Sprintf("invalid data at %q: %s", e.Path, e.Reason)
+}
+
+// Unwrap returns the Reason, so that errors.Is and errors.As can look at it.
+func (e ErrValidation) Unwrap() error {
+	return e.Reason
+}
+
+// Is provides support for Go's standard errors.Is function so that
+// errors.Is(yourError, ErrValidation) may be used to match the type of error.
+func (e ErrValidation) Is(err error) bool {
+	_, ok := err.(ErrValidation)
+	return ok
+}
+
+// ErrMissingType is the type of the errors returned by TypeSystem.ValidateGraph,
+// when a type refers to a type or advanced data layout which isn't in the TypeSystem.
+type ErrMissingType struct {
+	// TypeName is the name of the type with the reference.
+	TypeName TypeName
+
+	// Missing is the name of the type or advanced data layout which is missing.
+	Missing string
+
+	// Context says how the missing name was referred to, such as "as value type".
+	Context string
+
+	// AdvancedLayout is true if the missing name is that of an advanced data layout.
+	AdvancedLayout bool
+}
+
+func (e ErrMissingType) Error() string {
+	what := "type"
+	if e.AdvancedLayout {
+		what = "advanced layout"
+	}
+	return fmt.Sprintf("type %s refers to missing %s %s (%s)", e.TypeName, what, e.Missing, e.Context)
+}
+
+// Is provides support for Go's standard errors.Is function so that
+// errors.Is(yourError, ErrMissingType) may be used to match the type of error.
+func (e ErrMissingType) Is(err error) bool {
+	_, ok := err.(ErrMissingType)
+	return ok
+}
@@ -159,6 +159,15 @@ func sizeOfSchemaType(t schema.Type) uintptr {
 		return size
 	}
 
+	switch kind {
+	case schema.TypeKind_Enum:
+		// Enums are held as the name of their member, whatever their representation.
+		return sizeOfTypeKind[schema.TypeKind_String]
+	case schema.TypeKind_Any:
+		// Any holds a datamodel.Node, which is an interface.
+		return reflect.TypeOf((*datamodel.Node)(nil)).Elem().Size()
+	}
+
 	// TODO: handle typekinds like structs, unions, etc.
 	// For now, return a large size to fall back to using a pointer.
 	return 100 * sizeSmallEnoughForInlining
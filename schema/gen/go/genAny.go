@@ -0,0 +1,420 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// anyGenerator emits a node which holds any data model value.
+// The value is kept as a datamodel.Node (built with basicnode, unless it was assigned a node directly),
+// and every node method is forwarded to it;
+// so unlike every other generator, this one has no kind, and uses none of the mixins.
+type anyGenerator struct {
+	AdjCfg  *AdjunctCfg
+	PkgName string
+	Type    *schema.TypeAny
+}
+
+func (anyGenerator) IsRepr() bool { return false } // hint used in some generalized templates.
+
+// --- native content and specializations --->
+
+func (g anyGenerator) EmitNativeType(w io.Writer) {
+	doTemplate(`
+		{{- if Comments -}}
+		// {{ .Type | TypeSymbol }} matches the IPLD Schema type "{{ .Type.Name }}".  It has {{ .Type.TypeKind }} type-kind, and may hold data of any kind.
+		{{- end}}
+		type {{ .Type | TypeSymbol }} = *_{{ .Type | TypeSymbol }}
+		type _{{ .Type | TypeSymbol }} struct{ x datamodel.Node }
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNativeAccessors(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) Node() datamodel.Node {
+			return n.x
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNativeBuilder(w io.Writer) {
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__Prototype) FromNode(v datamodel.Node) ({{ .Type | TypeSymbol }}, error) {
+			var n _{{ .Type | TypeSymbol }}
+			var m schema.Maybe
+			if err := (&_{{ .Type | TypeSymbol }}__Assembler{w: &n, m: &m}).AssignNode(v); err != nil {
+				return nil, err
+			}
+			return &n, nil
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g anyGenerator) EmitNativeMaybe(w io.Writer) {
+	emitNativeMaybe(w, g.AdjCfg, g)
+}
+
+// --- type info --->
+
+func (g anyGenerator) EmitTypeConst(w io.Writer) {
+	doTemplate(`
+		// TODO EmitTypeConst
+	`, w, g.AdjCfg, g)
+}
+
+// --- TypedNode interface satisfaction --->
+
+func (g anyGenerator) EmitTypedNodeMethodType(w io.Writer) {
+	doTemplate(`
+		func ({{ .Type | TypeSymbol }}) Type() schema.Type {
+			return nil /*TODO:typelit*/
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g anyGenerator) EmitTypedNodeMethodRepresentation(w io.Writer) {
+	emitTypicalTypedNodeMethodRepresentation(w, g.AdjCfg, g)
+}
+
+// --- Node interface satisfaction --->
+
+func (g anyGenerator) EmitNodeType(w io.Writer) {
+	// No additional types needed.  Methods all attach to the native type.
+}
+func (g anyGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	emitNodeTypeAssertions_typical(w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodKind(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) Kind() datamodel.Kind {
+			return n.x.Kind()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodLookupByString(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) LookupByString(key string) (datamodel.Node, error) {
+			return n.x.LookupByString(key)
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodLookupByNode(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+			return n.x.LookupByNode(key)
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodLookupByIndex(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) LookupByIndex(idx int64) (datamodel.Node, error) {
+			return n.x.LookupByIndex(idx)
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodLookupBySegment(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) LookupBySegment(seg datamodel.PathSegment) (datamodel.Node, error) {
+			return n.x.LookupBySegment(seg)
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodMapIterator(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) MapIterator() datamodel.MapIterator {
+			return n.x.MapIterator()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodListIterator(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) ListIterator() datamodel.ListIterator {
+			return n.x.ListIterator()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodLength(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) Length() int64 {
+			return n.x.Length()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodIsAbsent(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) IsAbsent() bool {
+			return false
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodIsNull(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) IsNull() bool {
+			return n.x.IsNull()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodAsBool(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) AsBool() (bool, error) {
+			return n.x.AsBool()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodAsInt(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) AsInt() (int64, error) {
+			return n.x.AsInt()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodAsFloat(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) AsFloat() (float64, error) {
+			return n.x.AsFloat()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodAsString(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) AsString() (string, error) {
+			return n.x.AsString()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodAsBytes(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) AsBytes() ([]byte, error) {
+			return n.x.AsBytes()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodAsLink(w io.Writer) {
+	doTemplate(`
+		func (n {{ .Type | TypeSymbol }}) AsLink() (datamodel.Link, error) {
+			return n.x.AsLink()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+func (g anyGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g anyGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return anyBuilderGenerator(g)
+}
+
+// anyBuilderGenerator emits an assembler which builds its value with a basicnode builder,
+// and fills in the value when that's done.
+// Scalars are simply assigned through AssignNode;
+// maps and lists get a thin wrapper around basicnode's assemblers, so that their Finish can fill in the value.
+type anyBuilderGenerator struct {
+	AdjCfg  *AdjunctCfg
+	PkgName string
+	Type    *schema.TypeAny
+}
+
+func (anyBuilderGenerator) IsRepr() bool { return false } // hint used in some generalized templates.
+
+func (g anyBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	// The 'nb' field is only used while a map or list is being assembled.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Assembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+
+			nb datamodel.NodeBuilder
+		}
+
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) reset() {
+			na.nb = nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodBeginMap(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) BeginMap(sizeHint int64) (datamodel.MapAssembler, error) {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			case midvalue:
+				panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+			}
+			na.nb = basicnode.Prototype.Any.NewBuilder()
+			ma, err := na.nb.BeginMap(sizeHint)
+			if err != nil {
+				return nil, err
+			}
+			*na.m = midvalue
+			return &_{{ .Type | TypeSymbol }}__MapAssembler{ma, na}, nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodBeginList(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			case midvalue:
+				panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+			}
+			na.nb = basicnode.Prototype.Any.NewBuilder()
+			la, err := na.nb.BeginList(sizeHint)
+			if err != nil {
+				return nil, err
+			}
+			*na.m = midvalue
+			return &_{{ .Type | TypeSymbol }}__ListAssembler{la, na}, nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	// Null is a value like any other here, unless the assembler is for something nullable,
+	//  in which case it's the absence of a value, as usual.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignNull() error {
+			if *na.m == allowNull {
+				*na.m = schema.Maybe_Null
+				return nil
+			}
+			return na.AssignNode(datamodel.Null)
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignBool(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignBool(v bool) error {
+			return na.AssignNode(basicnode.NewBool(v))
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignInt(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignInt(v int64) error {
+			return na.AssignNode(basicnode.NewInt(v))
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignFloat(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignFloat(v float64) error {
+			return na.AssignNode(basicnode.NewFloat(v))
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignString(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignString(v string) error {
+			return na.AssignNode(basicnode.NewString(v))
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignBytes(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignBytes(v []byte) error {
+			return na.AssignNode(basicnode.NewBytes(v))
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignLink(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignLink(v datamodel.Link) error {
+			return na.AssignNode(basicnode.NewLink(v))
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	// Nodes are kept as they are, since they're immutable, except that:
+	//  a node of this type is unwrapped, so there's never more than one layer of this;
+	//  and typed nodes are kept as their representation, since that's the data this holds.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignNode(v datamodel.Node) error {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			case midvalue:
+				panic("invalid state: cannot assign a node into an assembler that's already begun working on recursive structures!")
+			}
+			if v.IsAbsent() {
+				return schema.ErrUnmatchable{TypeName: "{{ .PkgName }}.{{ .Type.Name }}"}.Reasonf("cannot hold an absent value")
+			}
+			if v.IsNull() && *na.m == allowNull {
+				*na.m = schema.Maybe_Null
+				return nil
+			}
+			switch v2 := v.(type) {
+			case *_{{ .Type | TypeSymbol }}:
+				v = v2.x
+			case schema.TypedNode:
+				v = v2.Representation()
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			na.w.x = v
+			*na.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerMethodPrototype(w io.Writer) {
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__Assembler) Prototype() datamodel.NodePrototype {
+			return _{{ .Type | TypeSymbol }}__Prototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g anyBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	// The map and list assemblers are basicnode's, with Finish wrapped.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__MapAssembler struct {
+			datamodel.MapAssembler
+			na *_{{ .Type | TypeSymbol }}__Assembler
+		}
+
+		func (ma *_{{ .Type | TypeSymbol }}__MapAssembler) Finish() error {
+			if err := ma.MapAssembler.Finish(); err != nil {
+				return err
+			}
+			ma.na.finish()
+			return nil
+		}
+
+		type _{{ .Type | TypeSymbol }}__ListAssembler struct {
+			datamodel.ListAssembler
+			na *_{{ .Type | TypeSymbol }}__Assembler
+		}
+
+		func (la *_{{ .Type | TypeSymbol }}__ListAssembler) Finish() error {
+			if err := la.ListAssembler.Finish(); err != nil {
+				return err
+			}
+			la.na.finish()
+			return nil
+		}
+
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) finish() {
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			na.w.x = na.nb.Build()
+			na.nb = nil
+			*na.m = schema.Maybe_Value
+		}
+	`, w, g.AdjCfg, g)
+}
@@ -0,0 +1,173 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+// enumGenerator emits the type-level parts of an enum, which are the same for every representation strategy.
+// At the type level, an enum behaves as a string, which is always the name of one of its members;
+// the representation strategies vary only in what the members look like when serialized.
+type enumGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringTraits
+	PkgName string
+	Type    *schema.TypeEnum
+}
+
+func (enumGenerator) IsRepr() bool { return false } // hint used in some generalized templates.
+
+// --- native content and specializations --->
+
+func (g enumGenerator) EmitNativeType(w io.Writer) {
+	// Same as emitNativeType_scalar, except for the comment.
+	//  The member is stored by name; the representation is computed when asked for.
+	doTemplate(`
+		{{- if Comments -}}
+		// {{ .Type | TypeSymbol }} matches the IPLD Schema type "{{ .Type.Name }}".  It has {{ .Type.TypeKind }} type-kind, and may be interrogated like {{ .Kind }} kind.
+		{{- end}}
+		type {{ .Type | TypeSymbol }} = *_{{ .Type | TypeSymbol }}
+		type _{{ .Type | TypeSymbol }} struct{ x string }
+	`, w, g.AdjCfg, g)
+}
+func (g enumGenerator) EmitNativeAccessors(w io.Writer) {
+	emitNativeAccessors_scalar(w, g.AdjCfg, g)
+}
+func (g enumGenerator) EmitNativeBuilder(w io.Writer) {
+	// Unlike the builders for plain strings, these check that the value is one of the members,
+	//  so that it's impossible to hold a value of the enum type that isn't.
+	// The unexported fromString is also what the type-level assembler uses.
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__Prototype) fromString(w *_{{ .Type | TypeSymbol }}, v string) error {
+			switch v {
+			{{- range $i, $member := .Type.Members }}
+			{{- if not $i }}
+			case {{ printf "%q" $member }}
+			{{- else }}, {{ printf "%q" $member }}
+			{{- end }}
+			{{- end }}:
+				*w = _{{ .Type | TypeSymbol }}{v}
+				return nil
+			}
+			return schema.ErrUnmatchable{TypeName: "{{ .PkgName }}.{{ .Type.Name }}"}.Reasonf("%q is not a member of the enum", v)
+		}
+		func (_{{ .Type | TypeSymbol }}__Prototype) FromString(v string) ({{ .Type | TypeSymbol }}, error) {
+			var n _{{ .Type | TypeSymbol }}
+			if err := (_{{ .Type | TypeSymbol }}__Prototype{}).fromString(&n, v); err != nil {
+				return nil, err
+			}
+			return &n, nil
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g enumGenerator) EmitNativeMaybe(w io.Writer) {
+	emitNativeMaybe(w, g.AdjCfg, g)
+}
+
+// --- type info --->
+
+func (g enumGenerator) EmitTypeConst(w io.Writer) {
+	doTemplate(`
+		// TODO EmitTypeConst
+	`, w, g.AdjCfg, g)
+}
+
+// --- TypedNode interface satisfaction --->
+
+func (g enumGenerator) EmitTypedNodeMethodType(w io.Writer) {
+	doTemplate(`
+		func ({{ .Type | TypeSymbol }}) Type() schema.Type {
+			return nil /*TODO:typelit*/
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g enumGenerator) EmitTypedNodeMethodRepresentation(w io.Writer) {
+	emitTypicalTypedNodeMethodRepresentation(w, g.AdjCfg, g)
+}
+
+// --- Node interface satisfaction --->
+
+func (g enumGenerator) EmitNodeType(w io.Writer) {
+	// No additional types needed.  Methods all attach to the native type.
+}
+
+func (g enumGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	emitNodeTypeAssertions_typical(w, g.AdjCfg, g)
+}
+func (g enumGenerator) EmitNodeMethodAsString(w io.Writer) {
+	emitNodeMethodAsKind_scalar(w, g.AdjCfg, g)
+}
+func (g enumGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+func (g enumGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g enumGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return enumBuilderGenerator{
+		g.AdjCfg,
+		mixins.StringAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type enumBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringAssemblerTraits
+	PkgName string
+	Type    *schema.TypeEnum
+}
+
+func (enumBuilderGenerator) IsRepr() bool { return false } // hint used in some generalized templates.
+
+func (g enumBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g enumBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+}
+func (g enumBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	emitNodeAssemblerType_scalar(w, g.AdjCfg, g)
+}
+func (g enumBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_scalar(w, g.AdjCfg, g)
+}
+func (g enumBuilderGenerator) EmitNodeAssemblerMethodAssignString(w io.Writer) {
+	// Much like emitNodeAssemblerMethodAssignKind_scalar, but goes through fromString, so that non-members are rejected.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__Assembler) AssignString(v string) error {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			if err := (_{{ .Type | TypeSymbol }}__Prototype{}).fromString(na.w, v); err != nil {
+				return err
+			}
+			*na.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g enumBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	emitNodeAssemblerMethodAssignNode_scalar(w, g.AdjCfg, g)
+}
+func (g enumBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	// Nothing needed here for enums.
+}
@@ -0,0 +1,242 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &enumReprStringGenerator{}
+
+func NewEnumReprStringGenerator(pkgName string, typ *schema.TypeEnum, adjCfg *AdjunctCfg) TypeGenerator {
+	return enumReprStringGenerator{
+		enumGenerator{
+			adjCfg,
+			mixins.StringTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			pkgName,
+			typ,
+		},
+	}
+}
+
+type enumReprStringGenerator struct {
+	enumGenerator
+}
+
+func (g enumReprStringGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return enumReprStringReprGenerator{
+		g.AdjCfg,
+		mixins.StringTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+// enumStringMember pairs an enum member with the string it's represented as.
+type enumStringMember struct {
+	Member string
+	Repr   string
+}
+
+// enumStringMembers returns every member of the enum, in order, with its string representation.
+// Members which the representation doesn't mention are represented by their own name.
+func enumStringMembers(typ *schema.TypeEnum) []enumStringMember {
+	stg := typ.RepresentationStrategy().(schema.EnumRepresentation_String)
+	members := make([]enumStringMember, len(typ.Members()))
+	for i, member := range typ.Members() {
+		members[i] = enumStringMember{member, member}
+		if repr, ok := stg[member]; ok {
+			members[i].Repr = repr
+		}
+	}
+	return members
+}
+
+type enumReprStringReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringTraits
+	PkgName string
+	Type    *schema.TypeEnum
+}
+
+func (enumReprStringReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g enumReprStringReprGenerator) Members() []enumStringMember {
+	return enumStringMembers(g.Type)
+}
+
+func (g enumReprStringReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+	`, w, g.AdjCfg, g)
+}
+
+func (g enumReprStringReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g enumReprStringReprGenerator) EmitNodeMethodAsString(w io.Writer) {
+	// A String method is generated here too, as the stringjoin representation of structs
+	//  expects one on the representation node of each of its fields.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) AsString() (string, error) {
+			return n.String(), nil
+		}
+		func (n *_{{ .Type | TypeSymbol }}__Repr) String() string {
+			switch n.x {
+			{{- range $member := .Members }}
+			{{- if ne $member.Member $member.Repr }}
+			case {{ printf "%q" $member.Member }}:
+				return {{ printf "%q" $member.Repr }}
+			{{- end}}
+			{{- end}}
+			default:
+				return n.x
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g enumReprStringReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g enumReprStringReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g enumReprStringReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return enumReprStringReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.StringAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type enumReprStringReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringAssemblerTraits
+	PkgName string
+	Type    *schema.TypeEnum
+}
+
+func (enumReprStringReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g enumReprStringReprBuilderGenerator) Members() []enumStringMember {
+	return enumStringMembers(g.Type)
+}
+
+func (g enumReprStringReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g enumReprStringReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+
+	// As with other representations of string kind, a plain construction function is generated,
+	//  so that this can be used as a field of a struct with the stringjoin representation, etc.
+	// Note that the member names aren't accepted here, unless they're also what the member is represented as.
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__ReprPrototype) fromString(w *_{{ .Type | TypeSymbol }}, v string) error {
+			switch v {
+			{{- range $member := .Members }}
+			case {{ printf "%q" $member.Repr }}:
+				*w = _{{ dot.Type | TypeSymbol }}{ {{- printf "%q" $member.Member -}} }
+			{{- end}}
+			default:
+				return schema.ErrUnmatchable{TypeName: "{{ .PkgName }}.{{ .Type.Name }}.Repr"}.Reasonf("%q is not the representation of any member of the enum", v)
+			}
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g enumReprStringReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+		}
+
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {}
+	`, w, g.AdjCfg, g)
+}
+func (g enumReprStringReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_scalar(w, g.AdjCfg, g)
+}
+func (g enumReprStringReprBuilderGenerator) EmitNodeAssemblerMethodAssignString(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignString(v string) error {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			if err := (_{{ .Type | TypeSymbol }}__ReprPrototype{}).fromString(na.w, v); err != nil {
+				return err
+			}
+			*na.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g enumReprStringReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	emitNodeAssemblerMethodAssignNode_enumRepr(w, g.AdjCfg, g)
+}
+func (g enumReprStringReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	// None for this.
+}
+
+// emitNodeAssemblerMethodAssignNode_enumRepr is shared by the representation assemblers of all enums.
+// It accepts a type-level node of the enum as it is; anything else must be of the representation's kind.
+func emitNodeAssemblerMethodAssignNode_enumRepr(w io.Writer, adjCfg *AdjunctCfg, data interface{}) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignNode(v datamodel.Node) error {
+			if v.IsNull() {
+				return na.AssignNull()
+			}
+			if v2, ok := v.(*_{{ .Type | TypeSymbol }}); ok {
+				switch *na.m {
+				case schema.Maybe_Value, schema.Maybe_Null:
+					panic("invalid state: cannot assign into assembler that's already finished")
+				}
+				{{- if .Type | MaybeUsesPtr }}
+				if na.w == nil {
+					na.w = v2
+					*na.m = schema.Maybe_Value
+					return nil
+				}
+				{{- end}}
+				*na.w = *v2
+				*na.m = schema.Maybe_Value
+				return nil
+			}
+			if v2, err := v.As{{ .Kind.String | title }}(); err != nil {
+				return err
+			} else {
+				return na.Assign{{ .Kind.String | title }}(v2)
+			}
+		}
+	`, w, adjCfg, data)
+}
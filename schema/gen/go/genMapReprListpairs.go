@@ -0,0 +1,492 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &mapReprListpairsGenerator{}
+
+func NewMapReprListpairsGenerator(pkgName string, typ *schema.TypeMap, adjCfg *AdjunctCfg) TypeGenerator {
+	return mapReprListpairsGenerator{
+		mapGenerator{
+			adjCfg,
+			mixins.MapTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			pkgName,
+			typ,
+		},
+	}
+}
+
+type mapReprListpairsGenerator struct {
+	mapGenerator
+}
+
+func (g mapReprListpairsGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return mapReprListpairsReprGenerator{
+		g.AdjCfg,
+		mixins.ListTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type mapReprListpairsReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.ListTraits
+	PkgName string
+	Type    *schema.TypeMap
+}
+
+func (mapReprListpairsReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g mapReprListpairsReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	//
+	// Each entry of the map is also a node at the representation level: a list of exactly two values, the key and the value.
+	//  We get those nodes for free by casting pointers into the 't' slice, so iterating costs no allocations.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+		type _{{ .Type | TypeSymbol }}__ReprEntry _{{ .Type | TypeSymbol }}__entry
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__ReprEntry{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodeMethodLookupByIndex(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByIndex(idx int64) (datamodel.Node, error) {
+			if idx < 0 || idx >= int64(len(n.t)) {
+				return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
+			}
+			return (*_{{ .Type | TypeSymbol }}__ReprEntry)(&n.t[idx]), nil
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodeMethodLookupByNode(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+			ki, err := key.AsInt()
+			if err != nil {
+				return nil, err
+			}
+			return n.LookupByIndex(ki)
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodeMethodListIterator(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) ListIterator() datamodel.ListIterator {
+			return &_{{ .Type | TypeSymbol }}__ReprListItr{n, 0}
+		}
+
+		type _{{ .Type | TypeSymbol }}__ReprListItr struct {
+			n   *_{{ .Type | TypeSymbol }}__Repr
+			idx int
+		}
+
+		func (itr *_{{ .Type | TypeSymbol }}__ReprListItr) Next() (idx int64, v datamodel.Node, _ error) {
+			if itr.idx >= len(itr.n.t) {
+				return -1, nil, datamodel.ErrIteratorOverread{}
+			}
+			idx = int64(itr.idx)
+			v = (*_{{ .Type | TypeSymbol }}__ReprEntry)(&itr.n.t[itr.idx])
+			itr.idx++
+			return
+		}
+		func (itr *_{{ .Type | TypeSymbol }}__ReprListItr) Done() bool {
+			return itr.idx >= len(itr.n.t)
+		}
+
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodeMethodLength(w io.Writer) {
+	doTemplate(`
+		func (rn *_{{ .Type | TypeSymbol }}__Repr) Length() int64 {
+			return int64(len(rn.t))
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g mapReprListpairsReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+
+	// The entry node gets all of its methods emitted here, since it's not a type that the rest of the generator knows about.
+	g.emitEntryNode(w)
+}
+
+func (g mapReprListpairsReprGenerator) emitEntryNode(w io.Writer) {
+	entryTraits := mixins.ListTraits{
+		PkgName:    g.PkgName,
+		TypeName:   g.TypeName + ".Entry",
+		TypeSymbol: g.TypeSymbol + "Entry",
+	}
+	entryTraits.EmitNodeMethodKind(w)
+	entryTraits.EmitNodeMethodLookupByString(w)
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__ReprEntry) LookupByIndex(idx int64) (datamodel.Node, error) {
+			switch idx {
+			case 0:
+				return (&n.k).Representation(), nil
+			case 1:
+				{{- if .Type.ValueIsNullable }}
+				if n.v.m == schema.Maybe_Null {
+					return datamodel.Null, nil
+				}
+				return {{ if (MaybeUsesPtr .Type.ValueType) }}n.v.v{{else}}(&n.v.v){{end}}.Representation(), nil
+				{{- else}}
+				return (&n.v).Representation(), nil
+				{{- end}}
+			default:
+				return nil, datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(idx)}
+			}
+		}
+		func (n *_{{ .Type | TypeSymbol }}__ReprEntry) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+			ki, err := key.AsInt()
+			if err != nil {
+				return nil, err
+			}
+			return n.LookupByIndex(ki)
+		}
+	`, w, g.AdjCfg, g)
+	entryTraits.EmitNodeMethodLookupBySegment(w)
+	entryTraits.EmitNodeMethodMapIterator(w)
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__ReprEntry) ListIterator() datamodel.ListIterator {
+			return &_{{ .Type | TypeSymbol }}__ReprEntryItr{n, 0}
+		}
+
+		type _{{ .Type | TypeSymbol }}__ReprEntryItr struct {
+			n   *_{{ .Type | TypeSymbol }}__ReprEntry
+			idx int
+		}
+
+		func (itr *_{{ .Type | TypeSymbol }}__ReprEntryItr) Next() (idx int64, v datamodel.Node, err error) {
+			if itr.idx >= 2 {
+				return -1, nil, datamodel.ErrIteratorOverread{}
+			}
+			idx = int64(itr.idx)
+			v, err = itr.n.LookupByIndex(idx)
+			itr.idx++
+			return
+		}
+		func (itr *_{{ .Type | TypeSymbol }}__ReprEntryItr) Done() bool {
+			return itr.idx >= 2
+		}
+
+		func (*_{{ .Type | TypeSymbol }}__ReprEntry) Length() int64 {
+			return 2
+		}
+	`, w, g.AdjCfg, g)
+	entryTraits.EmitNodeMethodIsAbsent(w)
+	entryTraits.EmitNodeMethodIsNull(w)
+	entryTraits.EmitNodeMethodAsBool(w)
+	entryTraits.EmitNodeMethodAsInt(w)
+	entryTraits.EmitNodeMethodAsFloat(w)
+	entryTraits.EmitNodeMethodAsString(w)
+	entryTraits.EmitNodeMethodAsBytes(w)
+	entryTraits.EmitNodeMethodAsLink(w)
+
+	// An entry can't be built on its own, since it has nowhere to put itself;
+	//  entries are only ever assembled as part of the whole map, by the map's repr assembler.
+	doTemplate(`
+		func (*_{{ .Type | TypeSymbol }}__ReprEntry) Prototype() datamodel.NodePrototype {
+			return _{{ .Type | TypeSymbol }}__ReprEntryPrototype{}
+		}
+
+		type _{{ .Type | TypeSymbol }}__ReprEntryPrototype struct{}
+
+		func (_{{ .Type | TypeSymbol }}__ReprEntryPrototype) NewBuilder() datamodel.NodeBuilder {
+			panic("cannot build a listpairs entry on its own; build the {{ .PkgName }}.{{ .Type.Name }}.Repr it belongs to instead")
+		}
+	`, w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g mapReprListpairsReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return mapReprListpairsReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.ListAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type mapReprListpairsReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.ListAssemblerTraits
+	PkgName string
+	Type    *schema.TypeMap
+}
+
+func (mapReprListpairsReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	// - 'w' is the "**w**ip" pointer.
+	// - 'm' is the **m**aybe which communicates our completeness to the parent if we're a child assembler.
+	// - 'state' is the state of the outer list; each of its values is one entry of the map.
+	// - 'p' is the **p**osition within the entry currently being assembled:
+	//    0 means the entry hasn't begun; 1 means it's begun and expects the key;
+	//    2 means the key is being assembled; 3 means the value is being assembled; and 4 means the entry is finished.
+	//
+	// - 'cm', 'ka', and 'va' are exactly as in the map representation's assembler.
+	//
+	// The entry assembler is the same memory, under another name: it's both the NodeAssembler and the ListAssembler for one entry.
+	//  There's only ever one entry in progress, so this costs nothing more than the map repr assembler would.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+			state laState
+			p int
+
+			cm schema.Maybe
+			ka _{{ .Type.KeyType | TypeSymbol }}__ReprAssembler
+			va _{{ .Type.ValueType | TypeSymbol }}__ReprAssembler
+		}
+
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {
+			na.state = laState_initial
+			na.p = 0
+			na.ka.reset()
+			na.va.reset()
+		}
+
+		type _{{ .Type | TypeSymbol }}__ReprEntryAssembler _{{ .Type | TypeSymbol }}__ReprAssembler
+	`, w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeAssemblerMethodBeginList(w io.Writer) {
+	// This method contains a branch to support MaybeUsesPtr because new memory may need to be allocated.
+	//  This allocation only happens if the 'w' ptr is nil, which means we're being used on a Maybe;
+	//  otherwise, the 'w' ptr should already be set, and we fill that memory location without allocating, as usual.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) BeginList(sizeHint int64) (datamodel.ListAssembler, error) {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			case midvalue:
+				panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+			}
+			*na.m = midvalue
+			if sizeHint < 0 {
+				sizeHint = 0
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			na.w.m = make(map[_{{ .Type.KeyType | TypeSymbol }}]{{if .Type.ValueIsNullable }}Maybe{{else}}*_{{end}}{{ .Type.ValueType | TypeSymbol }}, sizeHint)
+			na.w.t = make([]_{{ .Type | TypeSymbol }}__entry, 0, sizeHint)
+			return na, nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_recursive(w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	emitNodeAssemblerMethodAssignNode_listoid(w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	g.emitListAssemblerMethods(w)
+	g.emitEntryAssembler(w)
+}
+func (g mapReprListpairsReprBuilderGenerator) emitListAssemblerMethods(w io.Writer) {
+	doTemplate(`
+		func (la *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleValue() datamodel.NodeAssembler {
+			switch la.state {
+			case laState_initial:
+				// carry on
+			case laState_midValue:
+				if la.p != 4 {
+					panic("invalid state: AssembleValue cannot be called when still in the middle of assembling the previous entry")
+				}
+			case laState_finished:
+				panic("invalid state: AssembleValue cannot be called on an assembler that's already finished")
+			}
+			la.w.t = append(la.w.t, _{{ .Type | TypeSymbol }}__entry{})
+			la.state = laState_midValue
+			la.p = 0
+			return (*_{{ .Type | TypeSymbol }}__ReprEntryAssembler)(la)
+		}
+		func (la *_{{ .Type | TypeSymbol }}__ReprAssembler) Finish() error {
+			switch la.state {
+			case laState_initial:
+				// carry on
+			case laState_midValue:
+				if la.p != 4 {
+					panic("invalid state: Finish cannot be called when in the middle of assembling an entry")
+				}
+			case laState_finished:
+				panic("invalid state: Finish cannot be called on an assembler that's already finished")
+			}
+			la.state = laState_finished
+			*la.m = schema.Maybe_Value
+			return nil
+		}
+		func (la *_{{ .Type | TypeSymbol }}__ReprAssembler) ValuePrototype(_ int64) datamodel.NodePrototype {
+			return _{{ .Type | TypeSymbol }}__ReprEntryPrototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g mapReprListpairsReprBuilderGenerator) emitEntryAssembler(w io.Writer) {
+	entryTraits := mixins.ListAssemblerTraits{
+		PkgName:       g.PkgName,
+		TypeName:      g.TypeName + ".Repr.Entry",
+		AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__ReprEntry",
+	}
+	entryTraits.EmitNodeAssemblerMethodBeginMap(w)
+	doTemplate(`
+		func (ea *_{{ .Type | TypeSymbol }}__ReprEntryAssembler) BeginList(int64) (datamodel.ListAssembler, error) {
+			if ea.p != 0 {
+				panic("invalid state: it makes no sense to 'begin' twice on the same assembler!")
+			}
+			ea.p = 1
+			return ea, nil
+		}
+	`, w, g.AdjCfg, g)
+	entryTraits.EmitNodeAssemblerMethodAssignNull(w)
+	entryTraits.EmitNodeAssemblerMethodAssignBool(w)
+	entryTraits.EmitNodeAssemblerMethodAssignInt(w)
+	entryTraits.EmitNodeAssemblerMethodAssignFloat(w)
+	entryTraits.EmitNodeAssemblerMethodAssignString(w)
+	entryTraits.EmitNodeAssemblerMethodAssignBytes(w)
+	entryTraits.EmitNodeAssemblerMethodAssignLink(w)
+	doTemplate(`
+		func (ea *_{{ .Type | TypeSymbol }}__ReprEntryAssembler) AssignNode(v datamodel.Node) error {
+			if v.Kind() != datamodel.Kind_List {
+				return datamodel.ErrWrongKind{TypeName: "{{ .PkgName }}.{{ .Type.Name }}.Repr.Entry", MethodName: "AssignNode", AppropriateKind: datamodel.KindSet_JustList, ActualKind: v.Kind()}
+			}
+			if _, err := ea.BeginList(2); err != nil {
+				return err
+			}
+			itr := v.ListIterator()
+			for !itr.Done() {
+				_, v, err := itr.Next()
+				if err != nil {
+					return err
+				}
+				if err := ea.AssembleValue().AssignNode(v); err != nil {
+					return err
+				}
+			}
+			return ea.Finish()
+		}
+	`, w, g.AdjCfg, g)
+	entryTraits.EmitNodeAssemblerMethodPrototype(w)
+
+	// The key is collected when the value is started, since that's the first moment we know the key is complete.
+	//  That's also when the key gets checked for uniqueness, and the 'm' index is pointed at the entry's value.
+	doTemplate(`
+		func (ea *_{{ .Type | TypeSymbol }}__ReprEntryAssembler) AssembleValue() datamodel.NodeAssembler {
+			switch ea.p {
+			case 1:
+				ea.p = 2
+				ea.ka.w = &ea.w.t[len(ea.w.t)-1].k
+				ea.ka.m = &ea.cm
+				return &ea.ka
+			case 2:
+				if ea.cm != schema.Maybe_Value {
+					panic("invalid state: AssembleValue cannot be called when in the middle of assembling the key")
+				}
+				ea.ka.w = nil
+				ea.cm = schema.Maybe_Absent
+				ea.ka.reset()
+				tz := &ea.w.t[len(ea.w.t)-1]
+				if _, exists := ea.w.m[tz.k]; exists {
+					return _ErrorThunkAssembler{datamodel.ErrRepeatedMapKey{Key: &tz.k}}
+				}
+				ea.p = 3
+				ea.w.m[tz.k] = &tz.v
+				{{- if .Type.ValueIsNullable }}
+				{{- if not (MaybeUsesPtr .Type.ValueType) }}
+				ea.va.w = &tz.v.v
+				{{- end}}
+				ea.va.m = &tz.v.m
+				tz.v.m = allowNull
+				{{- else}}
+				ea.va.w = &tz.v
+				ea.va.m = &ea.cm
+				{{- end}}
+				return &ea.va
+			case 3:
+				return _ErrorThunkAssembler{datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(2)}}
+			default:
+				panic("invalid state: AssembleValue cannot be called on an entry that hasn't begun or is already finished")
+			}
+		}
+		func (ea *_{{ .Type | TypeSymbol }}__ReprEntryAssembler) Finish() error {
+			switch ea.p {
+			case 1, 2:
+				return datamodel.ErrNotExists{Segment: datamodel.PathSegmentOfInt(int64(ea.p - 1))}
+			case 3:
+				// carry on
+			default:
+				panic("invalid state: Finish cannot be called on an entry that hasn't begun or is already finished")
+			}
+			{{- if .Type.ValueIsNullable }}
+			tz := &ea.w.t[len(ea.w.t)-1]
+			switch tz.v.m {
+			case schema.Maybe_Null:
+				// carry on
+			case schema.Maybe_Value:
+				{{- if (MaybeUsesPtr .Type.ValueType) }}
+				tz.v.v = ea.va.w
+				{{- end}}
+				ea.va.w = nil
+			default:
+				panic("invalid state: Finish cannot be called when in the middle of assembling the value")
+			}
+			{{- else}}
+			if ea.cm != schema.Maybe_Value {
+				panic("invalid state: Finish cannot be called when in the middle of assembling the value")
+			}
+			ea.va.w = nil
+			ea.cm = schema.Maybe_Absent
+			{{- end}}
+			ea.va.reset()
+			ea.p = 4
+			return nil
+		}
+		func (ea *_{{ .Type | TypeSymbol }}__ReprEntryAssembler) ValuePrototype(idx int64) datamodel.NodePrototype {
+			if idx == 0 {
+				return _{{ .Type.KeyType | TypeSymbol }}__ReprPrototype{}
+			}
+			return _{{ .Type.ValueType | TypeSymbol }}__ReprPrototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
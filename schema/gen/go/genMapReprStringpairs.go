@@ -0,0 +1,232 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &mapReprStringpairsGenerator{}
+
+func NewMapReprStringpairsGenerator(pkgName string, typ *schema.TypeMap, adjCfg *AdjunctCfg) TypeGenerator {
+	return mapReprStringpairsGenerator{
+		mapGenerator{
+			adjCfg,
+			mixins.MapTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			pkgName,
+			typ,
+		},
+	}
+}
+
+type mapReprStringpairsGenerator struct {
+	mapGenerator
+}
+
+func (g mapReprStringpairsGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return mapReprStringpairsReprGenerator{
+		g.AdjCfg,
+		mixins.StringTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type mapReprStringpairsReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringTraits
+	PkgName string
+	Type    *schema.TypeMap
+}
+
+func (mapReprStringpairsReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g mapReprStringpairsReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprStringpairsReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprStringpairsReprGenerator) EmitNodeMethodAsString(w io.Writer) {
+	// Prerequisites:
+	//  - both keys and values must have string representation.
+	//  - values may not be nullable.
+	//    - both of these should've been checked when compiling the type system info.
+	//  - there are NO sanity checks that keys and values don't contain the delimiters.
+	//
+	// Entries are written in the map's insertion order, same as iteration order at the type level.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) AsString() (string, error) {
+			return n.String(), nil
+		}
+		func (n *_{{ .Type | TypeSymbol }}__Repr) String() string {
+			var buf []byte
+			for i := range n.t {
+				x := &n.t[i]
+				if i > 0 {
+					buf = append(buf, "{{ .Type.RepresentationStrategy.GetEntryDelim }}"...)
+				}
+				buf = append(buf, (*_{{ .Type.KeyType | TypeSymbol }}__Repr)(&x.k).String()...)
+				buf = append(buf, "{{ .Type.RepresentationStrategy.GetInnerDelim }}"...)
+				buf = append(buf, (*_{{ .Type.ValueType | TypeSymbol }}__Repr)(&x.v).String()...)
+			}
+			return string(buf)
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprStringpairsReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g mapReprStringpairsReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g mapReprStringpairsReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return mapReprStringpairsReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.StringAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type mapReprStringpairsReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringAssemblerTraits
+	PkgName string
+	Type    *schema.TypeMap
+}
+
+func (mapReprStringpairsReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+
+	// Like any other representation of scalar kind, we get a single-step construction function.
+	//  The number of entries is known up front, so both the lookup map and the entry table are allocated exactly once.
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__ReprPrototype) fromString(w *_{{ .Type | TypeSymbol }}, v string) error {
+			pairs, err := mixins.SplitPairs(v, "{{ .Type.RepresentationStrategy.GetInnerDelim }}", "{{ .Type.RepresentationStrategy.GetEntryDelim }}")
+			if err != nil {
+				return schema.ErrUnmatchable{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Reason: err}
+			}
+			w.m = make(map[_{{ .Type.KeyType | TypeSymbol }}]*_{{ .Type.ValueType | TypeSymbol }}, len(pairs))
+			w.t = make([]_{{ .Type | TypeSymbol }}__entry, len(pairs))
+			for i, kv := range pairs {
+				x := &w.t[i]
+				if err := (_{{ .Type.KeyType | TypeSymbol }}__ReprPrototype{}).fromString(&x.k, kv[0]); err != nil {
+					return schema.ErrUnmatchable{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Reason: err}
+				}
+				if _, exists := w.m[x.k]; exists {
+					return datamodel.ErrRepeatedMapKey{Key: &x.k}
+				}
+				if err := (_{{ .Type.ValueType | TypeSymbol }}__ReprPrototype{}).fromString(&x.v, kv[1]); err != nil {
+					return schema.ErrUnmatchable{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Reason: err}
+				}
+				w.m[x.k] = &x.v
+			}
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+		}
+
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {}
+	`, w, g.AdjCfg, g)
+}
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_scalar(w, g.AdjCfg, g)
+}
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignString(w io.Writer) {
+	// This method contains a branch to support MaybeUsesPtr because new memory may need to be allocated.
+	//  This allocation only happens if the 'w' ptr is nil, which means we're being used on a Maybe;
+	//  otherwise, the 'w' ptr should already be set, and we fill that memory location without allocating, as usual.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignString(v string) error {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			if err := (_{{ .Type | TypeSymbol }}__ReprPrototype{}).fromString(na.w, v); err != nil {
+				return err
+			}
+			*na.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	// AssignNode goes through three phases:
+	// 1. is it null?  Jump over to AssignNull (which may or may not reject it).
+	// 2. is it our own type?  Handle specially -- we might be able to do efficient things.
+	// 3. is it the right kind to morph into us?  Do so.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignNode(v datamodel.Node) error {
+			if v.IsNull() {
+				return na.AssignNull()
+			}
+			if v2, ok := v.(*_{{ .Type | TypeSymbol }}); ok {
+				switch *na.m {
+				case schema.Maybe_Value, schema.Maybe_Null:
+					panic("invalid state: cannot assign into assembler that's already finished")
+				}
+				{{- if .Type | MaybeUsesPtr }}
+				if na.w == nil {
+					na.w = v2
+					*na.m = schema.Maybe_Value
+					return nil
+				}
+				{{- end}}
+				*na.w = *v2
+				*na.m = schema.Maybe_Value
+				return nil
+			}
+			if v2, err := v.AsString(); err != nil {
+				return err
+			} else {
+				return na.AssignString(v2)
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g mapReprStringpairsReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	// None for this.
+}
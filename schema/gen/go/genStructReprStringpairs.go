@@ -0,0 +1,256 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &structReprStringpairsGenerator{}
+
+func NewStructReprStringpairsGenerator(pkgName string, typ *schema.TypeStruct, adjCfg *AdjunctCfg) TypeGenerator {
+	return structReprStringpairsGenerator{
+		structGenerator{
+			adjCfg,
+			mixins.MapTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			pkgName,
+			typ,
+		},
+	}
+}
+
+type structReprStringpairsGenerator struct {
+	structGenerator
+}
+
+func (g structReprStringpairsGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return structReprStringpairsReprGenerator{
+		g.AdjCfg,
+		mixins.StringTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type structReprStringpairsReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringTraits
+	PkgName string
+	Type    *schema.TypeStruct
+}
+
+func (structReprStringpairsReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g structReprStringpairsReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+	`, w, g.AdjCfg, g)
+}
+
+func (g structReprStringpairsReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g structReprStringpairsReprGenerator) EmitNodeMethodAsString(w io.Writer) {
+	// Prerequisites are the same as for stringjoin:
+	//  - every field must be a string, or have string representation.
+	//  - there are NO sanity checks that your values don't contain either delimiter.
+	//  - optional or nullable fields are not supported with this representation strategy.
+	//    - this should've been checked when compiling the type system info.
+	//
+	// Fields are written in the order they're declared in,
+	//  and since field names are fixed, the name and inner delimiter can be one constant per field.
+	//
+	// As with stringjoin, a String method is also generated on both the repr node and the type-level node.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) AsString() (string, error) {
+			return n.String(), nil
+		}
+		func (n *_{{ .Type | TypeSymbol }}__Repr) String() string {
+			return {{ "" }}
+			{{- $stg := .Type.RepresentationStrategy -}} {{- /* ranging modifies dot, unhelpfully */ -}}
+			{{- range $i, $field := .Type.Fields }}
+			{{- if $i }} + {{end -}}
+			"{{ if $i }}{{ $stg.GetEntryDelim }}{{end}}{{ $field.Name }}{{ $stg.GetInnerDelim }}" + (*_{{ $field.Type | TypeSymbol }}__Repr)(&n.{{ $field | FieldSymbolLower }}).String()
+			{{- end}}
+		}
+		func (n {{ .Type | TypeSymbol }}) String() string {
+			return (*_{{ .Type | TypeSymbol }}__Repr)(n).String()
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g structReprStringpairsReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g structReprStringpairsReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g structReprStringpairsReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return structReprStringpairsReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.StringAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type structReprStringpairsReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.StringAssemblerTraits
+	PkgName string
+	Type    *schema.TypeStruct
+}
+
+func (structReprStringpairsReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+
+	// Generate a single-step construction function -- this is easy to do for a scalar,
+	//  and all representations of scalar kind can be expected to have a method like this.
+	// The function is attached to the NodePrototype for convenient namespacing;
+	//  it needs no new memory, so it would be inappropriate to attach to the builder or assembler.
+	// The function is directly used internally by anything else that might involve recursive destructuring on the same scalar kind
+	//  (for example, structs using stringjoin strategies that have one of this type as a field, etc).
+	// Since we're a representation of scalar kind, and can recurse,
+	//  we ourselves presume this plain construction method must also exist for all our members.
+	// REVIEW: We could make an immut-safe version of this and export it on the NodePrototype too, as `FromString(string)`.
+	// FUTURE: should engage validation flow.
+	//
+	// Unlike stringjoin, the fields may appear in any order, so we track which have been seen
+	//  using the same bits as the type-level map assembler does.
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__ReprPrototype) fromString(w *_{{ .Type | TypeSymbol }}, v string) error {
+			pairs, err := mixins.SplitPairs(v, "{{ .Type.RepresentationStrategy.GetInnerDelim }}", "{{ .Type.RepresentationStrategy.GetEntryDelim }}")
+			if err != nil {
+				return schema.ErrUnmatchable{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Reason: err}
+			}
+			{{- $dot := . }} {{- /* ranging modifies dot, unhelpfully */}}
+			s := 0
+			for _, kv := range pairs {
+				switch kv[0] {
+				{{- range $i, $field := .Type.Fields }}
+				case "{{ $field.Name }}":
+					if s & fieldBit__{{ $dot.Type | TypeSymbol }}_{{ $field | FieldSymbolUpper }} != 0 {
+						return datamodel.ErrRepeatedMapKey{Key: &fieldName__{{ $dot.Type | TypeSymbol }}_{{ $field | FieldSymbolUpper }}}
+					}
+					s += fieldBit__{{ $dot.Type | TypeSymbol }}_{{ $field | FieldSymbolUpper }}
+					if err := (_{{ $field.Type | TypeSymbol }}__ReprPrototype{}).fromString(&w.{{ $field | FieldSymbolLower }}, kv[1]); err != nil {
+						return schema.ErrUnmatchable{TypeName:"{{ $dot.PkgName }}.{{ $dot.Type.Name }}.Repr", Reason: err}
+					}
+				{{- end}}
+				default:
+					return schema.ErrInvalidKey{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Key:&_String{kv[0]}}
+				}
+			}
+			if s & fieldBits__{{ .Type | TypeSymbol }}_sufficient != fieldBits__{{ .Type | TypeSymbol }}_sufficient {
+				err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
+				{{- range $i, $field := .Type.Fields }}
+				if s & fieldBit__{{ $dot.Type | TypeSymbol }}_{{ $field | FieldSymbolUpper }} == 0 {
+					err.Missing = append(err.Missing, "{{ $field.Name }}")
+				}
+				{{- end}}
+				return err
+			}
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+		}
+
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {}
+	`, w, g.AdjCfg, g)
+}
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_scalar(w, g.AdjCfg, g)
+}
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignString(w io.Writer) {
+	// This method contains a branch to support MaybeUsesPtr because new memory may need to be allocated.
+	//  This allocation only happens if the 'w' ptr is nil, which means we're being used on a Maybe;
+	//  otherwise, the 'w' ptr should already be set, and we fill that memory location without allocating, as usual.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignString(v string) error {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			if err := (_{{ .Type | TypeSymbol }}__ReprPrototype{}).fromString(na.w, v); err != nil {
+				return err
+			}
+			*na.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	// AssignNode goes through three phases:
+	// 1. is it null?  Jump over to AssignNull (which may or may not reject it).
+	// 2. is it our own type?  Handle specially -- we might be able to do efficient things.
+	// 3. is it the right kind to morph into us?  Do so.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignNode(v datamodel.Node) error {
+			if v.IsNull() {
+				return na.AssignNull()
+			}
+			if v2, ok := v.(*_{{ .Type | TypeSymbol }}); ok {
+				switch *na.m {
+				case schema.Maybe_Value, schema.Maybe_Null:
+					panic("invalid state: cannot assign into assembler that's already finished")
+				}
+				{{- if .Type | MaybeUsesPtr }}
+				if na.w == nil {
+					na.w = v2
+					*na.m = schema.Maybe_Value
+					return nil
+				}
+				{{- end}}
+				*na.w = *v2
+				*na.m = schema.Maybe_Value
+				return nil
+			}
+			if v2, err := v.AsString(); err != nil {
+				return err
+			} else {
+				return na.AssignString(v2)
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g structReprStringpairsReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	// None for this.
+}
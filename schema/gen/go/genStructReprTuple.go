@@ -80,7 +80,7 @@ func (g structReprTupleReprGenerator) EmitNodeMethodLookupByIndex(w io.Writer) {
 	doTemplate(`
 		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByIndex(idx int64) (datamodel.Node, error) {
 			switch idx {
-			{{- range $i, $field := .Type.Fields }}
+			{{- range $i, $field := .Type.TupleFields }}
 			case {{ $i }}:
 				{{- if $field.IsOptional }}
 				if n.{{ $field | FieldSymbolLower }}.m == schema.Maybe_Absent {
@@ -131,7 +131,7 @@ func (g structReprTupleReprGenerator) EmitNodeMethodListIterator(w io.Writer) {
 
 	// Count how many trailing fields are optional.
 	//  The 'Done' predicate gets more complex when in the trailing optionals.
-	fields := g.Type.Fields()
+	fields := g.Type.TupleFields()
 	fieldCount := len(fields)
 	beginTrailingOptionalField := fieldCount
 	for i := fieldCount - 1; i >= 0; i-- {
@@ -146,7 +146,7 @@ func (g structReprTupleReprGenerator) EmitNodeMethodListIterator(w io.Writer) {
 	doTemplate(`
 		func (n *_{{ .Type | TypeSymbol }}__Repr) ListIterator() datamodel.ListIterator {
 			{{- if .HaveTrailingOptionals }}
-			end := {{ len .Type.Fields }}`+
+			end := {{ len .Type.TupleFields }}`+
 		func() string { // this next part was too silly in templates due to lack of reverse ranging.
 			v := "\n"
 			for i := fieldCount - 1; i >= beginTrailingOptionalField; i-- {
@@ -171,11 +171,11 @@ func (g structReprTupleReprGenerator) EmitNodeMethodListIterator(w io.Writer) {
 		}
 
 		func (itr *_{{ .Type | TypeSymbol }}__ReprListItr) Next() (idx int64, v datamodel.Node, err error) {
-			if itr.idx >= {{ len .Type.Fields }} {
+			if itr.idx >= {{ len .Type.TupleFields }} {
 				return -1, nil, datamodel.ErrIteratorOverread{}
 			}
 			switch itr.idx {
-			{{- range $i, $field := .Type.Fields }}
+			{{- range $i, $field := .Type.TupleFields }}
 			case {{ $i }}:
 				idx = int64(itr.idx)
 				{{- if $field.IsOptional }}
@@ -207,7 +207,7 @@ func (g structReprTupleReprGenerator) EmitNodeMethodListIterator(w io.Writer) {
 		}
 		{{- else}}
 		func (itr *_{{ .Type | TypeSymbol }}__ReprListItr) Done() bool {
-			return itr.idx >= {{ len .Type.Fields }}
+			return itr.idx >= {{ len .Type.TupleFields }}
 		}
 		{{- end}}
 
@@ -224,8 +224,8 @@ func (g structReprTupleReprGenerator) EmitNodeMethodLength(w io.Writer) {
 	// This is fun: it has to count down for any unset optional fields.
 	doTemplate(`
 		func (rn *_{{ .Type | TypeSymbol }}__Repr) Length() int64 {
-			l := {{ len .Type.Fields }}
-			{{- range $field := .Type.Fields }}
+			l := {{ len .Type.TupleFields }}
+			{{- range $field := .Type.TupleFields }}
 			{{- if $field.IsOptional }}
 			if rn.{{ $field | FieldSymbolLower }}.m == schema.Maybe_Absent {
 				l--
@@ -295,7 +295,7 @@ func (g structReprTupleReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer)
 			f int
 
 			cm schema.Maybe
-			{{range $field := .Type.Fields -}}
+			{{range $field := .Type.TupleFields -}}
 			ca_{{ $field | FieldSymbolLower }} _{{ $field.Type | TypeSymbol }}__ReprAssembler
 			{{end -}}
 		}
@@ -303,7 +303,7 @@ func (g structReprTupleReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer)
 		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {
 			na.state = laState_initial
 			na.f = 0
-			{{- range $field := .Type.Fields }}
+			{{- range $field := .Type.TupleFields }}
 			na.ca_{{ $field | FieldSymbolLower }}.reset()
 			{{- end}}
 		}
@@ -343,7 +343,7 @@ func (g structReprTupleReprBuilderGenerator) emitListAssemblerChildTidyHelper(w
 	doTemplate(`
 		func (la *_{{ .Type | TypeSymbol }}__ReprAssembler) valueFinishTidy() bool {
 			switch la.f {
-			{{- range $i, $field := .Type.Fields }}
+			{{- range $i, $field := .Type.TupleFields }}
 			case {{ $i }}:
 				{{- if $field.IsMaybe }}
 				switch la.w.{{ $field | FieldSymbolLower }}.m {
@@ -391,12 +391,12 @@ func (g structReprTupleReprBuilderGenerator) emitListAssemblerChildListAssembler
 			case laState_finished:
 				panic("invalid state: AssembleValue cannot be called on an assembler that's already finished")
 			}
-			if la.f >= {{ len .Type.Fields }} {
-				return _ErrorThunkAssembler{schema.ErrNoSuchField{Type: nil /*TODO*/, Field: datamodel.PathSegmentOfInt({{ len .Type.Fields }})}}
+			if la.f >= {{ len .Type.TupleFields }} {
+				return _ErrorThunkAssembler{schema.ErrNoSuchField{Type: nil /*TODO*/, Field: datamodel.PathSegmentOfInt({{ len .Type.TupleFields }})}}
 			}
 			la.state = laState_midValue
 			switch la.f {
-			{{- range $i, $field := .Type.Fields }}
+			{{- range $i, $field := .Type.TupleFields }}
 			case {{ $i }}:
 				{{- if $field.IsMaybe }}
 				la.ca_{{ $field | FieldSymbolLower }}.w = {{if not (MaybeUsesPtr $field.Type) }}&{{end}}la.w.{{ $field | FieldSymbolLower }}.v
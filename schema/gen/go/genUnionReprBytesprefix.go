@@ -0,0 +1,286 @@
+package gengo
+
+import (
+	"encoding/hex"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &unionReprBytesprefixGenerator{}
+
+func NewUnionReprBytesprefixGenerator(pkgName string, typ *schema.TypeUnion, adjCfg *AdjunctCfg) TypeGenerator {
+	return unionReprBytesprefixGenerator{
+		unionGenerator{
+			AdjCfg: adjCfg,
+			MapTraits: mixins.MapTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			PkgName: pkgName,
+			Type:    typ,
+		},
+	}
+}
+
+type unionReprBytesprefixGenerator struct {
+	unionGenerator
+}
+
+func (g unionReprBytesprefixGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return unionReprBytesprefixReprGenerator{
+		AdjCfg: g.AdjCfg,
+		BytesTraits: mixins.BytesTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		PkgName: g.PkgName,
+		Type:    g.Type,
+	}
+}
+
+type unionReprBytesprefixReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.BytesTraits
+	PkgName string
+	Type    *schema.TypeUnion
+}
+
+func (unionReprBytesprefixReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+// PrefixLiteral returns the prefix bytes for a member, as a quoted Go string literal.
+func (g unionReprBytesprefixReprGenerator) PrefixLiteral(member schema.Type) string {
+	prefix, err := hex.DecodeString(g.Type.RepresentationStrategy().(schema.UnionRepresentation_BytesPrefix).GetDiscriminant(member))
+	if err != nil {
+		panic(err) // the schema compiler should've rejected this already.
+	}
+	return strconv.Quote(string(prefix))
+}
+
+func (g unionReprBytesprefixReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+	`, w, g.AdjCfg, g)
+
+	// The prefixes are emitted as constants, so that both the node and its assembler can refer to them.
+	doTemplate(`
+		const (
+			{{- range $member := .Type.Members }}
+			memberPrefix__{{ dot.Type | TypeSymbol }}_{{ $member.Name }} = {{ dot.PrefixLiteral $member }}
+			{{- end }}
+		)
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprBytesprefixReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprBytesprefixReprGenerator) EmitNodeMethodAsBytes(w io.Writer) {
+	// Each member's own representation is asked for its bytes, and then the prefix is prepended.
+	//  This means a copy on every call; that's unavoidable, since no member holds the prefixed form.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) AsBytes() ([]byte, error) {
+			var prefix string
+			var member datamodel.Node
+			{{- if (eq (.AdjCfg.UnionMemlayout .Type) "embedAll") }}
+			switch n.tag {
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				prefix, member = memberPrefix__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}, n.x{{ add $i 1 }}.Representation()
+			{{- end}}
+			{{- else if (eq (.AdjCfg.UnionMemlayout .Type) "interface") }}
+			switch n2 := n.x.(type) {
+			{{- range $member := .Type.Members }}
+			case {{ $member | TypeSymbol }}:
+				prefix, member = memberPrefix__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}, n2.Representation()
+			{{- end}}
+			{{- end}}
+			default:
+				panic("unreachable")
+			}
+			b, err := member.AsBytes()
+			if err != nil {
+				return nil, err
+			}
+			return append([]byte(prefix), b...), nil
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprBytesprefixReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g unionReprBytesprefixReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g unionReprBytesprefixReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return unionReprBytesprefixReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.BytesAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type unionReprBytesprefixReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.BytesAssemblerTraits
+	PkgName string
+	Type    *schema.TypeUnion
+}
+
+func (unionReprBytesprefixReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+// bytesprefixMember is a member of a bytesprefix union, along with its index in the union's members.
+type bytesprefixMember struct {
+	Index  int
+	Member schema.Type
+}
+
+// MembersLongestPrefixFirst returns the members of the union ordered by the length of their prefix, longest first,
+// so that trying each in turn finds the longest prefix which matches, as schema.Validate and bindnode do.
+func (g unionReprBytesprefixReprBuilderGenerator) MembersLongestPrefixFirst() []bytesprefixMember {
+	stg := g.Type.RepresentationStrategy().(schema.UnionRepresentation_BytesPrefix)
+	members := make([]bytesprefixMember, 0, len(g.Type.Members()))
+	for i, member := range g.Type.Members() {
+		members = append(members, bytesprefixMember{i, member})
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return len(stg.GetDiscriminant(members[i].Member)) > len(stg.GetDiscriminant(members[j].Member))
+	})
+	return members
+}
+
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+}
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	// Much like the kinded union's assembler: once the prefix has picked a member,
+	//  the rest of the bytes are handed to that member's representation assembler, which shares our 'm'.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+
+			{{- range $i, $member := .Type.Members }}
+			ca{{ add $i 1 }} {{ if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "interface") }}*{{end}}_{{ $member | TypeSymbol }}__ReprAssembler
+			{{- end}}
+			ca uint
+		}
+	`, w, g.AdjCfg, g)
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {
+			switch na.ca {
+			case 0:
+				return
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				na.ca{{ add $i 1 }}.reset()
+			{{- end}}
+			default:
+				panic("unreachable")
+			}
+			na.ca = 0
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_scalar(w, g.AdjCfg, g)
+}
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeAssemblerMethodAssignBytes(w io.Writer) {
+	// Prefixes may overlap, as in 01 and 0102: the longest one which matches wins, so they're tried longest first.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignBytes(v []byte) error {
+			switch *na.m {
+			case schema.Maybe_Value, schema.Maybe_Null:
+				panic("invalid state: cannot assign into assembler that's already finished")
+			}
+			{{- if .Type | MaybeUsesPtr }}
+			if na.w == nil {
+				na.w = &_{{ .Type | TypeSymbol }}{}
+			}
+			{{- end}}
+			{{- range $m := .MembersLongestPrefixFirst }}
+			{{- $i := $m.Index }}
+			{{- $member := $m.Member }}
+			if p := memberPrefix__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}; len(v) >= len(p) && string(v[:len(p)]) == p {
+				na.ca = {{ add $i 1 }}
+				{{- if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "embedAll") }}
+				na.w.tag = {{ add $i 1 }}
+				na.ca{{ add $i 1 }}.w = &na.w.x{{ add $i 1 }}
+				na.ca{{ add $i 1 }}.m = na.m
+				return na.ca{{ add $i 1 }}.AssignBytes(v[len(p):])
+				{{- else if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "interface") }}
+				x := &_{{ $member | TypeSymbol }}{}
+				na.w.x = x
+				if na.ca{{ add $i 1 }} == nil {
+					na.ca{{ add $i 1 }} = &_{{ $member | TypeSymbol }}__ReprAssembler{}
+				}
+				na.ca{{ add $i 1 }}.w = x
+				na.ca{{ add $i 1 }}.m = na.m
+				return na.ca{{ add $i 1 }}.AssignBytes(v[len(p):])
+				{{- end}}
+			}
+			{{- end}}
+			return schema.ErrNotUnionStructure{TypeName: "{{ .PkgName }}.{{ .Type.Name }}.Repr", Detail: "the data does not start with any of the prefixes of this union"}
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	// AssignNode goes through three phases:
+	// 1. is it null?  Jump over to AssignNull (which may or may not reject it).
+	// 2. is it our own type?  Handle specially -- we might be able to do efficient things.
+	// 3. is it the right kind to morph into us?  Do so.
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignNode(v datamodel.Node) error {
+			if v.IsNull() {
+				return na.AssignNull()
+			}
+			if v2, ok := v.(*_{{ .Type | TypeSymbol }}); ok {
+				switch *na.m {
+				case schema.Maybe_Value, schema.Maybe_Null:
+					panic("invalid state: cannot assign into assembler that's already finished")
+				}
+				{{- if .Type | MaybeUsesPtr }}
+				if na.w == nil {
+					na.w = v2
+					*na.m = schema.Maybe_Value
+					return nil
+				}
+				{{- end}}
+				*na.w = *v2
+				*na.m = schema.Maybe_Value
+				return nil
+			}
+			if v2, err := v.AsBytes(); err != nil {
+				return err
+			} else {
+				return na.AssignBytes(v2)
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprBytesprefixReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	// None for this.
+}
@@ -0,0 +1,580 @@
+package gengo
+
+import (
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &unionReprEnvelopeGenerator{}
+
+// The envelope representation is a map with exactly two entries: one holding the discriminant, and one holding the member's representation.
+//  The awkward part is that nothing says which of the two entries comes first in the data.
+//  When the content arrives first, the assembler can't know which member it's for yet,
+//  so it buffers it in a basicnode, and assigns it into the member once the discriminant turns up.
+//  (This, and the inline representation, are what make generated code depend on basicnode; see the imports in Generate.)
+
+func NewUnionReprEnvelopeGenerator(pkgName string, typ *schema.TypeUnion, adjCfg *AdjunctCfg) TypeGenerator {
+	return unionReprEnvelopeGenerator{
+		unionGenerator{
+			adjCfg,
+			mixins.MapTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			pkgName,
+			typ,
+		},
+	}
+}
+
+type unionReprEnvelopeGenerator struct {
+	unionGenerator
+}
+
+func (g unionReprEnvelopeGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return unionReprEnvelopeReprGenerator{
+		g.AdjCfg,
+		mixins.MapTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type unionReprEnvelopeReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.MapTraits
+	PkgName string
+	Type    *schema.TypeUnion
+}
+
+func (unionReprEnvelopeReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+	`, w, g.AdjCfg, g)
+
+	// Constants for the two keys, and for each discriminant value, so the node can return them without allocating.
+	doTemplate(`
+		var (
+			envelopeKey__{{ .Type | TypeSymbol }}_discriminant = _String{"{{ .Type.RepresentationStrategy.GetDiscriminantKey }}"}
+			envelopeKey__{{ .Type | TypeSymbol }}_content = _String{"{{ .Type.RepresentationStrategy.GetContentKey }}"}
+			{{- range $member := .Type.Members }}
+			memberName__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}_serial = _String{"{{ $member | dot.Type.RepresentationStrategy.GetDiscriminant }}"}
+			{{- end }}
+		)
+	`, w, g.AdjCfg, g)
+
+	// A helper for finding the discriminant and content together; the lookup and iterator methods are all built on it.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) envelope() (discriminant datamodel.Node, content datamodel.Node) {
+			{{- if (eq (.AdjCfg.UnionMemlayout .Type) "embedAll") }}
+			switch n.tag {
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				return &memberName__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}_serial, n.x{{ add $i 1 }}.Representation()
+			{{- end}}
+			{{- else if (eq (.AdjCfg.UnionMemlayout .Type) "interface") }}
+			switch n2 := n.x.(type) {
+			{{- range $member := .Type.Members }}
+			case {{ $member | TypeSymbol }}:
+				return &memberName__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}_serial, n2.Representation()
+			{{- end}}
+			{{- end}}
+			default:
+				panic("unreachable")
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeMethodLookupByString(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByString(key string) (datamodel.Node, error) {
+			switch key {
+			case "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}":
+				d, _ := n.envelope()
+				return d, nil
+			case "{{ .Type.RepresentationStrategy.GetContentKey }}":
+				_, c := n.envelope()
+				return c, nil
+			default:
+				return nil, schema.ErrNoSuchField{Type: nil /*TODO*/, Field: datamodel.PathSegmentOfString(key)}
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeMethodLookupByNode(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+			ks, err := key.AsString()
+			if err != nil {
+				return nil, err
+			}
+			return n.LookupByString(ks)
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeMethodMapIterator(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) MapIterator() datamodel.MapIterator {
+			return &_{{ .Type | TypeSymbol }}__ReprMapItr{n, 0}
+		}
+
+		type _{{ .Type | TypeSymbol }}__ReprMapItr struct {
+			n   *_{{ .Type | TypeSymbol }}__Repr
+			idx int
+		}
+
+		func (itr *_{{ .Type | TypeSymbol }}__ReprMapItr) Next() (k datamodel.Node, v datamodel.Node, _ error) {
+			d, c := itr.n.envelope()
+			switch itr.idx {
+			case 0:
+				k, v = &envelopeKey__{{ .Type | TypeSymbol }}_discriminant, d
+			case 1:
+				k, v = &envelopeKey__{{ .Type | TypeSymbol }}_content, c
+			default:
+				return nil, nil, datamodel.ErrIteratorOverread{}
+			}
+			itr.idx++
+			return
+		}
+		func (itr *_{{ .Type | TypeSymbol }}__ReprMapItr) Done() bool {
+			return itr.idx >= 2
+		}
+
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeMethodLength(w io.Writer) {
+	doTemplate(`
+		func (_{{ .Type | TypeSymbol }}__Repr) Length() int64 {
+			return 2
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g unionReprEnvelopeReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g unionReprEnvelopeReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return unionReprEnvelopeReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.MapAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type unionReprEnvelopeReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.MapAssemblerTraits
+	PkgName string
+	Type    *schema.TypeUnion
+}
+
+func (unionReprEnvelopeReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	// Like the keyed union's assembler, plus a few things to keep track of the two keys:
+	//  's' is a bitfield of the keys seen so far (1 for the discriminant, 2 for the content),
+	//  'f' says which of the two the current value is for,
+	//  and 'buf' holds the content if it arrived before the discriminant.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+			state maState
+			s int
+			f int
+
+			cm schema.Maybe
+			{{- range $i, $member := .Type.Members }}
+			ca{{ add $i 1 }} {{ if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "interface") }}*{{end}}_{{ $member | TypeSymbol }}__ReprAssembler
+			{{end -}}
+			ca uint
+			buf datamodel.NodeBuilder
+		}
+	`, w, g.AdjCfg, g)
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {
+			na.state = maState_initial
+			na.s = 0
+			na.buf = nil
+			switch na.ca {
+			case 0:
+				return
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				na.ca{{ add $i 1 }}.reset()
+			{{end -}}
+			default:
+				panic("unreachable")
+			}
+			na.ca = 0
+			na.cm = schema.Maybe_Absent
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeAssemblerMethodBeginMap(w io.Writer) {
+	emitNodeAssemblerMethodBeginMap_strictoid(w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_recursive(w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignNode(v datamodel.Node) error {
+			if v.IsNull() {
+				return na.AssignNull()
+			}
+			if v2, ok := v.(*_{{ .Type | TypeSymbol }}); ok {
+				switch *na.m {
+				case schema.Maybe_Value, schema.Maybe_Null:
+					panic("invalid state: cannot assign into assembler that's already finished")
+				case midvalue:
+					panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+				}
+				{{- if .Type | MaybeUsesPtr }}
+				if na.w == nil {
+					na.w = v2
+					*na.m = schema.Maybe_Value
+					return nil
+				}
+				{{- end}}
+				*na.w = *v2
+				*na.m = schema.Maybe_Value
+				return nil
+			}
+			if v.Kind() != datamodel.Kind_Map {
+				return datamodel.ErrWrongKind{TypeName: "{{ .PkgName }}.{{ .Type.Name }}.Repr", MethodName: "AssignNode", AppropriateKind: datamodel.KindSet_JustMap, ActualKind: v.Kind()}
+			}
+			itr := v.MapIterator()
+			for !itr.Done() {
+				k, v, err := itr.Next()
+				if err != nil {
+					return err
+				}
+				if err := na.AssembleKey().AssignNode(k); err != nil {
+					return err
+				}
+				if err := na.AssembleValue().AssignNode(v); err != nil {
+					return err
+				}
+			}
+			return na.Finish()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	g.emitMapAssemblerChildTidyHelper(w)
+	g.emitMapAssemblerMemberHelper(w)
+	g.emitMapAssemblerMethods(w)
+	g.emitKeyAssembler(w)
+	g.emitDiscriminantAssembler(w)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) emitMapAssemblerChildTidyHelper(w io.Writer) {
+	// The discriminant's assembler moves the state along by itself, so only the content needs tidying.
+	//  Buffered content is finished by the time anyone could ask; it's checked again when it's copied, in Finish.
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) valueFinishTidy() bool {
+			if ma.f != 2 {
+				return false
+			}
+			if ma.buf != nil || ma.cm == schema.Maybe_Value {
+				ma.state = maState_initial
+				return true
+			}
+			return false
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) emitMapAssemblerMemberHelper(w io.Writer) {
+	// Prepares the assembler for the member chosen by the discriminant; used for the content if the discriminant came first,
+	//  and for copying in the buffered content otherwise.
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) member() datamodel.NodeAssembler {
+			switch ma.ca {
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				{{- if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "embedAll") }}
+				ma.ca{{ add $i 1 }}.w = &ma.w.x{{ add $i 1 }}
+				ma.ca{{ add $i 1 }}.m = &ma.cm
+				return &ma.ca{{ add $i 1 }}
+				{{- else if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "interface") }}
+				x := &_{{ $member | TypeSymbol }}{}
+				ma.w.x = x
+				if ma.ca{{ add $i 1 }} == nil {
+					ma.ca{{ add $i 1 }} = &_{{ $member | TypeSymbol }}__ReprAssembler{}
+				}
+				ma.ca{{ add $i 1 }}.w = x
+				ma.ca{{ add $i 1 }}.m = &ma.cm
+				return ma.ca{{ add $i 1 }}
+				{{- end}}
+			{{- end}}
+			default:
+				panic("unreachable")
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) emitMapAssemblerMethods(w io.Writer) {
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAssembler, error) {
+			switch ma.state {
+			case maState_initial:
+				// carry on
+			case maState_midKey:
+				panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+			case maState_expectValue:
+				panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+			case maState_midValue:
+				if !ma.valueFinishTidy() {
+					panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+				} // if tidy success: carry on
+			case maState_finished:
+				panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+			}
+			ma.state = maState_midKey
+			if err := (*_{{ .Type | TypeSymbol }}__ReprKeyAssembler)(ma).AssignString(k); err != nil {
+				return nil, err
+			}
+			return ma.AssembleValue(), nil
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleKey() datamodel.NodeAssembler {
+			switch ma.state {
+			case maState_initial:
+				// carry on
+			case maState_midKey:
+				panic("invalid state: AssembleKey cannot be called when in the middle of assembling another key")
+			case maState_expectValue:
+				panic("invalid state: AssembleKey cannot be called when expecting start of value assembly")
+			case maState_midValue:
+				if !ma.valueFinishTidy() {
+					panic("invalid state: AssembleKey cannot be called when in the middle of assembling a value")
+				} // if tidy success: carry on
+			case maState_finished:
+				panic("invalid state: AssembleKey cannot be called on an assembler that's already finished")
+			}
+			ma.state = maState_midKey
+			return (*_{{ .Type | TypeSymbol }}__ReprKeyAssembler)(ma)
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleValue() datamodel.NodeAssembler {
+			switch ma.state {
+			case maState_initial:
+				panic("invalid state: AssembleValue cannot be called when no key is primed")
+			case maState_midKey:
+				panic("invalid state: AssembleValue cannot be called when in the middle of assembling a key")
+			case maState_expectValue:
+				// carry on
+			case maState_midValue:
+				panic("invalid state: AssembleValue cannot be called when in the middle of assembling another value")
+			case maState_finished:
+				panic("invalid state: AssembleValue cannot be called on an assembler that's already finished")
+			}
+			ma.state = maState_midValue
+			switch {
+			case ma.f == 1:
+				return (*_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler)(ma)
+			case ma.ca != 0:
+				return ma.member()
+			default:
+				ma.buf = basicnode.Prototype.Any.NewBuilder()
+				return ma.buf
+			}
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) Finish() error {
+			switch ma.state {
+			case maState_initial:
+				// carry on
+			case maState_midKey:
+				panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+			case maState_expectValue:
+				panic("invalid state: Finish cannot be called when expecting start of value assembly")
+			case maState_midValue:
+				if !ma.valueFinishTidy() {
+					panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+				} // if tidy success: carry on
+			case maState_finished:
+				panic("invalid state: Finish cannot be called on an assembler that's already finished")
+			}
+			if ma.s != 3 {
+				err := schema.ErrMissingRequiredField{Missing: make([]string, 0)}
+				if ma.s&1 == 0 {
+					err.Missing = append(err.Missing, "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}")
+				}
+				if ma.s&2 == 0 {
+					err.Missing = append(err.Missing, "{{ .Type.RepresentationStrategy.GetContentKey }}")
+				}
+				return err
+			}
+			if ma.buf != nil {
+				if err := ma.member().AssignNode(ma.buf.Build()); err != nil {
+					return err
+				}
+				ma.buf = nil
+			}
+			ma.state = maState_finished
+			*ma.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) KeyPrototype() datamodel.NodePrototype {
+			return _String__Prototype{}
+		}
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) ValuePrototype(k string) datamodel.NodePrototype {
+			switch k {
+			case "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}":
+				return _String__Prototype{}
+			default:
+				return nil
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) emitKeyAssembler(w io.Writer) {
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprKeyAssembler _{{ .Type | TypeSymbol }}__ReprAssembler
+	`, w, g.AdjCfg, g)
+	stubs := mixins.StringAssemblerTraits{
+		PkgName:       g.PkgName,
+		TypeName:      g.TypeName + ".KeyAssembler", // ".Repr" is already in `g.TypeName`, so don't stutter the "Repr" part.
+		AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__ReprKey",
+	}
+	stubs.EmitNodeAssemblerMethodBeginMap(w)
+	stubs.EmitNodeAssemblerMethodBeginList(w)
+	stubs.EmitNodeAssemblerMethodAssignNull(w)
+	stubs.EmitNodeAssemblerMethodAssignBool(w)
+	stubs.EmitNodeAssemblerMethodAssignInt(w)
+	stubs.EmitNodeAssemblerMethodAssignFloat(w)
+	doTemplate(`
+		func (ka *_{{ .Type | TypeSymbol }}__ReprKeyAssembler) AssignString(k string) error {
+			if ka.state != maState_midKey {
+				panic("misuse: KeyAssembler held beyond its valid lifetime")
+			}
+			switch k {
+			case "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}":
+				ka.f = 1
+			case "{{ .Type.RepresentationStrategy.GetContentKey }}":
+				ka.f = 2
+			default:
+				return schema.ErrInvalidKey{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Key:&_String{k}}
+			}
+			if ka.s&ka.f != 0 {
+				return datamodel.ErrRepeatedMapKey{Key: &_String{k}}
+			}
+			ka.s += ka.f
+			ka.state = maState_expectValue
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+	stubs.EmitNodeAssemblerMethodAssignBytes(w)
+	stubs.EmitNodeAssemblerMethodAssignLink(w)
+	doTemplate(`
+		func (ka *_{{ .Type | TypeSymbol }}__ReprKeyAssembler) AssignNode(v datamodel.Node) error {
+			if v2, err := v.AsString(); err != nil {
+				return err
+			} else {
+				return ka.AssignString(v2)
+			}
+		}
+		func (_{{ .Type | TypeSymbol }}__ReprKeyAssembler) Prototype() datamodel.NodePrototype {
+			return _String__Prototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprEnvelopeReprBuilderGenerator) emitDiscriminantAssembler(w io.Writer) {
+	// Assembles the value of the discriminant entry: it's always a string, so this looks a lot like the key assembler.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler _{{ .Type | TypeSymbol }}__ReprAssembler
+	`, w, g.AdjCfg, g)
+	stubs := mixins.StringAssemblerTraits{
+		PkgName:       g.PkgName,
+		TypeName:      g.TypeName + ".DiscriminantAssembler",
+		AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__ReprDiscriminant",
+	}
+	stubs.EmitNodeAssemblerMethodBeginMap(w)
+	stubs.EmitNodeAssemblerMethodBeginList(w)
+	stubs.EmitNodeAssemblerMethodAssignNull(w)
+	stubs.EmitNodeAssemblerMethodAssignBool(w)
+	stubs.EmitNodeAssemblerMethodAssignInt(w)
+	stubs.EmitNodeAssemblerMethodAssignFloat(w)
+	doTemplate(`
+		func (da *_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler) AssignString(v string) error {
+			if da.state != maState_midValue || da.f != 1 {
+				panic("misuse: DiscriminantAssembler held beyond its valid lifetime")
+			}
+			switch v {
+			{{- range $i, $member := .Type.Members }}
+			case "{{ $member | dot.Type.RepresentationStrategy.GetDiscriminant }}":
+				da.ca = {{ add $i 1 }}
+				{{- if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "embedAll") }}
+				da.w.tag = {{ add $i 1 }}
+				{{- end}}
+			{{- end}}
+			default:
+				return schema.ErrNotUnionStructure{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Detail: "no member with discriminant " + v}
+			}
+			da.state = maState_initial
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+	stubs.EmitNodeAssemblerMethodAssignBytes(w)
+	stubs.EmitNodeAssemblerMethodAssignLink(w)
+	doTemplate(`
+		func (da *_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler) AssignNode(v datamodel.Node) error {
+			if v2, err := v.AsString(); err != nil {
+				return err
+			} else {
+				return da.AssignString(v2)
+			}
+		}
+		func (_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler) Prototype() datamodel.NodePrototype {
+			return _String__Prototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
@@ -0,0 +1,621 @@
+package gengo
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/schema/gen/go/mixins"
+)
+
+var _ TypeGenerator = &unionReprInlineGenerator{}
+
+// The inline representation puts the discriminant in the same map as the member's own fields,
+//  so every member must be a struct with map representation.
+//  As with envelopes, nothing says where in the map the discriminant appears.
+//  Any entries that arrive before it are buffered in a basicnode map,
+//  and replayed into the member's assembler as soon as the discriminant says which member that is;
+//  entries after the discriminant are forwarded straight to the member.
+
+func NewUnionReprInlineGenerator(pkgName string, typ *schema.TypeUnion, adjCfg *AdjunctCfg) TypeGenerator {
+	for _, member := range typ.Members() {
+		t2, ok := member.(*schema.TypeStruct)
+		if !ok {
+			panic(fmt.Sprintf("inline union %s has member %s which is not a struct", typ.Name(), member.Name()))
+		}
+		if _, ok := t2.RepresentationStrategy().(schema.StructRepresentation_Map); !ok {
+			panic(fmt.Sprintf("inline union %s has member %s which does not have map representation", typ.Name(), member.Name()))
+		}
+	}
+	return unionReprInlineGenerator{
+		unionGenerator{
+			adjCfg,
+			mixins.MapTraits{
+				PkgName:    pkgName,
+				TypeName:   string(typ.Name()),
+				TypeSymbol: adjCfg.TypeSymbol(typ),
+			},
+			pkgName,
+			typ,
+		},
+	}
+}
+
+type unionReprInlineGenerator struct {
+	unionGenerator
+}
+
+func (g unionReprInlineGenerator) GetRepresentationNodeGen() NodeGenerator {
+	return unionReprInlineReprGenerator{
+		g.AdjCfg,
+		mixins.MapTraits{
+			PkgName:    g.PkgName,
+			TypeName:   string(g.Type.Name()) + ".Repr",
+			TypeSymbol: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type unionReprInlineReprGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.MapTraits
+	PkgName string
+	Type    *schema.TypeUnion
+}
+
+func (unionReprInlineReprGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g unionReprInlineReprGenerator) EmitNodeType(w io.Writer) {
+	// The type is structurally the same, but will have a different set of methods.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__Repr _{{ .Type | TypeSymbol }}
+	`, w, g.AdjCfg, g)
+
+	// Constants for the discriminant key and each discriminant value, so the node can return them without allocating.
+	doTemplate(`
+		var (
+			inlineKey__{{ .Type | TypeSymbol }}_discriminant = _String{"{{ .Type.RepresentationStrategy.GetDiscriminantKey }}"}
+			{{- range $member := .Type.Members }}
+			memberName__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}_serial = _String{"{{ $member | dot.Type.RepresentationStrategy.GetDiscriminant }}"}
+			{{- end }}
+		)
+	`, w, g.AdjCfg, g)
+
+	// A helper for finding the discriminant and the member's representation together.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) inline() (discriminant datamodel.Node, content datamodel.Node) {
+			{{- if (eq (.AdjCfg.UnionMemlayout .Type) "embedAll") }}
+			switch n.tag {
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				return &memberName__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}_serial, n.x{{ add $i 1 }}.Representation()
+			{{- end}}
+			{{- else if (eq (.AdjCfg.UnionMemlayout .Type) "interface") }}
+			switch n2 := n.x.(type) {
+			{{- range $member := .Type.Members }}
+			case {{ $member | TypeSymbol }}:
+				return &memberName__{{ dot.Type | TypeSymbol }}_{{ $member.Name }}_serial, n2.Representation()
+			{{- end}}
+			{{- end}}
+			default:
+				panic("unreachable")
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodeTypeAssertions(w io.Writer) {
+	doTemplate(`
+		var _ datamodel.Node = &_{{ .Type | TypeSymbol }}__Repr{}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodeMethodLookupByString(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByString(key string) (datamodel.Node, error) {
+			d, c := n.inline()
+			if key == "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}" {
+				return d, nil
+			}
+			return c.LookupByString(key)
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodeMethodLookupByNode(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) LookupByNode(key datamodel.Node) (datamodel.Node, error) {
+			ks, err := key.AsString()
+			if err != nil {
+				return nil, err
+			}
+			return n.LookupByString(ks)
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodeMethodMapIterator(w io.Writer) {
+	// The discriminant comes first, then everything the member's own iterator yields.
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) MapIterator() datamodel.MapIterator {
+			d, c := n.inline()
+			return &_{{ .Type | TypeSymbol }}__ReprMapItr{d, c.MapIterator(), false}
+		}
+
+		type _{{ .Type | TypeSymbol }}__ReprMapItr struct {
+			d    datamodel.Node
+			itr  datamodel.MapIterator
+			done bool
+		}
+
+		func (itr *_{{ .Type | TypeSymbol }}__ReprMapItr) Next() (k datamodel.Node, v datamodel.Node, _ error) {
+			if !itr.done {
+				itr.done = true
+				return &inlineKey__{{ .Type | TypeSymbol }}_discriminant, itr.d, nil
+			}
+			return itr.itr.Next()
+		}
+		func (itr *_{{ .Type | TypeSymbol }}__ReprMapItr) Done() bool {
+			return itr.done && itr.itr.Done()
+		}
+
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodeMethodLength(w io.Writer) {
+	doTemplate(`
+		func (n *_{{ .Type | TypeSymbol }}__Repr) Length() int64 {
+			_, c := n.inline()
+			return c.Length() + 1
+		}
+	`, w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodeMethodPrototype(w io.Writer) {
+	emitNodeMethodPrototype_typical(w, g.AdjCfg, g)
+}
+
+func (g unionReprInlineReprGenerator) EmitNodePrototypeType(w io.Writer) {
+	emitNodePrototypeType_typical(w, g.AdjCfg, g)
+}
+
+// --- NodeBuilder and NodeAssembler --->
+
+func (g unionReprInlineReprGenerator) GetNodeBuilderGenerator() NodeBuilderGenerator {
+	return unionReprInlineReprBuilderGenerator{
+		g.AdjCfg,
+		mixins.MapAssemblerTraits{
+			PkgName:       g.PkgName,
+			TypeName:      g.TypeName,
+			AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__Repr",
+		},
+		g.PkgName,
+		g.Type,
+	}
+}
+
+type unionReprInlineReprBuilderGenerator struct {
+	AdjCfg *AdjunctCfg
+	mixins.MapAssemblerTraits
+	PkgName string
+	Type    *schema.TypeUnion
+}
+
+func (unionReprInlineReprBuilderGenerator) IsRepr() bool { return true } // hint used in some generalized templates.
+
+func (g unionReprInlineReprBuilderGenerator) EmitNodeBuilderType(w io.Writer) {
+	emitEmitNodeBuilderType_typical(w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) EmitNodeBuilderMethods(w io.Writer) {
+	emitNodeBuilderMethods_typical(w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) EmitNodeAssemblerType(w io.Writer) {
+	// Like the keyed union's assembler, plus:
+	//  'f' is 1 when the current value is the discriminant's (and 0 for any other entry),
+	//  'mma' is the member's map assembler, once the discriminant has said which member to begin,
+	//  and 'buf' (with its builder 'bufb') holds any entries that arrived before that.
+	//
+	// Entries other than the discriminant are handed straight to 'mma' or 'buf', which do their own state checks;
+	//  so we don't need to track the progress of those values ourselves.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprAssembler struct {
+			w *_{{ .Type | TypeSymbol }}
+			m *schema.Maybe
+			state maState
+			f int
+
+			cm schema.Maybe
+			{{- range $i, $member := .Type.Members }}
+			ca{{ add $i 1 }} {{ if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "interface") }}*{{end}}_{{ $member | TypeSymbol }}__ReprAssembler
+			{{end -}}
+			ca uint
+			mma datamodel.MapAssembler
+			bufb datamodel.NodeBuilder
+			buf datamodel.MapAssembler
+		}
+	`, w, g.AdjCfg, g)
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) reset() {
+			na.state = maState_initial
+			na.f = 0
+			na.mma = nil
+			na.bufb = nil
+			na.buf = nil
+			switch na.ca {
+			case 0:
+				return
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				na.ca{{ add $i 1 }}.reset()
+			{{end -}}
+			default:
+				panic("unreachable")
+			}
+			na.ca = 0
+			na.cm = schema.Maybe_Absent
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) EmitNodeAssemblerMethodBeginMap(w io.Writer) {
+	emitNodeAssemblerMethodBeginMap_strictoid(w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) EmitNodeAssemblerMethodAssignNull(w io.Writer) {
+	emitNodeAssemblerMethodAssignNull_recursive(w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) EmitNodeAssemblerMethodAssignNode(w io.Writer) {
+	doTemplate(`
+		func (na *_{{ .Type | TypeSymbol }}__ReprAssembler) AssignNode(v datamodel.Node) error {
+			if v.IsNull() {
+				return na.AssignNull()
+			}
+			if v2, ok := v.(*_{{ .Type | TypeSymbol }}); ok {
+				switch *na.m {
+				case schema.Maybe_Value, schema.Maybe_Null:
+					panic("invalid state: cannot assign into assembler that's already finished")
+				case midvalue:
+					panic("invalid state: cannot assign null into an assembler that's already begun working on recursive structures!")
+				}
+				{{- if .Type | MaybeUsesPtr }}
+				if na.w == nil {
+					na.w = v2
+					*na.m = schema.Maybe_Value
+					return nil
+				}
+				{{- end}}
+				*na.w = *v2
+				*na.m = schema.Maybe_Value
+				return nil
+			}
+			if v.Kind() != datamodel.Kind_Map {
+				return datamodel.ErrWrongKind{TypeName: "{{ .PkgName }}.{{ .Type.Name }}.Repr", MethodName: "AssignNode", AppropriateKind: datamodel.KindSet_JustMap, ActualKind: v.Kind()}
+			}
+			itr := v.MapIterator()
+			for !itr.Done() {
+				k, v, err := itr.Next()
+				if err != nil {
+					return err
+				}
+				if err := na.AssembleKey().AssignNode(k); err != nil {
+					return err
+				}
+				if err := na.AssembleValue().AssignNode(v); err != nil {
+					return err
+				}
+			}
+			return na.Finish()
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) EmitNodeAssemblerOtherBits(w io.Writer) {
+	g.emitMapAssemblerChildTidyHelper(w)
+	g.emitMapAssemblerMemberHelper(w)
+	g.emitMapAssemblerMethods(w)
+	g.emitKeyAssembler(w)
+	g.emitDiscriminantAssembler(w)
+}
+func (g unionReprInlineReprBuilderGenerator) emitMapAssemblerChildTidyHelper(w io.Writer) {
+	// The discriminant's assembler moves the state along by itself.
+	//  Any other value belongs to 'mma' or 'buf', which will complain for themselves if it's left unfinished.
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) valueFinishTidy() bool {
+			if ma.f != 0 {
+				return false
+			}
+			ma.state = maState_initial
+			return true
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) emitMapAssemblerMemberHelper(w io.Writer) {
+	// Begins the member chosen by the discriminant, and replays anything that was buffered before it arrived.
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) beginMember() error {
+			var na datamodel.NodeAssembler
+			switch ma.ca {
+			{{- range $i, $member := .Type.Members }}
+			case {{ add $i 1 }}:
+				{{- if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "embedAll") }}
+				ma.ca{{ add $i 1 }}.w = &ma.w.x{{ add $i 1 }}
+				ma.ca{{ add $i 1 }}.m = &ma.cm
+				na = &ma.ca{{ add $i 1 }}
+				{{- else if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "interface") }}
+				x := &_{{ $member | TypeSymbol }}{}
+				ma.w.x = x
+				if ma.ca{{ add $i 1 }} == nil {
+					ma.ca{{ add $i 1 }} = &_{{ $member | TypeSymbol }}__ReprAssembler{}
+				}
+				ma.ca{{ add $i 1 }}.w = x
+				ma.ca{{ add $i 1 }}.m = &ma.cm
+				na = ma.ca{{ add $i 1 }}
+				{{- end}}
+			{{- end}}
+			default:
+				panic("unreachable")
+			}
+			mma, err := na.BeginMap(0)
+			if err != nil {
+				return err
+			}
+			ma.mma = mma
+			if ma.buf == nil {
+				return nil
+			}
+			if err := ma.buf.Finish(); err != nil {
+				return err
+			}
+			itr := ma.bufb.Build().MapIterator()
+			ma.bufb, ma.buf = nil, nil
+			for !itr.Done() {
+				k, v, err := itr.Next()
+				if err != nil {
+					return err
+				}
+				if err := mma.AssembleKey().AssignNode(k); err != nil {
+					return err
+				}
+				if err := mma.AssembleValue().AssignNode(v); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) emitMapAssemblerMethods(w io.Writer) {
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleEntry(k string) (datamodel.NodeAssembler, error) {
+			switch ma.state {
+			case maState_initial:
+				// carry on
+			case maState_midKey:
+				panic("invalid state: AssembleEntry cannot be called when in the middle of assembling another key")
+			case maState_expectValue:
+				panic("invalid state: AssembleEntry cannot be called when expecting start of value assembly")
+			case maState_midValue:
+				if !ma.valueFinishTidy() {
+					panic("invalid state: AssembleEntry cannot be called when in the middle of assembling a value")
+				} // if tidy success: carry on
+			case maState_finished:
+				panic("invalid state: AssembleEntry cannot be called on an assembler that's already finished")
+			}
+			ma.state = maState_midKey
+			if err := (*_{{ .Type | TypeSymbol }}__ReprKeyAssembler)(ma).AssignString(k); err != nil {
+				return nil, err
+			}
+			return ma.AssembleValue(), nil
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleKey() datamodel.NodeAssembler {
+			switch ma.state {
+			case maState_initial:
+				// carry on
+			case maState_midKey:
+				panic("invalid state: AssembleKey cannot be called when in the middle of assembling another key")
+			case maState_expectValue:
+				panic("invalid state: AssembleKey cannot be called when expecting start of value assembly")
+			case maState_midValue:
+				if !ma.valueFinishTidy() {
+					panic("invalid state: AssembleKey cannot be called when in the middle of assembling a value")
+				} // if tidy success: carry on
+			case maState_finished:
+				panic("invalid state: AssembleKey cannot be called on an assembler that's already finished")
+			}
+			ma.state = maState_midKey
+			return (*_{{ .Type | TypeSymbol }}__ReprKeyAssembler)(ma)
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) AssembleValue() datamodel.NodeAssembler {
+			switch ma.state {
+			case maState_initial:
+				panic("invalid state: AssembleValue cannot be called when no key is primed")
+			case maState_midKey:
+				panic("invalid state: AssembleValue cannot be called when in the middle of assembling a key")
+			case maState_expectValue:
+				// carry on
+			case maState_midValue:
+				panic("invalid state: AssembleValue cannot be called when in the middle of assembling another value")
+			case maState_finished:
+				panic("invalid state: AssembleValue cannot be called on an assembler that's already finished")
+			}
+			ma.state = maState_midValue
+			switch {
+			case ma.f == 1:
+				return (*_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler)(ma)
+			case ma.mma != nil:
+				return ma.mma.AssembleValue()
+			default:
+				return ma.buf.AssembleValue()
+			}
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) Finish() error {
+			switch ma.state {
+			case maState_initial:
+				// carry on
+			case maState_midKey:
+				panic("invalid state: Finish cannot be called when in the middle of assembling a key")
+			case maState_expectValue:
+				panic("invalid state: Finish cannot be called when expecting start of value assembly")
+			case maState_midValue:
+				if !ma.valueFinishTidy() {
+					panic("invalid state: Finish cannot be called when in the middle of assembling a value")
+				} // if tidy success: carry on
+			case maState_finished:
+				panic("invalid state: Finish cannot be called on an assembler that's already finished")
+			}
+			if ma.mma == nil {
+				return schema.ErrMissingRequiredField{Missing: []string{"{{ .Type.RepresentationStrategy.GetDiscriminantKey }}"}}
+			}
+			if err := ma.mma.Finish(); err != nil {
+				return err
+			}
+			ma.state = maState_finished
+			*ma.m = schema.Maybe_Value
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+
+	doTemplate(`
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) KeyPrototype() datamodel.NodePrototype {
+			return _String__Prototype{}
+		}
+		func (ma *_{{ .Type | TypeSymbol }}__ReprAssembler) ValuePrototype(k string) datamodel.NodePrototype {
+			switch k {
+			case "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}":
+				return _String__Prototype{}
+			default:
+				return nil
+			}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) emitKeyAssembler(w io.Writer) {
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprKeyAssembler _{{ .Type | TypeSymbol }}__ReprAssembler
+	`, w, g.AdjCfg, g)
+	stubs := mixins.StringAssemblerTraits{
+		PkgName:       g.PkgName,
+		TypeName:      g.TypeName + ".KeyAssembler", // ".Repr" is already in `g.TypeName`, so don't stutter the "Repr" part.
+		AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__ReprKey",
+	}
+	stubs.EmitNodeAssemblerMethodBeginMap(w)
+	stubs.EmitNodeAssemblerMethodBeginList(w)
+	stubs.EmitNodeAssemblerMethodAssignNull(w)
+	stubs.EmitNodeAssemblerMethodAssignBool(w)
+	stubs.EmitNodeAssemblerMethodAssignInt(w)
+	stubs.EmitNodeAssemblerMethodAssignFloat(w)
+	// Keys other than the discriminant go to the member (or the buffer) right away,
+	//  so that the member's own key checks happen at the same moment they would for the member on its own.
+	doTemplate(`
+		func (ka *_{{ .Type | TypeSymbol }}__ReprKeyAssembler) AssignString(k string) error {
+			if ka.state != maState_midKey {
+				panic("misuse: KeyAssembler held beyond its valid lifetime")
+			}
+			if k == "{{ .Type.RepresentationStrategy.GetDiscriminantKey }}" {
+				if ka.ca != 0 {
+					return datamodel.ErrRepeatedMapKey{Key: &inlineKey__{{ .Type | TypeSymbol }}_discriminant}
+				}
+				ka.f = 1
+				ka.state = maState_expectValue
+				return nil
+			}
+			ka.f = 0
+			if ka.mma == nil && ka.buf == nil {
+				ka.bufb = basicnode.Prototype.Map.NewBuilder()
+				buf, err := ka.bufb.BeginMap(0)
+				if err != nil {
+					return err
+				}
+				ka.buf = buf
+			}
+			var err error
+			if ka.mma != nil {
+				err = ka.mma.AssembleKey().AssignString(k)
+			} else {
+				err = ka.buf.AssembleKey().AssignString(k)
+			}
+			if err != nil {
+				return err
+			}
+			ka.state = maState_expectValue
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+	stubs.EmitNodeAssemblerMethodAssignBytes(w)
+	stubs.EmitNodeAssemblerMethodAssignLink(w)
+	doTemplate(`
+		func (ka *_{{ .Type | TypeSymbol }}__ReprKeyAssembler) AssignNode(v datamodel.Node) error {
+			if v2, err := v.AsString(); err != nil {
+				return err
+			} else {
+				return ka.AssignString(v2)
+			}
+		}
+		func (_{{ .Type | TypeSymbol }}__ReprKeyAssembler) Prototype() datamodel.NodePrototype {
+			return _String__Prototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
+func (g unionReprInlineReprBuilderGenerator) emitDiscriminantAssembler(w io.Writer) {
+	// Assembles the value of the discriminant entry: it's always a string, so this looks a lot like the key assembler.
+	doTemplate(`
+		type _{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler _{{ .Type | TypeSymbol }}__ReprAssembler
+	`, w, g.AdjCfg, g)
+	stubs := mixins.StringAssemblerTraits{
+		PkgName:       g.PkgName,
+		TypeName:      g.TypeName + ".DiscriminantAssembler",
+		AppliedPrefix: "_" + g.AdjCfg.TypeSymbol(g.Type) + "__ReprDiscriminant",
+	}
+	stubs.EmitNodeAssemblerMethodBeginMap(w)
+	stubs.EmitNodeAssemblerMethodBeginList(w)
+	stubs.EmitNodeAssemblerMethodAssignNull(w)
+	stubs.EmitNodeAssemblerMethodAssignBool(w)
+	stubs.EmitNodeAssemblerMethodAssignInt(w)
+	stubs.EmitNodeAssemblerMethodAssignFloat(w)
+	doTemplate(`
+		func (da *_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler) AssignString(v string) error {
+			if da.state != maState_midValue || da.f != 1 {
+				panic("misuse: DiscriminantAssembler held beyond its valid lifetime")
+			}
+			switch v {
+			{{- range $i, $member := .Type.Members }}
+			case "{{ $member | dot.Type.RepresentationStrategy.GetDiscriminant }}":
+				da.ca = {{ add $i 1 }}
+				{{- if (eq (dot.AdjCfg.UnionMemlayout dot.Type) "embedAll") }}
+				da.w.tag = {{ add $i 1 }}
+				{{- end}}
+			{{- end}}
+			default:
+				return schema.ErrNotUnionStructure{TypeName:"{{ .PkgName }}.{{ .Type.Name }}.Repr", Detail: "no member with discriminant " + v}
+			}
+			if err := (*_{{ .Type | TypeSymbol }}__ReprAssembler)(da).beginMember(); err != nil {
+				return err
+			}
+			da.f = 0
+			da.state = maState_initial
+			return nil
+		}
+	`, w, g.AdjCfg, g)
+	stubs.EmitNodeAssemblerMethodAssignBytes(w)
+	stubs.EmitNodeAssemblerMethodAssignLink(w)
+	doTemplate(`
+		func (da *_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler) AssignNode(v datamodel.Node) error {
+			if v2, err := v.AsString(); err != nil {
+				return err
+			} else {
+				return da.AssignString(v2)
+			}
+		}
+		func (_{{ .Type | TypeSymbol }}__ReprDiscriminantAssembler) Prototype() datamodel.NodePrototype {
+			return _String__Prototype{}
+		}
+	`, w, g.AdjCfg, g)
+}
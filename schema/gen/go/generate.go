@@ -55,6 +55,17 @@ func Generate(pth string, pkgName string, ts schema.TypeSystem, adjCfg *AdjunctC
 				fn(NewBytesReprBytesGenerator(pkgName, t2, adjCfg), f)
 			case *schema.TypeLink:
 				fn(NewLinkReprLinkGenerator(pkgName, t2, adjCfg), f)
+			case *schema.TypeAny:
+				fn(NewAnyReprAnyGenerator(pkgName, t2, adjCfg), f)
+			case *schema.TypeEnum:
+				switch t2.RepresentationStrategy().(type) {
+				case schema.EnumRepresentation_String:
+					fn(NewEnumReprStringGenerator(pkgName, t2, adjCfg), f)
+				case schema.EnumRepresentation_Int:
+					fn(NewEnumReprIntGenerator(pkgName, t2, adjCfg), f)
+				default:
+					panic("unrecognized enum representation strategy")
+				}
 			case *schema.TypeStruct:
 				switch t2.RepresentationStrategy().(type) {
 				case schema.StructRepresentation_Map:
@@ -63,11 +74,22 @@ func Generate(pth string, pkgName string, ts schema.TypeSystem, adjCfg *AdjunctC
 					fn(NewStructReprTupleGenerator(pkgName, t2, adjCfg), f)
 				case schema.StructRepresentation_Stringjoin:
 					fn(NewStructReprStringjoinGenerator(pkgName, t2, adjCfg), f)
+				case schema.StructRepresentation_StringPairs:
+					fn(NewStructReprStringpairsGenerator(pkgName, t2, adjCfg), f)
 				default:
 					panic("unrecognized struct representation strategy")
 				}
 			case *schema.TypeMap:
-				fn(NewMapReprMapGenerator(pkgName, t2, adjCfg), f)
+				switch t2.RepresentationStrategy().(type) {
+				case schema.MapRepresentation_Map:
+					fn(NewMapReprMapGenerator(pkgName, t2, adjCfg), f)
+				case schema.MapRepresentation_StringPairs:
+					fn(NewMapReprStringpairsGenerator(pkgName, t2, adjCfg), f)
+				case schema.MapRepresentation_ListPairs:
+					fn(NewMapReprListpairsGenerator(pkgName, t2, adjCfg), f)
+				default:
+					panic("unrecognized map representation strategy")
+				}
 			case *schema.TypeList:
 				fn(NewListReprListGenerator(pkgName, t2, adjCfg), f)
 			case *schema.TypeUnion:
@@ -78,6 +100,12 @@ func Generate(pth string, pkgName string, ts schema.TypeSystem, adjCfg *AdjunctC
 					fn(NewUnionReprKindedGenerator(pkgName, t2, adjCfg), f)
 				case schema.UnionRepresentation_Stringprefix:
 					fn(NewUnionReprStringprefixGenerator(pkgName, t2, adjCfg), f)
+				case schema.UnionRepresentation_Inline:
+					fn(NewUnionReprInlineGenerator(pkgName, t2, adjCfg), f)
+				case schema.UnionRepresentation_Envelope:
+					fn(NewUnionReprEnvelopeGenerator(pkgName, t2, adjCfg), f)
+				case schema.UnionRepresentation_BytesPrefix:
+					fn(NewUnionReprBytesprefixGenerator(pkgName, t2, adjCfg), f)
 				default:
 					panic("unrecognized union representation strategy")
 				}
@@ -119,7 +147,10 @@ func Generate(pth string, pkgName string, ts schema.TypeSystem, adjCfg *AdjunctC
 		fmt.Fprintf(f, "import (\n")
 		fmt.Fprintf(f, "\t\"github.com/ipld/go-ipld-prime/datamodel\"\n")   // referenced everywhere.
 		fmt.Fprintf(f, "\t\"github.com/ipld/go-ipld-prime/node/mixins\"\n") // referenced by node implementation guts.
-		fmt.Fprintf(f, "\t\"github.com/ipld/go-ipld-prime/schema\"\n")      // referenced by maybes (and surprisingly little else).
+		if usesBasicnode(ts) {
+			fmt.Fprintf(f, "\t\"github.com/ipld/go-ipld-prime/node/basicnode\"\n") // referenced by Any, and by envelope and inline unions for buffering.
+		}
+		fmt.Fprintf(f, "\t\"github.com/ipld/go-ipld-prime/schema\"\n") // referenced by maybes (and surprisingly little else).
 		fmt.Fprintf(f, ")\n\n")
 
 		// For each type, we'll emit... everything except the native type, really.
@@ -170,3 +201,21 @@ type sortableTypeNames []schema.TypeName
 func (a sortableTypeNames) Len() int           { return len(a) }
 func (a sortableTypeNames) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a sortableTypeNames) Less(i, j int) bool { return a[i] < a[j] }
+
+// usesBasicnode reports whether the type system has an Any type,
+// or any union using the envelope or inline representation,
+// which are the only things that make the generated code depend on basicnode.
+func usesBasicnode(ts schema.TypeSystem) bool {
+	for _, typ := range ts.GetTypes() {
+		switch t2 := typ.(type) {
+		case *schema.TypeAny:
+			return true
+		case *schema.TypeUnion:
+			switch t2.RepresentationStrategy().(type) {
+			case schema.UnionRepresentation_Envelope, schema.UnionRepresentation_Inline:
+				return true
+			}
+		}
+	}
+	return false
+}
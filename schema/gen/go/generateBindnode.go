@@ -0,0 +1,249 @@
+package gengo
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
+	schemadsl "github.com/ipld/go-ipld-prime/schema/dsl"
+)
+
+// GenerateBindnode takes a typesystem and the adjunct config for codegen,
+// and emits plain Go types for use with bindnode in the given path with the given package name,
+// rather than the types implementing datamodel.Node which Generate emits.
+//
+// Structs become Go structs, with a pointer for each optional or nullable field
+// (and a double pointer for fields which are both);
+// unions become Go structs with a pointer for each member, of which exactly one is set;
+// enums become Go strings, with a constant for each member;
+// and maps become Go structs with ordered Keys and a Values map, as bindnode expects.
+// The types named like the ones every schema has, such as String or Any, become
+// the matching Go types rather than being declared, as do links and types with an advanced representation.
+//
+// A Register function is emitted too, which adds every declared type to a registry.BindnodeRegistry,
+// along with the schema (which is embedded in the generated code in DSL form).
+//
+// The file produced is named "ipldsch_bindnode.go".
+func GenerateBindnode(pth string, pkgName string, ts schema.TypeSystem, adjCfg *AdjunctCfg) {
+	sch, err := schemadmt.ConvertTypeSystem(&ts)
+	if err != nil {
+		panic(err)
+	}
+	var dslBuf bytes.Buffer
+	if err := schemadsl.Print(&dslBuf, sch); err != nil {
+		panic(err)
+	}
+
+	// Sort the type names so we have a deterministic order, just like Generate.
+	types := ts.GetTypes()
+	keys := make(sortableTypeNames, 0, len(types))
+	for tn := range types {
+		keys = append(keys, tn)
+	}
+	sort.Sort(keys)
+	g := bindnodeGenerator{AdjCfg: adjCfg, Schema: dslBuf.String()}
+	for _, tn := range keys {
+		if _, builtin := g.builtinGoType(types[tn]); builtin {
+			continue
+		}
+		g.Types = append(g.Types, types[tn])
+	}
+
+	withFile(filepath.Join(pth, "ipldsch_bindnode.go"), func(f io.Writer) {
+		fmt.Fprintf(f, "package %s\n\n", pkgName)
+		fmt.Fprintf(f, doNotEditComment+"\n\n")
+		doTemplate(`
+			import (
+				"github.com/ipld/go-ipld-prime/datamodel"
+				"github.com/ipld/go-ipld-prime/node/bindnode"
+				"github.com/ipld/go-ipld-prime/node/bindnode/registry"
+			)
+
+			var _ datamodel.Node = nil // suppress errors when this dependency is not referenced
+
+			// ipldSchema is the schema the types in this package were generated from.
+			const ipldSchema = {{ .QuotedSchema }}
+
+			// Register adds every type in this package to the registry, along with the schema they were generated from.
+			// The options are used with every type.
+			func Register(reg registry.BindnodeRegistry, options ...bindnode.Option) error {
+				for _, t := range []struct {
+					ptrType  interface{}
+					typeName string
+				}{
+					{{- range .Types }}
+					{(*{{ . | TypeSymbol }})(nil), {{ printf "%q" .Name }}},
+					{{- end}}
+				} {
+					if err := reg.RegisterType(t.ptrType, ipldSchema, t.typeName, options...); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			// --- type definitions follow ---
+		`, f, adjCfg, g)
+		for _, t := range g.Types {
+			fmt.Fprintf(f, "\n")
+			g.emitType(f, t)
+		}
+	})
+}
+
+type bindnodeGenerator struct {
+	AdjCfg *AdjunctCfg
+	Schema string
+	Types  []schema.Type // the ones which get a Go type declared.
+}
+
+// QuotedSchema is the schema DSL as a Go string literal, raw if possible.
+func (g bindnodeGenerator) QuotedSchema() string {
+	if strings.Contains(g.Schema, "`") {
+		return strconv.Quote(g.Schema)
+	}
+	return "`\n" + g.Schema + "`"
+}
+
+// builtinGoType returns the Go type for a schema type which doesn't get its own declaration:
+// the types which every schema has (see schema.SpawnDefaultBasicTypes), links, and types with an advanced representation.
+func (g bindnodeGenerator) builtinGoType(t schema.Type) (string, bool) {
+	if schema.AdvancedLayoutOf(t) != "" {
+		// bindnode holds the substrate of the ADL as it is.
+		return "datamodel.Node", true
+	}
+	switch t := t.(type) {
+	case *schema.TypeAny:
+		return "datamodel.Node", true
+	case *schema.TypeLink:
+		return "datamodel.Link", true
+	case *schema.TypeBool, *schema.TypeInt, *schema.TypeFloat, *schema.TypeString, *schema.TypeBytes:
+		if t.Name() == basicTypeNames[t.RepresentationBehavior()] {
+			return goPrimitive(t.RepresentationBehavior()), true
+		}
+	case *schema.TypeList:
+		if t.Name() == "List" && t.ValueType().Name() == "Any" && !t.ValueIsNullable() {
+			return "[]datamodel.Node", true
+		}
+	case *schema.TypeMap:
+		_, isMapRepr := t.RepresentationStrategy().(schema.MapRepresentation_Map)
+		if t.Name() == "Map" && isMapRepr && t.KeyType().Name() == "String" && t.ValueType().Name() == "Any" && !t.ValueIsNullable() {
+			return "struct {\n\tKeys []string\n\tValues map[string]datamodel.Node\n}", true
+		}
+	}
+	return "", false
+}
+
+// GoType returns the Go type to use wherever the schema type is referenced.
+func (g bindnodeGenerator) GoType(t schema.Type) string {
+	if goType, ok := g.builtinGoType(t); ok {
+		return goType
+	}
+	return g.AdjCfg.TypeSymbol(t)
+}
+
+// basicTypeNames are the names schema.SpawnDefaultBasicTypes gives the scalar types.
+var basicTypeNames = map[datamodel.Kind]schema.TypeName{
+	datamodel.Kind_Bool:   "Bool",
+	datamodel.Kind_Int:    "Int",
+	datamodel.Kind_Float:  "Float",
+	datamodel.Kind_String: "String",
+	datamodel.Kind_Bytes:  "Bytes",
+}
+
+func goPrimitive(kind datamodel.Kind) string {
+	switch kind {
+	case datamodel.Kind_Bool:
+		return "bool"
+	case datamodel.Kind_Int:
+		return "int64"
+	case datamodel.Kind_Float:
+		return "float64"
+	case datamodel.Kind_String:
+		return "string"
+	case datamodel.Kind_Bytes:
+		return "[]byte"
+	default:
+		panic(fmt.Sprintf("no Go primitive for kind %s", kind))
+	}
+}
+
+// goIdent checks that a name built from the schema is a valid Go identifier.
+func goIdent(name string) string {
+	if !token.IsIdentifier(name) {
+		panic(fmt.Sprintf("%q is not a valid Go identifier", name))
+	}
+	return name
+}
+
+// GoFieldName returns the name bindnode expects a struct field or union member to have in Go.
+func (g bindnodeGenerator) GoFieldName(name string) string {
+	return goIdent(strings.Title(name)) //lint:ignore SA1019 cases.Title doesn't work for this
+}
+
+func (g bindnodeGenerator) emitType(w io.Writer, t schema.Type) {
+	data := struct {
+		bindnodeGenerator
+		Type schema.Type
+	}{g, t}
+	symbol := goIdent(g.AdjCfg.TypeSymbol(t))
+	switch t := t.(type) {
+	case *schema.TypeBool, *schema.TypeInt, *schema.TypeFloat, *schema.TypeString, *schema.TypeBytes:
+		fmt.Fprintf(w, "type %s %s\n", symbol, goPrimitive(t.RepresentationBehavior()))
+	case *schema.TypeEnum:
+		doTemplate(`
+			// {{ .Type | TypeSymbol }} is an enum; its values should be one of the constants below.
+			type {{ .Type | TypeSymbol }} string
+
+			const (
+				{{- range $member := .Type.Members }}
+				{{ $.EnumConst $.Type $member }} {{ $.Type | TypeSymbol }} = {{ printf "%q" $member }}
+				{{- end}}
+			)
+		`, w, g.AdjCfg, data)
+	case *schema.TypeStruct:
+		doTemplate(`
+			type {{ .Type | TypeSymbol }} struct {
+				{{- range $field := .Type.Fields }}
+				{{ $.GoFieldName $field.Name }} {{ if $field.IsOptional }}*{{ end }}{{ if $field.IsNullable }}*{{ end }}{{ $.GoType $field.Type }}
+				{{- end}}
+			}
+		`, w, g.AdjCfg, data)
+	case *schema.TypeUnion:
+		doTemplate(`
+			// {{ .Type | TypeSymbol }} is a union; exactly one of its fields should be set.
+			type {{ .Type | TypeSymbol }} struct {
+				{{- range $member := .Type.Members }}
+				{{ $.GoFieldName $member.Name }} *{{ $.GoType $member }}
+				{{- end}}
+			}
+		`, w, g.AdjCfg, data)
+	case *schema.TypeMap:
+		doTemplate(`
+			// {{ .Type | TypeSymbol }} is a map; Keys holds the keys in Values in their order.
+			type {{ .Type | TypeSymbol }} struct {
+				Keys   []{{ $.GoType .Type.KeyType }}
+				Values map[{{ $.GoType .Type.KeyType }}]{{ if .Type.ValueIsNullable }}*{{ end }}{{ $.GoType .Type.ValueType }}
+			}
+		`, w, g.AdjCfg, data)
+	case *schema.TypeList:
+		doTemplate(`
+			type {{ .Type | TypeSymbol }} []{{ if .Type.ValueIsNullable }}*{{ end }}{{ $.GoType .Type.ValueType }}
+		`, w, g.AdjCfg, data)
+	default:
+		panic(fmt.Sprintf("add more type switches here :), failed at type %s", t.Name()))
+	}
+}
+
+// EnumConst returns the name of the Go constant for a member of an enum.
+func (g bindnodeGenerator) EnumConst(t schema.Type, member string) string {
+	return goIdent(g.AdjCfg.TypeSymbol(t) + strings.Title(member)) //lint:ignore SA1019 cases.Title doesn't work for this
+}
@@ -0,0 +1,118 @@
+package gengo
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/tests"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+var _ tests.Engine = (*genBindnodeEngine)(nil)
+
+// genBindnodeEngine is like genAndCompileEngine, but uses GenerateBindnode.
+// The prototypes come from bindnode, using the schema embedded in the generated code,
+// so this also checks that the schema survives being printed as DSL.
+type genBindnodeEngine struct {
+	prefix string
+
+	adjCfg AdjunctCfg
+
+	prototypeByName func(string) datamodel.NodePrototype
+}
+
+func (e *genBindnodeEngine) Init(t *testing.T, ts schema.TypeSystem) {
+	dir := filepath.Join(tmpGenBuildDir, e.prefix)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	pkgName := "main"
+
+	GenerateBindnode(dir, pkgName, ts, &e.adjCfg)
+
+	// Types which don't get declared, like String, are left for bindnode to infer.
+	g := bindnodeGenerator{AdjCfg: &e.adjCfg}
+	type goTypeFor struct {
+		Name   schema.TypeName
+		GoExpr string
+	}
+	var goTypes []goTypeFor
+	for _, typ := range ts.GetTypes() {
+		expr := "nil"
+		if _, builtin := g.builtinGoType(typ); !builtin {
+			expr = "(*" + e.adjCfg.TypeSymbol(typ) + ")(nil)"
+		}
+		goTypes = append(goTypes, goTypeFor{typ.Name(), expr})
+	}
+	sort.Slice(goTypes, func(i, j int) bool { return goTypes[i].Name < goTypes[j].Name })
+
+	// Registering everything checks each Go type against the schema before any tests run.
+	withFile(filepath.Join(dir, "prototypeGetter.go"), func(w io.Writer) {
+		doTemplate(`
+			package `+pkgName+`
+
+			import (
+				"github.com/ipld/go-ipld-prime"
+				"github.com/ipld/go-ipld-prime/datamodel"
+				"github.com/ipld/go-ipld-prime/node/bindnode"
+				"github.com/ipld/go-ipld-prime/node/bindnode/registry"
+				"github.com/ipld/go-ipld-prime/schema"
+			)
+
+			var typeSystem *schema.TypeSystem
+
+			func init() {
+				ts, err := ipld.LoadSchemaBytes([]byte(ipldSchema))
+				if err != nil {
+					panic(err)
+				}
+				typeSystem = ts
+				if err := Register(registry.NewRegistry()); err != nil {
+					panic(err)
+				}
+			}
+
+			func GetPrototypeByName(name string) datamodel.NodePrototype {
+				switch name {
+				{{- range . }}
+				case "{{ .Name }}":
+					return bindnode.Prototype({{ .GoExpr }}, typeSystem.TypeByName("{{ .Name }}"))
+				case "{{ .Name }}.Repr":
+					return bindnode.Prototype({{ .GoExpr }}, typeSystem.TypeByName("{{ .Name }}")).Representation()
+				{{- end}}
+				default:
+					return nil
+				}
+			}
+		`, w, &e.adjCfg, goTypes)
+	})
+
+	buildGennedCode(t, e.prefix, pkgName)
+
+	e.prototypeByName = fnPrototypeByName(e.prefix)
+}
+
+func (e *genBindnodeEngine) PrototypeByName(name string) datamodel.NodePrototype {
+	return e.prototypeByName(name)
+}
+
+func TestBindnode(t *testing.T) {
+	if runtime.GOOS != "darwin" { // TODO: enable parallelism on macos
+		t.Parallel()
+	}
+
+	tests.SchemaTestAll(t, func(name string) []tests.EngineSubtest {
+		switch name {
+		case "Links", "UnionKeyedComplexChildren":
+			return nil // bindnode doesn't support these yet; see node/bindnode/schema_test.go
+		}
+		return []tests.EngineSubtest{{
+			Engine: &genBindnodeEngine{prefix: "bindnode-" + name},
+		}}
+	})
+}
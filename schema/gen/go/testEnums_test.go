@@ -0,0 +1,17 @@
+package gengo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/node/tests"
+)
+
+func TestEnums(t *testing.T) {
+	if runtime.GOOS != "darwin" { // TODO: enable parallelism on macos
+		t.Parallel()
+	}
+
+	engine := &genAndCompileEngine{prefix: "enums"}
+	tests.SchemaTestEnums(t, engine)
+}
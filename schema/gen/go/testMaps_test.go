@@ -52,3 +52,39 @@ func TestMapsWithComplexKeys(t *testing.T) {
 	engine := &genAndCompileEngine{prefix: "maps-cmplx-keys"}
 	tests.SchemaTestMapsWithComplexKeys(t, engine)
 }
+
+func TestMapReprStringpairs(t *testing.T) {
+	if runtime.GOOS != "darwin" { // TODO: enable parallelism on macos
+		t.Parallel()
+	}
+
+	engine := &genAndCompileEngine{prefix: "maps-str-pairs"}
+	tests.SchemaTestMapReprStringpairs(t, engine)
+}
+
+func TestMapReprListpairs(t *testing.T) {
+	if runtime.GOOS != "darwin" { // TODO: enable parallelism on macos
+		t.Parallel()
+	}
+
+	for _, engine := range []*genAndCompileEngine{
+		{
+			subtestName: "maybe-using-embed",
+			prefix:      "maps-list-pairs-embed",
+			adjCfg: AdjunctCfg{
+				maybeUsesPtr: map[schema.TypeName]bool{"String": false},
+			},
+		},
+		{
+			subtestName: "maybe-using-ptr",
+			prefix:      "maps-list-pairs-mptr",
+			adjCfg: AdjunctCfg{
+				maybeUsesPtr: map[schema.TypeName]bool{"String": true},
+			},
+		},
+	} {
+		t.Run(engine.subtestName, func(t *testing.T) {
+			tests.SchemaTestMapReprListpairs(t, engine)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package gengo
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/ipld/go-ipld-prime/node/tests"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func TestUnionInline(t *testing.T) {
+	if runtime.GOOS != "darwin" { // TODO: enable parallelism on macos
+		t.Parallel()
+	}
+
+	for _, engine := range []*genAndCompileEngine{
+		{
+			subtestName: "union-using-embed",
+			prefix:      "union-inline-using-embed",
+			adjCfg: AdjunctCfg{
+				CfgUnionMemlayout: map[schema.TypeName]string{"WheeUnion": "embedAll"},
+			},
+		},
+		{
+			subtestName: "union-using-interface",
+			prefix:      "union-inline-using-interface",
+			adjCfg: AdjunctCfg{
+				CfgUnionMemlayout: map[schema.TypeName]string{"WheeUnion": "interface"},
+			},
+		},
+	} {
+		t.Run(engine.subtestName, func(t *testing.T) {
+			tests.SchemaTestUnionInline(t, engine)
+		})
+	}
+}
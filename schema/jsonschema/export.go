@@ -0,0 +1,387 @@
+/*
+Package schema/jsonschema converts between IPLD Schemas and JSON Schema.
+
+Export describes each type of a schema.TypeSystem with a JSON Schema,
+and the JSON Schema describes the type's representation as it appears in dag-json:
+so a tuple struct is an array, a keyed union is an object with a single property,
+renamed fields appear under their renamed keys, and so on.
+Bytes and links take the special forms dag-json gives them
+(`{"/": {"bytes": "..."}}` and `{"/": "..."}`, respectively).
+
+Import goes the other way, producing a schema DMT from a JSON Schema document.
+JSON Schema can say far more than IPLD Schemas can, and says some things very differently,
+so this is best-effort: anything without a close equivalent is imported as Any.
+The result can be compiled with schemadmt.Compile, or edited further first.
+
+The JSON Schema documents produced and consumed here follow draft 2020-12,
+and are handled as Data Model nodes. Use codec/json to read and write them:
+dag-json would mistake the "/" properties describing bytes and links for bytes and links themselves.
+*/
+package jsonschema
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+// Dialect is the JSON Schema dialect of the documents produced by Export.
+const Dialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Export produces a JSON Schema document describing the dag-json representation of every type in ts.
+// Each type is described in the document's "$defs", under its own name,
+// and types refer to each other with "$ref".
+//
+// If root isn't empty, the document as a whole also describes data of the named type.
+func Export(ts *schema.TypeSystem, root schema.TypeName) (datamodel.Node, error) {
+	if root != "" && ts.TypeByName(root) == nil {
+		return nil, fmt.Errorf("jsonschema: no type named %q", root)
+	}
+	return qp.BuildMap(basicnode.Prototype.Map, 3, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "$schema", qp.String(Dialect))
+		if root != "" {
+			qp.MapEntry(ma, "$ref", qp.String(refTo(root)))
+		}
+		qp.MapEntry(ma, "$defs", qp.Map(int64(len(ts.Names())), func(ma datamodel.MapAssembler) {
+			for _, name := range ts.Names() {
+				qp.MapEntry(ma, name, exportType(ts.TypeByName(name)))
+			}
+		}))
+	})
+}
+
+func refTo(name schema.TypeName) string {
+	return "#/$defs/" + name
+}
+
+func ref(name schema.TypeName) qp.Assemble {
+	return qp.Map(1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "$ref", qp.String(refTo(name)))
+	})
+}
+
+func ofType(jsonType string) qp.Assemble {
+	return qp.Map(1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "type", qp.String(jsonType))
+	})
+}
+
+// maybeNull allows null as well as whatever the given schema allows, if nullable is true.
+func maybeNull(a qp.Assemble, nullable bool) qp.Assemble {
+	if !nullable {
+		return a
+	}
+	return qp.Map(1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "anyOf", qp.List(2, func(la datamodel.ListAssembler) {
+			qp.ListEntry(la, a)
+			qp.ListEntry(la, ofType("null"))
+		}))
+	})
+}
+
+func stringList(ss []string) qp.Assemble {
+	return qp.List(int64(len(ss)), func(la datamodel.ListAssembler) {
+		for _, s := range ss {
+			qp.ListEntry(la, qp.String(s))
+		}
+	})
+}
+
+// slashObject describes the objects dag-json uses for bytes and links: a single "/" property.
+func slashObject(inner qp.Assemble) qp.Assemble {
+	return qp.Map(4, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "type", qp.String("object"))
+		qp.MapEntry(ma, "properties", qp.Map(1, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "/", inner)
+		}))
+		qp.MapEntry(ma, "required", stringList([]string{"/"}))
+		qp.MapEntry(ma, "additionalProperties", qp.Bool(false))
+	})
+}
+
+var bytesSchema = slashObject(qp.Map(4, func(ma datamodel.MapAssembler) {
+	qp.MapEntry(ma, "type", qp.String("object"))
+	qp.MapEntry(ma, "properties", qp.Map(1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "bytes", qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("string"))
+			qp.MapEntry(ma, "contentEncoding", qp.String("base64"))
+		}))
+	}))
+	qp.MapEntry(ma, "required", stringList([]string{"bytes"}))
+	qp.MapEntry(ma, "additionalProperties", qp.Bool(false))
+}))
+
+var linkSchema = slashObject(ofType("string"))
+
+// anything is the schema which allows any value.
+var anything = qp.Map(0, func(datamodel.MapAssembler) {})
+
+func exportType(t schema.Type) qp.Assemble {
+	if adl := schema.AdvancedLayoutOf(t); adl != "" {
+		// The data's shape is up to the advanced layout, which we know nothing about.
+		return qp.Map(1, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "description", qp.String("represented by the advanced data layout "+adl))
+		})
+	}
+	switch t := t.(type) {
+	case *schema.TypeBool:
+		return ofType("boolean")
+	case *schema.TypeString:
+		return ofType("string")
+	case *schema.TypeInt:
+		return ofType("integer")
+	case *schema.TypeFloat:
+		return ofType("number")
+	case *schema.TypeBytes:
+		return bytesSchema
+	case *schema.TypeLink:
+		return linkSchema
+	case *schema.TypeAny:
+		return anything
+	case *schema.TypeList:
+		return qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("array"))
+			qp.MapEntry(ma, "items", maybeNull(ref(t.ValueType().Name()), t.ValueIsNullable()))
+		})
+	case *schema.TypeMap:
+		return exportMap(t)
+	case *schema.TypeStruct:
+		return exportStruct(t)
+	case *schema.TypeUnion:
+		return exportUnion(t)
+	case *schema.TypeEnum:
+		return exportEnum(t)
+	default:
+		panic(fmt.Errorf("jsonschema: cannot export type %s of kind %s", t.Name(), t.TypeKind()))
+	}
+}
+
+func exportMap(t *schema.TypeMap) qp.Assemble {
+	value := maybeNull(ref(t.ValueType().Name()), t.ValueIsNullable())
+	switch t.RepresentationStrategy().(type) {
+	case schema.MapRepresentation_Map:
+		return qp.Map(3, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("object"))
+			if _, ok := t.KeyType().(*schema.TypeString); !ok {
+				qp.MapEntry(ma, "propertyNames", ref(t.KeyType().Name()))
+			}
+			qp.MapEntry(ma, "additionalProperties", value)
+		})
+	case schema.MapRepresentation_StringPairs:
+		return ofType("string")
+	case schema.MapRepresentation_ListPairs:
+		return qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("array"))
+			qp.MapEntry(ma, "items", pair(ref(t.KeyType().Name()), value))
+		})
+	default:
+		panic(fmt.Errorf("jsonschema: cannot export the representation of map %s", t.Name()))
+	}
+}
+
+// pair describes a two-element array, as used by the listpairs representations.
+func pair(k, v qp.Assemble) qp.Assemble {
+	return qp.Map(5, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "type", qp.String("array"))
+		qp.MapEntry(ma, "prefixItems", qp.List(2, func(la datamodel.ListAssembler) {
+			qp.ListEntry(la, k)
+			qp.ListEntry(la, v)
+		}))
+		qp.MapEntry(ma, "items", qp.Bool(false))
+		qp.MapEntry(ma, "minItems", qp.Int(2))
+	})
+}
+
+func fieldSchema(f schema.StructField) qp.Assemble {
+	return maybeNull(ref(f.Type().Name()), f.IsNullable())
+}
+
+func exportStruct(t *schema.TypeStruct) qp.Assemble {
+	switch stg := t.RepresentationStrategy().(type) {
+	case schema.StructRepresentation_Map:
+		return structMap(t, stg, "", "")
+	case schema.StructRepresentation_Tuple:
+		fields := t.TupleFields()
+		required := 0
+		for _, f := range fields {
+			if !f.IsOptional() {
+				required++
+			}
+		}
+		return qp.Map(5, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("array"))
+			qp.MapEntry(ma, "prefixItems", qp.List(int64(len(fields)), func(la datamodel.ListAssembler) {
+				for _, f := range fields {
+					qp.ListEntry(la, fieldSchema(f))
+				}
+			}))
+			qp.MapEntry(ma, "items", qp.Bool(false))
+			qp.MapEntry(ma, "minItems", qp.Int(int64(required)))
+		})
+	case schema.StructRepresentation_ListPairs:
+		return qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("array"))
+			qp.MapEntry(ma, "items", qp.Map(1, func(ma datamodel.MapAssembler) {
+				qp.MapEntry(ma, "oneOf", qp.List(int64(len(t.Fields())), func(la datamodel.ListAssembler) {
+					for _, f := range t.Fields() {
+						qp.ListEntry(la, pair(constant(f.Name()), fieldSchema(f)))
+					}
+				}))
+			}))
+		})
+	case schema.StructRepresentation_Stringjoin, schema.StructRepresentation_StringPairs:
+		return ofType("string")
+	default:
+		panic(fmt.Errorf("jsonschema: cannot export the representation of struct %s", t.Name()))
+	}
+}
+
+// structMap describes a struct with map representation.
+// If discriminantKey isn't empty, the struct is a member of an inline union, and the discriminant is described too.
+func structMap(t *schema.TypeStruct, stg schema.StructRepresentation_Map, discriminantKey, discriminant string) qp.Assemble {
+	var required []string
+	if discriminantKey != "" {
+		required = append(required, discriminantKey)
+	}
+	for _, f := range t.Fields() {
+		if !f.IsOptional() && stg.FieldImplicit(f) == nil {
+			required = append(required, stg.GetFieldKey(f))
+		}
+	}
+	return qp.Map(4, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "type", qp.String("object"))
+		qp.MapEntry(ma, "properties", qp.Map(int64(len(t.Fields())+1), func(ma datamodel.MapAssembler) {
+			if discriminantKey != "" {
+				qp.MapEntry(ma, discriminantKey, constant(discriminant))
+			}
+			for _, f := range t.Fields() {
+				qp.MapEntry(ma, stg.GetFieldKey(f), fieldSchema(f))
+			}
+		}))
+		if len(required) > 0 {
+			qp.MapEntry(ma, "required", stringList(required))
+		}
+		qp.MapEntry(ma, "additionalProperties", qp.Bool(false))
+	})
+}
+
+func constant(s string) qp.Assemble {
+	return qp.Map(1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "const", qp.String(s))
+	})
+}
+
+// oneOf describes the members of a union, exactly one of which must match.
+// That's only right when the members' schemas can't overlap; otherwise, use anyOf.
+func oneOf(members []schema.Type, fn func(member schema.Type) qp.Assemble) qp.Assemble {
+	return combinator("oneOf", members, fn)
+}
+
+// anyOf describes the members of a union, at least one of which must match.
+func anyOf(members []schema.Type, fn func(member schema.Type) qp.Assemble) qp.Assemble {
+	return combinator("anyOf", members, fn)
+}
+
+func combinator(keyword string, members []schema.Type, fn func(member schema.Type) qp.Assemble) qp.Assemble {
+	return qp.Map(1, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, keyword, qp.List(int64(len(members)), func(la datamodel.ListAssembler) {
+			for _, member := range members {
+				qp.ListEntry(la, fn(member))
+			}
+		}))
+	})
+}
+
+func exportUnion(t *schema.TypeUnion) qp.Assemble {
+	switch stg := t.RepresentationStrategy().(type) {
+	case schema.UnionRepresentation_Keyed:
+		return oneOf(t.Members(), func(member schema.Type) qp.Assemble {
+			key := stg.GetDiscriminant(member)
+			return qp.Map(4, func(ma datamodel.MapAssembler) {
+				qp.MapEntry(ma, "type", qp.String("object"))
+				qp.MapEntry(ma, "properties", qp.Map(1, func(ma datamodel.MapAssembler) {
+					qp.MapEntry(ma, key, ref(member.Name()))
+				}))
+				qp.MapEntry(ma, "required", stringList([]string{key}))
+				qp.MapEntry(ma, "additionalProperties", qp.Bool(false))
+			})
+		})
+	case schema.UnionRepresentation_Kinded:
+		// Each member has a different kind, but their JSON Schemas can still overlap:
+		// "integer" is also a "number", and maps look just like the dag-json forms of bytes and links.
+		return anyOf(t.Members(), func(member schema.Type) qp.Assemble {
+			return ref(member.Name())
+		})
+	case schema.UnionRepresentation_Envelope:
+		return oneOf(t.Members(), func(member schema.Type) qp.Assemble {
+			return qp.Map(4, func(ma datamodel.MapAssembler) {
+				qp.MapEntry(ma, "type", qp.String("object"))
+				qp.MapEntry(ma, "properties", qp.Map(2, func(ma datamodel.MapAssembler) {
+					qp.MapEntry(ma, stg.GetDiscriminantKey(), constant(stg.GetDiscriminant(member)))
+					qp.MapEntry(ma, stg.GetContentKey(), ref(member.Name()))
+				}))
+				qp.MapEntry(ma, "required", stringList([]string{stg.GetDiscriminantKey(), stg.GetContentKey()}))
+				qp.MapEntry(ma, "additionalProperties", qp.Bool(false))
+			})
+		})
+	case schema.UnionRepresentation_Inline:
+		// The members' own schemas forbid additional properties, so they can't be referred to:
+		// each member is described again here, with the discriminant added in.
+		return oneOf(t.Members(), func(member schema.Type) qp.Assemble {
+			t2, ok := member.(*schema.TypeStruct)
+			if !ok {
+				panic(fmt.Errorf("jsonschema: member %s of inline union %s is not a struct", member.Name(), t.Name()))
+			}
+			stg2, ok := t2.RepresentationStrategy().(schema.StructRepresentation_Map)
+			if !ok {
+				panic(fmt.Errorf("jsonschema: member %s of inline union %s does not have map representation", member.Name(), t.Name()))
+			}
+			return structMap(t2, stg2, stg.GetDiscriminantKey(), stg.GetDiscriminant(member))
+		})
+	case schema.UnionRepresentation_Stringprefix:
+		// One prefix may well be the start of another, especially without a delimiter.
+		return anyOf(t.Members(), func(member schema.Type) qp.Assemble {
+			return qp.Map(2, func(ma datamodel.MapAssembler) {
+				qp.MapEntry(ma, "type", qp.String("string"))
+				qp.MapEntry(ma, "pattern", qp.String("^"+regexp.QuoteMeta(stg.GetDiscriminant(member)+stg.GetDelim())))
+			})
+		})
+	case schema.UnionRepresentation_BytesPrefix:
+		return bytesSchema
+	default:
+		panic(fmt.Errorf("jsonschema: cannot export the representation of union %s", t.Name()))
+	}
+}
+
+func exportEnum(t *schema.TypeEnum) qp.Assemble {
+	switch stg := t.RepresentationStrategy().(type) {
+	case schema.EnumRepresentation_String:
+		return qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("string"))
+			qp.MapEntry(ma, "enum", qp.List(int64(len(t.Members())), func(la datamodel.ListAssembler) {
+				for _, member := range t.Members() {
+					if s, ok := stg[member]; ok {
+						qp.ListEntry(la, qp.String(s))
+					} else {
+						qp.ListEntry(la, qp.String(member))
+					}
+				}
+			}))
+		})
+	case schema.EnumRepresentation_Int:
+		return qp.Map(2, func(ma datamodel.MapAssembler) {
+			qp.MapEntry(ma, "type", qp.String("integer"))
+			qp.MapEntry(ma, "enum", qp.List(int64(len(t.Members())), func(la datamodel.ListAssembler) {
+				for _, member := range t.Members() {
+					qp.ListEntry(la, qp.Int(int64(stg[member])))
+				}
+			}))
+		})
+	default:
+		panic(fmt.Errorf("jsonschema: cannot export the representation of enum %s", t.Name()))
+	}
+}
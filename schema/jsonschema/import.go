@@ -0,0 +1,694 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
+)
+
+// Import produces a schema DMT from a JSON Schema document.
+//
+// Each schema in the document's "$defs" (or "definitions") becomes a type of the same name.
+// If root isn't empty and the document itself describes some data,
+// rather than only being a container of definitions, that becomes a type named root too.
+// Objects and arrays described in place (as a property of an object, for example)
+// become types named after where they're found, such as "Person_address".
+//
+// Definitions named like the types schemadmt.Compile always provides, such as "String" and "Map", are skipped.
+// Only references to the document's own definitions ("#/$defs/..." and "#/definitions/...") are understood.
+// Schemas without a clear IPLD Schema equivalent are imported as Any,
+// and constraints with no equivalent at all (such as "minLength" or "pattern") are ignored.
+// The result isn't checked for coherency; schemadmt.Compile will do that.
+func Import(n datamodel.Node, root string) (*schemadmt.Schema, error) {
+	if n.Kind() != datamodel.Kind_Map {
+		return nil, fmt.Errorf("jsonschema: document must be a map, not %s", n.Kind())
+	}
+	imp := importer{
+		out: &schemadmt.Schema{
+			Types: schemadmt.Map__TypeName__TypeDefn{Values: make(map[string]schemadmt.TypeDefn)},
+		},
+		defs: make(map[string]datamodel.Node),
+	}
+	for _, key := range []string{"$defs", "definitions"} {
+		defs, err := n.LookupByString(key)
+		if err != nil {
+			continue
+		}
+		if err := eachEntry(defs, func(name string, s datamodel.Node) error {
+			imp.names = append(imp.names, name)
+			imp.defs[name] = s
+			return nil
+		}); err != nil {
+			return nil, fmt.Errorf("jsonschema: %s: %w", key, err)
+		}
+	}
+	for _, name := range imp.names {
+		if prelude[name] {
+			continue
+		}
+		if err := imp.define(name, imp.defs[name]); err != nil {
+			return nil, err
+		}
+	}
+	if root != "" && describesData(n) {
+		if _, exists := imp.defs[root]; exists {
+			return nil, fmt.Errorf("jsonschema: root type name %q is already defined", root)
+		}
+		if err := imp.define(root, n); err != nil {
+			return nil, err
+		}
+	}
+	return imp.out, nil
+}
+
+// prelude holds the names of the types which schemadmt.Compile always defines.
+var prelude = map[string]bool{
+	"Bool": true, "Int": true, "Float": true, "String": true, "Bytes": true,
+	"Any": true, "Map": true, "List": true, "Link": true,
+}
+
+type importer struct {
+	out   *schemadmt.Schema
+	names []string                  // names of the document's definitions, in order.
+	defs  map[string]datamodel.Node // the document's definitions.
+	depth int                       // guards against definitions which are only references, in a cycle.
+}
+
+// describesData reports whether a document has anything to say about data,
+// other than the keywords which only identify it or hold definitions.
+func describesData(n datamodel.Node) bool {
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, _, err := itr.Next()
+		if err != nil {
+			return false
+		}
+		switch ks, _ := k.AsString(); ks {
+		case "$schema", "$id", "$defs", "definitions", "title", "description", "$comment":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func eachEntry(n datamodel.Node, fn func(k string, v datamodel.Node) error) error {
+	if n.Kind() != datamodel.Kind_Map {
+		return fmt.Errorf("must be a map, not %s", n.Kind())
+	}
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, v, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			return err
+		}
+		if err := fn(ks, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func eachItem(n datamodel.Node, fn func(v datamodel.Node) error) error {
+	if n.Kind() != datamodel.Kind_List {
+		return fmt.Errorf("must be a list, not %s", n.Kind())
+	}
+	itr := n.ListIterator()
+	for !itr.Done() {
+		_, v, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		if err := fn(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup returns the value of a keyword, or nil if the schema doesn't use it.
+// Boolean schemas have no keywords at all.
+func lookup(s datamodel.Node, key string) datamodel.Node {
+	if s.Kind() != datamodel.Kind_Map {
+		return nil
+	}
+	v, err := s.LookupByString(key)
+	if err != nil {
+		return nil
+	}
+	return v
+}
+
+func lookupString(s datamodel.Node, key string) string {
+	v := lookup(s, key)
+	if v == nil {
+		return ""
+	}
+	str, _ := v.AsString()
+	return str
+}
+
+// jsonTypes returns the types a schema allows, with "null" separated out.
+func jsonTypes(s datamodel.Node) (types []string, nullable bool) {
+	v := lookup(s, "type")
+	if v == nil {
+		return nil, false
+	}
+	if str, err := v.AsString(); err == nil {
+		return []string{str}, str == "null"
+	}
+	eachItem(v, func(v datamodel.Node) error {
+		if str, err := v.AsString(); err == nil {
+			if str == "null" {
+				nullable = true
+			} else {
+				types = append(types, str)
+			}
+		}
+		return nil
+	})
+	return types, nullable
+}
+
+// jsonType returns the one type a schema allows besides null, if there is one.
+func jsonType(s datamodel.Node) string {
+	types, _ := jsonTypes(s)
+	if len(types) != 1 || types[0] == "null" {
+		return ""
+	}
+	return types[0]
+}
+
+// refName returns the name of the definition a schema refers to with "$ref", if it does.
+func refName(s datamodel.Node) (string, bool) {
+	ref := lookupString(s, "$ref")
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if strings.HasPrefix(ref, prefix) {
+			return strings.TrimPrefix(ref, prefix), true
+		}
+	}
+	return "", false
+}
+
+// alternatives returns the schemas listed by "oneOf" or "anyOf",
+// except for any which only allow null, which are reported by nullable instead.
+func alternatives(s datamodel.Node) (alts []datamodel.Node, nullable bool) {
+	v := lookup(s, "oneOf")
+	if v == nil {
+		v = lookup(s, "anyOf")
+	}
+	if v == nil {
+		return nil, false
+	}
+	eachItem(v, func(alt datamodel.Node) error {
+		if types, null := jsonTypes(alt); null && len(types) == 1 {
+			nullable = true
+		} else {
+			alts = append(alts, alt)
+		}
+		return nil
+	})
+	return alts, nullable
+}
+
+// isSlashObject reports whether a schema describes dag-json's form for bytes or links,
+// which are objects with a single "/" property.
+// The "/" property of links is a string; the "/" property of bytes is another object.
+func isSlashObject(s datamodel.Node) (ok, isLink bool) {
+	props := lookup(s, "properties")
+	if props == nil || props.Length() != 1 {
+		return false, false
+	}
+	slash, err := props.LookupByString("/")
+	if err != nil {
+		return false, false
+	}
+	return true, jsonType(slash) == "string"
+}
+
+func named(name string) schemadmt.TypeNameOrInlineDefn {
+	return schemadmt.TypeNameOrInlineDefn{TypeName: &name}
+}
+
+func boolPtr(b bool) *bool {
+	if !b {
+		return nil
+	}
+	return &b
+}
+
+// unusedName returns name, or if that's already taken, name with a number appended.
+func (imp *importer) unusedName(name string) string {
+	taken := func(name string) bool {
+		_, inDoc := imp.defs[name]
+		_, inOut := imp.out.Types.Values[name]
+		return inDoc || inOut || prelude[name]
+	}
+	if !taken(name) {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + strconv.Itoa(i); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+func (imp *importer) add(name string, defn schemadmt.TypeDefn) {
+	imp.out.Types.Keys = append(imp.out.Types.Keys, name)
+	imp.out.Types.Values[name] = defn
+}
+
+// typeOf returns the type to use where a schema appears as the type of something else,
+// such as a property of an object or the items of an array,
+// along with whether that something may also be null.
+// Scalars, lists and maps are used directly, as basic types or inline definitions;
+// anything else is defined as a new type, named suggestedName.
+func (imp *importer) typeOf(s datamodel.Node, suggestedName string) (schemadmt.TypeNameOrInlineDefn, bool, error) {
+	if name, ok := refName(s); ok {
+		return named(name), false, nil
+	}
+	if s.Kind() == datamodel.Kind_Bool {
+		return named("Any"), false, nil
+	}
+	if alts, nullable := alternatives(s); nullable && len(alts) == 1 {
+		t, _, err := imp.typeOf(alts[0], suggestedName)
+		return t, true, err
+	}
+	types, nullable := jsonTypes(s)
+	if len(types) == 1 && lookup(s, "enum") == nil {
+		switch types[0] {
+		case "boolean":
+			return named("Bool"), nullable, nil
+		case "integer":
+			return named("Int"), nullable, nil
+		case "number":
+			return named("Float"), nullable, nil
+		case "string":
+			return named("String"), nullable, nil
+		case "array":
+			if lookup(s, "prefixItems") != nil {
+				break
+			}
+			defn, err := imp.list(s, suggestedName+"_item")
+			return schemadmt.TypeNameOrInlineDefn{InlineDefn: &schemadmt.InlineDefn{TypeDefnList: defn}}, nullable, err
+		case "object":
+			if ok, _ := isSlashObject(s); ok || lookup(s, "properties") != nil {
+				break
+			}
+			defn, err := imp.mapOf(s, suggestedName+"_value")
+			return schemadmt.TypeNameOrInlineDefn{InlineDefn: &schemadmt.InlineDefn{TypeDefnMap: defn}}, nullable, err
+		}
+	}
+	if describesData(s) {
+		name := imp.unusedName(suggestedName)
+		return named(name), nullable, imp.define(name, s)
+	}
+	return named("Any"), nullable, nil
+}
+
+// namedTypeOf is like typeOf, but always returns the name of a type,
+// as is required for the members of unions.
+func (imp *importer) namedTypeOf(s datamodel.Node, suggestedName string) (string, error) {
+	if name, ok := refName(s); ok {
+		return name, nil
+	}
+	t, _, err := imp.typeOf(s, suggestedName)
+	if err != nil {
+		return "", err
+	}
+	if t.TypeName != nil {
+		return *t.TypeName, nil
+	}
+	name := imp.unusedName(suggestedName)
+	return name, imp.define(name, s)
+}
+
+func (imp *importer) list(s datamodel.Node, itemName string) (*schemadmt.TypeDefnList, error) {
+	items := lookup(s, "items")
+	if items == nil {
+		return &schemadmt.TypeDefnList{ValueType: named("Any")}, nil
+	}
+	t, nullable, err := imp.typeOf(items, itemName)
+	return &schemadmt.TypeDefnList{ValueType: t, ValueNullable: boolPtr(nullable)}, err
+}
+
+func (imp *importer) mapOf(s datamodel.Node, valueName string) (*schemadmt.TypeDefnMap, error) {
+	values := lookup(s, "additionalProperties")
+	if values == nil || values.Kind() == datamodel.Kind_Bool {
+		return &schemadmt.TypeDefnMap{KeyType: "String", ValueType: named("Any")}, nil
+	}
+	t, nullable, err := imp.typeOf(values, valueName)
+	return &schemadmt.TypeDefnMap{KeyType: "String", ValueType: t, ValueNullable: boolPtr(nullable)}, err
+}
+
+// define adds a type of the given name, as described by the schema s.
+func (imp *importer) define(name string, s datamodel.Node) error {
+	defn, err := imp.defn(name, s)
+	if err != nil {
+		return fmt.Errorf("jsonschema: %s: %w", name, err)
+	}
+	imp.add(name, defn)
+	return nil
+}
+
+func (imp *importer) defn(name string, s datamodel.Node) (schemadmt.TypeDefn, error) {
+	if ref, ok := refName(s); ok {
+		// There's no way to alias a type, so the type is defined again under this name.
+		target, ok := imp.defs[ref]
+		if !ok {
+			return schemadmt.TypeDefn{}, fmt.Errorf("reference to undefined %q", ref)
+		}
+		if imp.depth > len(imp.defs) {
+			return schemadmt.TypeDefn{}, fmt.Errorf("cyclic references")
+		}
+		imp.depth++
+		defer func() { imp.depth-- }()
+		return imp.defn(name, target)
+	}
+	if alts, _ := alternatives(s); alts != nil {
+		// A type can't be nullable; only its uses can.
+		if len(alts) == 1 {
+			return imp.defn(name, alts[0])
+		}
+		return imp.union(name, alts)
+	}
+	switch jsonType(s) {
+	case "boolean":
+		return schemadmt.TypeDefn{TypeDefnBool: &schemadmt.TypeDefnBool{}}, nil
+	case "integer":
+		return schemadmt.TypeDefn{TypeDefnInt: &schemadmt.TypeDefnInt{}}, nil
+	case "number":
+		return schemadmt.TypeDefn{TypeDefnFloat: &schemadmt.TypeDefnFloat{}}, nil
+	case "string":
+		if enum := lookup(s, "enum"); enum != nil {
+			return imp.enum(enum)
+		}
+		return schemadmt.TypeDefn{TypeDefnString: &schemadmt.TypeDefnString{}}, nil
+	case "array":
+		if prefixItems := lookup(s, "prefixItems"); prefixItems != nil {
+			return imp.tuple(name, s, prefixItems)
+		}
+		defn, err := imp.list(s, name+"_item")
+		return schemadmt.TypeDefn{TypeDefnList: defn}, err
+	case "object":
+		if ok, isLink := isSlashObject(s); ok {
+			if isLink {
+				return schemadmt.TypeDefn{TypeDefnLink: &schemadmt.TypeDefnLink{}}, nil
+			}
+			return schemadmt.TypeDefn{TypeDefnBytes: &schemadmt.TypeDefnBytes{}}, nil
+		}
+		if props := lookup(s, "properties"); props != nil {
+			return imp.structMap(name, s, props, "")
+		}
+		defn, err := imp.mapOf(s, name+"_value")
+		return schemadmt.TypeDefn{TypeDefnMap: defn}, err
+	}
+	if enum := lookup(s, "enum"); enum != nil {
+		return imp.enum(enum)
+	}
+	return schemadmt.TypeDefn{TypeDefnAny: &schemadmt.TypeDefnAny{}}, nil
+}
+
+// enum imports string enums. Enums of anything else are imported as Any.
+func (imp *importer) enum(values datamodel.Node) (schemadmt.TypeDefn, error) {
+	var members []string
+	err := eachItem(values, func(v datamodel.Node) error {
+		str, err := v.AsString()
+		if err != nil {
+			return err
+		}
+		members = append(members, str)
+		return nil
+	})
+	if err != nil {
+		return schemadmt.TypeDefn{TypeDefnAny: &schemadmt.TypeDefnAny{}}, nil
+	}
+	return schemadmt.TypeDefn{TypeDefnEnum: &schemadmt.TypeDefnEnum{
+		Members: members,
+		Representation: schemadmt.EnumRepresentation{
+			EnumRepresentation_String: &schemadmt.EnumRepresentation_String{Values: map[string]string{}},
+		},
+	}}, nil
+}
+
+// structMap imports an object with fixed properties as a struct with map representation.
+// Properties not listed as required are optional.
+// If skip isn't empty, that property is left out; it's the discriminant of an inline union.
+func (imp *importer) structMap(name string, s, props datamodel.Node, skip string) (schemadmt.TypeDefn, error) {
+	required := make(map[string]bool)
+	if v := lookup(s, "required"); v != nil {
+		eachItem(v, func(v datamodel.Node) error {
+			if str, err := v.AsString(); err == nil {
+				required[str] = true
+			}
+			return nil
+		})
+	}
+	fields := schemadmt.Map__FieldName__StructField{Values: make(map[string]schemadmt.StructField)}
+	err := eachEntry(props, func(fname string, fs datamodel.Node) error {
+		if fname == skip {
+			return nil
+		}
+		t, nullable, err := imp.typeOf(fs, name+"_"+fname)
+		if err != nil {
+			return err
+		}
+		fields.Keys = append(fields.Keys, fname)
+		fields.Values[fname] = schemadmt.StructField{
+			Type:     t,
+			Optional: boolPtr(!required[fname]),
+			Nullable: boolPtr(nullable),
+		}
+		return nil
+	})
+	if err != nil {
+		return schemadmt.TypeDefn{}, err
+	}
+	return schemadmt.TypeDefn{TypeDefnStruct: &schemadmt.TypeDefnStruct{
+		Fields: fields,
+		Representation: schemadmt.StructRepresentation{
+			StructRepresentation_Map: &schemadmt.StructRepresentation_Map{},
+		},
+	}}, nil
+}
+
+// tuple imports an array with a fixed sequence of items as a struct with tuple representation.
+// JSON Schema doesn't name the items, so the fields are named after their position.
+// Items beyond "minItems" are optional.
+func (imp *importer) tuple(name string, s, prefixItems datamodel.Node) (schemadmt.TypeDefn, error) {
+	minItems := int64(-1)
+	if v := lookup(s, "minItems"); v != nil {
+		minItems, _ = v.AsInt()
+	}
+	fields := schemadmt.Map__FieldName__StructField{Values: make(map[string]schemadmt.StructField)}
+	err := eachItem(prefixItems, func(item datamodel.Node) error {
+		i := int64(len(fields.Keys))
+		fname := "field" + strconv.FormatInt(i, 10)
+		t, nullable, err := imp.typeOf(item, name+"_"+fname)
+		if err != nil {
+			return err
+		}
+		fields.Keys = append(fields.Keys, fname)
+		fields.Values[fname] = schemadmt.StructField{
+			Type:     t,
+			Optional: boolPtr(minItems >= 0 && i >= minItems),
+			Nullable: boolPtr(nullable),
+		}
+		return nil
+	})
+	if err != nil {
+		return schemadmt.TypeDefn{}, err
+	}
+	return schemadmt.TypeDefn{TypeDefnStruct: &schemadmt.TypeDefnStruct{
+		Fields: fields,
+		Representation: schemadmt.StructRepresentation{
+			StructRepresentation_Tuple: &schemadmt.StructRepresentation_Tuple{},
+		},
+	}}, nil
+}
+
+// union imports a choice between several schemas as a union, if it looks like one.
+// Choices between objects with a single property each are keyed unions;
+// choices between objects which all have a property with a constant value are inline unions;
+// and choices between schemas which all allow different kinds of data are kinded unions.
+// Any other choice is imported as Any.
+func (imp *importer) union(name string, alts []datamodel.Node) (schemadmt.TypeDefn, error) {
+	switch {
+	case imp.isKeyed(alts):
+		table := schemadmt.UnionRepresentation_Keyed{Values: make(map[string]schemadmt.UnionMember)}
+		var members schemadmt.List__UnionMember
+		for _, alt := range alts {
+			props := lookup(alt, "properties")
+			itr := props.MapIterator()
+			k, v, err := itr.Next()
+			if err != nil {
+				return schemadmt.TypeDefn{}, err
+			}
+			key, _ := k.AsString()
+			mname, err := imp.namedTypeOf(v, name+"_"+key)
+			if err != nil {
+				return schemadmt.TypeDefn{}, err
+			}
+			member := schemadmt.UnionMember{TypeName: &mname}
+			members = append(members, member)
+			table.Keys = append(table.Keys, key)
+			table.Values[key] = member
+		}
+		return schemadmt.TypeDefn{TypeDefnUnion: &schemadmt.TypeDefnUnion{
+			Members:        members,
+			Representation: schemadmt.UnionRepresentation{UnionRepresentation_Keyed: &table},
+		}}, nil
+	case imp.discriminantKey(alts) != "":
+		key := imp.discriminantKey(alts)
+		table := schemadmt.UnionRepresentation_Inline{
+			DiscriminantKey:   key,
+			DiscriminantTable: schemadmt.Map__String__TypeName{Values: make(map[string]string)},
+		}
+		var members schemadmt.List__UnionMember
+		for _, alt := range alts {
+			props := lookup(alt, "properties")
+			d, _ := props.LookupByString(key)
+			discriminant := lookupString(d, "const")
+			mname := imp.unusedName(name + "_" + discriminant)
+			defn, err := imp.structMap(mname, alt, props, key)
+			if err != nil {
+				return schemadmt.TypeDefn{}, err
+			}
+			imp.add(mname, defn)
+			members = append(members, schemadmt.UnionMember{TypeName: &mname})
+			table.DiscriminantTable.Keys = append(table.DiscriminantTable.Keys, discriminant)
+			table.DiscriminantTable.Values[discriminant] = mname
+		}
+		return schemadmt.TypeDefn{TypeDefnUnion: &schemadmt.TypeDefnUnion{
+			Members:        members,
+			Representation: schemadmt.UnionRepresentation{UnionRepresentation_Inline: &table},
+		}}, nil
+	case imp.isKinded(alts):
+		table := schemadmt.UnionRepresentation_Kinded{Values: make(map[string]schemadmt.UnionMember)}
+		var members schemadmt.List__UnionMember
+		for _, alt := range alts {
+			kind := imp.kindOf(alt, 0)
+			mname, err := imp.namedTypeOf(alt, name+"_"+kind)
+			if err != nil {
+				return schemadmt.TypeDefn{}, err
+			}
+			member := schemadmt.UnionMember{TypeName: &mname}
+			members = append(members, member)
+			table.Keys = append(table.Keys, kind)
+			table.Values[kind] = member
+		}
+		return schemadmt.TypeDefn{TypeDefnUnion: &schemadmt.TypeDefnUnion{
+			Members:        members,
+			Representation: schemadmt.UnionRepresentation{UnionRepresentation_Kinded: &table},
+		}}, nil
+	default:
+		return schemadmt.TypeDefn{TypeDefnAny: &schemadmt.TypeDefnAny{}}, nil
+	}
+}
+
+func (imp *importer) isKeyed(alts []datamodel.Node) bool {
+	seen := make(map[string]bool)
+	for _, alt := range alts {
+		props := lookup(alt, "properties")
+		if jsonType(alt) != "object" || props == nil || props.Length() != 1 {
+			return false
+		}
+		if ok, _ := isSlashObject(alt); ok {
+			return false
+		}
+		k, _, err := props.MapIterator().Next()
+		if err != nil {
+			return false
+		}
+		key, _ := k.AsString()
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
+
+// discriminantKey returns the name of a property which every alternative has, with a different constant string value in each.
+func (imp *importer) discriminantKey(alts []datamodel.Node) string {
+	first := lookup(alts[0], "properties")
+	if first == nil {
+		return ""
+	}
+	var found string
+	eachEntry(first, func(key string, _ datamodel.Node) error {
+		seen := make(map[string]bool)
+		for _, alt := range alts {
+			props := lookup(alt, "properties")
+			if jsonType(alt) != "object" || props == nil {
+				return nil
+			}
+			d, err := props.LookupByString(key)
+			if err != nil {
+				return nil
+			}
+			discriminant := lookupString(d, "const")
+			if discriminant == "" || seen[discriminant] {
+				return nil
+			}
+			seen[discriminant] = true
+		}
+		if found == "" {
+			found = key
+		}
+		return nil
+	})
+	return found
+}
+
+func (imp *importer) isKinded(alts []datamodel.Node) bool {
+	seen := make(map[string]bool)
+	for _, alt := range alts {
+		kind := imp.kindOf(alt, 0)
+		if kind == "" || seen[kind] {
+			return false
+		}
+		seen[kind] = true
+	}
+	return true
+}
+
+// kindOf returns the name of the one Data Model kind a schema allows, if it allows exactly one.
+func (imp *importer) kindOf(s datamodel.Node, depth int) string {
+	if ref, ok := refName(s); ok {
+		target, ok := imp.defs[ref]
+		if !ok || depth > len(imp.defs) {
+			return ""
+		}
+		return imp.kindOf(target, depth+1)
+	}
+	switch jsonType(s) {
+	case "boolean":
+		return "bool"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "string":
+		return "string"
+	case "array":
+		return "list"
+	case "object":
+		if ok, isLink := isSlashObject(s); ok {
+			if isLink {
+				return "link"
+			}
+			return "bytes"
+		}
+		return "map"
+	}
+	return ""
+}
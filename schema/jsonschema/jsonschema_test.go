@@ -0,0 +1,326 @@
+package jsonschema_test
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/codec/json"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/must"
+	"github.com/ipld/go-ipld-prime/schema"
+	schemadmt "github.com/ipld/go-ipld-prime/schema/dmt"
+	"github.com/ipld/go-ipld-prime/schema/jsonschema"
+)
+
+// encode produces compact JSON, with maps in the order they were built in.
+func encode(t *testing.T, n datamodel.Node) string {
+	t.Helper()
+	var buf bytes.Buffer
+	err := dagjson.EncodeOptions{MapSortMode: codec.MapSortMode_None}.Encode(n, &buf)
+	qt.Assert(t, err, qt.IsNil)
+	return buf.String()
+}
+
+func lookup(t *testing.T, n datamodel.Node, path string) datamodel.Node {
+	t.Helper()
+	for _, seg := range strings.Split(path, "/") {
+		var err error
+		n, err = n.LookupByString(seg)
+		qt.Assert(t, err, qt.IsNil, qt.Commentf("%s", path))
+	}
+	return n
+}
+
+func TestExport(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes([]byte(`
+		type Person struct {
+			name String
+			age optional Int
+			nick nullable String
+			role Role (rename "r")
+			tags [String]
+		}
+		type Role enum {
+			| Admin ("admin")
+			| User
+		}
+		type Point struct {
+			x Int
+			y Int
+		} representation tuple
+		type Pet union {
+			| Cat "cat"
+			| Dog "dog"
+		} representation keyed
+		type Shape union {
+			| Cat "cat"
+			| Dog "dog"
+		} representation inline {
+			discriminantKey "kind"
+		}
+		type Cat struct {
+			lives Int
+		}
+		type Dog struct {}
+		type IntOrString union {
+			| Int int
+			| String string
+		} representation kinded
+	`))
+	qt.Assert(t, err, qt.IsNil)
+
+	doc, err := jsonschema.Export(ts, "Person")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, encode(t, lookup(t, doc, "$ref")), qt.Equals, `"#/$defs/Person"`)
+
+	for _, tc := range []struct{ path, json string }{
+		{"String", `{"type":"string"}`},
+		{"Link", `{"type":"object","properties":{"/":{"type":"string"}},"required":["/"],"additionalProperties":false}`},
+		{"Person", `{"type":"object","properties":{` +
+			`"name":{"$ref":"#/$defs/String"},` +
+			`"age":{"$ref":"#/$defs/Int"},` +
+			`"nick":{"anyOf":[{"$ref":"#/$defs/String"},{"type":"null"}]},` +
+			`"r":{"$ref":"#/$defs/Role"},` +
+			`"tags":{"$ref":"#/$defs/List__String"}` +
+			`},"required":["name","nick","r","tags"],"additionalProperties":false}`},
+		{"List__String", `{"type":"array","items":{"$ref":"#/$defs/String"}}`},
+		{"Role", `{"type":"string","enum":["admin","User"]}`},
+		{"Point", `{"type":"array","prefixItems":[{"$ref":"#/$defs/Int"},{"$ref":"#/$defs/Int"}],"items":false,"minItems":2}`},
+		{"Pet/oneOf/0", `{"type":"object","properties":{"cat":{"$ref":"#/$defs/Cat"}},"required":["cat"],"additionalProperties":false}`},
+		{"Shape/oneOf/0", `{"type":"object","properties":{"kind":{"const":"cat"},"lives":{"$ref":"#/$defs/Int"}},"required":["kind","lives"],"additionalProperties":false}`},
+		{"Shape/oneOf/1", `{"type":"object","properties":{"kind":{"const":"dog"}},"required":["kind"],"additionalProperties":false}`},
+		{"IntOrString", `{"anyOf":[{"$ref":"#/$defs/Int"},{"$ref":"#/$defs/String"}]}`},
+	} {
+		n := lookup(t, doc, "$defs")
+		for _, seg := range strings.Split(tc.path, "/") {
+			if n.Kind() == datamodel.Kind_List {
+				i, _ := strconv.Atoi(seg)
+				n, err = n.LookupByIndex(int64(i))
+				qt.Assert(t, err, qt.IsNil)
+				continue
+			}
+			n = lookup(t, n, seg)
+		}
+		qt.Check(t, encode(t, n), qt.Equals, tc.json, qt.Commentf("%s", tc.path))
+	}
+
+	_, err = jsonschema.Export(ts, "Nope")
+	qt.Check(t, err, qt.ErrorMatches, `jsonschema: no type named "Nope"`)
+}
+
+// validates is just enough of a JSON Schema validator to check which instances a union accepts:
+// it understands "$ref" (to the document's "$defs"), "type", "pattern", "oneOf" and "anyOf", and ignores everything else.
+func validates(t *testing.T, doc, s, instance datamodel.Node) bool {
+	t.Helper()
+	if r, err := s.LookupByString("$ref"); err == nil {
+		name := strings.TrimPrefix(must.String(r), "#/$defs/")
+		return validates(t, doc, lookup(t, doc, "$defs/"+name), instance)
+	}
+	if typ, err := s.LookupByString("type"); err == nil {
+		var ok bool
+		switch must.String(typ) {
+		case "integer":
+			ok = instance.Kind() == datamodel.Kind_Int
+		case "number":
+			ok = instance.Kind() == datamodel.Kind_Int || instance.Kind() == datamodel.Kind_Float
+		case "string":
+			ok = instance.Kind() == datamodel.Kind_String
+		default:
+			t.Fatalf("validates doesn't understand type %q", must.String(typ))
+		}
+		if !ok {
+			return false
+		}
+	}
+	if pattern, err := s.LookupByString("pattern"); err == nil {
+		if !regexp.MustCompile(must.String(pattern)).MatchString(must.String(instance)) {
+			return false
+		}
+	}
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		alts, err := s.LookupByString(keyword)
+		if err != nil {
+			continue
+		}
+		matched := 0
+		for itr := alts.ListIterator(); !itr.Done(); {
+			_, alt, err := itr.Next()
+			qt.Assert(t, err, qt.IsNil)
+			if validates(t, doc, alt, instance) {
+				matched++
+			}
+		}
+		if matched == 0 || keyword == "oneOf" && matched > 1 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestExportOverlappingUnions(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes([]byte(`
+		type Number union {
+			| Int int
+			| Float float
+		} representation kinded
+		type Prefixed union {
+			| Int "a"
+			| Float "ab"
+		} representation stringprefix
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	doc, err := jsonschema.Export(ts, "")
+	qt.Assert(t, err, qt.IsNil)
+
+	for _, tc := range []struct {
+		typ      string
+		instance string
+		valid    bool
+	}{
+		{"Number", `1`, true}, // an integer is also a number
+		{"Number", `1.5`, true},
+		{"Number", `"1"`, false},
+		{"Prefixed", `"a1"`, true},
+		{"Prefixed", `"ab1.5"`, true}, // has both prefixes
+		{"Prefixed", `"b"`, false},
+	} {
+		instance, err := ipld.Decode([]byte(tc.instance), json.Decode)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, validates(t, doc, lookup(t, doc, "$defs/"+tc.typ), instance), qt.Equals, tc.valid,
+			qt.Commentf("%s %s", tc.typ, tc.instance))
+	}
+}
+
+func TestImport(t *testing.T) {
+	doc, err := ipld.Decode([]byte(`{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": ["integer", "null"]},
+			"address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}},
+				"required": ["city"]
+			},
+			"scores": {"type": "object", "additionalProperties": {"type": "number"}},
+			"status": {"$ref": "#/definitions/Status"},
+			"shape": {"$ref": "#/$defs/Shape"},
+			"id": {"$ref": "#/$defs/Id"}
+		},
+		"required": ["name", "age", "status"],
+		"definitions": {
+			"Status": {"type": "string", "enum": ["active", "gone"]}
+		},
+		"$defs": {
+			"Shape": {"oneOf": [
+				{"type": "object", "properties": {"kind": {"const": "circle"}, "radius": {"type": "number"}}},
+				{"type": "object", "properties": {"kind": {"const": "square"}, "side": {"type": "number"}}}
+			]},
+			"Id": {"oneOf": [{"type": "integer"}, {"type": "string"}]},
+			"Point": {"type": "array", "prefixItems": [{"type": "integer"}, {"type": "integer"}], "minItems": 2},
+			"Misc": {"not": {"type": "string"}}
+		}
+	}`), json.Decode)
+	qt.Assert(t, err, qt.IsNil)
+
+	sch, err := jsonschema.Import(doc, "Person")
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, sch.Types.Keys, qt.DeepEquals, []string{
+		"Shape_circle", "Shape_square", "Shape", "Id", "Point", "Misc", "Status", "Person_address", "Person",
+	})
+
+	ts := new(schema.TypeSystem)
+	ts.Init()
+	qt.Assert(t, schemadmt.Compile(ts, sch), qt.IsNil)
+
+	person := ts.TypeByName("Person").(*schema.TypeStruct)
+	qt.Check(t, person.Field("name").IsOptional(), qt.IsFalse)
+	qt.Check(t, person.Field("age").IsNullable(), qt.IsTrue)
+	qt.Check(t, person.Field("age").Type().Name(), qt.Equals, "Int")
+	qt.Check(t, person.Field("address").IsOptional(), qt.IsTrue)
+	qt.Check(t, person.Field("address").Type().Name(), qt.Equals, "Person_address")
+	qt.Check(t, person.Field("scores").Type().Name(), qt.Equals, "Map__String__Float")
+	qt.Check(t, person.Field("status").Type().TypeKind(), qt.Equals, schema.TypeKind_Enum)
+
+	shape := ts.TypeByName("Shape").(*schema.TypeUnion)
+	stg := shape.RepresentationStrategy().(schema.UnionRepresentation_Inline)
+	qt.Check(t, stg.GetDiscriminantKey(), qt.Equals, "kind")
+	qt.Check(t, stg.GetMember("square"), qt.Equals, "Shape_square")
+
+	id := ts.TypeByName("Id").(*schema.TypeUnion)
+	kinded := id.RepresentationStrategy().(schema.UnionRepresentation_Kinded)
+	qt.Check(t, kinded.GetMember(datamodel.Kind_Int), qt.Equals, "Int")
+	qt.Check(t, kinded.GetMember(datamodel.Kind_String), qt.Equals, "String")
+
+	point := ts.TypeByName("Point").(*schema.TypeStruct)
+	tuple, ok := point.RepresentationStrategy().(schema.StructRepresentation_Tuple)
+	qt.Assert(t, ok, qt.IsTrue)
+	qt.Check(t, tuple.FieldOrder(), qt.IsNil)
+	qt.Check(t, point.Fields(), qt.HasLen, 2)
+
+	qt.Check(t, ts.TypeByName("Misc").TypeKind(), qt.Equals, schema.TypeKind_Any)
+}
+
+// TestRoundtrip checks that exporting a schema and importing the result gives back types of the same shape.
+func TestRoundtrip(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes([]byte(`
+		type Person struct {
+			name String
+			age optional Int
+			nick nullable String
+			tags [String]
+			pet Pet
+		}
+		type Pet union {
+			| Cat "cat"
+			| Dog "dog"
+		} representation keyed
+		type Cat struct {
+			lives Int
+		}
+		type Dog struct {
+			good Bool
+		}
+		type Color enum {
+			| Red ("red")
+			| Blue ("blue")
+		}
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	doc, err := jsonschema.Export(ts, "")
+	qt.Assert(t, err, qt.IsNil)
+
+	// Go through the codec too, as a user would.
+	var buf bytes.Buffer
+	qt.Assert(t, json.Encode(doc, &buf), qt.IsNil)
+	doc, err = ipld.Decode(buf.Bytes(), json.Decode)
+	qt.Assert(t, err, qt.IsNil)
+
+	sch, err := jsonschema.Import(doc, "")
+	qt.Assert(t, err, qt.IsNil)
+	ts2 := new(schema.TypeSystem)
+	ts2.Init()
+	qt.Assert(t, schemadmt.Compile(ts2, sch), qt.IsNil)
+
+	for _, name := range ts.Names() {
+		t1, t2 := ts.TypeByName(name), ts2.TypeByName(name)
+		qt.Assert(t, t2, qt.Not(qt.IsNil), qt.Commentf("%s", name))
+		qt.Check(t, t2.TypeKind(), qt.Equals, t1.TypeKind(), qt.Commentf("%s", name))
+	}
+	person := ts2.TypeByName("Person").(*schema.TypeStruct)
+	qt.Check(t, person.Field("age").IsOptional(), qt.IsTrue)
+	qt.Check(t, person.Field("nick").IsNullable(), qt.IsTrue)
+	qt.Check(t, person.Field("pet").Type().Name(), qt.Equals, "Pet")
+	keyed := ts2.TypeByName("Pet").(*schema.TypeUnion).RepresentationStrategy().(schema.UnionRepresentation_Keyed)
+	qt.Check(t, keyed.GetDiscriminant(ts2.TypeByName("Cat")), qt.Equals, "cat")
+	qt.Check(t, keyed.GetDiscriminant(ts2.TypeByName("Dog")), qt.Equals, "dog")
+}
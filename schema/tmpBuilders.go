@@ -2,6 +2,7 @@ package schema
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ipld/go-ipld-prime/datamodel"
 )
@@ -64,7 +65,13 @@ func SpawnFloat(name TypeName) *TypeFloat {
 }
 
 func SpawnBytes(name TypeName) *TypeBytes {
-	return &TypeBytes{typeBase{name, nil}}
+	return &TypeBytes{typeBase{name, nil}, ""}
+}
+
+// SpawnBytesAdvanced is like SpawnBytes, but for a type whose representation
+// is the advanced data layout with the given name, as in `type Foo bytes representation advanced Bar`.
+func SpawnBytesAdvanced(name TypeName, adl string) *TypeBytes {
+	return &TypeBytes{typeBase{name, nil}, adl}
 }
 
 func SpawnLink(name TypeName) *TypeLink {
@@ -76,11 +83,43 @@ func SpawnLinkReference(name TypeName, pointsTo TypeName) *TypeLink {
 }
 
 func SpawnList(name TypeName, valueType TypeName, nullable bool) *TypeList {
-	return &TypeList{typeBase{name, nil}, false, valueType, nullable}
+	return &TypeList{typeBase{name, nil}, false, valueType, nullable, ""}
+}
+
+// SpawnListAdvanced is like SpawnList, but for a type whose representation
+// is the advanced data layout with the given name, as in `type Foo [Int] representation advanced Bar`.
+func SpawnListAdvanced(name TypeName, valueType TypeName, nullable bool, adl string) *TypeList {
+	return &TypeList{typeBase{name, nil}, false, valueType, nullable, adl}
 }
 
 func SpawnMap(name TypeName, keyType TypeName, valueType TypeName, nullable bool) *TypeMap {
-	return &TypeMap{typeBase{name, nil}, false, keyType, valueType, nullable}
+	return &TypeMap{typeBase{name, nil}, false, keyType, valueType, nullable, MapRepresentation_Map{}, ""}
+}
+
+// SpawnMapWithRepresentation is like SpawnMap, but for a type with a representation strategy other than the default,
+// as in `type Foo {String:String} representation stringpairs`.
+func SpawnMapWithRepresentation(name TypeName, keyType TypeName, valueType TypeName, nullable bool, repr MapRepresentation) *TypeMap {
+	switch repr.(type) {
+	case MapRepresentation_StringPairs:
+		if nullable {
+			panic("nullable values are not supported on map stringpairs representation")
+		}
+	case nil:
+		repr = MapRepresentation_Map{}
+	}
+	return &TypeMap{typeBase{name, nil}, false, keyType, valueType, nullable, repr, ""}
+}
+func SpawnMapRepresentationStringPairs(innerDelim, entryDelim string) MapRepresentation_StringPairs {
+	return MapRepresentation_StringPairs{innerDelim, entryDelim}
+}
+func SpawnMapRepresentationListPairs() MapRepresentation_ListPairs {
+	return MapRepresentation_ListPairs{}
+}
+
+// SpawnMapAdvanced is like SpawnMap, but for a type whose representation
+// is the advanced data layout with the given name, as in `type Foo {String:Int} representation advanced Bar`.
+func SpawnMapAdvanced(name TypeName, keyType TypeName, valueType TypeName, nullable bool, adl string) *TypeMap {
+	return &TypeMap{typeBase{name, nil}, false, keyType, valueType, nullable, MapRepresentation_Map{}, adl}
 }
 
 func SpawnAny(name TypeName) *TypeAny {
@@ -93,18 +132,48 @@ func SpawnStruct(name TypeName, fields []StructField, repr StructRepresentation)
 		fields,
 		make(map[string]StructField, len(fields)),
 		repr,
+		nil,
 	}
 	for i := range fields {
 		fields[i].parent = v
 		v.fieldsMap[fields[i].name] = fields[i]
 	}
-	switch repr.(type) {
+	switch repr := repr.(type) {
+	case StructRepresentation_Tuple:
+		v.tupleIndexes = make([]int, len(fields))
+		if repr.fieldOrder == nil {
+			for i := range fields {
+				v.tupleIndexes[i] = i
+			}
+			break
+		}
+		if len(repr.fieldOrder) != len(fields) {
+			panic("struct tuple representation field order must list every field exactly once")
+		}
+		indexes := make(map[string]int, len(fields))
+		for i := range fields {
+			indexes[fields[i].name] = i
+		}
+		for i, name := range repr.fieldOrder {
+			idx, ok := indexes[name]
+			if !ok {
+				panic("struct tuple representation field order must list every field exactly once")
+			}
+			delete(indexes, name) // so a repeated name is caught.
+			v.tupleIndexes[i] = idx
+		}
 	case StructRepresentation_Stringjoin:
 		for _, f := range fields {
 			if f.IsMaybe() {
 				panic("neither nullable nor optional is supported on struct stringjoin representation")
 			}
 		}
+	case StructRepresentation_StringPairs:
+		for _, f := range fields {
+			if f.IsMaybe() {
+				panic("neither nullable nor optional is supported on struct stringpairs representation")
+			}
+		}
 	case nil:
 		v.representation = SpawnStructRepresentationMap(nil)
 	}
@@ -122,12 +191,23 @@ func SpawnStructRepresentationMap2(renames map[string]string, implicits map[stri
 func SpawnStructRepresentationTuple() StructRepresentation_Tuple {
 	return StructRepresentation_Tuple{}
 }
+
+// SpawnStructRepresentationTupleWithFieldOrder is like SpawnStructRepresentationTuple,
+// but the fields appear in the list in the given order rather than the order they're declared in,
+// as in `representation tuple { fieldOrder ["b", "a"] }`.
+// The order must list every field of the struct exactly once.
+func SpawnStructRepresentationTupleWithFieldOrder(fieldOrder []string) StructRepresentation_Tuple {
+	return StructRepresentation_Tuple{fieldOrder}
+}
 func SpawnStructRepresentationListPairs() StructRepresentation_ListPairs {
 	return StructRepresentation_ListPairs{}
 }
 func SpawnStructRepresentationStringjoin(delim string) StructRepresentation_Stringjoin {
 	return StructRepresentation_Stringjoin{delim}
 }
+func SpawnStructRepresentationStringPairs(innerDelim, entryDelim string) StructRepresentation_StringPairs {
+	return StructRepresentation_StringPairs{innerDelim, entryDelim}
+}
 
 func SpawnUnion(name TypeName, members []TypeName, repr UnionRepresentation) *TypeUnion {
 	return &TypeUnion{typeBase{name, nil}, members, repr}
@@ -141,6 +221,19 @@ func SpawnUnionRepresentationKinded(table map[datamodel.Kind]TypeName) UnionRepr
 func SpawnUnionRepresentationStringprefix(delim string, table map[string]TypeName) UnionRepresentation_Stringprefix {
 	return UnionRepresentation_Stringprefix{delim, table}
 }
+func SpawnUnionRepresentationEnvelope(discriminantKey string, contentKey string, table map[string]TypeName) UnionRepresentation_Envelope {
+	return UnionRepresentation_Envelope{discriminantKey, contentKey, table}
+}
+
+// SpawnUnionRepresentationBytesPrefix expects the keys of the table to be hex strings;
+// they are normalized to lowercase.
+func SpawnUnionRepresentationBytesPrefix(table map[string]TypeName) UnionRepresentation_BytesPrefix {
+	lower := make(map[string]TypeName, len(table))
+	for k, v := range table {
+		lower[strings.ToLower(k)] = v
+	}
+	return UnionRepresentation_BytesPrefix{lower}
+}
 func SpawnUnionRepresentationInline(discriminantKey string, table map[string]TypeName) UnionRepresentation_Inline {
 	return UnionRepresentation_Inline{discriminantKey, table}
 }
@@ -177,7 +270,7 @@ func Clone(typ Type) Type {
 	case *TypeString:
 		return SpawnString(kindedType.Name())
 	case *TypeBytes:
-		return SpawnBytes(kindedType.Name())
+		return SpawnBytesAdvanced(kindedType.Name(), kindedType.AdvancedLayout())
 	case *TypeInt:
 		return SpawnInt(kindedType.Name())
 	case *TypeFloat:
@@ -185,12 +278,20 @@ func Clone(typ Type) Type {
 	case *TypeAny:
 		return SpawnAny(kindedType.Name())
 	case *TypeMap:
-		return SpawnMap(kindedType.Name(),
+		if adl := kindedType.AdvancedLayout(); adl != "" {
+			return SpawnMapAdvanced(kindedType.Name(),
+				kindedType.KeyType().Name(),
+				kindedType.ValueType().Name(),
+				kindedType.ValueIsNullable(),
+				adl)
+		}
+		return SpawnMapWithRepresentation(kindedType.Name(),
 			kindedType.KeyType().Name(),
 			kindedType.ValueType().Name(),
-			kindedType.ValueIsNullable())
+			kindedType.ValueIsNullable(),
+			kindedType.RepresentationStrategy())
 	case *TypeList:
-		return SpawnList(kindedType.Name(), kindedType.ValueType().Name(), kindedType.ValueIsNullable())
+		return SpawnListAdvanced(kindedType.Name(), kindedType.ValueType().Name(), kindedType.ValueIsNullable(), kindedType.AdvancedLayout())
 	case *TypeLink:
 		if kindedType.HasReferencedType() {
 			return SpawnLinkReference(kindedType.Name(), kindedType.ReferencedType().Name())
@@ -219,6 +320,12 @@ func Clone(typ Type) Type {
 }
 
 func MergeTypeSystem(target *TypeSystem, source *TypeSystem, ignoreDups bool) {
+	for _, adl := range source.AdvancedLayoutNames() {
+		if ignoreDups && target.HasAdvancedLayout(adl) {
+			continue
+		}
+		target.AccumulateAdvancedLayout(adl)
+	}
 	for _, name := range source.Names() {
 		typ := Clone(source.TypeByName(name))
 		if ignoreDups {
@@ -250,6 +357,27 @@ func (ts *TypeSystem) Accumulate(typ Type) {
 	ts.namedTypes[name] = typ
 	ts.names = append(ts.names, name)
 }
+
+// AccumulateAdvancedLayout declares an advanced data layout, as in `advanced Foo`,
+// so that types with an advanced representation may refer to it by name.
+// As with Accumulate, declaring the same name twice panics.
+func (ts *TypeSystem) AccumulateAdvancedLayout(name string) {
+	if ts.HasAdvancedLayout(name) {
+		panic(fmt.Sprintf("duplicate advanced layout name: %s", name))
+	}
+	ts.advancedLayouts = append(ts.advancedLayouts, name)
+}
+func (ts TypeSystem) HasAdvancedLayout(name string) bool {
+	for _, adl := range ts.advancedLayouts {
+		if adl == name {
+			return true
+		}
+	}
+	return false
+}
+func (ts TypeSystem) AdvancedLayoutNames() []string {
+	return ts.advancedLayouts
+}
 func (ts TypeSystem) GetTypes() map[TypeName]Type {
 	return ts.namedTypes
 }
@@ -268,46 +396,56 @@ func (ts TypeSystem) Names() []TypeName {
 // and are roadmapped for after we research self-hosting)).
 func (ts TypeSystem) ValidateGraph() []error {
 	var ee []error
-	for tn, t := range ts.namedTypes {
-		switch t2 := t.(type) {
+	for _, tn := range ts.names {
+		switch t2 := ts.namedTypes[tn].(type) {
 		case *TypeBool,
 			*TypeInt,
 			*TypeFloat,
 			*TypeString,
-			*TypeBytes,
 			*TypeEnum:
 			continue // nothing to check: these are leaf nodes and refer to no other types.
+		case *TypeBytes:
+			ee = ts.validateAdvancedLayout(ee, tn, t2.advancedLayout)
 		case *TypeLink:
 			if !t2.hasReferencedType {
 				continue
 			}
 			if _, ok := ts.namedTypes[t2.referencedType]; !ok {
-				ee = append(ee, fmt.Errorf("type %s refers to missing type %s (as link reference type)", tn, t2.referencedType))
+				ee = append(ee, ErrMissingType{TypeName: tn, Missing: t2.referencedType, Context: "as link reference type"})
 			}
 		case *TypeStruct:
 			for _, f := range t2.fields {
 				if _, ok := ts.namedTypes[f.typ]; !ok {
-					ee = append(ee, fmt.Errorf("type %s refers to missing type %s (in field %q)", tn, f.typ, f.name))
+					ee = append(ee, ErrMissingType{TypeName: tn, Missing: f.typ, Context: fmt.Sprintf("in field %q", f.name)})
 				}
 			}
 		case *TypeMap:
 			if _, ok := ts.namedTypes[t2.keyType]; !ok {
-				ee = append(ee, fmt.Errorf("type %s refers to missing type %s (as key type)", tn, t2.keyType))
+				ee = append(ee, ErrMissingType{TypeName: tn, Missing: t2.keyType, Context: "as key type"})
 			}
 			if _, ok := ts.namedTypes[t2.valueType]; !ok {
-				ee = append(ee, fmt.Errorf("type %s refers to missing type %s (as value type)", tn, t2.valueType))
+				ee = append(ee, ErrMissingType{TypeName: tn, Missing: t2.valueType, Context: "as value type"})
 			}
+			ee = ts.validateAdvancedLayout(ee, tn, t2.advancedLayout)
 		case *TypeList:
 			if _, ok := ts.namedTypes[t2.valueType]; !ok {
-				ee = append(ee, fmt.Errorf("type %s refers to missing type %s (as value type)", tn, t2.valueType))
+				ee = append(ee, ErrMissingType{TypeName: tn, Missing: t2.valueType, Context: "as value type"})
 			}
+			ee = ts.validateAdvancedLayout(ee, tn, t2.advancedLayout)
 		case *TypeUnion:
 			for _, mn := range t2.members {
 				if _, ok := ts.namedTypes[mn]; !ok {
-					ee = append(ee, fmt.Errorf("type %s refers to missing type %s (as a member)", tn, mn))
+					ee = append(ee, ErrMissingType{TypeName: tn, Missing: mn, Context: "as a member"})
 				}
 			}
 		}
 	}
 	return ee
 }
+
+func (ts TypeSystem) validateAdvancedLayout(ee []error, tn TypeName, adl string) []error {
+	if adl != "" && !ts.HasAdvancedLayout(adl) {
+		ee = append(ee, ErrMissingType{TypeName: tn, Missing: adl, Context: "as representation", AdvancedLayout: true})
+	}
+	return ee
+}
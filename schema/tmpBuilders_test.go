@@ -0,0 +1,54 @@
+package schema_test
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func TestCloneMapRepresentation(t *testing.T) {
+	var source schema.TypeSystem
+	source.Init()
+	source.Accumulate(schema.SpawnString("String"))
+	source.Accumulate(schema.SpawnMapWithRepresentation("Pairs", "String", "String", false,
+		schema.SpawnMapRepresentationStringPairs("=", "&")))
+	source.Accumulate(schema.SpawnMapWithRepresentation("Entries", "String", "String", true,
+		schema.SpawnMapRepresentationListPairs()))
+	source.AccumulateAdvancedLayout("ADL")
+	source.Accumulate(schema.SpawnMapAdvanced("Big", "String", "String", false, "ADL"))
+	qt.Assert(t, source.ValidateGraph(), qt.IsNil)
+
+	check := func(t *testing.T, ts *schema.TypeSystem) {
+		pairs := ts.TypeByName("Pairs").(*schema.TypeMap)
+		stg, ok := pairs.RepresentationStrategy().(schema.MapRepresentation_StringPairs)
+		qt.Assert(t, ok, qt.IsTrue, qt.Commentf("%T", pairs.RepresentationStrategy()))
+		qt.Check(t, stg.GetInnerDelim(), qt.Equals, "=")
+		qt.Check(t, stg.GetEntryDelim(), qt.Equals, "&")
+
+		entries := ts.TypeByName("Entries").(*schema.TypeMap)
+		qt.Check(t, entries.RepresentationStrategy(), qt.Equals, schema.MapRepresentation(schema.SpawnMapRepresentationListPairs()))
+		qt.Check(t, entries.ValueIsNullable(), qt.IsTrue)
+
+		qt.Check(t, ts.TypeByName("Big").(*schema.TypeMap).AdvancedLayout(), qt.Equals, "ADL")
+	}
+
+	t.Run("Clone", func(t *testing.T) {
+		var ts schema.TypeSystem
+		ts.Init()
+		ts.AccumulateAdvancedLayout("ADL")
+		for _, name := range source.Names() {
+			ts.Accumulate(schema.Clone(source.TypeByName(name)))
+		}
+		qt.Assert(t, ts.ValidateGraph(), qt.IsNil)
+		check(t, &ts)
+	})
+	t.Run("MergeTypeSystem", func(t *testing.T) {
+		var ts schema.TypeSystem
+		ts.Init()
+		schema.MergeTypeSystem(&ts, &source, false)
+		qt.Assert(t, ts.ValidateGraph(), qt.IsNil)
+		check(t, &ts)
+	})
+}
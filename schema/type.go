@@ -119,6 +119,7 @@ type TypeString struct {
 
 type TypeBytes struct {
 	typeBase
+	advancedLayout string // if set, the name of the ADL this type is represented by.
 }
 
 type TypeInt struct {
@@ -135,17 +136,30 @@ type TypeAny struct {
 
 type TypeMap struct {
 	typeBase
-	anonymous     bool
-	keyType       TypeName // must be Kind==string (e.g. Type==String|Enum).
-	valueType     TypeName
-	valueNullable bool
+	anonymous      bool
+	keyType        TypeName // must be Kind==string (e.g. Type==String|Enum).
+	valueType      TypeName
+	valueNullable  bool
+	representation MapRepresentation
+	advancedLayout string // if set, the name of the ADL this type is represented by.
 }
 
+type MapRepresentation interface{ _MapRepresentation() }
+
+func (MapRepresentation_Map) _MapRepresentation()         {}
+func (MapRepresentation_StringPairs) _MapRepresentation() {}
+func (MapRepresentation_ListPairs) _MapRepresentation()   {}
+
+type MapRepresentation_Map struct{}
+type MapRepresentation_StringPairs struct{ sep1, sep2 string }
+type MapRepresentation_ListPairs struct{}
+
 type TypeList struct {
 	typeBase
-	anonymous     bool
-	valueType     TypeName
-	valueNullable bool
+	anonymous      bool
+	valueType      TypeName
+	valueNullable  bool
+	advancedLayout string // if set, the name of the ADL this type is represented by.
 }
 
 type TypeLink struct {
@@ -173,6 +187,7 @@ func (UnionRepresentation_Kinded) _UnionRepresentation()       {}
 func (UnionRepresentation_Envelope) _UnionRepresentation()     {}
 func (UnionRepresentation_Inline) _UnionRepresentation()       {}
 func (UnionRepresentation_Stringprefix) _UnionRepresentation() {}
+func (UnionRepresentation_BytesPrefix) _UnionRepresentation()  {}
 
 // A bunch of these tables in union representation might be easier to use if flipped;
 //  we almost always index into them by type (since that's what we have an ordered list of);
@@ -186,14 +201,12 @@ type UnionRepresentation_Kinded struct {
 	table map[datamodel.Kind]TypeName
 }
 
-//lint:ignore U1000 implementation TODO
 type UnionRepresentation_Envelope struct {
 	discriminantKey string
 	contentKey      string
 	table           map[string]TypeName // key is user-defined freetext
 }
 
-//lint:ignore U1000 implementation TODO
 type UnionRepresentation_Inline struct {
 	discriminantKey string
 	table           map[string]TypeName // key is user-defined freetext
@@ -202,6 +215,9 @@ type UnionRepresentation_Stringprefix struct {
 	delim string
 	table map[string]TypeName // key is user-defined freetext
 }
+type UnionRepresentation_BytesPrefix struct {
+	table map[string]TypeName // key is a hex string; always normalized to lowercase
+}
 
 type TypeStruct struct {
 	typeBase
@@ -211,6 +227,7 @@ type TypeStruct struct {
 	fields         []StructField
 	fieldsMap      map[string]StructField // same content, indexed for lookup.
 	representation StructRepresentation
+	tupleIndexes   []int // only for the tuple representation: the index in fields of each entry of the list.
 }
 type StructField struct {
 	parent   *TypeStruct
@@ -232,10 +249,10 @@ type StructRepresentation_Map struct {
 	renames   map[string]string
 	implicits map[string]ImplicitValue
 }
-type StructRepresentation_Tuple struct{}
+type StructRepresentation_Tuple struct {
+	fieldOrder []string // nil means the fields appear in the order they're declared in.
+}
 type StructRepresentation_ListPairs struct{}
-
-//lint:ignore U1000 implementation TODO
 type StructRepresentation_StringPairs struct{ sep1, sep2 string }
 type StructRepresentation_Stringjoin struct{ sep string }
 
@@ -264,9 +281,11 @@ func (ImplicitValue_EmptyMap) _ImplicitValue()  {}
 func (ImplicitValue_String) _ImplicitValue()    {}
 func (ImplicitValue_Int) _ImplicitValue()       {}
 func (ImplicitValue_Bool) _ImplicitValue()      {}
+func (ImplicitValue_Float) _ImplicitValue()     {}
 
 type ImplicitValue_EmptyList struct{}
 type ImplicitValue_EmptyMap struct{}
 type ImplicitValue_String string
 type ImplicitValue_Int int
 type ImplicitValue_Bool bool
+type ImplicitValue_Float float64
@@ -30,9 +30,20 @@ func (TypeString) RepresentationBehavior() datamodel.Kind { return datamodel.Kin
 func (TypeBytes) RepresentationBehavior() datamodel.Kind  { return datamodel.Kind_Bytes }
 func (TypeInt) RepresentationBehavior() datamodel.Kind    { return datamodel.Kind_Int }
 func (TypeFloat) RepresentationBehavior() datamodel.Kind  { return datamodel.Kind_Float }
-func (TypeMap) RepresentationBehavior() datamodel.Kind    { return datamodel.Kind_Map }
 func (TypeList) RepresentationBehavior() datamodel.Kind   { return datamodel.Kind_List }
 func (TypeLink) RepresentationBehavior() datamodel.Kind   { return datamodel.Kind_Link }
+func (t TypeMap) RepresentationBehavior() datamodel.Kind {
+	switch t.RepresentationStrategy().(type) {
+	case MapRepresentation_Map:
+		return datamodel.Kind_Map
+	case MapRepresentation_StringPairs:
+		return datamodel.Kind_String
+	case MapRepresentation_ListPairs:
+		return datamodel.Kind_List
+	default:
+		panic("unreachable")
+	}
+}
 func (t TypeUnion) RepresentationBehavior() datamodel.Kind {
 	switch t.representation.(type) {
 	case UnionRepresentation_Keyed:
@@ -45,6 +56,8 @@ func (t TypeUnion) RepresentationBehavior() datamodel.Kind {
 		return datamodel.Kind_Map
 	case UnionRepresentation_Stringprefix:
 		return datamodel.Kind_String
+	case UnionRepresentation_BytesPrefix:
+		return datamodel.Kind_Bytes
 	default:
 		panic("unreachable")
 	}
@@ -66,8 +79,12 @@ func (t TypeStruct) RepresentationBehavior() datamodel.Kind {
 	}
 }
 func (t TypeEnum) RepresentationBehavior() datamodel.Kind {
-	// TODO: this should have a representation strategy switch too; sometimes that will indicate int representation behavior.
-	return datamodel.Kind_String
+	switch t.representation.(type) {
+	case EnumRepresentation_Int:
+		return datamodel.Kind_Int
+	default:
+		return datamodel.Kind_String
+	}
 }
 func (t TypeAny) RepresentationBehavior() datamodel.Kind {
 	return datamodel.Kind_Invalid // TODO: what can we possibly do here?
@@ -90,6 +107,12 @@ func (t TypeAny) RepresentationBehavior() datamodel.Kind {
 // get a self-hosting gen of the schema-schema, not before
 // (the effort of updating template references is substantial).
 
+// AdvancedLayout returns the name of the advanced data layout this type is
+// represented by, or the empty string if it has the regular bytes representation.
+func (t TypeBytes) AdvancedLayout() string {
+	return t.advancedLayout
+}
+
 // IsAnonymous is returns true if the type was unnamed.  Unnamed types will
 // claim to have a Name property like `{Foo:Bar}`, and this is not guaranteed
 // to be a unique string for all types in the universe.
@@ -116,6 +139,29 @@ func (t TypeMap) ValueIsNullable() bool {
 	return t.valueNullable
 }
 
+// RepresentationStrategy returns the representation strategy of the map.
+// Maps with an advanced data layout report the regular map strategy here; see AdvancedLayout.
+func (t TypeMap) RepresentationStrategy() MapRepresentation {
+	if t.representation == nil {
+		return MapRepresentation_Map{}
+	}
+	return t.representation
+}
+
+func (r MapRepresentation_StringPairs) GetInnerDelim() string {
+	return r.sep1
+}
+
+func (r MapRepresentation_StringPairs) GetEntryDelim() string {
+	return r.sep2
+}
+
+// AdvancedLayout returns the name of the advanced data layout this type is
+// represented by, or the empty string if it has the regular map representation.
+func (t TypeMap) AdvancedLayout() string {
+	return t.advancedLayout
+}
+
 // IsAnonymous is returns true if the type was unnamed.  Unnamed types will
 // claim to have a Name property like `[Foo]`, and this is not guaranteed
 // to be a unique string for all types in the universe.
@@ -134,6 +180,30 @@ func (t TypeList) ValueIsNullable() bool {
 	return t.valueNullable
 }
 
+// AdvancedLayout returns the name of the advanced data layout this type is
+// represented by, or the empty string if it has the regular list representation.
+func (t TypeList) AdvancedLayout() string {
+	return t.advancedLayout
+}
+
+// AdvancedLayoutOf returns the name of the advanced data layout a type is
+// represented by, or the empty string if it doesn't have an advanced representation.
+// Only bytes, map, and list types can have one.
+//
+// The data of such a type, as stored, is the ADL's substrate;
+// an implementation of the ADL, such as a linking.NodeReifier, is needed to see it as the type describes.
+func AdvancedLayoutOf(t Type) string {
+	switch t := t.(type) {
+	case *TypeBytes:
+		return t.advancedLayout
+	case *TypeMap:
+		return t.advancedLayout
+	case *TypeList:
+		return t.advancedLayout
+	}
+	return ""
+}
+
 // Members returns the list of all types that are possible inhabitants of this union.
 func (t TypeUnion) Members() []Type {
 	a := make([]Type, len(t.members))
@@ -169,6 +239,58 @@ func (r UnionRepresentation_Stringprefix) GetDiscriminant(t Type) string {
 	panic("that type isn't a member of this union")
 }
 
+func (r UnionRepresentation_Envelope) GetDiscriminantKey() string {
+	return r.discriminantKey
+}
+
+func (r UnionRepresentation_Envelope) GetContentKey() string {
+	return r.contentKey
+}
+
+func (r UnionRepresentation_Envelope) GetDiscriminant(t Type) string {
+	for d, t2 := range r.table {
+		if t2 == t.Name() {
+			return d
+		}
+	}
+	panic("that type isn't a member of this union")
+}
+
+// GetMember returns the name of the member matching the discriminant,
+// or the empty string if the discriminant is not mapped to a member of this union.
+func (r UnionRepresentation_Envelope) GetMember(discriminant string) TypeName {
+	return r.table[discriminant]
+}
+
+func (r UnionRepresentation_Inline) GetDiscriminantKey() string {
+	return r.discriminantKey
+}
+
+func (r UnionRepresentation_Inline) GetDiscriminant(t Type) string {
+	for d, t2 := range r.table {
+		if t2 == t.Name() {
+			return d
+		}
+	}
+	panic("that type isn't a member of this union")
+}
+
+// GetMember returns the name of the member matching the discriminant,
+// or the empty string if the discriminant is not mapped to a member of this union.
+func (r UnionRepresentation_Inline) GetMember(discriminant string) TypeName {
+	return r.table[discriminant]
+}
+
+// GetDiscriminant returns the prefix for the member type, as a lowercase hex string.
+func (r UnionRepresentation_BytesPrefix) GetDiscriminant(t Type) string {
+	for d, t2 := range r.table {
+		if t2 == t.Name() {
+			return d
+		}
+	}
+	panic("that type isn't a member of this union")
+}
+
 // GetMember returns type info for the member matching the kind argument,
 // or may return nil if that kind is not mapped to a member of this union.
 func (r UnionRepresentation_Kinded) GetMember(k datamodel.Kind) TypeName {
@@ -260,10 +382,45 @@ func (r StructRepresentation_Map) FieldImplicit(field StructField) ImplicitValue
 	return r.implicits[field.name]
 }
 
+// FieldOrder returns the names of the fields in the order they appear in the list,
+// or nil if that's the order they're declared in.
+func (r StructRepresentation_Tuple) FieldOrder() []string {
+	return r.fieldOrder
+}
+
+// TupleFieldIndexes returns, for each entry in the list of a struct with a tuple representation,
+// the index in Fields of the field that entry holds.
+// It returns nil if the struct has any other representation.
+func (t TypeStruct) TupleFieldIndexes() []int {
+	return t.tupleIndexes
+}
+
+// TupleFields returns the fields of a struct with a tuple representation
+// in the order they appear in its list, which may differ from the order of Fields.
+// It returns nil if the struct has any other representation.
+func (t TypeStruct) TupleFields() []StructField {
+	if t.tupleIndexes == nil {
+		return nil
+	}
+	fields := make([]StructField, len(t.tupleIndexes))
+	for i, idx := range t.tupleIndexes {
+		fields[i] = t.fields[idx]
+	}
+	return fields
+}
+
 func (r StructRepresentation_Stringjoin) GetDelim() string {
 	return r.sep
 }
 
+func (r StructRepresentation_StringPairs) GetInnerDelim() string {
+	return r.sep1
+}
+
+func (r StructRepresentation_StringPairs) GetEntryDelim() string {
+	return r.sep2
+}
+
 // Members returns a slice the strings which are valid inhabitants of this enum.
 func (t TypeEnum) Members() []string {
 	return t.members
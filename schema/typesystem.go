@@ -15,4 +15,9 @@ type TypeSystem struct {
 	// names are the same set of names stored in namedTypes,
 	// but in insertion order.
 	names []TypeName
+
+	// advancedLayouts are the names of the advanced data layouts declared
+	// in this universe, in the order they were declared.
+	// Types with an advanced representation must refer to one of these.
+	advancedLayouts []string
 }
@@ -1,5 +1,16 @@
 package schema
 
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/mixins"
+)
+
 /*
 	Okay, so.  There are several fun considerations for a "validate" method.
 
@@ -68,3 +79,597 @@ package schema
 	returns *only* errors: only then we can have it in the schema package.
 
 */
+
+// Validate checks whether the Data Model node n matches the representation of the type t,
+// and returns every mismatch it finds, without building any typed nodes.
+// (This is "Option 1" above.)
+// An empty result means the data matches.
+//
+// Each error is an ErrValidation, which says where in n the mismatch was found,
+// and wraps the reason: usually one of datamodel.ErrWrongKind, ErrMissingRequiredField,
+// ErrNoSuchField, ErrNotUnionStructure, or ErrUnmatchable.
+//
+// Validation keeps going after a mismatch wherever it can, but it doesn't guess:
+// if the member of a union can't be determined, nothing beneath the union is checked.
+// Links aren't followed, and data of types with an advanced layout isn't checked at all,
+// since neither is possible without more than the schema to go on.
+func Validate(ts *TypeSystem, t Type, n datamodel.Node) []error {
+	v := validator{ts: ts}
+	v.validate(datamodel.Path{}, t, n)
+	return v.errs
+}
+
+type validator struct {
+	ts   *TypeSystem
+	errs []error
+}
+
+func (v *validator) fail(path datamodel.Path, reason error) {
+	v.errs = append(v.errs, ErrValidation{Path: path, Reason: reason})
+}
+
+// expectKind records a wrong kind error if n isn't of kind k, and returns whether it was.
+func (v *validator) expectKind(path datamodel.Path, t Type, n datamodel.Node, k datamodel.Kind) bool {
+	if n.Kind() == k {
+		return true
+	}
+	v.fail(path, datamodel.ErrWrongKind{TypeName: t.Name(), MethodName: "Validate", AppropriateKind: datamodel.KindSet{k}, ActualKind: n.Kind()})
+	return false
+}
+
+// validateValue is validate for the values of lists, maps, and struct fields, which may be nullable.
+func (v *validator) validateValue(path datamodel.Path, t Type, nullable bool, n datamodel.Node) {
+	if nullable && n.IsNull() {
+		return
+	}
+	v.validate(path, t, n)
+}
+
+func (v *validator) validate(path datamodel.Path, t Type, n datamodel.Node) {
+	if AdvancedLayoutOf(t) != "" {
+		return
+	}
+	switch t2 := t.(type) {
+	case *TypeBool:
+		v.expectKind(path, t, n, datamodel.Kind_Bool)
+	case *TypeString:
+		v.expectKind(path, t, n, datamodel.Kind_String)
+	case *TypeBytes:
+		v.expectKind(path, t, n, datamodel.Kind_Bytes)
+	case *TypeInt:
+		v.expectKind(path, t, n, datamodel.Kind_Int)
+	case *TypeFloat:
+		v.expectKind(path, t, n, datamodel.Kind_Float)
+	case *TypeLink:
+		v.expectKind(path, t, n, datamodel.Kind_Link)
+	case *TypeAny:
+		// Anything goes.
+	case *TypeEnum:
+		v.validateEnum(path, t2, n)
+	case *TypeList:
+		v.validateList(path, t2, n)
+	case *TypeMap:
+		switch stg := t2.RepresentationStrategy().(type) {
+		case MapRepresentation_Map:
+			v.validateMap(path, t2, n)
+		case MapRepresentation_StringPairs:
+			v.validateMapStringPairs(path, t2, stg, n)
+		case MapRepresentation_ListPairs:
+			v.validateMapListPairs(path, t2, n)
+		default:
+			panic("unreachable")
+		}
+	case *TypeStruct:
+		switch stg := t2.RepresentationStrategy().(type) {
+		case StructRepresentation_Map:
+			v.validateStructMap(path, t2, n, "")
+		case StructRepresentation_Tuple:
+			v.validateStructTuple(path, t2, n)
+		case StructRepresentation_ListPairs:
+			v.validateStructListPairs(path, t2, n)
+		case StructRepresentation_StringPairs:
+			v.validateStructStringPairs(path, t2, stg, n)
+		case StructRepresentation_Stringjoin:
+			v.validateStructStringjoin(path, t2, stg, n)
+		default:
+			panic("unreachable")
+		}
+	case *TypeUnion:
+		switch stg := t2.RepresentationStrategy().(type) {
+		case UnionRepresentation_Keyed:
+			v.validateUnionKeyed(path, t2, stg, n)
+		case UnionRepresentation_Kinded:
+			v.validateUnionKinded(path, t2, stg, n)
+		case UnionRepresentation_Envelope:
+			v.validateUnionEnvelope(path, t2, stg, n)
+		case UnionRepresentation_Inline:
+			v.validateUnionInline(path, t2, stg, n)
+		case UnionRepresentation_Stringprefix:
+			v.validateUnionStringprefix(path, t2, stg, n)
+		case UnionRepresentation_BytesPrefix:
+			v.validateUnionBytesPrefix(path, t2, stg, n)
+		default:
+			panic("unreachable")
+		}
+	default:
+		panic(fmt.Sprintf("unknown type kind %T", t))
+	}
+}
+
+func (v *validator) validateEnum(path datamodel.Path, t *TypeEnum, n datamodel.Node) {
+	switch stg := t.RepresentationStrategy().(type) {
+	case EnumRepresentation_String:
+		if !v.expectKind(path, t, n, datamodel.Kind_String) {
+			return
+		}
+		s, _ := n.AsString()
+		for _, member := range t.Members() {
+			serial, ok := stg[member]
+			if !ok {
+				serial = member
+			}
+			if s == serial {
+				return
+			}
+		}
+		v.fail(path, ErrUnmatchable{TypeName: t.Name()}.Reasonf("%q is not a valid member of the enum", s))
+	case EnumRepresentation_Int:
+		if !v.expectKind(path, t, n, datamodel.Kind_Int) {
+			return
+		}
+		i, _ := n.AsInt()
+		for _, member := range t.Members() {
+			if serial, ok := stg[member]; ok && int64(serial) == i {
+				return
+			}
+		}
+		v.fail(path, ErrUnmatchable{TypeName: t.Name()}.Reasonf("%d is not a valid member of the enum", i))
+	default:
+		panic("unreachable")
+	}
+}
+
+func (v *validator) validateList(path datamodel.Path, t *TypeList, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_List) {
+		return
+	}
+	itr := n.ListIterator()
+	for !itr.Done() {
+		idx, val, err := itr.Next()
+		if err != nil {
+			v.fail(path, err)
+			return
+		}
+		v.validateValue(path.AppendSegmentInt(idx), t.ValueType(), t.ValueIsNullable(), val)
+	}
+}
+
+func (v *validator) validateMap(path datamodel.Path, t *TypeMap, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_Map) {
+		return
+	}
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, val, err := itr.Next()
+		if err != nil {
+			v.fail(path, err)
+			return
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			v.fail(path, err)
+			continue
+		}
+		// Key errors are reported at the path of the entry, since keys have no path of their own.
+		v.validate(path.AppendSegmentString(ks), t.KeyType(), k)
+		v.validateValue(path.AppendSegmentString(ks), t.ValueType(), t.ValueIsNullable(), val)
+	}
+}
+
+func (v *validator) validateMapStringPairs(path datamodel.Path, t *TypeMap, stg MapRepresentation_StringPairs, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_String) {
+		return
+	}
+	s, _ := n.AsString()
+	pairs, err := mixins.SplitPairs(s, stg.GetInnerDelim(), stg.GetEntryDelim())
+	if err != nil {
+		v.fail(path, ErrUnmatchable{TypeName: t.Name(), Reason: err})
+		return
+	}
+	seen := make(map[string]struct{}, len(pairs))
+	for _, kv := range pairs {
+		entryPath := path.AppendSegmentString(kv[0])
+		if _, exists := seen[kv[0]]; exists {
+			v.fail(entryPath, datamodel.ErrRepeatedMapKey{Key: basicnode.NewString(kv[0])})
+			continue
+		}
+		seen[kv[0]] = struct{}{}
+		v.validate(entryPath, t.KeyType(), basicnode.NewString(kv[0]))
+		v.validate(entryPath, t.ValueType(), basicnode.NewString(kv[1]))
+	}
+}
+
+func (v *validator) validateMapListPairs(path datamodel.Path, t *TypeMap, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_List) {
+		return
+	}
+	seen := make(map[string]struct{})
+	v.eachListPair(path, t, n, func(entryPath datamodel.Path, k, val datamodel.Node) {
+		ks, err := k.AsString()
+		if err != nil {
+			v.fail(entryPath.AppendSegmentInt(0), err)
+			return
+		}
+		if _, exists := seen[ks]; exists {
+			v.fail(entryPath.AppendSegmentInt(0), datamodel.ErrRepeatedMapKey{Key: k})
+			return
+		}
+		seen[ks] = struct{}{}
+		v.validate(entryPath.AppendSegmentInt(0), t.KeyType(), k)
+		v.validateValue(entryPath.AppendSegmentInt(1), t.ValueType(), t.ValueIsNullable(), val)
+	})
+}
+
+// eachListPair calls fn with the key and value of each entry in a listpairs representation,
+// and records an error for any entry that isn't a list of exactly two values.
+func (v *validator) eachListPair(path datamodel.Path, t Type, n datamodel.Node, fn func(entryPath datamodel.Path, k, val datamodel.Node)) {
+	itr := n.ListIterator()
+	for !itr.Done() {
+		idx, entry, err := itr.Next()
+		if err != nil {
+			v.fail(path, err)
+			return
+		}
+		entryPath := path.AppendSegmentInt(idx)
+		if entry.Kind() != datamodel.Kind_List {
+			v.fail(entryPath, datamodel.ErrWrongKind{TypeName: t.Name() + ".Repr.Entry", MethodName: "Validate", AppropriateKind: datamodel.KindSet_JustList, ActualKind: entry.Kind()})
+			continue
+		}
+		if entry.Length() != 2 {
+			v.fail(entryPath, ErrUnmatchable{TypeName: t.Name()}.Reasonf("expected an entry to be a list of a key and a value, found a list of %d values", entry.Length()))
+			continue
+		}
+		k, err := entry.LookupByIndex(0)
+		if err != nil {
+			v.fail(entryPath, err)
+			continue
+		}
+		val, err := entry.LookupByIndex(1)
+		if err != nil {
+			v.fail(entryPath, err)
+			continue
+		}
+		fn(entryPath, k, val)
+	}
+}
+
+// structFields keeps track of which fields of a struct have been seen,
+// for the representations in which fields are identified by a key and may come in any order.
+type structFields struct {
+	t     *TypeStruct
+	keyOf func(StructField) string
+	byKey map[string]StructField
+	seen  map[string]struct{}
+}
+
+func newStructFields(t *TypeStruct, keyOf func(StructField) string) structFields {
+	sf := structFields{t, keyOf, make(map[string]StructField, len(t.Fields())), make(map[string]struct{}, len(t.Fields()))}
+	for _, f := range t.Fields() {
+		sf.byKey[keyOf(f)] = f
+	}
+	return sf
+}
+
+// entry validates the value for a key, which is found at valuePath;
+// unknown and repeated keys are reported at keyPath.
+func (sf structFields) entry(v *validator, keyPath, valuePath datamodel.Path, k string, val datamodel.Node) {
+	f, ok := sf.byKey[k]
+	if !ok {
+		v.fail(keyPath, ErrNoSuchField{Type: sf.t, Field: datamodel.PathSegmentOfString(k)})
+		return
+	}
+	if _, exists := sf.seen[k]; exists {
+		v.fail(keyPath, datamodel.ErrRepeatedMapKey{Key: basicnode.NewString(k)})
+		return
+	}
+	sf.seen[k] = struct{}{}
+	v.validateValue(valuePath, f.Type(), f.IsNullable(), val)
+}
+
+// missing reports any required fields that weren't seen, as a single error at the path of the struct.
+func (sf structFields) missing(v *validator, path datamodel.Path, optional func(StructField) bool) {
+	var missing []string
+	for _, f := range sf.t.Fields() {
+		k := sf.keyOf(f)
+		if _, ok := sf.seen[k]; ok || optional(f) {
+			continue
+		}
+		if k != f.Name() {
+			missing = append(missing, fmt.Sprintf("%s (serial:%q)", f.Name(), k))
+		} else {
+			missing = append(missing, f.Name())
+		}
+	}
+	if len(missing) > 0 {
+		v.fail(path, ErrMissingRequiredField{Missing: missing})
+	}
+}
+
+func isOptional(f StructField) bool { return f.IsOptional() }
+
+// validateStructMap validates a struct with map representation.
+// If discriminantKey isn't empty, the entry with that key belongs to an inline union, and is skipped.
+func (v *validator) validateStructMap(path datamodel.Path, t *TypeStruct, n datamodel.Node, discriminantKey string) {
+	if !v.expectKind(path, t, n, datamodel.Kind_Map) {
+		return
+	}
+	stg := t.RepresentationStrategy().(StructRepresentation_Map)
+	sf := newStructFields(t, stg.GetFieldKey)
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, val, err := itr.Next()
+		if err != nil {
+			v.fail(path, err)
+			return
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			v.fail(path, err)
+			continue
+		}
+		if discriminantKey != "" && ks == discriminantKey {
+			continue
+		}
+		sf.entry(v, path.AppendSegmentString(ks), path.AppendSegmentString(ks), ks, val)
+	}
+	sf.missing(v, path, func(f StructField) bool {
+		return f.IsOptional() || stg.FieldImplicit(f) != nil
+	})
+}
+
+func (v *validator) validateStructTuple(path datamodel.Path, t *TypeStruct, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_List) {
+		return
+	}
+	fields := t.TupleFields()
+	length := n.Length()
+	var missing []string
+	for i, f := range fields {
+		if int64(i) >= length {
+			if !f.IsOptional() {
+				missing = append(missing, f.Name())
+			}
+			continue
+		}
+		val, err := n.LookupByIndex(int64(i))
+		if err != nil {
+			v.fail(path.AppendSegmentInt(int64(i)), err)
+			continue
+		}
+		v.validateValue(path.AppendSegmentInt(int64(i)), f.Type(), f.IsNullable(), val)
+	}
+	for i := int64(len(fields)); i < length; i++ {
+		v.fail(path.AppendSegmentInt(i), ErrNoSuchField{Type: t, Field: datamodel.PathSegmentOfInt(i)})
+	}
+	if len(missing) > 0 {
+		v.fail(path, ErrMissingRequiredField{Missing: missing})
+	}
+}
+
+func (v *validator) validateStructListPairs(path datamodel.Path, t *TypeStruct, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_List) {
+		return
+	}
+	sf := newStructFields(t, StructField.Name)
+	v.eachListPair(path, t, n, func(entryPath datamodel.Path, k, val datamodel.Node) {
+		ks, err := k.AsString()
+		if err != nil {
+			v.fail(entryPath.AppendSegmentInt(0), err)
+			return
+		}
+		sf.entry(v, entryPath.AppendSegmentInt(0), entryPath.AppendSegmentInt(1), ks, val)
+	})
+	sf.missing(v, path, isOptional)
+}
+
+func (v *validator) validateStructStringPairs(path datamodel.Path, t *TypeStruct, stg StructRepresentation_StringPairs, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_String) {
+		return
+	}
+	s, _ := n.AsString()
+	pairs, err := mixins.SplitPairs(s, stg.GetInnerDelim(), stg.GetEntryDelim())
+	if err != nil {
+		v.fail(path, ErrUnmatchable{TypeName: t.Name(), Reason: err})
+		return
+	}
+	sf := newStructFields(t, StructField.Name)
+	for _, kv := range pairs {
+		sf.entry(v, path.AppendSegmentString(kv[0]), path.AppendSegmentString(kv[0]), kv[0], basicnode.NewString(kv[1]))
+	}
+	sf.missing(v, path, isOptional)
+}
+
+func (v *validator) validateStructStringjoin(path datamodel.Path, t *TypeStruct, stg StructRepresentation_Stringjoin, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_String) {
+		return
+	}
+	s, _ := n.AsString()
+	fields := t.Fields()
+	parts := strings.Split(s, stg.GetDelim())
+	if len(parts) != len(fields) {
+		v.fail(path, ErrUnmatchable{TypeName: t.Name()}.Reasonf("expected %d parts separated by %q, found %d", len(fields), stg.GetDelim(), len(parts)))
+		return
+	}
+	for i, f := range fields {
+		v.validate(path.AppendSegmentString(f.Name()), f.Type(), basicnode.NewString(parts[i]))
+	}
+}
+
+// member looks up the union member for a discriminant, recording an error if there isn't one.
+func (v *validator) member(path datamodel.Path, t *TypeUnion, name TypeName, discriminant string) Type {
+	if name == "" {
+		v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: fmt.Sprintf("no member with discriminant %q", discriminant)})
+		return nil
+	}
+	return v.ts.TypeByName(name)
+}
+
+func (v *validator) validateUnionKeyed(path datamodel.Path, t *TypeUnion, stg UnionRepresentation_Keyed, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_Map) {
+		return
+	}
+	if n.Length() != 1 {
+		v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: fmt.Sprintf("a keyed union must have exactly one entry, found %d", n.Length())})
+		return
+	}
+	k, val, err := n.MapIterator().Next()
+	if err != nil {
+		v.fail(path, err)
+		return
+	}
+	ks, err := k.AsString()
+	if err != nil {
+		v.fail(path, err)
+		return
+	}
+	if member := v.member(path, t, stg.table[ks], ks); member != nil {
+		v.validate(path.AppendSegmentString(ks), member, val)
+	}
+}
+
+func (v *validator) validateUnionKinded(path datamodel.Path, t *TypeUnion, stg UnionRepresentation_Kinded, n datamodel.Node) {
+	name := stg.GetMember(n.Kind())
+	if name == "" {
+		v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: fmt.Sprintf("no member with kind %s", n.Kind())})
+		return
+	}
+	v.validate(path, v.ts.TypeByName(name), n)
+}
+
+// discriminant finds the string value for a union's discriminant key in n,
+// recording an error if it's missing or not a string.
+func (v *validator) discriminant(path datamodel.Path, t *TypeUnion, n datamodel.Node, key string) (string, bool) {
+	d, err := n.LookupByString(key)
+	if err != nil {
+		return "", false
+	}
+	ds, err := d.AsString()
+	if err != nil {
+		v.fail(path.AppendSegmentString(key), datamodel.ErrWrongKind{TypeName: t.Name(), MethodName: "Validate", AppropriateKind: datamodel.KindSet_JustString, ActualKind: d.Kind()})
+		return "", false
+	}
+	return ds, true
+}
+
+func (v *validator) validateUnionEnvelope(path datamodel.Path, t *TypeUnion, stg UnionRepresentation_Envelope, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_Map) {
+		return
+	}
+	var missing []string
+	ds, dok := v.discriminant(path, t, n, stg.GetDiscriminantKey())
+	if _, err := n.LookupByString(stg.GetDiscriminantKey()); err != nil {
+		missing = append(missing, stg.GetDiscriminantKey())
+	}
+	content, err := n.LookupByString(stg.GetContentKey())
+	if err != nil {
+		missing = append(missing, stg.GetContentKey())
+	}
+	if len(missing) > 0 {
+		v.fail(path, ErrMissingRequiredField{Missing: missing})
+	}
+	itr := n.MapIterator()
+	for !itr.Done() {
+		k, _, err := itr.Next()
+		if err != nil {
+			v.fail(path, err)
+			return
+		}
+		if ks, _ := k.AsString(); ks != stg.GetDiscriminantKey() && ks != stg.GetContentKey() {
+			v.fail(path.AppendSegmentString(ks), ErrNoSuchField{Type: t, Field: datamodel.PathSegmentOfString(ks)})
+		}
+	}
+	if !dok || content == nil {
+		return
+	}
+	if member := v.member(path.AppendSegmentString(stg.GetDiscriminantKey()), t, stg.GetMember(ds), ds); member != nil {
+		v.validate(path.AppendSegmentString(stg.GetContentKey()), member, content)
+	}
+}
+
+func (v *validator) validateUnionInline(path datamodel.Path, t *TypeUnion, stg UnionRepresentation_Inline, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_Map) {
+		return
+	}
+	if _, err := n.LookupByString(stg.GetDiscriminantKey()); err != nil {
+		v.fail(path, ErrMissingRequiredField{Missing: []string{stg.GetDiscriminantKey()}})
+		return
+	}
+	ds, ok := v.discriminant(path, t, n, stg.GetDiscriminantKey())
+	if !ok {
+		return
+	}
+	member := v.member(path.AppendSegmentString(stg.GetDiscriminantKey()), t, stg.GetMember(ds), ds)
+	if member == nil {
+		return
+	}
+	t2, ok := member.(*TypeStruct)
+	if !ok {
+		v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: fmt.Sprintf("member %s of an inline union must be a struct", member.Name())})
+		return
+	}
+	if _, ok := t2.RepresentationStrategy().(StructRepresentation_Map); !ok {
+		v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: fmt.Sprintf("member %s of an inline union must have map representation", member.Name())})
+		return
+	}
+	v.validateStructMap(path, t2, n, stg.GetDiscriminantKey())
+}
+
+func (v *validator) validateUnionStringprefix(path datamodel.Path, t *TypeUnion, stg UnionRepresentation_Stringprefix, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_String) {
+		return
+	}
+	s, _ := n.AsString()
+	var discriminant, rest string
+	if stg.GetDelim() != "" {
+		var found bool
+		discriminant, rest, found = strings.Cut(s, stg.GetDelim())
+		if !found {
+			v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: fmt.Sprintf("expected the delimiter %q", stg.GetDelim())})
+			return
+		}
+	} else {
+		// Without a delimiter, the longest matching prefix wins.
+		for d := range stg.table {
+			if strings.HasPrefix(s, d) && len(d) >= len(discriminant) {
+				discriminant = d
+			}
+		}
+		rest = s[len(discriminant):]
+	}
+	if member := v.member(path, t, stg.table[discriminant], discriminant); member != nil {
+		v.validate(path, member, basicnode.NewString(rest))
+	}
+}
+
+func (v *validator) validateUnionBytesPrefix(path datamodel.Path, t *TypeUnion, stg UnionRepresentation_BytesPrefix, n datamodel.Node) {
+	if !v.expectKind(path, t, n, datamodel.Kind_Bytes) {
+		return
+	}
+	b, _ := n.AsBytes()
+	var discriminant string
+	var prefix []byte
+	for d := range stg.table {
+		p, err := hex.DecodeString(d)
+		if err != nil {
+			continue
+		}
+		if bytes.HasPrefix(b, p) && (prefix == nil || len(p) > len(prefix)) {
+			discriminant, prefix = d, p
+		}
+	}
+	if prefix == nil {
+		v.fail(path, ErrNotUnionStructure{TypeName: t.Name(), Detail: "no member with a matching prefix"})
+		return
+	}
+	v.validate(path, v.ts.TypeByName(stg.table[discriminant]), basicnode.NewBytes(b[len(prefix):]))
+}
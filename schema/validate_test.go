@@ -0,0 +1,173 @@
+package schema_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/schema"
+)
+
+func TestValidate(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes([]byte(`
+		type Person struct {
+			name String
+			age optional Int
+			role Role
+			tags [nullable String]
+			pet Pet (rename "animal")
+		}
+		type Role enum {
+			| Admin ("admin")
+			| User ("user")
+		}
+		type Pet union {
+			| Cat "cat"
+			| Dog "dog"
+		} representation keyed
+		type Cat struct {
+			lives Int
+		}
+		type Dog struct {
+			good Bool
+		} representation tuple
+		type Point struct {
+			x String
+			y Role
+		} representation stringjoin {
+			join ","
+		}
+		type Points {String:Point}
+	`))
+	qt.Assert(t, err, qt.IsNil)
+
+	type expected struct {
+		path   string
+		reason error
+	}
+	for _, tc := range []struct {
+		name string
+		typ  string
+		json string
+		errs []expected
+	}{
+		{
+			name: "valid",
+			typ:  "Person",
+			json: `{"name":"alice","age":30,"role":"admin","tags":["a",null],"animal":{"cat":{"lives":9}}}`,
+		},
+		{
+			name: "valid without optional field",
+			typ:  "Person",
+			json: `{"name":"bob","role":"user","tags":[],"animal":{"dog":[true]}}`,
+		},
+		{
+			name: "every field wrong",
+			typ:  "Person",
+			json: `{"name":1,"age":"old","role":"root","tags":[2],"animal":{"fish":{}}}`,
+			errs: []expected{
+				{"name", datamodel.ErrWrongKind{}},
+				{"age", datamodel.ErrWrongKind{}},
+				{"role", schema.ErrUnmatchable{}},
+				{"tags/0", datamodel.ErrWrongKind{}},
+				{"animal", schema.ErrNotUnionStructure{}},
+			},
+		},
+		{
+			name: "missing and unknown fields",
+			typ:  "Person",
+			json: `{"name":"carol","tags":[],"pet":{}}`,
+			errs: []expected{
+				{"pet", schema.ErrNoSuchField{}},
+				{"", schema.ErrMissingRequiredField{}},
+			},
+		},
+		{
+			name: "errors nested in a union member",
+			typ:  "Person",
+			json: `{"name":"dave","role":"user","tags":null,"animal":{"dog":[true,false]}}`,
+			errs: []expected{
+				{"tags", datamodel.ErrWrongKind{}},
+				{"animal/dog/1", schema.ErrNoSuchField{}},
+			},
+		},
+		{
+			name: "wrong kind at the root",
+			typ:  "Person",
+			json: `[]`,
+			errs: []expected{
+				{"", datamodel.ErrWrongKind{}},
+			},
+		},
+		{
+			name: "string representations",
+			typ:  "Points",
+			json: `{"a":"1,admin","b":"1,root","c":"1"}`,
+			errs: []expected{
+				{"b/y", schema.ErrUnmatchable{}},
+				{"c", schema.ErrUnmatchable{}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := ipld.Decode([]byte(tc.json), dagjson.Decode)
+			qt.Assert(t, err, qt.IsNil)
+			errs := schema.Validate(ts, ts.TypeByName(tc.typ), n)
+			qt.Assert(t, errs, qt.HasLen, len(tc.errs), qt.Commentf("%v", errs))
+			for i, exp := range tc.errs {
+				var verr schema.ErrValidation
+				qt.Assert(t, errors.As(errs[i], &verr), qt.IsTrue)
+				qt.Check(t, verr.Path.String(), qt.Equals, exp.path)
+				qt.Check(t, reflect.TypeOf(verr.Reason), qt.Equals, reflect.TypeOf(exp.reason), qt.Commentf("%v", verr.Reason))
+			}
+		})
+	}
+}
+
+func TestValidateInlineUnion(t *testing.T) {
+	ts, errs := schema.SpawnTypeSystem(
+		schema.SpawnString("String"),
+		schema.SpawnStruct("Circle",
+			[]schema.StructField{schema.SpawnStructField("radius", "String", false, false)},
+			schema.SpawnStructRepresentationMap(nil),
+		),
+		schema.SpawnStruct("Square",
+			[]schema.StructField{schema.SpawnStructField("side", "String", false, false)},
+			schema.SpawnStructRepresentationMap(nil),
+		),
+		schema.SpawnUnion("Shape",
+			[]schema.TypeName{"Circle", "Square"},
+			schema.SpawnUnionRepresentationInline("kind", map[string]schema.TypeName{
+				"circle": "Circle",
+				"square": "Square",
+			}),
+		),
+	)
+	qt.Assert(t, errs, qt.IsNil)
+	shape := ts.TypeByName("Shape")
+
+	decode := func(s string) datamodel.Node {
+		n, err := ipld.Decode([]byte(s), dagjson.Decode)
+		qt.Assert(t, err, qt.IsNil)
+		return n
+	}
+	qt.Check(t, schema.Validate(ts, shape, decode(`{"kind":"circle","radius":"1"}`)), qt.HasLen, 0)
+
+	errs = schema.Validate(ts, shape, decode(`{"kind":"square","radius":"1"}`))
+	qt.Assert(t, errs, qt.HasLen, 2)
+	qt.Check(t, errs[0].Error(), qt.Equals, `invalid data at "radius": no such field: Square.radius`)
+	qt.Check(t, errs[1].Error(), qt.Equals, `invalid data at the root: missing required fields: side`)
+
+	errs = schema.Validate(ts, shape, decode(`{"kind":"triangle"}`))
+	qt.Assert(t, errs, qt.HasLen, 1)
+	qt.Check(t, errors.Is(errs[0], schema.ErrNotUnionStructure{}), qt.IsTrue)
+
+	errs = schema.Validate(ts, shape, decode(`{"radius":"1"}`))
+	qt.Assert(t, errs, qt.HasLen, 1)
+	qt.Check(t, errs[0].Error(), qt.Equals, `invalid data at the root: missing required fields: kind`)
+}
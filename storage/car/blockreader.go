@@ -0,0 +1,89 @@
+package car
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+)
+
+// BlockReader iterates over the blocks of a CAR in the order they appear in the file.
+// Unlike ReadableCar, it needs only an io.Reader, and so is suitable for reading from network streams.
+// Either CARv1 or CARv2 may be given; any CARv2 index is ignored.
+type BlockReader struct {
+	version uint64
+	roots   []cid.Cid
+	s       sectionScanner
+}
+
+// NewBlockReader reads the header of a CAR from the given reader,
+// and returns a BlockReader ready to iterate over the blocks which follow it.
+func NewBlockReader(r io.Reader, opts ...Option) (*BlockReader, error) {
+	cfg := applyOptions(opts)
+	br := bufio.NewReader(r)
+	hdr, hdrLen, err := readV1Header(br, cfg.maxAllowedHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	version := hdr.version
+	switch version {
+	case 1:
+		// Carry on; the sections begin right here.
+	case 2:
+		hdrBytes := make([]byte, v2HeaderSize)
+		if _, err := io.ReadFull(br, hdrBytes); err != nil {
+			return nil, fmt.Errorf("car: truncated CARv2 header: %w", err)
+		}
+		v2hdr, err := unmarshalV2Header(hdrBytes)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := br.Discard(int(v2hdr.dataOffset - pragmaSize - v2HeaderSize)); err != nil {
+			return nil, fmt.Errorf("car: truncated CARv2 header: %w", err)
+		}
+		br = bufio.NewReader(io.LimitReader(br, int64(v2hdr.dataSize)))
+		hdr, hdrLen, err = readV1Header(br, cfg.maxAllowedHeaderSize)
+		if err != nil {
+			return nil, err
+		}
+		if hdr.version != 1 {
+			return nil, fmt.Errorf("car: invalid CARv2: payload has version %d, expected 1", hdr.version)
+		}
+	default:
+		return nil, fmt.Errorf("car: unsupported version %d", version)
+	}
+	return &BlockReader{
+		version: version,
+		roots:   hdr.roots,
+		s:       sectionScanner{r: br, offset: hdrLen, maxSize: cfg.maxAllowedSectionSize},
+	}, nil
+}
+
+// Version returns the version of the CAR format that was read (either 1 or 2).
+func (br *BlockReader) Version() uint64 {
+	return br.version
+}
+
+// Roots returns the root CIDs listed in the CAR's header.
+func (br *BlockReader) Roots() []cid.Cid {
+	return br.roots
+}
+
+// Next returns the next block in the CAR.
+// It returns io.EOF when there are no more blocks.
+//
+// No verification is done that the data matches the CID's hash;
+// if the source is untrusted, it's up to the caller to check this
+// (loading the data through a linking.LinkSystem will do so, for example).
+func (br *BlockReader) Next() (cid.Cid, []byte, error) {
+	_, ref, err := br.s.next()
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+	data, err := br.s.read(ref)
+	if err != nil {
+		return cid.Undef, nil, err
+	}
+	return ref.cid, data, nil
+}
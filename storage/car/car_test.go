@@ -0,0 +1,271 @@
+package car_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/ipfs/go-cid"
+
+	_ "github.com/ipld/go-ipld-prime/codec/dagcbor"
+	_ "github.com/ipld/go-ipld-prime/codec/raw"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/storage/car"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+var (
+	cborlp = cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x71, MhType: 0x12, MhLength: 32}}
+	rawlp  = cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x55, MhType: 0x12, MhLength: 32}}
+
+	cidsEqual = qt.CmpEquals(cmpopts.EquateComparable(cid.Cid{}))
+)
+
+// fixture builds a small graph in a memstore:
+//
+//	root -> {"left": middle, "right": leafC}
+//	middle -> [leafA, leafB]
+//
+// and returns the store, the root link, and every link in the order a depth-first walk would load them.
+func fixture(t *testing.T) (*memstore.Store, datamodel.Link, []datamodel.Link) {
+	store := &memstore.Store{}
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetWriteStorage(store)
+	lctx := linking.LinkContext{Ctx: context.Background()}
+
+	leafA := lsys.MustStore(lctx, rawlp, basicnode.NewBytes([]byte("leaf a")))
+	leafB := lsys.MustStore(lctx, rawlp, basicnode.NewBytes([]byte("leaf b")))
+	leafC := lsys.MustStore(lctx, rawlp, basicnode.NewBytes([]byte("leaf c")))
+	middle := lsys.MustStore(lctx, cborlp, must(qp.BuildList(basicnode.Prototype.Any, 2, func(la datamodel.ListAssembler) {
+		qp.ListEntry(la, qp.Link(leafA))
+		qp.ListEntry(la, qp.Link(leafB))
+	})))
+	root := lsys.MustStore(lctx, cborlp, must(qp.BuildMap(basicnode.Prototype.Any, 2, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "left", qp.Link(middle))
+		qp.MapEntry(ma, "right", qp.Link(leafC))
+	})))
+	return store, root, []datamodel.Link{root, middle, leafA, leafB, leafC}
+}
+
+func must(n datamodel.Node, err error) datamodel.Node {
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+func cids(lnks []datamodel.Link) []cid.Cid {
+	result := make([]cid.Cid, len(lnks))
+	for i, lnk := range lnks {
+		result[i] = lnk.(cidlink.Link).Cid
+	}
+	return result
+}
+
+func writeAll(t *testing.T, wc *car.WritableCar, store *memstore.Store, lnks []datamodel.Link) {
+	ctx := context.Background()
+	for _, lnk := range lnks {
+		qt.Assert(t, wc.Put(ctx, lnk.Binary(), store.Bag[lnk.Binary()]), qt.IsNil)
+	}
+	qt.Assert(t, wc.Finalize(), qt.IsNil)
+}
+
+func checkReadable(t *testing.T, rc *car.ReadableCar, store *memstore.Store, root datamodel.Link, lnks []datamodel.Link) {
+	ctx := context.Background()
+	qt.Check(t, rc.Roots(), cidsEqual, cids([]datamodel.Link{root}))
+	for _, lnk := range lnks {
+		has, err := rc.Has(ctx, lnk.Binary())
+		qt.Check(t, err, qt.IsNil)
+		qt.Check(t, has, qt.IsTrue)
+
+		data, err := rc.Get(ctx, lnk.Binary())
+		qt.Check(t, err, qt.IsNil)
+		qt.Check(t, data, qt.DeepEquals, store.Bag[lnk.Binary()])
+
+		stream, err := rc.GetStream(ctx, lnk.Binary())
+		qt.Assert(t, err, qt.IsNil)
+		data, err = io.ReadAll(stream)
+		qt.Check(t, err, qt.IsNil)
+		qt.Check(t, data, qt.DeepEquals, store.Bag[lnk.Binary()])
+	}
+
+	absent := cidlink.Link{Cid: cid.NewCidV1(0x55, must2(rawlp.Prefix.Sum([]byte("absent"))).Hash())}
+	has, err := rc.Has(ctx, absent.Binary())
+	qt.Check(t, err, qt.IsNil)
+	qt.Check(t, has, qt.IsFalse)
+	_, err = rc.Get(ctx, absent.Binary())
+	qt.Check(t, err, qt.ErrorAs, new(car.ErrNotFound))
+
+	// Finally, the whole graph should be loadable through a LinkSystem.
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(rc)
+	n, err := lsys.Load(linking.LinkContext{Ctx: ctx}, root, basicnode.Prototype.Any)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, n.Length(), qt.Equals, int64(2))
+}
+
+func must2(c cid.Cid, err error) cid.Cid {
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+func TestRoundtripV1(t *testing.T) {
+	store, root, lnks := fixture(t)
+	var buf bytes.Buffer
+	wc, err := car.NewWritableStorage(&buf, cids([]datamodel.Link{root}), car.WriteAsCarV1(true))
+	qt.Assert(t, err, qt.IsNil)
+	writeAll(t, wc, store, lnks)
+
+	rc, err := car.NewReadableStorage(bytes.NewReader(buf.Bytes()))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, rc.Version(), qt.Equals, uint64(1))
+	checkReadable(t, rc, store, root, lnks)
+}
+
+func TestRoundtripV2(t *testing.T) {
+	store, root, lnks := fixture(t)
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.car"))
+	qt.Assert(t, err, qt.IsNil)
+	defer f.Close()
+	wc, err := car.NewWritableStorage(f, cids([]datamodel.Link{root}))
+	qt.Assert(t, err, qt.IsNil)
+	writeAll(t, wc, store, lnks)
+
+	t.Run("with index", func(t *testing.T) {
+		rc, err := car.NewReadableStorage(f)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, rc.Version(), qt.Equals, uint64(2))
+		checkReadable(t, rc, store, root, lnks)
+	})
+	t.Run("ignoring index", func(t *testing.T) {
+		rc, err := car.NewReadableStorage(f, car.SkipIndex(true))
+		qt.Assert(t, err, qt.IsNil)
+		checkReadable(t, rc, store, root, lnks)
+	})
+}
+
+func TestWriteV2RequiresWriterAt(t *testing.T) {
+	_, err := car.NewWritableStorage(&bytes.Buffer{}, nil)
+	qt.Check(t, err, qt.ErrorMatches, "car: writing CARv2 requires an io.WriterAt.*")
+}
+
+func TestWritableDeduplicates(t *testing.T) {
+	store, root, lnks := fixture(t)
+	var buf bytes.Buffer
+	wc, err := car.NewWritableStorage(&buf, cids([]datamodel.Link{root}), car.WriteAsCarV1(true))
+	qt.Assert(t, err, qt.IsNil)
+	writeAll(t, wc, store, append(lnks, lnks...))
+
+	br, err := car.NewBlockReader(&buf)
+	qt.Assert(t, err, qt.IsNil)
+	var count int
+	for {
+		_, _, err := br.Next()
+		if err == io.EOF {
+			break
+		}
+		qt.Assert(t, err, qt.IsNil)
+		count++
+	}
+	qt.Check(t, count, qt.Equals, len(lnks))
+}
+
+func TestStreamingWrite(t *testing.T) {
+	store, root, lnks := fixture(t)
+	var buf bytes.Buffer
+	wc, err := car.NewWritableStorage(&buf, cids([]datamodel.Link{root}), car.WriteAsCarV1(true))
+	qt.Assert(t, err, qt.IsNil)
+
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(store)
+	lsys.SetWriteStorage(wc)
+	lctx := linking.LinkContext{Ctx: context.Background()}
+	for _, lnk := range lnks {
+		n, err := lsys.Load(lctx, lnk, basicnode.Prototype.Any)
+		qt.Assert(t, err, qt.IsNil)
+		lnk2, err := lsys.Store(lctx, lnk.Prototype(), n)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, lnk2, qt.Equals, lnk)
+	}
+	qt.Assert(t, wc.Finalize(), qt.IsNil)
+
+	rc, err := car.NewReadableStorage(bytes.NewReader(buf.Bytes()))
+	qt.Assert(t, err, qt.IsNil)
+	checkReadable(t, rc, store, root, lnks)
+}
+
+func TestBlockReader(t *testing.T) {
+	store, root, lnks := fixture(t)
+	f, err := os.Create(filepath.Join(t.TempDir(), "test.car"))
+	qt.Assert(t, err, qt.IsNil)
+	defer f.Close()
+	wc, err := car.NewWritableStorage(f, cids([]datamodel.Link{root}))
+	qt.Assert(t, err, qt.IsNil)
+	writeAll(t, wc, store, lnks)
+	_, err = f.Seek(0, io.SeekStart)
+	qt.Assert(t, err, qt.IsNil)
+
+	br, err := car.NewBlockReader(f)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, br.Version(), qt.Equals, uint64(2))
+	qt.Check(t, br.Roots(), cidsEqual, cids([]datamodel.Link{root}))
+	for _, lnk := range lnks {
+		c, data, err := br.Next()
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, c, qt.Equals, lnk.(cidlink.Link).Cid)
+		qt.Check(t, data, qt.DeepEquals, store.Bag[lnk.Binary()])
+	}
+	_, _, err = br.Next()
+	qt.Check(t, err, qt.Equals, io.EOF)
+}
+
+func TestWriteSelective(t *testing.T) {
+	store, root, lnks := fixture(t)
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(store)
+
+	readAll := func(t *testing.T, buf []byte) []cid.Cid {
+		br, err := car.NewBlockReader(bytes.NewReader(buf))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, br.Roots(), cidsEqual, cids([]datamodel.Link{root}))
+		var result []cid.Cid
+		for {
+			c, _, err := br.Next()
+			if err == io.EOF {
+				return result
+			}
+			qt.Assert(t, err, qt.IsNil)
+			result = append(result, c)
+		}
+	}
+
+	t.Run("explore all", func(t *testing.T) {
+		sel, err := selector.CompileSelector(selectorparse.CommonSelector_ExploreAllRecursively)
+		qt.Assert(t, err, qt.IsNil)
+		var buf bytes.Buffer
+		err = car.WriteSelective(context.Background(), lsys, &buf, root, sel, car.WriteAsCarV1(true))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, readAll(t, buf.Bytes()), cidsEqual, cids(lnks))
+	})
+	t.Run("one field", func(t *testing.T) {
+		sel, err := selectorparse.ParseAndCompileJSONSelector(`{"f":{"f>":{"right":{".":{}}}}}`)
+		qt.Assert(t, err, qt.IsNil)
+		var buf bytes.Buffer
+		err = car.WriteSelective(context.Background(), lsys, &buf, root, sel, car.WriteAsCarV1(true))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, readAll(t, buf.Bytes()), cidsEqual, cids([]datamodel.Link{root, lnks[4]}))
+	})
+}
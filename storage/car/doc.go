@@ -0,0 +1,32 @@
+// The car package provides storage implementations that read and write CAR ("Content Addressable aRchive") files.
+//
+// A CAR file is a simple concatenation of blocks, each prefixed by its CID and its length,
+// behind a small header which lists one or more "root" CIDs.
+// There are two versions of the format:
+// CARv1 is the plain concatenation, and is well suited to streaming;
+// CARv2 wraps a CARv1 payload with a fixed-size header and an optional index after the payload,
+// which allows random access to blocks without scanning the whole file.
+// See https://ipld.io/specs/transport/car/ for the specifications of both.
+//
+// ReadableCar implements storage.ReadableStorage and storage.StreamingReadableStorage,
+// so it can be handed to linking.LinkSystem.SetReadStorage directly.
+// It works over an io.ReaderAt, and accepts either version of the format:
+// if a CARv2 index is present, it is used for lookups;
+// otherwise, the payload is scanned once when the ReadableCar is opened, and an index is built in memory.
+//
+// WritableCar implements storage.WritableStorage and storage.StreamingWritableStorage,
+// so it can be handed to linking.LinkSystem.SetWriteStorage directly.
+// It writes CARv2 by default (which requires an io.WriterAt, since the header is written last),
+// or CARv1 if the WriteAsCarV1 option is given (which only requires an io.Writer).
+// Remember to call WritableCar.Finalize when done writing.
+//
+// BlockReader offers plain sequential iteration over the blocks in a CAR, for use with any io.Reader.
+//
+// WriteSelective is a helper which writes a CAR containing exactly the blocks
+// that a traversal of a selector over a graph visits,
+// by driving a traversal with a linking.LinkSystem and recording every block it loads.
+//
+// Keys used with these storage implementations are the binary form of CIDs
+// (which is also what linking.LinkSystem uses, by way of cidlink.Link.Binary).
+// Keys which are not a valid CID are rejected with an error.
+package car
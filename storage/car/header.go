@@ -0,0 +1,171 @@
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+// pragma is the sequence of bytes a CARv2 begins with.
+// It is itself a (degenerate) CARv1 header: a varint length, followed by the dag-cbor map `{"version": 2}`.
+// This means a CARv1 reader encountering a CARv2 will at least find a sensible version number and refuse it.
+var pragma = []byte{
+	0x0a,                                     // uint(10)
+	0xa1,                                     // map(1)
+	0x67,                                     // string(7)
+	0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, // "version"
+	0x02, // uint(2)
+}
+
+const (
+	pragmaSize   = 11
+	v2HeaderSize = 40
+
+	// fullyIndexedCharacteristic is the most significant bit of the CARv2 characteristics field.
+	fullyIndexedCharacteristic = uint64(1) << 63
+)
+
+// v1Header is the header at the start of every CARv1 (and of the payload inside every CARv2).
+type v1Header struct {
+	version uint64
+	roots   []cid.Cid
+}
+
+func (h v1Header) encode(w io.Writer) (int64, error) {
+	n, err := fluent.BuildMap(basicnode.Prototype.Map, 2, func(ma fluent.MapAssembler) {
+		ma.AssembleEntry("roots").CreateList(int64(len(h.roots)), func(la fluent.ListAssembler) {
+			for _, root := range h.roots {
+				la.AssembleValue().AssignLink(cidlink.Link{Cid: root})
+			}
+		})
+		ma.AssembleEntry("version").AssignInt(int64(h.version))
+	})
+	if err != nil {
+		return 0, err
+	}
+	var buf bytes.Buffer
+	if err := dagcbor.Encode(n, &buf); err != nil {
+		return 0, err
+	}
+	return writeLengthPrefixed(w, buf.Bytes())
+}
+
+// readV1Header reads a CARv1 header from a reader.
+// If the header is in fact a CARv2 pragma, the returned version will be 2 and there will be no roots;
+// it is up to the caller to decide what to do next in that case.
+func readV1Header(r *bufio.Reader, maxSize uint64) (v1Header, int64, error) {
+	hdrLen, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			return v1Header{}, 0, fmt.Errorf("car: empty input")
+		}
+		return v1Header{}, 0, fmt.Errorf("car: invalid header length: %w", err)
+	}
+	if hdrLen == 0 {
+		return v1Header{}, 0, fmt.Errorf("car: invalid header: zero length")
+	}
+	if hdrLen > maxSize {
+		return v1Header{}, 0, fmt.Errorf("car: header length %d exceeds maximum allowed size %d", hdrLen, maxSize)
+	}
+	hdrBytes := make([]byte, hdrLen)
+	if _, err := io.ReadFull(r, hdrBytes); err != nil {
+		return v1Header{}, 0, fmt.Errorf("car: truncated header: %w", err)
+	}
+	nb := basicnode.Prototype.Map.NewBuilder()
+	if err := dagcbor.Decode(nb, bytes.NewReader(hdrBytes)); err != nil {
+		return v1Header{}, 0, fmt.Errorf("car: invalid header: %w", err)
+	}
+	hdr, err := parseV1Header(nb.Build())
+	return hdr, int64(uvarintSize(hdrLen)) + int64(hdrLen), err
+}
+
+func parseV1Header(n datamodel.Node) (v1Header, error) {
+	var hdr v1Header
+	versionNode, err := n.LookupByString("version")
+	if err != nil {
+		return hdr, fmt.Errorf("car: invalid header: missing version")
+	}
+	version, err := versionNode.AsInt()
+	if err != nil || version < 0 {
+		return hdr, fmt.Errorf("car: invalid header: version must be a positive integer")
+	}
+	hdr.version = uint64(version)
+	if hdr.version != 1 {
+		// Only the CARv1 header has roots; a CARv2 pragma is a header with nothing but a version.
+		return hdr, nil
+	}
+	rootsNode, err := n.LookupByString("roots")
+	if err != nil {
+		return hdr, fmt.Errorf("car: invalid header: missing roots")
+	}
+	if rootsNode.Kind() != datamodel.Kind_List {
+		return hdr, fmt.Errorf("car: invalid header: roots must be a list")
+	}
+	for itr := rootsNode.ListIterator(); !itr.Done(); {
+		_, v, err := itr.Next()
+		if err != nil {
+			return hdr, err
+		}
+		lnk, err := v.AsLink()
+		if err != nil {
+			return hdr, fmt.Errorf("car: invalid header: roots must be links")
+		}
+		cl, ok := lnk.(cidlink.Link)
+		if !ok {
+			return hdr, fmt.Errorf("car: invalid header: roots must be CIDs")
+		}
+		hdr.roots = append(hdr.roots, cl.Cid)
+	}
+	return hdr, nil
+}
+
+// v2Header is the fixed-size header which follows the pragma in a CARv2.
+// All offsets are from the start of the file.
+type v2Header struct {
+	characteristics [2]uint64
+	dataOffset      uint64
+	dataSize        uint64
+	indexOffset     uint64
+}
+
+func (h v2Header) marshal() []byte {
+	buf := make([]byte, v2HeaderSize)
+	binary.LittleEndian.PutUint64(buf[0:], h.characteristics[0])
+	binary.LittleEndian.PutUint64(buf[8:], h.characteristics[1])
+	binary.LittleEndian.PutUint64(buf[16:], h.dataOffset)
+	binary.LittleEndian.PutUint64(buf[24:], h.dataSize)
+	binary.LittleEndian.PutUint64(buf[32:], h.indexOffset)
+	return buf
+}
+
+func unmarshalV2Header(buf []byte) (v2Header, error) {
+	if len(buf) != v2HeaderSize {
+		return v2Header{}, fmt.Errorf("car: invalid CARv2 header: wrong length %d", len(buf))
+	}
+	h := v2Header{
+		characteristics: [2]uint64{
+			binary.LittleEndian.Uint64(buf[0:]),
+			binary.LittleEndian.Uint64(buf[8:]),
+		},
+		dataOffset:  binary.LittleEndian.Uint64(buf[16:]),
+		dataSize:    binary.LittleEndian.Uint64(buf[24:]),
+		indexOffset: binary.LittleEndian.Uint64(buf[32:]),
+	}
+	if h.dataOffset < pragmaSize+v2HeaderSize {
+		return h, fmt.Errorf("car: invalid CARv2 header: data offset %d overlaps header", h.dataOffset)
+	}
+	if h.indexOffset != 0 && h.indexOffset < h.dataOffset+h.dataSize {
+		return h, fmt.Errorf("car: invalid CARv2 header: index offset %d overlaps data payload", h.indexOffset)
+	}
+	return h, nil
+}
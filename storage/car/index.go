@@ -0,0 +1,225 @@
+package car
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+)
+
+// Multicodec indicators for the index formats that can follow the payload in a CARv2.
+const (
+	indexSortedCodec          = 0x0400
+	multihashIndexSortedCodec = 0x0401
+
+	// anyMultihashCode is used to file index records whose multihash function is unknown,
+	// which is the case for the older IndexSorted format (it stores digests only).
+	anyMultihashCode = ^uint64(0)
+)
+
+// index maps multihashes to the offsets of the sections containing them.
+// The offsets point at the start of a section (its varint), relative to the start of the payload.
+//
+// The in-memory form is also what's serialized, in the "MultihashIndexSorted" format:
+// records are grouped by multihash function code, then by digest width, and sorted by digest within each group.
+// Lookups are only valid after sort has been called.
+type index struct {
+	buckets map[uint64][]indexRecord
+}
+
+type indexRecord struct {
+	digest []byte
+	offset uint64
+}
+
+func newIndex() *index {
+	return &index{buckets: make(map[uint64][]indexRecord)}
+}
+
+func (idx *index) insert(c cid.Cid, offset uint64) error {
+	dmh, err := multihash.Decode(c.Hash())
+	if err != nil {
+		return err
+	}
+	idx.buckets[dmh.Code] = append(idx.buckets[dmh.Code], indexRecord{dmh.Digest, offset})
+	return nil
+}
+
+func (idx *index) sort() {
+	for _, records := range idx.buckets {
+		sort.SliceStable(records, func(i, j int) bool {
+			if len(records[i].digest) != len(records[j].digest) {
+				return len(records[i].digest) < len(records[j].digest)
+			}
+			return bytes.Compare(records[i].digest, records[j].digest) < 0
+		})
+	}
+}
+
+// offsets returns the offsets of every section which may contain the given CID.
+// (More than one section can match when a CAR contains e.g. the same multihash in both a CIDv0 and a CIDv1,
+// so callers should check the CID found at each offset.)
+func (idx *index) offsets(c cid.Cid) ([]uint64, error) {
+	dmh, err := multihash.Decode(c.Hash())
+	if err != nil {
+		return nil, err
+	}
+	var result []uint64
+	for _, code := range [2]uint64{dmh.Code, anyMultihashCode} {
+		records := idx.buckets[code]
+		i := sort.Search(len(records), func(i int) bool {
+			if len(records[i].digest) != len(dmh.Digest) {
+				return len(records[i].digest) > len(dmh.Digest)
+			}
+			return bytes.Compare(records[i].digest, dmh.Digest) >= 0
+		})
+		for ; i < len(records) && bytes.Equal(records[i].digest, dmh.Digest); i++ {
+			result = append(result, records[i].offset)
+		}
+	}
+	return result, nil
+}
+
+func (idx *index) marshal(w io.Writer) error {
+	idx.sort()
+	codes := make([]uint64, 0, len(idx.buckets))
+	for code := range idx.buckets {
+		if code == anyMultihashCode {
+			return fmt.Errorf("car: cannot write an index with records of unknown multihash function")
+		}
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i] < codes[j] })
+
+	var varintBuf [binary.MaxVarintLen64]byte
+	if _, err := w.Write(varintBuf[:binary.PutUvarint(varintBuf[:], multihashIndexSortedCodec)]); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(codes))); err != nil {
+		return err
+	}
+	for _, code := range codes {
+		if err := binary.Write(w, binary.LittleEndian, code); err != nil {
+			return err
+		}
+		if err := marshalWidthBuckets(w, idx.buckets[code]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// marshalWidthBuckets writes records (which must already be sorted) grouped by their width.
+// The width of a record is the length of its digest, plus eight bytes for the offset.
+func marshalWidthBuckets(w io.Writer, records []indexRecord) error {
+	var groups [][]indexRecord
+	for i := 0; i < len(records); {
+		j := i + 1
+		for j < len(records) && len(records[j].digest) == len(records[i].digest) {
+			j++
+		}
+		groups = append(groups, records[i:j])
+		i = j
+	}
+	if err := binary.Write(w, binary.LittleEndian, int32(len(groups))); err != nil {
+		return err
+	}
+	for _, group := range groups {
+		width := uint32(len(group[0].digest) + 8)
+		if err := binary.Write(w, binary.LittleEndian, width); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, int64(len(group))*int64(width)); err != nil {
+			return err
+		}
+		var offsetBuf [8]byte
+		for _, record := range group {
+			if _, err := w.Write(record.digest); err != nil {
+				return err
+			}
+			binary.LittleEndian.PutUint64(offsetBuf[:], record.offset)
+			if _, err := w.Write(offsetBuf[:]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// unmarshalIndex reads either of the two sorted index formats.
+func unmarshalIndex(r io.Reader) (*index, error) {
+	br := bufio.NewReader(r)
+	codec, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("car: invalid index: %w", err)
+	}
+	idx := newIndex()
+	switch codec {
+	case indexSortedCodec:
+		if err := unmarshalWidthBuckets(br, idx, anyMultihashCode); err != nil {
+			return nil, err
+		}
+	case multihashIndexSortedCodec:
+		var codeCount int32
+		if err := binary.Read(br, binary.LittleEndian, &codeCount); err != nil {
+			return nil, fmt.Errorf("car: invalid index: %w", err)
+		}
+		if codeCount < 0 {
+			return nil, fmt.Errorf("car: invalid index: negative bucket count")
+		}
+		for i := int32(0); i < codeCount; i++ {
+			var code uint64
+			if err := binary.Read(br, binary.LittleEndian, &code); err != nil {
+				return nil, fmt.Errorf("car: invalid index: %w", err)
+			}
+			if err := unmarshalWidthBuckets(br, idx, code); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("car: unsupported index format 0x%x", codec)
+	}
+	idx.sort()
+	return idx, nil
+}
+
+func unmarshalWidthBuckets(r io.Reader, idx *index, code uint64) error {
+	var widthCount int32
+	if err := binary.Read(r, binary.LittleEndian, &widthCount); err != nil {
+		return fmt.Errorf("car: invalid index: %w", err)
+	}
+	if widthCount < 0 {
+		return fmt.Errorf("car: invalid index: negative bucket count")
+	}
+	for i := int32(0); i < widthCount; i++ {
+		var width uint32
+		var dataLen int64
+		if err := binary.Read(r, binary.LittleEndian, &width); err != nil {
+			return fmt.Errorf("car: invalid index: %w", err)
+		}
+		if err := binary.Read(r, binary.LittleEndian, &dataLen); err != nil {
+			return fmt.Errorf("car: invalid index: %w", err)
+		}
+		if width <= 8 || dataLen < 0 || dataLen%int64(width) != 0 {
+			return fmt.Errorf("car: invalid index: bucket of width %d has length %d", width, dataLen)
+		}
+		entry := make([]byte, width)
+		for j := int64(0); j < dataLen/int64(width); j++ {
+			if _, err := io.ReadFull(r, entry); err != nil {
+				return fmt.Errorf("car: invalid index: %w", err)
+			}
+			digest := make([]byte, width-8)
+			copy(digest, entry)
+			idx.buckets[code] = append(idx.buckets[code], indexRecord{
+				digest: digest,
+				offset: binary.LittleEndian.Uint64(entry[width-8:]),
+			})
+		}
+	}
+	return nil
+}
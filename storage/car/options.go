@@ -0,0 +1,62 @@
+package car
+
+// DefaultMaxAllowedSectionSize is the largest section (CID plus block data) that will be read by default.
+// Sections larger than this are rejected with an error rather than allocated, as a defense against corrupt or malicious inputs.
+// Use the MaxAllowedSectionSize option to change the limit.
+const DefaultMaxAllowedSectionSize uint64 = 8 << 20
+
+// DefaultMaxAllowedHeaderSize is the largest CARv1 header that will be read by default.
+// Use the MaxAllowedHeaderSize option to change the limit.
+const DefaultMaxAllowedHeaderSize uint64 = 32 << 20
+
+// Option is able to apply custom options to the readers and writers in this package.
+type Option func(*config)
+
+type config struct {
+	writeAsCarV1          bool
+	maxAllowedSectionSize uint64
+	maxAllowedHeaderSize  uint64
+	skipIndex             bool
+}
+
+func applyOptions(opts []Option) config {
+	cfg := config{
+		maxAllowedSectionSize: DefaultMaxAllowedSectionSize,
+		maxAllowedHeaderSize:  DefaultMaxAllowedHeaderSize,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WriteAsCarV1 causes a WritableCar to produce the CARv1 format, with no CARv2 header and no index.
+// This only requires an io.Writer, rather than an io.WriterAt.
+func WriteAsCarV1(asCarV1 bool) Option {
+	return func(cfg *config) {
+		cfg.writeAsCarV1 = asCarV1
+	}
+}
+
+// MaxAllowedSectionSize sets the largest section size that will be accepted when reading.
+func MaxAllowedSectionSize(max uint64) Option {
+	return func(cfg *config) {
+		cfg.maxAllowedSectionSize = max
+	}
+}
+
+// MaxAllowedHeaderSize sets the largest CARv1 header size that will be accepted when reading.
+func MaxAllowedHeaderSize(max uint64) Option {
+	return func(cfg *config) {
+		cfg.maxAllowedHeaderSize = max
+	}
+}
+
+// SkipIndex causes a WritableCar in CARv2 mode to omit the index.
+// The resulting file is still a valid CARv2, but readers will need to scan it to find blocks.
+// It also causes a ReadableCar to ignore any index already present in a CARv2, and scan the payload instead.
+func SkipIndex(skip bool) Option {
+	return func(cfg *config) {
+		cfg.skipIndex = skip
+	}
+}
@@ -0,0 +1,189 @@
+package car
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/ipfs/go-cid"
+)
+
+// ReadableCar gives access to the blocks in a CAR file, keyed by CID.
+//
+// ReadableCar conforms to the storage.ReadableStorage API,
+// and also to storage.StreamingReadableStorage;
+// streaming reads are served directly from the underlying io.ReaderAt without buffering the whole block.
+//
+// ReadableCar is safe for concurrent use, as long as the underlying io.ReaderAt is
+// (which is true of *os.File and *bytes.Reader, for example).
+type ReadableCar struct {
+	payload io.ReaderAt // the CARv1 payload, which is the whole file for a CARv1.
+	version uint64
+	roots   []cid.Cid
+	idx     *index
+	cfg     config
+}
+
+// NewReadableStorage opens a CAR from an io.ReaderAt.
+// Either CARv1 or CARv2 may be given.
+//
+// If the CAR is a CARv2 with an index, the index is loaded into memory;
+// otherwise, the whole payload is scanned once to build an index in memory.
+// Either way, subsequent reads only touch the sections they need.
+func NewReadableStorage(r io.ReaderAt, opts ...Option) (*ReadableCar, error) {
+	cfg := applyOptions(opts)
+	rc := &ReadableCar{cfg: cfg}
+
+	hdr, _, err := readV1Header(bufio.NewReader(io.NewSectionReader(r, 0, math.MaxInt64)), cfg.maxAllowedHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	rc.version = hdr.version
+	switch hdr.version {
+	case 1:
+		rc.payload = r
+		rc.roots = hdr.roots
+	case 2:
+		hdrBytes := make([]byte, v2HeaderSize)
+		if _, err := r.ReadAt(hdrBytes, pragmaSize); err != nil {
+			return nil, fmt.Errorf("car: truncated CARv2 header: %w", err)
+		}
+		v2hdr, err := unmarshalV2Header(hdrBytes)
+		if err != nil {
+			return nil, err
+		}
+		payload := io.NewSectionReader(r, int64(v2hdr.dataOffset), int64(v2hdr.dataSize))
+		innerHdr, _, err := readV1Header(bufio.NewReader(io.NewSectionReader(payload, 0, math.MaxInt64)), cfg.maxAllowedHeaderSize)
+		if err != nil {
+			return nil, err
+		}
+		if innerHdr.version != 1 {
+			return nil, fmt.Errorf("car: invalid CARv2: payload has version %d, expected 1", innerHdr.version)
+		}
+		rc.payload = payload
+		rc.roots = innerHdr.roots
+		if v2hdr.indexOffset != 0 && !cfg.skipIndex {
+			rc.idx, err = unmarshalIndex(io.NewSectionReader(r, int64(v2hdr.indexOffset), math.MaxInt64-int64(v2hdr.indexOffset)))
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("car: unsupported version %d", hdr.version)
+	}
+
+	if rc.idx == nil {
+		if rc.idx, err = rc.scan(); err != nil {
+			return nil, err
+		}
+	}
+	return rc, nil
+}
+
+// scan reads every section in the payload, building an index of them.
+func (rc *ReadableCar) scan() (*index, error) {
+	br := bufio.NewReader(io.NewSectionReader(rc.payload, 0, math.MaxInt64))
+	_, hdrLen, err := readV1Header(br, rc.cfg.maxAllowedHeaderSize)
+	if err != nil {
+		return nil, err
+	}
+	idx := newIndex()
+	s := sectionScanner{r: br, offset: hdrLen, maxSize: rc.cfg.maxAllowedSectionSize}
+	for {
+		sectionOffset, ref, err := s.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := idx.insert(ref.cid, uint64(sectionOffset)); err != nil {
+			return nil, err
+		}
+		if err := s.skip(ref); err != nil {
+			return nil, err
+		}
+	}
+	idx.sort()
+	return idx, nil
+}
+
+// Version returns the version of the CAR format that was read (either 1 or 2).
+func (rc *ReadableCar) Version() uint64 {
+	return rc.version
+}
+
+// Roots returns the root CIDs listed in the CAR's header.
+func (rc *ReadableCar) Roots() []cid.Cid {
+	return rc.roots
+}
+
+// find looks up the section holding the given key, returning ok=false if there is none.
+func (rc *ReadableCar) find(key string) (sectionRef, bool, error) {
+	c, err := cid.Cast([]byte(key))
+	if err != nil {
+		return sectionRef{}, false, fmt.Errorf("car: key is not a valid CID: %w", err)
+	}
+	offsets, err := rc.idx.offsets(c)
+	if err != nil {
+		return sectionRef{}, false, err
+	}
+	for _, offset := range offsets {
+		ref, err := readSectionAt(rc.payload, int64(offset), rc.cfg.maxAllowedSectionSize)
+		if err != nil {
+			return sectionRef{}, false, err
+		}
+		if ref.cid.Equals(c) {
+			return ref, true, nil
+		}
+	}
+	return sectionRef{}, false, nil
+}
+
+// Has implements go-ipld-prime/storage.Storage.Has.
+func (rc *ReadableCar) Has(ctx context.Context, key string) (bool, error) {
+	_, ok, err := rc.find(key)
+	return ok, err
+}
+
+// Get implements go-ipld-prime/storage.ReadableStorage.Get.
+func (rc *ReadableCar) Get(ctx context.Context, key string) ([]byte, error) {
+	ref, ok, err := rc.find(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotFound{key}
+	}
+	data := make([]byte, ref.dataLength)
+	if _, err := rc.payload.ReadAt(data, ref.dataOffset); err != nil {
+		return nil, fmt.Errorf("car: truncated section at offset %d: %w", ref.dataOffset, io.ErrUnexpectedEOF)
+	}
+	return data, nil
+}
+
+// GetStream implements go-ipld-prime/storage.StreamingReadableStorage.GetStream.
+func (rc *ReadableCar) GetStream(ctx context.Context, key string) (io.ReadCloser, error) {
+	ref, ok, err := rc.find(key)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotFound{key}
+	}
+	return io.NopCloser(io.NewSectionReader(rc.payload, ref.dataOffset, ref.dataLength)), nil
+}
+
+// ErrNotFound is returned when a key is not present in a CAR.
+type ErrNotFound struct {
+	Key string
+}
+
+func (e ErrNotFound) Error() string {
+	if c, err := cid.Cast([]byte(e.Key)); err == nil {
+		return fmt.Sprintf("car: block not found: %s", c)
+	}
+	return fmt.Sprintf("car: block not found: %q", e.Key)
+}
@@ -0,0 +1,126 @@
+package car
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+)
+
+// A "section" is the unit a CARv1 payload is made of, following the header:
+// a varint length, then a CID, then the block data.
+// The length covers both the CID and the block data.
+
+func uvarintSize(v uint64) int {
+	var buf [binary.MaxVarintLen64]byte
+	return binary.PutUvarint(buf[:], v)
+}
+
+func writeLengthPrefixed(w io.Writer, chunks ...[]byte) (int64, error) {
+	var total uint64
+	for _, chunk := range chunks {
+		total += uint64(len(chunk))
+	}
+	var buf [binary.MaxVarintLen64]byte
+	n, err := w.Write(buf[:binary.PutUvarint(buf[:], total)])
+	written := int64(n)
+	if err != nil {
+		return written, err
+	}
+	for _, chunk := range chunks {
+		n, err := w.Write(chunk)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// sectionRef describes where a section's block data can be found.
+// Offsets are from the start of the payload (e.g., where the CARv1 header begins).
+type sectionRef struct {
+	cid        cid.Cid
+	dataOffset int64
+	dataLength int64
+}
+
+// sectionScanner reads sections sequentially from the payload, keeping track of offsets as it goes.
+type sectionScanner struct {
+	r       *bufio.Reader
+	offset  int64 // offset of the next unread byte, relative to the start of the payload.
+	maxSize uint64
+}
+
+// next reads the varint and CID of the next section, leaving the block data unread.
+// The caller must either read or skip exactly ref.dataLength bytes before calling next again.
+// io.EOF is returned (unwrapped) if the payload ends cleanly at a section boundary.
+func (s *sectionScanner) next() (sectionOffset int64, ref sectionRef, err error) {
+	sectionOffset = s.offset
+	sectionLen, err := binary.ReadUvarint(s.r)
+	if err != nil {
+		if err == io.EOF {
+			return 0, ref, io.EOF
+		}
+		return 0, ref, fmt.Errorf("car: invalid section length at offset %d: %w", sectionOffset, err)
+	}
+	if sectionLen == 0 {
+		// Some historical writers padded the end of a payload with zeros; treat that as the end.
+		return 0, ref, io.EOF
+	}
+	if sectionLen > s.maxSize {
+		return 0, ref, fmt.Errorf("car: section length %d at offset %d exceeds maximum allowed size %d", sectionLen, sectionOffset, s.maxSize)
+	}
+	varintLen := int64(uvarintSize(sectionLen))
+	cidLen, c, err := cid.CidFromReader(s.r)
+	if err != nil {
+		return 0, ref, fmt.Errorf("car: invalid CID in section at offset %d: %w", sectionOffset, err)
+	}
+	if uint64(cidLen) > sectionLen {
+		return 0, ref, fmt.Errorf("car: CID in section at offset %d is longer than the section", sectionOffset)
+	}
+	ref = sectionRef{
+		cid:        c,
+		dataOffset: sectionOffset + varintLen + int64(cidLen),
+		dataLength: int64(sectionLen) - int64(cidLen),
+	}
+	s.offset = ref.dataOffset
+	return sectionOffset, ref, nil
+}
+
+// skip discards the block data of the section most recently returned by next.
+func (s *sectionScanner) skip(ref sectionRef) error {
+	n, err := s.r.Discard(int(ref.dataLength))
+	s.offset += int64(n)
+	if err != nil {
+		return fmt.Errorf("car: truncated section at offset %d: %w", ref.dataOffset, io.ErrUnexpectedEOF)
+	}
+	return nil
+}
+
+// read reads the block data of the section most recently returned by next.
+func (s *sectionScanner) read(ref sectionRef) ([]byte, error) {
+	data := make([]byte, ref.dataLength)
+	n, err := io.ReadFull(s.r, data)
+	s.offset += int64(n)
+	if err != nil {
+		return nil, fmt.Errorf("car: truncated section at offset %d: %w", ref.dataOffset, io.ErrUnexpectedEOF)
+	}
+	return data, nil
+}
+
+// readSectionAt reads the varint and CID of the section beginning at the given offset of the payload.
+func readSectionAt(payload io.ReaderAt, sectionOffset int64, maxSize uint64) (sectionRef, error) {
+	s := sectionScanner{
+		r:       bufio.NewReaderSize(io.NewSectionReader(payload, sectionOffset, 1<<62), 64),
+		offset:  sectionOffset,
+		maxSize: maxSize,
+	}
+	_, ref, err := s.next()
+	if err == io.EOF {
+		return ref, fmt.Errorf("car: no section at offset %d: %w", sectionOffset, io.ErrUnexpectedEOF)
+	}
+	return ref, err
+}
@@ -0,0 +1,84 @@
+package car
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+)
+
+// WriteSelective writes a CAR containing the blocks visited by a traversal of the given selector, starting at the root link.
+// The root link must be a cidlink.Link; it is the only root listed in the CAR's header.
+//
+// Blocks are loaded from the LinkSystem (which must have its StorageReadOpener configured),
+// and are written to the CAR in the order the traversal loads them, which is deterministic for a given selector and graph.
+// This includes any blocks loaded by ADLs which the selector asks to interpret data with,
+// if the LinkSystem's KnownReifiers includes them.
+//
+// The options are the same as for NewWritableStorage:
+// a CARv2 is written by default, which requires w to be an io.WriterAt;
+// use the WriteAsCarV1 option to write a CARv1 to any io.Writer.
+//
+// Nodes are loaded using basicnode.Prototype.Any, unless a link is found within typed data,
+// in which case the prototype that the schema suggests for the link's target is used.
+func WriteSelective(ctx context.Context, lsys linking.LinkSystem, w io.Writer, root datamodel.Link, sel selector.Selector, opts ...Option) error {
+	rootCid, ok := root.(cidlink.Link)
+	if !ok {
+		return fmt.Errorf("car: root link must be a cidlink.Link; got %T", root)
+	}
+	if lsys.StorageReadOpener == nil {
+		return fmt.Errorf("car: no StorageReadOpener configured on the LinkSystem")
+	}
+	wc, err := NewWritableStorage(w, []cid.Cid{rootCid.Cid}, opts...)
+	if err != nil {
+		return err
+	}
+
+	// Copy every block the traversal loads into the CAR on the way past.
+	readOpener := lsys.StorageReadOpener
+	lsys.StorageReadOpener = func(lctx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		r, err := readOpener(lctx, lnk)
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if err := wc.Put(lctx.Ctx, lnk.Binary(), data); err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(data), nil
+	}
+
+	rootNode, err := lsys.Load(linking.LinkContext{Ctx: ctx}, root, basicnode.Prototype.Any)
+	if err != nil {
+		return fmt.Errorf("car: failed to load root %s: %w", root, err)
+	}
+	prog := traversal.Progress{
+		Cfg: &traversal.Config{
+			Ctx:        ctx,
+			LinkSystem: lsys,
+			LinkTargetNodePrototypeChooser: func(_ datamodel.Link, lctx linking.LinkContext) (datamodel.NodePrototype, error) {
+				if tlnkNd, ok := lctx.LinkNode.(schema.TypedLinkNode); ok {
+					return tlnkNd.LinkTargetNodePrototype(), nil
+				}
+				return basicnode.Prototype.Any, nil
+			},
+		},
+	}
+	if err := prog.WalkAdv(rootNode, sel, func(traversal.Progress, datamodel.Node, traversal.VisitReason) error { return nil }); err != nil {
+		return err
+	}
+	return wc.Finalize()
+}
@@ -0,0 +1,152 @@
+package car
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+)
+
+// WritableCar writes blocks into a CAR file as they are put.
+//
+// WritableCar conforms to the storage.WritableStorage API,
+// and also to storage.StreamingWritableStorage.
+// (Streaming writes are buffered in memory until committed,
+// because the length of a section must be written before its content.)
+//
+// Blocks are written in the order they are put.
+// Putting a key which has already been put is a no-op, so each block appears in the CAR at most once.
+//
+// Finalize must be called when all blocks have been written.
+// For CARv1, this only marks the WritableCar as closed;
+// for CARv2, this is when the index and the header are written, and before then the file is incomplete.
+//
+// WritableCar is safe for concurrent use.
+type WritableCar struct {
+	mu        sync.Mutex
+	cfg       config
+	w         io.Writer   // where the payload is written.  For CARv2, this wraps wa, starting at the payload offset.
+	wa        io.WriterAt // only present for CARv2.
+	offset    int64       // current offset into the payload.
+	idx       *index
+	seen      map[string]struct{}
+	finalized bool
+}
+
+// NewWritableStorage begins writing a CAR with the given roots.
+//
+// By default, a CARv2 (including an index) is written, in which case the writer must implement io.WriterAt,
+// because the header can only be written after all the blocks are.
+// If the WriteAsCarV1 option is given, a CARv1 is written, and any io.Writer will do;
+// in this case, the header is written immediately.
+func NewWritableStorage(w io.Writer, roots []cid.Cid, opts ...Option) (*WritableCar, error) {
+	cfg := applyOptions(opts)
+	wc := &WritableCar{
+		cfg:  cfg,
+		idx:  newIndex(),
+		seen: make(map[string]struct{}),
+	}
+	if cfg.writeAsCarV1 {
+		wc.w = w
+	} else {
+		wa, ok := w.(io.WriterAt)
+		if !ok {
+			return nil, fmt.Errorf("car: writing CARv2 requires an io.WriterAt; use the WriteAsCarV1 option to write to a plain io.Writer")
+		}
+		wc.wa = wa
+		wc.w = io.NewOffsetWriter(wa, pragmaSize+v2HeaderSize)
+	}
+	n, err := v1Header{version: 1, roots: roots}.encode(wc.w)
+	wc.offset += n
+	if err != nil {
+		return nil, err
+	}
+	return wc, nil
+}
+
+// Has implements go-ipld-prime/storage.Storage.Has.
+// It reports whether the key has been put into this CAR so far.
+func (wc *WritableCar) Has(ctx context.Context, key string) (bool, error) {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	_, exists := wc.seen[key]
+	return exists, nil
+}
+
+// Put implements go-ipld-prime/storage.WritableStorage.Put.
+func (wc *WritableCar) Put(ctx context.Context, key string, content []byte) error {
+	c, err := cid.Cast([]byte(key))
+	if err != nil {
+		return fmt.Errorf("car: key is not a valid CID: %w", err)
+	}
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if wc.finalized {
+		return fmt.Errorf("car: cannot put: already finalized")
+	}
+	if _, exists := wc.seen[key]; exists {
+		return nil
+	}
+	sectionOffset := wc.offset
+	n, err := writeLengthPrefixed(wc.w, []byte(key), content)
+	wc.offset += n
+	if err != nil {
+		return err
+	}
+	wc.seen[key] = struct{}{}
+	return wc.idx.insert(c, uint64(sectionOffset))
+}
+
+// PutStream implements go-ipld-prime/storage.StreamingWritableStorage.PutStream.
+func (wc *WritableCar) PutStream(ctx context.Context) (io.Writer, func(key string) error, error) {
+	var buf bytes.Buffer
+	var committed bool
+	return &buf, func(key string) error {
+		if committed {
+			return fmt.Errorf("WriteCommitter already used")
+		}
+		committed = true
+		if key == "" {
+			return nil
+		}
+		return wc.Put(ctx, key, buf.Bytes())
+	}, nil
+}
+
+// Finalize completes the CAR.
+// For CARv2, the index (unless disabled by the SkipIndex option) and the header are written.
+// No further blocks can be put after Finalize has been called.
+// Finalize does not close the underlying writer.
+func (wc *WritableCar) Finalize() error {
+	wc.mu.Lock()
+	defer wc.mu.Unlock()
+	if wc.finalized {
+		return fmt.Errorf("car: already finalized")
+	}
+	wc.finalized = true
+	if wc.wa == nil {
+		return nil
+	}
+	hdr := v2Header{
+		dataOffset: pragmaSize + v2HeaderSize,
+		dataSize:   uint64(wc.offset),
+	}
+	if !wc.cfg.skipIndex {
+		hdr.characteristics[0] |= fullyIndexedCharacteristic
+		hdr.indexOffset = hdr.dataOffset + hdr.dataSize
+		var buf bytes.Buffer
+		if err := wc.idx.marshal(&buf); err != nil {
+			return err
+		}
+		if _, err := wc.wa.WriteAt(buf.Bytes(), int64(hdr.indexOffset)); err != nil {
+			return err
+		}
+	}
+	if _, err := wc.wa.WriteAt(append(append([]byte{}, pragma...), hdr.marshal()...), 0); err != nil {
+		return err
+	}
+	return nil
+}
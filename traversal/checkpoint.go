@@ -0,0 +1,265 @@
+package traversal
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
+	"github.com/ipld/go-ipld-prime/schema"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+)
+
+// Checkpoint is a snapshot of the state of a walk, taken part way through it,
+// from which the walk can later be resumed -- possibly in another process, since a Checkpoint can be
+// turned into a Node (see Checkpoint.Node and ParseCheckpoint) and encoded with any codec, such as dag-cbor.
+//
+// A Checkpoint records the root and selector the walk was started with, the path it had reached,
+// what was left of its Budget, and the links it had seen (if Config.LinkVisitOnlyOnce was set).
+// Resuming a walk from a Checkpoint, with ResumeWalkMatching or ResumeWalkAdv,
+// uses StartAtPath to get back to where the walk was: only the blocks on the path are loaded again,
+// and none of the nodes before it are visited again.
+type Checkpoint struct {
+	Root      datamodel.Link // The link the walk started from.
+	Selector  datamodel.Node // The selector the walk was started with, in its serial form.
+	Path      datamodel.Path
+	Budget    *Budget // May be nil, if the walk had no Budget.
+	SeenLinks []datamodel.Link
+}
+
+// Checkpoint takes a Checkpoint of a walk at the current position.
+// It's meant to be called from within a visit function, with the Progress it was given.
+//
+// The root and selector must be the ones the walk was started with:
+// a walk only has the loaded root Node and compiled Selector, so can't provide them itself.
+//
+// When the walk is resumed, it starts by visiting the node the Checkpoint was taken at again,
+// so the Checkpoint's Budget is what was left before that node (and the link to it, if any) was counted.
+// If the Checkpoint is taken before handling the node, nothing is missed or repeated.
+func (prog Progress) Checkpoint(root datamodel.Link, sel datamodel.Node) Checkpoint {
+	cp := Checkpoint{
+		Root:     root,
+		Selector: sel,
+		Path:     prog.Path,
+		Budget:   prog.Budget.Clone(),
+	}
+	if cp.Budget != nil {
+		cp.Budget.NodeBudget++
+		if prog.LastBlock.Link != nil && prog.LastBlock.Path.String() == prog.Path.String() {
+			cp.Budget.LinkBudget++
+		}
+	}
+	if len(prog.SeenLinks) > 0 {
+		cp.SeenLinks = make([]datamodel.Link, 0, len(prog.SeenLinks))
+		for lnk := range prog.SeenLinks {
+			cp.SeenLinks = append(cp.SeenLinks, lnk)
+		}
+		// Sort the links, so the same state always produces the same data.
+		sort.Slice(cp.SeenLinks, func(i, j int) bool {
+			return bytes.Compare([]byte(cp.SeenLinks[i].Binary()), []byte(cp.SeenLinks[j].Binary())) < 0
+		})
+	}
+	return cp
+}
+
+// checkpointRaw is the serial form of a Checkpoint, described by checkpointType.
+type checkpointRaw struct {
+	Root      datamodel.Link
+	Selector  datamodel.Node
+	Path      []string
+	Budget    *budgetRaw
+	SeenLinks []datamodel.Link
+}
+
+type budgetRaw struct {
+	Nodes int64
+	Links int64
+}
+
+// checkpointType is the schema for the serial form of a Checkpoint:
+//
+//	type Checkpoint struct {
+//		root Link
+//		selector Any
+//		path [String]
+//		budget optional Budget
+//		seenLinks [Link]
+//	}
+//
+//	type Budget struct {
+//		nodes Int
+//		links Int
+//	}
+var checkpointType schema.Type
+
+func init() {
+	var ts schema.TypeSystem
+	ts.Init()
+
+	ts.Accumulate(schema.SpawnString("String"))
+	ts.Accumulate(schema.SpawnInt("Int"))
+	ts.Accumulate(schema.SpawnLink("Link"))
+	ts.Accumulate(schema.SpawnAny("Any"))
+	ts.Accumulate(schema.SpawnList("List__String", "String", false))
+	ts.Accumulate(schema.SpawnList("List__Link", "Link", false))
+	ts.Accumulate(schema.SpawnStruct("Checkpoint",
+		[]schema.StructField{
+			schema.SpawnStructField("root", "Link", false, false),
+			schema.SpawnStructField("selector", "Any", false, false),
+			schema.SpawnStructField("path", "List__String", false, false),
+			schema.SpawnStructField("budget", "Budget", true, false),
+			schema.SpawnStructField("seenLinks", "List__Link", false, false),
+		},
+		schema.StructRepresentation_Map{},
+	))
+	ts.Accumulate(schema.SpawnStruct("Budget",
+		[]schema.StructField{
+			schema.SpawnStructField("nodes", "Int", false, false),
+			schema.SpawnStructField("links", "Int", false, false),
+		},
+		schema.StructRepresentation_Map{},
+	))
+
+	if errs := ts.ValidateGraph(); errs != nil {
+		for _, err := range errs {
+			panic(err)
+		}
+	}
+	checkpointType = ts.TypeByName("Checkpoint")
+}
+
+// Node returns the Checkpoint in its serial form, ready to be encoded.
+// ParseCheckpoint turns the data back into a Checkpoint.
+//
+// Path segments are recorded as strings, which is how they will be after parsing, too;
+// this makes no difference to resuming a walk, since path segments compare equal by their string form.
+func (cp Checkpoint) Node() datamodel.Node {
+	raw := &checkpointRaw{
+		Root:      cp.Root,
+		Selector:  cp.Selector,
+		Path:      make([]string, cp.Path.Len()),
+		SeenLinks: cp.SeenLinks,
+	}
+	for i, ps := range cp.Path.Segments() {
+		raw.Path[i] = ps.String()
+	}
+	if cp.Budget != nil {
+		raw.Budget = &budgetRaw{Nodes: cp.Budget.NodeBudget, Links: cp.Budget.LinkBudget}
+	}
+	if raw.SeenLinks == nil {
+		raw.SeenLinks = []datamodel.Link{}
+	}
+	return bindnode.Wrap(raw, checkpointType).Representation()
+}
+
+// ParseCheckpoint reads a Checkpoint from data in the form produced by Checkpoint.Node.
+func ParseCheckpoint(n datamodel.Node) (Checkpoint, error) {
+	nb := bindnode.Prototype((*checkpointRaw)(nil), checkpointType).Representation().NewBuilder()
+	if err := nb.AssignNode(n); err != nil {
+		return Checkpoint{}, fmt.Errorf("traversal: invalid checkpoint: %w", err)
+	}
+	raw := bindnode.Unwrap(nb.Build()).(*checkpointRaw)
+	cp := Checkpoint{
+		Root:     raw.Root,
+		Selector: raw.Selector,
+	}
+	segs := make([]datamodel.PathSegment, len(raw.Path))
+	for i, s := range raw.Path {
+		segs[i] = datamodel.PathSegmentOfString(s)
+	}
+	cp.Path = datamodel.NewPathNocopy(segs)
+	if raw.Budget != nil {
+		cp.Budget = &Budget{NodeBudget: raw.Budget.Nodes, LinkBudget: raw.Budget.Links}
+	}
+	if len(raw.SeenLinks) > 0 {
+		cp.SeenLinks = raw.SeenLinks
+	}
+	return cp, nil
+}
+
+// ResumeWalkMatching resumes a WalkMatching from a Checkpoint,
+// calling the VisitFn for the matches from the Checkpoint's path onwards.
+//
+// The root of the walk is loaded from the Checkpoint's link, using the LinkSystem in the Config,
+// and the selector is compiled from the Checkpoint's selector node.
+// The rest of the Config is used as given, except for StartAtPath, which is set to the Checkpoint's path;
+// the Config should otherwise be the same as the original walk's, for the resumed walk to carry on the way it would have.
+// The Checkpoint's Budget and SeenLinks replace any on the Progress.
+//
+// Budgets only start to be charged once the walk reaches the Checkpoint's path,
+// and links in SeenLinks are loaded again if they're on the way to it,
+// so that getting back to where the walk was costs nothing, and is never prevented by the walk's own state.
+func (prog Progress) ResumeWalkMatching(cp Checkpoint, fn VisitFn) error {
+	return prog.ResumeWalkAdv(cp, func(prog Progress, n datamodel.Node, tr VisitReason) error {
+		if tr != VisitReason_SelectionMatch {
+			return nil
+		}
+		return fn(prog, n)
+	})
+}
+
+// ResumeWalkAdv is the equivalent of ResumeWalkMatching for WalkAdv.
+func (prog Progress) ResumeWalkAdv(cp Checkpoint, fn AdvVisitFn) error {
+	n, s, err := prog.resume(cp)
+	if err != nil {
+		return err
+	}
+	if err := prog.startLinkLoader(); err != nil {
+		return err
+	}
+	defer prog.stopLinkLoader()
+	return prog.walkBlock(n, s, fn)
+}
+
+// resume sets up the Progress to resume a walk from a Checkpoint, returning the root node and selector to walk.
+func (prog *Progress) resume(cp Checkpoint) (datamodel.Node, selector.Selector, error) {
+	if cp.Root == nil || cp.Selector == nil {
+		return nil, nil, fmt.Errorf("traversal: cannot resume from a checkpoint without a root and selector")
+	}
+	s, err := selector.CompileSelector(cp.Selector)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cfg Config
+	if prog.Cfg != nil {
+		cfg = *prog.Cfg
+	}
+	cfg.StartAtPath = cp.Path
+	prog.Cfg = &cfg
+	prog.init()
+	prog.PastStartAtPath = false
+	for _, lnk := range cp.SeenLinks {
+		if prog.SeenLinks != nil {
+			prog.SeenLinks[lnk] = struct{}{}
+		}
+	}
+	prog.Budget = nil
+	prog.resumedBudget = cp.Budget.Clone()
+
+	lnkCtx := linking.LinkContext{Ctx: prog.Cfg.Ctx, LinkPath: prog.Path}
+	np, err := prog.Cfg.LinkTargetNodePrototypeChooser(cp.Root, lnkCtx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resuming traversal: could not load root %q: %w", cp.Root, err)
+	}
+	n, err := prog.Cfg.LinkSystem.Load(lnkCtx, cp.Root, np)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resuming traversal: could not load root %q: %w", cp.Root, err)
+	}
+	return n, s, nil
+}
+
+// applyResumedBudget switches a resumed walk over to the Budget from its Checkpoint,
+// once the walk has reached the path it's resuming from.
+func (prog *Progress) applyResumedBudget() {
+	if prog.resumedBudget != nil && (prog.PastStartAtPath || prog.Path.Len() >= prog.Cfg.StartAtPath.Len()) {
+		prog.Budget, prog.resumedBudget = prog.resumedBudget, nil
+	}
+}
+
+// onWayToStartAtPath reports whether the current position is on the path to StartAtPath (or is StartAtPath itself).
+func (prog Progress) onWayToStartAtPath() bool {
+	return prog.Cfg.StartAtPath.Len() > 0 && !prog.PastStartAtPath && prog.Path.Len() <= prog.Cfg.StartAtPath.Len()
+}
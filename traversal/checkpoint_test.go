@@ -0,0 +1,143 @@
+package traversal_test
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/codec/dagcbor"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+// countingLinkSystem returns a LinkSystem reading from the test store, and a counter of the loads it's done.
+func countingLinkSystem() (linking.LinkSystem, *int) {
+	var loads int
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		loads++
+		return store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	return lsys, &loads
+}
+
+func TestWalkResumeFromCheckpoint(t *testing.T) {
+	wideDAG := mkWideDAG()
+	fieldsSel, err := selectorparse.ParseJSONSelector(`{"R":{"l":{"none":{}},":>":{"f":{"f>":{
+		"index":{".":{}},
+		"leaf1":{".":{}},
+		"shared":{".":{}},
+		"linkedMap":{"a":{">":{"@":{}}}},
+		"linkedList":{"i":{"i":2,">":{"@":{}}}}
+	}}}}}`)
+	qt.Assert(t, err, qt.IsNil)
+
+	for _, tc := range []struct {
+		name        string
+		root        datamodel.Link
+		sel         datamodel.Node
+		cfg         traversal.Config
+		budget      *traversal.Budget
+		concurrency int
+	}{
+		{"explore all", rootNodeLnk, selectorparse.CommonSelector_ExploreAllRecursively, traversal.Config{}, nil, 0},
+		{"explore some fields", rootNodeLnk, fieldsSel, traversal.Config{}, nil, 0},
+		{"wide, visit once", wideDAG, selectorparse.CommonSelector_ExploreAllRecursively, traversal.Config{LinkVisitOnlyOnce: true}, nil, 0},
+		{"wide, budget", wideDAG, selectorparse.CommonSelector_ExploreAllRecursively, traversal.Config{}, &traversal.Budget{NodeBudget: 1000, LinkBudget: 1000}, 0},
+		{"wide, node budget runs out", wideDAG, selectorparse.CommonSelector_ExploreAllRecursively, traversal.Config{}, &traversal.Budget{NodeBudget: 25, LinkBudget: 1000}, 0},
+		{"wide, link budget runs out", wideDAG, selectorparse.CommonSelector_ExploreAllRecursively, traversal.Config{LinkVisitOnlyOnce: true}, &traversal.Budget{NodeBudget: 1000, LinkBudget: 12}, 0},
+		{"wide, concurrent", wideDAG, selectorparse.CommonSelector_ExploreAllRecursively, traversal.Config{LinkVisitOnlyOnce: true}, &traversal.Budget{NodeBudget: 1000, LinkBudget: 20}, 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sel, err := selector.CompileSelector(tc.sel)
+			qt.Assert(t, err, qt.IsNil)
+			lsys, loads := countingLinkSystem()
+			cfg := tc.cfg
+			cfg.LinkSystem = lsys
+			cfg.LinkTargetNodePrototypeChooser = basicnode.Chooser
+
+			// Walk the whole way, taking a checkpoint at every visit, encoded with dag-cbor.
+			root, err := lsys.Load(linking.LinkContext{}, tc.root, basicnode.Prototype.Any)
+			qt.Assert(t, err, qt.IsNil)
+			var full []visit
+			var checkpoints [][]byte
+			var fullBudget *traversal.Budget
+			prog := traversal.Progress{Cfg: &cfg, Budget: tc.budget.Clone()}
+			fullErr := prog.WalkAdv(root, sel, func(prog traversal.Progress, n datamodel.Node, vr traversal.VisitReason) error {
+				var buf bytes.Buffer
+				qt.Assert(t, dagcbor.Encode(prog.Checkpoint(tc.root, tc.sel).Node(), &buf), qt.IsNil)
+				checkpoints = append(checkpoints, buf.Bytes())
+				full = append(full, visit{prog.Path.String(), vr})
+				fullBudget = prog.Budget
+				return nil
+			})
+			fullLoads := *loads
+			qt.Assert(t, len(full) > 5, qt.IsTrue)
+
+			// Resuming from each checkpoint should pick up exactly where the walk was,
+			// and end the same way, with the same budget left, without loading more than the whole walk did.
+			for i, data := range checkpoints {
+				nb := basicnode.Prototype.Any.NewBuilder()
+				qt.Assert(t, dagcbor.Decode(nb, bytes.NewReader(data)), qt.IsNil)
+				cp, err := traversal.ParseCheckpoint(nb.Build())
+				qt.Assert(t, err, qt.IsNil)
+
+				resumeCfg := tc.cfg
+				resumeCfg.LinkSystem = lsys
+				resumeCfg.LinkTargetNodePrototypeChooser = basicnode.Chooser
+				resumeCfg.LinkLoadConcurrency = tc.concurrency
+				*loads = 0
+				var resumed []visit
+				var resumedBudget *traversal.Budget
+				err = traversal.Progress{Cfg: &resumeCfg}.ResumeWalkAdv(cp, func(prog traversal.Progress, n datamodel.Node, vr traversal.VisitReason) error {
+					resumed = append(resumed, visit{prog.Path.String(), vr})
+					resumedBudget = prog.Budget
+					return nil
+				})
+				qt.Check(t, resumed, qt.DeepEquals, full[i:], qt.Commentf("resuming from visit %d (%s)", i, full[i].Path))
+				if fullErr == nil {
+					qt.Check(t, err, qt.IsNil)
+				} else {
+					qt.Assert(t, err, qt.IsNotNil)
+					qt.Check(t, err.Error(), qt.Equals, fullErr.Error())
+				}
+				qt.Check(t, resumedBudget, qt.DeepEquals, fullBudget)
+				qt.Check(t, *loads <= fullLoads+1, qt.IsTrue, qt.Commentf("%d loads resuming from visit %d, %d for the whole walk", *loads, i, fullLoads))
+			}
+		})
+	}
+}
+
+func TestCheckpointNode(t *testing.T) {
+	cp := traversal.Checkpoint{
+		Root:      rootNodeLnk,
+		Selector:  selectorparse.CommonSelector_MatchPoint,
+		Path:      datamodel.NewPath([]datamodel.PathSegment{datamodel.PathSegmentOfString("linkedList"), datamodel.PathSegmentOfInt(2)}),
+		Budget:    &traversal.Budget{NodeBudget: 10, LinkBudget: 3},
+		SeenLinks: []datamodel.Link{leafAlphaLnk, middleListNodeLnk},
+	}
+	var buf bytes.Buffer
+	qt.Assert(t, dagcbor.Encode(cp.Node(), &buf), qt.IsNil)
+	nb := basicnode.Prototype.Any.NewBuilder()
+	qt.Assert(t, dagcbor.Decode(nb, &buf), qt.IsNil)
+	cp2, err := traversal.ParseCheckpoint(nb.Build())
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, cp2.Root, qt.Equals, cp.Root)
+	qt.Check(t, datamodel.DeepEqual(cp2.Selector, cp.Selector), qt.IsTrue)
+	qt.Check(t, cp2.Path.String(), qt.Equals, "linkedList/2")
+	qt.Check(t, cp2.Budget, qt.DeepEquals, cp.Budget)
+	qt.Assert(t, cp2.SeenLinks, qt.HasLen, len(cp.SeenLinks))
+	for i := range cp.SeenLinks {
+		qt.Check(t, cp2.SeenLinks[i], qt.Equals, cp.SeenLinks[i])
+	}
+
+	_, err = traversal.ParseCheckpoint(basicnode.NewString("nope"))
+	qt.Check(t, err, qt.ErrorMatches, "traversal: invalid checkpoint: .*")
+}
@@ -0,0 +1,325 @@
+package traversal
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+)
+
+// linkLoader loads links ahead of a walk, using a pool of worker goroutines,
+// when Config.LinkLoadConcurrency is more than one.
+//
+// The walk itself still runs on a single goroutine, and takes the results in its own order:
+// when it reaches a link, it takes the result if the load is done, waits if the load is in flight,
+// or does the load itself if no worker has started it yet.
+// Workers always start whichever pending load comes first in the walk's order,
+// so the blocks the walk will need soonest are loaded first.
+//
+// The number of loads which are in flight or done but not yet taken by the walk is limited,
+// so that the workers can't get arbitrarily far ahead of the walk, holding many blocks in memory.
+type linkLoader struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	queue     loadQueue               // loads that haven't been started, by position in the walk.
+	loads     map[string]*pendingLoad // all loads the walk hasn't taken yet, by path.
+	inFlight  int
+	unclaimed int // loads that are done, but haven't been taken by the walk.
+	limit     int
+	closed    bool
+
+	wg sync.WaitGroup
+}
+
+type loadState uint8
+
+const (
+	loadQueued loadState = iota
+	loadStarted
+	loadDone
+	loadClaimed // taken by the walk, or abandoned because the walk has moved on.
+)
+
+type pendingLoad struct {
+	prog     Progress // at the position of the link; used to do the load.
+	lnk      datamodel.Link
+	linkNode datamodel.Node
+	parent   datamodel.Node
+
+	childIdx int  // the position of the link among its parent's children.
+	picked   bool // used by unordered walks to track which children have been walked.
+	heapIdx  int
+	state    loadState
+	done     chan struct{}
+	n        datamodel.Node
+	err      error
+}
+
+// child is a child of a node that a walk will explore.
+type child struct {
+	ps datamodel.PathSegment
+	v  datamodel.Node
+}
+
+func newLinkLoader(workers int) *linkLoader {
+	l := &linkLoader{
+		loads: make(map[string]*pendingLoad),
+		limit: 2 * workers,
+	}
+	l.cond = sync.NewCond(&l.mu)
+	l.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go l.work()
+	}
+	return l
+}
+
+func (l *linkLoader) work() {
+	defer l.wg.Done()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for {
+		for !l.closed && (len(l.queue) == 0 || l.inFlight+l.unclaimed >= l.limit) {
+			l.cond.Wait()
+		}
+		if l.closed {
+			return
+		}
+		pl := heap.Pop(&l.queue).(*pendingLoad)
+		pl.state = loadStarted
+		l.inFlight++
+		l.mu.Unlock()
+		n, err := pl.prog.loadLinkNow(pl.lnk, pl.linkNode, pl.parent)
+		l.mu.Lock()
+		l.inFlight--
+		pl.n, pl.err = n, err
+		if pl.state == loadStarted {
+			pl.state = loadDone
+			l.unclaimed++
+		}
+		close(pl.done)
+		l.cond.Broadcast()
+	}
+}
+
+// close stops the workers, waiting for any loads they're doing to finish.
+func (l *linkLoader) close() {
+	l.mu.Lock()
+	l.closed = true
+	l.cond.Broadcast()
+	l.mu.Unlock()
+	l.wg.Wait()
+}
+
+// schedule queues loads for the links among the children of n which the walk is going to load,
+// and returns a batch of them, which must be released when the walk is done with n.
+//
+// It follows the same rules as the walk does to decide which links will be loaded,
+// and additionally never queues more links than are left in the link budget.
+func (l *linkLoader) schedule(prog Progress, s selector.Selector, n datamodel.Node, children []child) *loadBatch {
+	batch := &loadBatch{l: l, byChild: make(map[int]*pendingLoad)}
+	budget := int64(-1)
+	if prog.Budget != nil {
+		budget = prog.Budget.LinkBudget
+	} else if prog.resumedBudget != nil {
+		budget = prog.resumedBudget.LinkBudget
+	}
+	skipping := prog.Cfg.StartAtPath.Len() > 0 && !prog.PastStartAtPath && prog.Path.Len() < prog.Cfg.StartAtPath.Len()
+	var seen map[datamodel.Link]struct{}
+	if prog.Cfg.LinkVisitOnlyOnce {
+		seen = make(map[datamodel.Link]struct{})
+	}
+	for i, c := range children {
+		if skipping {
+			if !c.ps.Equals(prog.Cfg.StartAtPath.Segments()[prog.Path.Len()]) {
+				continue
+			}
+			skipping = false
+		}
+		if c.v.Kind() != datamodel.Kind_Link {
+			continue
+		}
+		if sNext, err := s.Explore(n, c.ps); err != nil || sNext == nil {
+			continue
+		}
+		lnk, _ := c.v.AsLink()
+		if seen != nil {
+			if _, ok := prog.SeenLinks[lnk]; ok {
+				continue
+			}
+			if _, ok := seen[lnk]; ok {
+				continue
+			}
+			seen[lnk] = struct{}{}
+		}
+		if budget == 0 {
+			break
+		}
+		budget--
+		progNext := prog
+		progNext.Path = prog.Path.AppendSegment(c.ps)
+		progNext.walkPos = appendWalkPos(prog.walkPos, i)
+		batch.loads = append(batch.loads, &pendingLoad{
+			prog:     progNext,
+			lnk:      lnk,
+			linkNode: c.v,
+			parent:   n,
+			childIdx: i,
+			done:     make(chan struct{}),
+		})
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, pl := range batch.loads {
+		key := pl.prog.Path.String()
+		if _, exists := l.loads[key]; exists {
+			pl.state = loadClaimed
+			continue
+		}
+		l.loads[key] = pl
+		heap.Push(&l.queue, pl)
+		batch.byChild[pl.childIdx] = pl
+	}
+	l.cond.Broadcast()
+	return batch
+}
+
+// claim takes the load of the link at the given path, if one was scheduled, waiting for it to finish if it's in flight.
+// If no worker has started the load yet, it's removed from the queue, and claim returns false,
+// so that the caller does the load itself rather than wait.
+func (l *linkLoader) claim(path datamodel.Path) (*pendingLoad, bool) {
+	key := path.String()
+	l.mu.Lock()
+	pl, ok := l.loads[key]
+	if !ok {
+		l.mu.Unlock()
+		return nil, false
+	}
+	delete(l.loads, key)
+	state := pl.state
+	pl.state = loadClaimed
+	switch state {
+	case loadQueued:
+		heap.Remove(&l.queue, pl.heapIdx)
+		l.mu.Unlock()
+		return nil, false
+	case loadDone:
+		l.unclaimed--
+		l.cond.Broadcast()
+	}
+	l.mu.Unlock()
+	<-pl.done
+	return pl, true
+}
+
+// loadBatch is the set of loads scheduled for the children of one node.
+type loadBatch struct {
+	l       *linkLoader
+	loads   []*pendingLoad
+	byChild map[int]*pendingLoad
+}
+
+// release abandons any loads in the batch that the walk didn't take,
+// which it never will, once it's done with the node they're children of.
+func (b *loadBatch) release() {
+	l := b.l
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, pl := range b.loads {
+		switch pl.state {
+		case loadClaimed:
+			continue
+		case loadQueued:
+			heap.Remove(&l.queue, pl.heapIdx)
+		case loadDone:
+			l.unclaimed--
+		}
+		pl.state = loadClaimed
+		delete(l.loads, pl.prog.Path.String())
+	}
+	l.cond.Broadcast()
+}
+
+// has reports whether the child at the given position has a load in the batch.
+func (b *loadBatch) has(childIdx int) bool {
+	_, ok := b.byChild[childIdx]
+	return ok
+}
+
+// next picks the child an unordered walk should explore next, out of those with loads in the batch:
+// one whose load is done, if there is any; otherwise one whose load hasn't started,
+// which the walk will then do itself; otherwise it waits for a load in flight to finish.
+// It returns false when every child in the batch has been picked.
+func (b *loadBatch) next() (int, bool) {
+	l := b.l
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for {
+		var queued *pendingLoad
+		var inFlight bool
+		for _, pl := range b.loads {
+			if pl.picked || pl.state == loadClaimed {
+				continue
+			}
+			switch pl.state {
+			case loadDone:
+				pl.picked = true
+				return pl.childIdx, true
+			case loadQueued:
+				if queued == nil {
+					queued = pl
+				}
+			case loadStarted:
+				inFlight = true
+			}
+		}
+		if queued != nil {
+			queued.picked = true
+			return queued.childIdx, true
+		}
+		if !inFlight {
+			return 0, false
+		}
+		l.cond.Wait()
+	}
+}
+
+// appendWalkPos returns the position in the walk of the child at childIdx of the node at walkPos.
+// Positions are compared lexically, which orders them as a depth-first walk visits them.
+func appendWalkPos(walkPos []int, childIdx int) []int {
+	return append(walkPos[:len(walkPos):len(walkPos)], childIdx)
+}
+
+// loadQueue is a heap of pending loads, ordered by their position in the walk.
+type loadQueue []*pendingLoad
+
+func (q loadQueue) Len() int { return len(q) }
+func (q loadQueue) Less(i, j int) bool {
+	a, b := q[i].prog.walkPos, q[j].prog.walkPos
+	for k := 0; k < len(a) && k < len(b); k++ {
+		if a[k] != b[k] {
+			return a[k] < b[k]
+		}
+	}
+	return len(a) < len(b)
+}
+func (q loadQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].heapIdx = i
+	q[j].heapIdx = j
+}
+func (q *loadQueue) Push(x any) {
+	pl := x.(*pendingLoad)
+	pl.heapIdx = len(*q)
+	*q = append(*q, pl)
+}
+func (q *loadQueue) Pop() any {
+	old := *q
+	pl := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return pl
+}
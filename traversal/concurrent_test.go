@@ -0,0 +1,166 @@
+package traversal_test
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+)
+
+// mkWideDAG makes a DAG of a list of links to maps, each of which links to a few leaves,
+// some of which are shared, with plenty of sibling links to load concurrently.
+func mkWideDAG() datamodel.Link {
+	var branches []datamodel.Link
+	for i := 0; i < 6; i++ {
+		_, branch := encode(fluent.MustBuildMap(basicnode.Prototype.Map, 6, func(na fluent.MapAssembler) {
+			na.AssembleEntry("index").AssignInt(int64(i))
+			for j := 0; j < 4; j++ {
+				_, leaf := encode(basicnode.NewString(fmt.Sprintf("leaf %d", (i*4+j)%10)))
+				na.AssembleEntry(fmt.Sprintf("leaf%d", j)).AssignLink(leaf)
+			}
+			na.AssembleEntry("shared").AssignLink(leafAlphaLnk)
+		}))
+		branches = append(branches, branch)
+	}
+	_, root := encode(fluent.MustBuildList(basicnode.Prototype.List, int64(len(branches)), func(na fluent.ListAssembler) {
+		for _, branch := range branches {
+			na.AssembleValue().AssignLink(branch)
+		}
+	}))
+	return root
+}
+
+// slowLinkSystem returns a LinkSystem reading from the test store, with loads that take varying amounts of time,
+// so that concurrent loads complete out of order, and which reports the most loads it saw in flight at once.
+func slowLinkSystem() (linking.LinkSystem, func() int) {
+	var mu sync.Mutex
+	var inFlight, maxInFlight int
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(time.Duration(lnk.Binary()[len(lnk.Binary())-1]%4) * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	return lsys, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return maxInFlight
+	}
+}
+
+type visit struct {
+	Path   string
+	Reason traversal.VisitReason
+}
+
+func walkRecording(t *testing.T, prog traversal.Progress, root datamodel.Link, sel selector.Selector) ([]visit, error) {
+	t.Helper()
+	prog.Cfg.LinkTargetNodePrototypeChooser = basicnode.Chooser
+	n, err := prog.Cfg.LinkSystem.Load(linking.LinkContext{}, root, basicnode.Prototype.Any)
+	qt.Assert(t, err, qt.IsNil)
+	var visits []visit
+	err = prog.WalkAdv(n, sel, func(prog traversal.Progress, n datamodel.Node, reason traversal.VisitReason) error {
+		visits = append(visits, visit{prog.Path.String(), reason})
+		return nil
+	})
+	return visits, err
+}
+
+func TestWalkConcurrentLoading(t *testing.T) {
+	root := mkWideDAG()
+	exploreAll, err := selector.CompileSelector(selectorparse.CommonSelector_ExploreAllRecursively)
+	qt.Assert(t, err, qt.IsNil)
+	someFields, err := selectorparse.ParseAndCompileJSONSelector(`{"a":{">":{"f":{"f>":{"leaf1":{".":{}},"shared":{".":{}},"index":{".":{}}}}}}}`)
+	qt.Assert(t, err, qt.IsNil)
+
+	for _, tc := range []struct {
+		name   string
+		sel    selector.Selector
+		cfg    traversal.Config
+		budget *traversal.Budget
+	}{
+		{"explore all", exploreAll, traversal.Config{}, nil},
+		{"some fields", someFields, traversal.Config{}, nil},
+		{"visit links once", exploreAll, traversal.Config{LinkVisitOnlyOnce: true}, nil},
+		{"start at path", exploreAll, traversal.Config{StartAtPath: datamodel.ParsePath("3/leaf2")}, nil},
+		{"link budget", exploreAll, traversal.Config{}, &traversal.Budget{NodeBudget: 1000, LinkBudget: 12}},
+		{"node budget", exploreAll, traversal.Config{}, &traversal.Budget{NodeBudget: 30, LinkBudget: 1000}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lsys, _ := slowLinkSystem()
+			cfg := tc.cfg
+			cfg.LinkSystem = lsys
+			expected, expectedErr := walkRecording(t, traversal.Progress{Cfg: &cfg, Budget: tc.budget.Clone()}, root, tc.sel)
+
+			lsys, maxInFlight := slowLinkSystem()
+			cfg = tc.cfg
+			cfg.LinkSystem = lsys
+			cfg.LinkLoadConcurrency = 4
+			actual, actualErr := walkRecording(t, traversal.Progress{Cfg: &cfg, Budget: tc.budget.Clone()}, root, tc.sel)
+
+			qt.Check(t, actual, qt.DeepEquals, expected)
+			if expectedErr == nil {
+				qt.Check(t, actualErr, qt.IsNil)
+			} else {
+				qt.Assert(t, actualErr, qt.IsNotNil)
+				qt.Check(t, actualErr.Error(), qt.Equals, expectedErr.Error())
+			}
+			qt.Check(t, maxInFlight() > 1, qt.IsTrue, qt.Commentf("loads were not concurrent"))
+		})
+	}
+}
+
+func TestWalkConcurrentLoadingUnordered(t *testing.T) {
+	root := mkWideDAG()
+	sel, err := selector.CompileSelector(selectorparse.CommonSelector_ExploreAllRecursively)
+	qt.Assert(t, err, qt.IsNil)
+
+	lsys, _ := slowLinkSystem()
+	expected, err := walkRecording(t, traversal.Progress{Cfg: &traversal.Config{LinkSystem: lsys}}, root, sel)
+	qt.Assert(t, err, qt.IsNil)
+
+	lsys, _ = slowLinkSystem()
+	actual, err := walkRecording(t, traversal.Progress{Cfg: &traversal.Config{
+		LinkSystem:          lsys,
+		LinkLoadConcurrency: 4,
+		LinkLoadUnordered:   true,
+	}}, root, sel)
+	qt.Assert(t, err, qt.IsNil)
+
+	// The same nodes are visited, but not necessarily in the same order.
+	sortVisits := func(visits []visit) {
+		sort.Slice(visits, func(i, j int) bool { return visits[i].Path < visits[j].Path })
+	}
+	sortVisits(expected)
+	sortVisits(actual)
+	qt.Check(t, actual, qt.DeepEquals, expected)
+
+	_, err = walkRecording(t, traversal.Progress{Cfg: &traversal.Config{
+		LinkSystem:          lsys,
+		LinkLoadConcurrency: 4,
+		LinkLoadUnordered:   true,
+		StartAtPath:         datamodel.ParsePath("1"),
+	}}, root, sel)
+	qt.Check(t, err, qt.ErrorMatches, ".*LinkLoadUnordered cannot be used together with StartAtPath")
+}
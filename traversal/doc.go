@@ -75,6 +75,11 @@
 // Nodes and/or Links encountered before failing the traversal (with the
 // ErrBudgetExceeded error).
 //
+// A long walk can be resumed later, even in another process, by taking a
+// Checkpoint from the Progress given to a visit function, and encoding its
+// Node with any codec. ResumeWalkMatching and ResumeWalkAdv carry on from a
+// Checkpoint, loading only the blocks on the path back to where the walk was.
+//
 // The "Preloader" option provides a way to parallelize block loading in
 // environments where block loading is a high-latency operation (such as
 // fetching over the network).
@@ -86,6 +91,18 @@
 // A Preloader and a Budget option can be used on the same traversal, BUT the
 // Preloader may not receive the same links that the traversal wants to load
 // from the LinkSystem. Use with care. See notes below.
+//
+// Alternatively, the "LinkLoadConcurrency" option has WalkMatching and WalkAdv
+// load links themselves using a pool of goroutines, fetching the sibling links
+// the selector will explore ahead of time, while still calling visit functions
+// one at a time, in selector order. This one does respect Budgets and
+// LinkVisitOnlyOnce, but requires a LinkSystem that is safe for concurrent use.
+//
+// When walking typed nodes, links whose type points to a type with an advanced
+// representation (such as `&Big`, given `type Big {String:Int} representation advanced HAMT`)
+// are reified once loaded, using the NodeReifier registered under the ADL's name
+// in the LinkSystem's KnownReifiers, so the walk sees the type's data rather than the ADL's substrate.
+// It's an error to load such a link if no NodeReifier is registered for it.
 package traversal
 
 // Why only "point-mutation"?  This use-case gets core library support because
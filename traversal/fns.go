@@ -70,6 +70,11 @@ type Progress struct {
 
 	// SeenLinks is a set used to remember which links have been visited before, if Cfg.LinkVisitOnlyOnce is true.
 	SeenLinks map[datamodel.Link]struct{}
+
+	loader  *linkLoader // set during walks if Cfg.LinkLoadConcurrency is more than one.
+	walkPos []int       // the position of the current node in the walk, as child indexes from the root; only tracked if loader is set.
+
+	resumedBudget *Budget // set while a walk resumed from a Checkpoint is on its way back to where it was; see applyResumedBudget.
 }
 
 // Config is a set of options for a traversal. Set a Config on a Progress to customize the traversal.
@@ -99,6 +104,30 @@ type Config struct {
 	// Preload calls are not de-duplicated, it is up to the receiver to do so if desired.
 	// Beware of using both Budget and Preloader!  See the documentation on Progress for more information on this usage and the likely surprising effects.
 	Preloader preload.Loader
+
+	// LinkLoadConcurrency, if greater than one, makes WalkMatching and WalkAdv load links concurrently:
+	// whenever the walk reaches a map or list, the links among its children which the selector will explore are
+	// queued to be loaded by a pool of this many goroutines, in the order the walk will reach them.
+	// The walk itself is still done on one goroutine, and visits nodes in the same order as a walk without concurrency,
+	// so visit functions are still called one at a time, in the order the selector spec requires
+	// (unless LinkLoadUnordered is set).
+	//
+	// Budgets and LinkVisitOnlyOnce apply as usual; loads are never queued for links already in SeenLinks,
+	// nor for more links than are left in the link budget at the time, though they may still be made for links
+	// that the walk doesn't end up reaching -- for example, if the walk is halted by an error or a budget running out first.
+	//
+	// The LinkSystem and LinkTargetNodePrototypeChooser are called from multiple goroutines when this is set,
+	// so must be safe for concurrent use.
+	// Other walk functions, such as WalkTransforming and Focus, ignore this option.
+	LinkLoadConcurrency int
+
+	// LinkLoadUnordered relaxes the order in which a walk with LinkLoadConcurrency visits the children of each map or list:
+	// the children which aren't links (or whose loads haven't been queued) are still walked first, in order,
+	// but the children which are links are then walked in the order their loads complete,
+	// so that one slow load doesn't hold up the rest.
+	// The walk is still done on one goroutine, and each child's subtree is still walked before the next.
+	// LinkLoadUnordered can't be combined with StartAtPath, which depends on the order of the walk.
+	LinkLoadUnordered bool
 }
 
 // Budget is a set of monotonically-decrementing "budgets" for how many more steps we're willing to take before we should halt.
@@ -142,6 +142,9 @@ func (prog *Progress) get(n datamodel.Node, p datamodel.Path, trackProgress bool
 			// Load link!
 			prev = n
 			n, err = prog.Cfg.LinkSystem.Load(lnkCtx, lnk, np)
+			if err == nil {
+				n, err = prog.reifyAdvanced(lnkCtx, n)
+			}
 			if err != nil {
 				return nil, fmt.Errorf("error traversing node at %q: could not load link %q: %w", p.Truncate(i+1), lnk, err)
 			}
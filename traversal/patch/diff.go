@@ -0,0 +1,352 @@
+package patch
+
+import (
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+// DiffOptions can be used to customize the behavior of Diff.
+type DiffOptions struct {
+	// LinkSystem, if set, is used to load the blocks behind links that differ between the two trees,
+	// so that the differences in their content are described, rather than the link being replaced as a whole.
+	// Links which are equal are never loaded, since their content is known to be the same.
+	//
+	// The paths of the resulting operations then cross links, so the operations need to be applied by EvalLinked, rather than Eval.
+	LinkSystem *linking.LinkSystem
+
+	// Moves enables the detection of map entries which were renamed:
+	// when an entry is removed from a map and another entry with an equal value is added to the same map,
+	// a single "move" operation is produced, rather than a "remove" and an "add".
+	Moves bool
+
+	// MaxListEdit limits the effort spent working out the smallest set of insertions and removals
+	// that turns one list into another, which costs time and memory proportional to the lengths of the two lists multiplied.
+	// When the lists (after skipping the entries they start and end with in common) are longer than this, multiplied,
+	// the entries are compared pairwise by position instead, which still produces a correct patch, but may not be the smallest.
+	// If zero, DefaultMaxListEdit is used.
+	MaxListEdit int
+}
+
+// DefaultMaxListEdit is the limit used when DiffOptions.MaxListEdit is not set.
+const DefaultMaxListEdit = 1 << 20
+
+// Diff is a shortcut for DiffOptions.Diff with the default options.
+func Diff(a, b datamodel.Node) ([]Operation, error) {
+	return DiffOptions{}.Diff(a, b)
+}
+
+// Diff compares two Node trees, and returns the operations which turn a into b when applied by Eval.
+//
+// The operations are minimal for maps: entries are only added, removed or replaced where they differ,
+// and entries present in both are compared recursively.
+// For lists, the entries which the two lists have in common, in order, are kept,
+// and the rest are removed or added around them (or compared recursively, where an entry of a is replaced by an entry of b).
+// Where a node in a has a different kind than the node at the same position in b, it's replaced as a whole.
+//
+// Eval adds entries to the end of maps, so if b has entries in a different order than a does,
+// the result of applying the operations has the same entries as b but possibly in a different order.
+// Codecs which sort map entries, such as dag-json and dag-cbor, encode the two the same.
+//
+// Links which are equal are never looked behind; links which differ are replaced,
+// unless a LinkSystem is given in the options, in which case the blocks they point to are loaded and compared.
+func (opts DiffOptions) Diff(a, b datamodel.Node) ([]Operation, error) {
+	if opts.MaxListEdit == 0 {
+		opts.MaxListEdit = DefaultMaxListEdit
+	}
+	d := differ{opts: opts}
+	if err := d.diff(datamodel.Path{}, a, b); err != nil {
+		return nil, err
+	}
+	return d.ops, nil
+}
+
+type differ struct {
+	opts DiffOptions
+	ops  []Operation
+}
+
+func (d *differ) emit(op Operation) {
+	d.ops = append(d.ops, op)
+}
+
+func (d *differ) diff(at datamodel.Path, a, b datamodel.Node) error {
+	if a.Kind() != b.Kind() {
+		d.emit(Operation{Op: Op_Replace, Path: at, Value: b})
+		return nil
+	}
+	switch a.Kind() {
+	case datamodel.Kind_Map:
+		return d.diffMap(at, a, b)
+	case datamodel.Kind_List:
+		return d.diffList(at, a, b)
+	case datamodel.Kind_Link:
+		return d.diffLink(at, a, b)
+	default:
+		if !datamodel.DeepEqual(a, b) {
+			d.emit(Operation{Op: Op_Replace, Path: at, Value: b})
+		}
+		return nil
+	}
+}
+
+func (d *differ) diffLink(at datamodel.Path, a, b datamodel.Node) error {
+	al, err := a.AsLink()
+	if err != nil {
+		return err
+	}
+	bl, err := b.AsLink()
+	if err != nil {
+		return err
+	}
+	if al == bl {
+		return nil
+	}
+	if d.opts.LinkSystem == nil {
+		d.emit(Operation{Op: Op_Replace, Path: at, Value: b})
+		return nil
+	}
+	an, err := d.load(at, a, al)
+	if err != nil {
+		return err
+	}
+	bn, err := d.load(at, b, bl)
+	if err != nil {
+		return err
+	}
+	return d.diff(at, an, bn)
+}
+
+func (d *differ) load(at datamodel.Path, n datamodel.Node, lnk datamodel.Link) (datamodel.Node, error) {
+	lnkCtx := linking.LinkContext{LinkPath: at, LinkNode: n}
+	loaded, err := d.opts.LinkSystem.Load(lnkCtx, lnk, basicnode.Prototype.Any)
+	if err != nil {
+		return nil, fmt.Errorf("patch: diff could not load link %q at %q: %w", lnk, at, err)
+	}
+	return loaded, nil
+}
+
+func (d *differ) diffMap(at datamodel.Path, a, b datamodel.Node) error {
+	var removed []string
+	for itr := a.MapIterator(); !itr.Done(); {
+		k, av, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			return err
+		}
+		bv, err := b.LookupByString(ks)
+		if err != nil {
+			if _, ok := err.(datamodel.ErrNotExists); !ok {
+				return err
+			}
+			removed = append(removed, ks)
+			continue
+		}
+		if err := d.diff(at.AppendSegmentString(ks), av, bv); err != nil {
+			return err
+		}
+	}
+
+	// Find the entries to add, pairing them up with removed entries of equal value, if looking for moves.
+	moves := make(map[string]string) // from removed key to added key.
+	var added []string
+	for itr := b.MapIterator(); !itr.Done(); {
+		k, bv, err := itr.Next()
+		if err != nil {
+			return err
+		}
+		ks, err := k.AsString()
+		if err != nil {
+			return err
+		}
+		if _, err := a.LookupByString(ks); err == nil {
+			continue
+		}
+		added = append(added, ks)
+		if !d.opts.Moves {
+			continue
+		}
+		for _, from := range removed {
+			if _, taken := moves[from]; taken {
+				continue
+			}
+			av, err := a.LookupByString(from)
+			if err != nil {
+				return err
+			}
+			if datamodel.DeepEqual(av, bv) {
+				moves[from] = ks
+				break
+			}
+		}
+	}
+
+	moved := make(map[string]bool, len(moves))
+	for _, from := range removed {
+		if to, ok := moves[from]; ok {
+			d.emit(Operation{Op: Op_Move, Path: at.AppendSegmentString(to), From: at.AppendSegmentString(from)})
+			moved[to] = true
+			continue
+		}
+		d.emit(Operation{Op: Op_Remove, Path: at.AppendSegmentString(from)})
+	}
+	for _, ks := range added {
+		if moved[ks] {
+			continue
+		}
+		bv, err := b.LookupByString(ks)
+		if err != nil {
+			return err
+		}
+		d.emit(Operation{Op: Op_Add, Path: at.AppendSegmentString(ks), Value: bv})
+	}
+	return nil
+}
+
+func (d *differ) diffList(at datamodel.Path, a, b datamodel.Node) error {
+	as, err := listEntries(a)
+	if err != nil {
+		return err
+	}
+	bs, err := listEntries(b)
+	if err != nil {
+		return err
+	}
+
+	// Entries in common at the start and end are kept as they are;
+	// only the middle section needs working out.
+	var start int
+	for start < len(as) && start < len(bs) && datamodel.DeepEqual(as[start], bs[start]) {
+		start++
+	}
+	aEnd, bEnd := len(as), len(bs)
+	for aEnd > start && bEnd > start && datamodel.DeepEqual(as[aEnd-1], bs[bEnd-1]) {
+		aEnd--
+		bEnd--
+	}
+	edits := d.listEdits(as[start:aEnd], bs[start:bEnd])
+
+	// Turn the edits into operations, keeping track of where in the list being patched each one applies,
+	// as earlier operations shift the entries after them.
+	// A run of removals next to a run of additions is paired up into replacements, which are compared recursively.
+	idx := int64(start)
+	ai, bi := start, start
+	for i := 0; i < len(edits); {
+		if edits[i] == editKeep {
+			idx++
+			ai++
+			bi++
+			i++
+			continue
+		}
+		var removes, adds int
+		for ; i < len(edits) && edits[i] != editKeep; i++ {
+			if edits[i] == editRemove {
+				removes++
+			} else {
+				adds++
+			}
+		}
+		for ; removes > 0 && adds > 0; removes, adds = removes-1, adds-1 {
+			if err := d.diff(at.AppendSegmentInt(idx), as[ai], bs[bi]); err != nil {
+				return err
+			}
+			idx++
+			ai++
+			bi++
+		}
+		for ; removes > 0; removes-- {
+			d.emit(Operation{Op: Op_Remove, Path: at.AppendSegmentInt(idx)})
+			ai++
+		}
+		for ; adds > 0; adds-- {
+			d.emit(Operation{Op: Op_Add, Path: at.AppendSegmentInt(idx), Value: bs[bi]})
+			idx++
+			bi++
+		}
+	}
+	return nil
+}
+
+type edit uint8
+
+const (
+	editKeep edit = iota
+	editRemove
+	editAdd
+)
+
+// listEdits works out a shortest sequence of edits turning as into bs,
+// using the longest common subsequence of the two.
+// If that would be too costly, it falls back to pairing the entries up by position instead.
+func (d *differ) listEdits(as, bs []datamodel.Node) []edit {
+	n, m := len(as), len(bs)
+	if n*m > d.opts.MaxListEdit {
+		var edits []edit
+		for i := 0; i < n || i < m; i++ {
+			switch {
+			case i < n && i < m && datamodel.DeepEqual(as[i], bs[i]):
+				edits = append(edits, editKeep)
+			case i < n && i < m:
+				edits = append(edits, editRemove, editAdd)
+			case i < n:
+				edits = append(edits, editRemove)
+			default:
+				edits = append(edits, editAdd)
+			}
+		}
+		return edits
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of as[i:] and bs[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case datamodel.DeepEqual(as[i], bs[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && datamodel.DeepEqual(as[i], bs[j]):
+			edits = append(edits, editKeep)
+			i++
+			j++
+		case j == m || (i < n && lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, editRemove)
+			i++
+		default:
+			edits = append(edits, editAdd)
+			j++
+		}
+	}
+	return edits
+}
+
+func listEntries(n datamodel.Node) ([]datamodel.Node, error) {
+	entries := make([]datamodel.Node, 0, n.Length())
+	for itr := n.ListIterator(); !itr.Done(); {
+		_, v, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, v)
+	}
+	return entries, nil
+}
@@ -0,0 +1,299 @@
+package patch
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+)
+
+func mustParseJSON(t *testing.T, s string) datamodel.Node {
+	t.Helper()
+	n, err := ipld.Decode([]byte(s), dagjson.Decode)
+	qt.Assert(t, err, qt.IsNil)
+	return n
+}
+
+func mustEncodeJSON(t *testing.T, n datamodel.Node) string {
+	t.Helper()
+	b, err := ipld.Encode(n, dagjson.Encode)
+	qt.Assert(t, err, qt.IsNil)
+	return string(b)
+}
+
+// opStrings renders operations compactly, for comparing in tests.
+func opStrings(t *testing.T, ops []Operation) []string {
+	var out []string
+	for _, op := range ops {
+		s := fmt.Sprintf("%s %s", op.Op, op.Path)
+		if op.From.Len() > 0 {
+			s += " from " + op.From.String()
+		}
+		if op.Value != nil {
+			s += " " + mustEncodeJSON(t, op.Value)
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// checkDiff diffs a and b, checks the operations are as expected, and that applying them to a gives b.
+func checkDiff(t *testing.T, opts DiffOptions, a, b datamodel.Node, expect []string) {
+	t.Helper()
+	ops, err := opts.Diff(a, b)
+	qt.Assert(t, err, qt.IsNil)
+	if expect != nil {
+		qt.Check(t, opStrings(t, ops), qt.DeepEquals, expect)
+	}
+	result, err := Eval(a, ops)
+	qt.Assert(t, err, qt.IsNil, qt.Commentf("ops: %v", opStrings(t, ops)))
+	qt.Check(t, mustEncodeJSON(t, result), qt.Equals, mustEncodeJSON(t, b), qt.Commentf("ops: %v", opStrings(t, ops)))
+}
+
+func TestDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		a, b   string
+		expect []string
+	}{
+		{"equal", `{"a":[1,2,{"b":true}]}`, `{"a":[1,2,{"b":true}]}`, []string{}},
+		{"scalar", `"x"`, `"y"`, []string{`replace  "y"`}},
+		{"kind change", `{"a":1}`, `{"a":[1]}`, []string{`replace a [1]`}},
+		{"map entries", `{"a":1,"b":2,"c":3}`, `{"a":1,"c":4,"d":5}`, []string{
+			`replace c 4`,
+			`remove b`,
+			`add d 5`,
+		}},
+		{"nested map", `{"a":{"b":{"c":1,"d":2}}}`, `{"a":{"b":{"c":1,"d":3}}}`, []string{`replace a/b/d 3`}},
+		{"list append", `[1,2]`, `[1,2,3,4]`, []string{`add 2 3`, `add 3 4`}},
+		{"list insert", `[1,2,3]`, `[1,9,2,3]`, []string{`add 1 9`}},
+		{"list remove", `[1,2,3,4]`, `[1,4]`, []string{`remove 1`, `remove 1`}},
+		{"list entry changed", `[1,{"a":1,"b":2},3]`, `[1,{"a":1,"b":5},3]`, []string{`replace 1/b 5`}},
+		{"list mixed", `[1,2,3,4,5]`, `[0,1,3,6,5,7]`, []string{
+			`add 0 0`,
+			`remove 2`,
+			`replace 3 6`,
+			`add 5 7`,
+		}},
+		{"list to empty", `[1,2]`, `[]`, []string{`remove 0`, `remove 0`}},
+		{"list in map in list", `[{"x":[1,2]},{"y":[3]}]`, `[{"x":[2]},{"y":[3,4]}]`, []string{
+			`remove 0/x/0`,
+			`add 1/y/1 4`,
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if len(tc.expect) == 0 {
+				tc.expect = nil
+			}
+			a, b := mustParseJSON(t, tc.a), mustParseJSON(t, tc.b)
+			ops, err := Diff(a, b)
+			qt.Assert(t, err, qt.IsNil)
+			if tc.expect == nil {
+				qt.Check(t, ops, qt.HasLen, 0)
+			}
+			checkDiff(t, DiffOptions{}, a, b, tc.expect)
+		})
+	}
+}
+
+func TestDiffMoves(t *testing.T) {
+	a := mustParseJSON(t, `{"old":{"big":[1,2,3]},"keep":1,"gone":2}`)
+	b := mustParseJSON(t, `{"new":{"big":[1,2,3]},"keep":1,"added":2,"other":3}`)
+	checkDiff(t, DiffOptions{Moves: true}, a, b, []string{
+		`move new from old`,
+		`move added from gone`,
+		`add other 3`,
+	})
+	checkDiff(t, DiffOptions{}, a, b, []string{
+		`remove old`,
+		`remove gone`,
+		`add new {"big":[1,2,3]}`,
+		`add added 2`,
+		`add other 3`,
+	})
+}
+
+func TestDiffMaxListEdit(t *testing.T) {
+	a := mustParseJSON(t, `[0,1,2,3,4,5]`)
+	b := mustParseJSON(t, `[0,9,1,2,3,5]`)
+	checkDiff(t, DiffOptions{}, a, b, []string{`add 1 9`, `remove 5`})
+	// With too little room to find the common entries, everything in the middle is replaced by position.
+	checkDiff(t, DiffOptions{MaxListEdit: 4}, a, b, []string{
+		`replace 1 9`,
+		`replace 2 1`,
+		`replace 3 2`,
+		`replace 4 3`,
+	})
+}
+
+func TestDiffRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 300; i++ {
+		a := randomTree(rng, 3)
+		b := mutateTree(rng, a, 3)
+		checkDiff(t, DiffOptions{Moves: i%2 == 0}, a, b, nil)
+	}
+}
+
+func randomTree(rng *rand.Rand, depth int) datamodel.Node {
+	switch k := rng.Intn(6); {
+	case depth > 0 && k == 0:
+		nb := basicnode.Prototype.Map.NewBuilder()
+		size := rng.Intn(5)
+		ma, _ := nb.BeginMap(int64(size))
+		seen := map[string]bool{}
+		for j := 0; j < size; j++ {
+			key := fmt.Sprintf("k%d", rng.Intn(8))
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			ma.AssembleKey().AssignString(key)
+			ma.AssembleValue().AssignNode(randomTree(rng, depth-1))
+		}
+		ma.Finish()
+		return nb.Build()
+	case depth > 0 && k == 1:
+		nb := basicnode.Prototype.List.NewBuilder()
+		size := rng.Intn(6)
+		la, _ := nb.BeginList(int64(size))
+		for j := 0; j < size; j++ {
+			la.AssembleValue().AssignNode(randomTree(rng, depth-1))
+		}
+		la.Finish()
+		return nb.Build()
+	case k == 2:
+		return basicnode.NewString(fmt.Sprintf("s%d", rng.Intn(3)))
+	default:
+		return basicnode.NewInt(int64(rng.Intn(4)))
+	}
+}
+
+// mutateTree returns a copy of n with some random changes made.
+func mutateTree(rng *rand.Rand, n datamodel.Node, depth int) datamodel.Node {
+	if rng.Intn(8) == 0 {
+		return randomTree(rng, depth)
+	}
+	switch n.Kind() {
+	case datamodel.Kind_Map:
+		nb := basicnode.Prototype.Map.NewBuilder()
+		ma, _ := nb.BeginMap(0)
+		seen := map[string]bool{}
+		for itr := n.MapIterator(); !itr.Done(); {
+			k, v, _ := itr.Next()
+			ks, _ := k.AsString()
+			if rng.Intn(5) == 0 {
+				continue
+			}
+			if rng.Intn(6) == 0 {
+				ks += "-renamed"
+			}
+			seen[ks] = true
+			ma.AssembleKey().AssignString(ks)
+			ma.AssembleValue().AssignNode(mutateTree(rng, v, depth-1))
+		}
+		if key := fmt.Sprintf("k%d", rng.Intn(10)); rng.Intn(3) == 0 && !seen[key] {
+			ma.AssembleKey().AssignString(key)
+			ma.AssembleValue().AssignNode(randomTree(rng, depth-1))
+		}
+		ma.Finish()
+		return nb.Build()
+	case datamodel.Kind_List:
+		nb := basicnode.Prototype.List.NewBuilder()
+		la, _ := nb.BeginList(0)
+		for itr := n.ListIterator(); !itr.Done(); {
+			_, v, _ := itr.Next()
+			if rng.Intn(4) == 0 {
+				la.AssembleValue().AssignNode(randomTree(rng, depth-1))
+			}
+			if rng.Intn(5) == 0 {
+				continue
+			}
+			la.AssembleValue().AssignNode(mutateTree(rng, v, depth-1))
+		}
+		la.Finish()
+		return nb.Build()
+	default:
+		return n
+	}
+}
+
+func TestDiffLinks(t *testing.T) {
+	store := &memstore.Store{}
+	var loads int
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetWriteStorage(store)
+	lsys.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		loads++
+		return store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	lp := cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x0129, MhType: 0x12, MhLength: 32}}
+	store1 := func(json string) datamodel.Link {
+		lnk, err := lsys.Store(linking.LinkContext{}, lp, mustParseJSON(t, json))
+		qt.Assert(t, err, qt.IsNil)
+		return lnk
+	}
+	unchanged := store1(`{"huge":"subgraph"}`)
+	before := store1(`{"x":1,"y":[1,2]}`)
+	after := store1(`{"x":1,"y":[1,2,3]}`)
+	withLinks := func(lnk datamodel.Link) datamodel.Node {
+		nb := basicnode.Prototype.Map.NewBuilder()
+		ma, _ := nb.BeginMap(2)
+		ma.AssembleKey().AssignString("same")
+		ma.AssembleValue().AssignLink(unchanged)
+		ma.AssembleKey().AssignString("changed")
+		ma.AssembleValue().AssignLink(lnk)
+		ma.Finish()
+		return nb.Build()
+	}
+	a, b := withLinks(before), withLinks(after)
+
+	// Without a LinkSystem, the differing link is replaced.
+	ops, err := Diff(a, b)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Assert(t, ops, qt.HasLen, 1)
+	qt.Check(t, ops[0].Op, qt.Equals, Op(Op_Replace))
+	qt.Check(t, ops[0].Path.String(), qt.Equals, "changed")
+	result, err := Eval(a, ops)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, datamodel.DeepEqual(result, b), qt.IsTrue)
+
+	// With one, the blocks behind it are compared, but the equal links are never loaded.
+	ops, err = DiffOptions{LinkSystem: &lsys}.Diff(a, b)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, opStrings(t, ops), qt.DeepEquals, []string{`add changed/y/2 3`})
+	qt.Check(t, loads, qt.Equals, 2)
+}
+
+func TestEvalListEntries(t *testing.T) {
+	n := mustParseJSON(t, `{"l":[1,2,3]}`)
+	for _, tc := range []struct {
+		op     Operation
+		expect string
+	}{
+		{Operation{Op: Op_Add, Path: datamodel.ParsePath("l/0"), Value: basicnode.NewInt(0)}, `{"l":[0,1,2,3]}`},
+		{Operation{Op: Op_Add, Path: datamodel.ParsePath("l/3"), Value: basicnode.NewInt(4)}, `{"l":[1,2,3,4]}`},
+		{Operation{Op: Op_Add, Path: datamodel.ParsePath("l/-"), Value: basicnode.NewInt(4)}, `{"l":[1,2,3,4]}`},
+		{Operation{Op: Op_Remove, Path: datamodel.ParsePath("l/1")}, `{"l":[1,3]}`},
+		{Operation{Op: Op_Move, Path: datamodel.ParsePath("m"), From: datamodel.ParsePath("l/0")}, `{"l":[2,3],"m":1}`},
+	} {
+		result, err := EvalOne(n, tc.op)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, mustEncodeJSON(t, result), qt.Equals, tc.expect)
+	}
+	_, err := EvalOne(n, Operation{Op: Op_Add, Path: datamodel.ParsePath("l/4"), Value: basicnode.NewInt(4)})
+	qt.Check(t, err, qt.ErrorAs, new(*ErrInvalidPath))
+	_, err = EvalOne(n, Operation{Op: Op_Remove, Path: datamodel.ParsePath("l/3")})
+	qt.Check(t, err, qt.ErrorAs, new(*ErrPathNotFound))
+}
@@ -0,0 +1,120 @@
+package patch
+
+import (
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+)
+
+// Error is implemented by all the errors describing why an operation failed.
+// Code returns a short, stable string identifying the kind of failure,
+// suitable for the "code" field of the Error type in the patch schema.
+//
+// Use errors.As to get at the details of a particular kind of failure;
+// the errors returned by Eval and EvalLinked are wrapped in an ErrOpFailed, which says which operation failed.
+type Error interface {
+	error
+	Code() string
+}
+
+var (
+	_ Error = (*ErrTestFailed)(nil)
+	_ Error = (*ErrTargetExists)(nil)
+	_ Error = (*ErrPathNotFound)(nil)
+	_ Error = (*ErrInvalidPath)(nil)
+	_ Error = (*ErrInvalidOp)(nil)
+)
+
+// ErrTestFailed is returned when the value at the path of a "test" operation isn't the one it expects.
+type ErrTestFailed struct {
+	Path     datamodel.Path
+	Expected datamodel.Node
+	Actual   datamodel.Node
+}
+
+func (e *ErrTestFailed) Code() string { return "patch-test-failed" }
+func (e *ErrTestFailed) Error() string {
+	return fmt.Sprintf("%s: at %q", e.Code(), e.Path)
+}
+
+// ErrTargetExists is returned when an "add" operation would add a map entry which already exists.
+type ErrTargetExists struct {
+	Path datamodel.Path
+}
+
+func (e *ErrTargetExists) Code() string { return "patch-target-exists" }
+func (e *ErrTargetExists) Error() string {
+	return fmt.Sprintf("%s: at %q", e.Code(), e.Path)
+}
+
+// ErrPathNotFound is returned when a path an operation needs to exist doesn't:
+// the path of a "remove" or "test", the "from" of a "move" or "copy",
+// or the parent of the path of any other operation.
+// Err is the error from looking the path up, if there was one.
+type ErrPathNotFound struct {
+	Path datamodel.Path
+	Err  error
+}
+
+func (e *ErrPathNotFound) Code() string { return "patch-path-not-found" }
+func (e *ErrPathNotFound) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: at %q: %s", e.Code(), e.Path, e.Err)
+	}
+	return fmt.Sprintf("%s: at %q", e.Code(), e.Path)
+}
+func (e *ErrPathNotFound) Unwrap() error { return e.Err }
+
+// ErrInvalidPath is returned when a path can't be used for an operation,
+// such as one with a segment that isn't an index within the bounds of a list it goes through.
+type ErrInvalidPath struct {
+	Path   datamodel.Path
+	Reason string
+}
+
+func (e *ErrInvalidPath) Code() string { return "patch-invalid-path" }
+func (e *ErrInvalidPath) Error() string {
+	return fmt.Sprintf("%s: at %q: %s", e.Code(), e.Path, e.Reason)
+}
+
+// ErrInvalidOp is returned for an operation with an Op which isn't one of the known ones.
+type ErrInvalidOp struct {
+	Op Op
+}
+
+func (e *ErrInvalidOp) Code() string { return "patch-invalid-op" }
+func (e *ErrInvalidOp) Error() string {
+	return fmt.Sprintf("%s: %q", e.Code(), e.Op)
+}
+
+// ErrOpFailed is returned by Eval and EvalLinked when one of the operations fails,
+// saying which one it was.
+// Err is the reason it failed: usually one of the other error types in this package.
+type ErrOpFailed struct {
+	Index int // The position of the operation in the list given to Eval.
+	Op    Operation
+	Err   error
+}
+
+func (e *ErrOpFailed) Error() string {
+	return fmt.Sprintf("patch operation %d (%s at %q) failed: %s", e.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+func (e *ErrOpFailed) Unwrap() error { return e.Err }
+
+// prefixErrorPath adds a prefix to the path held by an error from this package,
+// for errors from an operation applied to a node at that path.
+func prefixErrorPath(err error, prefix datamodel.Path) {
+	if prefix.Len() == 0 {
+		return
+	}
+	switch e := err.(type) {
+	case *ErrTestFailed:
+		e.Path = prefix.Join(e.Path)
+	case *ErrTargetExists:
+		e.Path = prefix.Join(e.Path)
+	case *ErrPathNotFound:
+		e.Path = prefix.Join(e.Path)
+	case *ErrInvalidPath:
+		e.Path = prefix.Join(e.Path)
+	}
+}
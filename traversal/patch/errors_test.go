@@ -0,0 +1,76 @@
+package patch
+
+import (
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+)
+
+func TestEvalErrors(t *testing.T) {
+	n := mustParseJSON(t, `{"a":{"b":1},"l":[1,2]}`)
+	for _, tc := range []struct {
+		name   string
+		op     Operation
+		code   string
+		target any
+	}{
+		{"test failed", Operation{Op: Op_Test, Path: datamodel.ParsePath("a/b"), Value: basicnode.NewInt(2)}, "patch-test-failed", new(*ErrTestFailed)},
+		{"target exists", Operation{Op: Op_Add, Path: datamodel.ParsePath("a/b"), Value: basicnode.NewInt(2)}, "patch-target-exists", new(*ErrTargetExists)},
+		{"remove missing", Operation{Op: Op_Remove, Path: datamodel.ParsePath("a/c")}, "patch-path-not-found", new(*ErrPathNotFound)},
+		{"add under missing", Operation{Op: Op_Add, Path: datamodel.ParsePath("x/y"), Value: basicnode.NewInt(2)}, "patch-path-not-found", new(*ErrPathNotFound)},
+		{"test missing", Operation{Op: Op_Test, Path: datamodel.ParsePath("a/c"), Value: basicnode.NewInt(2)}, "patch-path-not-found", new(*ErrPathNotFound)},
+		{"copy from missing", Operation{Op: Op_Copy, Path: datamodel.ParsePath("a/c"), From: datamodel.ParsePath("a/d")}, "patch-path-not-found", new(*ErrPathNotFound)},
+		{"list index", Operation{Op: Op_Add, Path: datamodel.ParsePath("l/x"), Value: basicnode.NewInt(2)}, "patch-invalid-path", new(*ErrInvalidPath)},
+		{"invalid op", Operation{Op: "frob", Path: datamodel.ParsePath("a")}, "patch-invalid-op", new(*ErrInvalidOp)},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ops := []Operation{
+				{Op: Op_Test, Path: datamodel.ParsePath("a/b"), Value: basicnode.NewInt(1)},
+				tc.op,
+			}
+			result, err := Eval(n, ops)
+			qt.Assert(t, err, qt.IsNotNil)
+			qt.Check(t, result, qt.IsNil)
+
+			var opErr *ErrOpFailed
+			qt.Assert(t, err, qt.ErrorAs, &opErr)
+			qt.Check(t, opErr.Index, qt.Equals, 1)
+			qt.Check(t, opErr.Op.Op, qt.Equals, tc.op.Op)
+			qt.Check(t, err, qt.ErrorAs, tc.target)
+			var pErr Error
+			qt.Assert(t, errors.As(err, &pErr), qt.IsTrue)
+			qt.Check(t, pErr.Code(), qt.Equals, tc.code)
+		})
+	}
+
+	t.Run("test failure details", func(t *testing.T) {
+		_, err := Eval(n, []Operation{{Op: Op_Test, Path: datamodel.ParsePath("a/b"), Value: basicnode.NewInt(2)}})
+		var testErr *ErrTestFailed
+		qt.Assert(t, err, qt.ErrorAs, &testErr)
+		qt.Check(t, testErr.Path.String(), qt.Equals, "a/b")
+		qt.Check(t, mustEncodeJSON(t, testErr.Expected), qt.Equals, "2")
+		qt.Check(t, mustEncodeJSON(t, testErr.Actual), qt.Equals, "1")
+	})
+}
+
+func TestEvalLinkedErrors(t *testing.T) {
+	f := newLinkedFixture(t)
+	ops := []Operation{
+		{Op: Op_Replace, Path: datamodel.ParsePath("child/n"), Value: basicnode.NewInt(2)},
+		{Op: Op_Add, Path: datamodel.ParsePath("child/grandchild/v"), Value: basicnode.NewString("b")},
+	}
+	_, _, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+	var opErr *ErrOpFailed
+	qt.Assert(t, err, qt.ErrorAs, &opErr)
+	qt.Check(t, opErr.Index, qt.Equals, 1)
+	// The path in the error is the whole path, not the part of it within the block the operation was applied to.
+	var existsErr *ErrTargetExists
+	qt.Assert(t, err, qt.ErrorAs, &existsErr)
+	qt.Check(t, existsErr.Path.String(), qt.Equals, "child/grandchild/v")
+	qt.Check(t, f.stores, qt.Equals, 0)
+}
@@ -31,17 +31,26 @@ type Operation struct {
 	From  datamodel.Path // Present on 'move', 'copy'.
 }
 
+// Eval applies the operations to n in order, and returns the result.
+//
+// If any operation fails, Eval stops there, and returns an ErrOpFailed saying which one it was, and why,
+// and no result: there's never a partially patched node to deal with.
+// (The node given to Eval is not modified, either way; nodes are immutable.)
 func Eval(n datamodel.Node, ops []Operation) (datamodel.Node, error) {
 	var err error
-	for _, op := range ops {
+	for i, op := range ops {
 		n, err = EvalOne(n, op)
 		if err != nil {
-			return nil, err
+			return nil, &ErrOpFailed{Index: i, Op: op, Err: err}
 		}
 	}
 	return n, nil
 }
 
+// EvalOne applies a single operation to n, and returns the result.
+//
+// Failures of the operation itself are reported with the error types in this package,
+// such as ErrTestFailed and ErrPathNotFound, all of which implement Error.
 func EvalOne(n datamodel.Node, op Operation) (datamodel.Node, error) {
 	switch op.Op {
 	case Op_Add:
@@ -50,17 +59,22 @@ func EvalOne(n datamodel.Node, op Operation) (datamodel.Node, error) {
 		// There's also a special case for "-", which means "append to the end of the list".
 		// Otherwise, if the destination path exists, it's an error.  (No upserting.)
 		// Handling this requires looking at the parent of the destination node, so we split this into *two* traversal.FocusedTransform calls.
+		if op.Path.Len() == 0 {
+			return nil, &ErrInvalidPath{Path: op.Path, Reason: "cannot add the document itself"}
+		}
+		if err := checkExists(n, op.Path.Pop()); err != nil {
+			return nil, err
+		}
 		return traversal.FocusedTransform(n, op.Path.Pop(), func(prog traversal.Progress, parent datamodel.Node) (datamodel.Node, error) {
 			if parent.Kind() == datamodel.Kind_List {
 				seg := op.Path.Last()
-				var idx int64
-				if seg.String() == "-" {
-					idx = -1
-				}
-				var err error
-				idx, err = seg.Index()
-				if err != nil {
-					return nil, fmt.Errorf("patch-invalid-path-through-list: at %q", op.Path) // TODO error structuralization and review the code
+				idx := parent.Length() // "-" means append, which is the same as inserting one past the end.
+				if seg.String() != "-" {
+					var err error
+					idx, err = seg.Index()
+					if err != nil || idx < 0 || idx > parent.Length() {
+						return nil, &ErrInvalidPath{Path: op.Path, Reason: "not an index within the list"}
+					}
 				}
 
 				nb := parent.Prototype().NewBuilder()
@@ -68,21 +82,24 @@ func EvalOne(n datamodel.Node, op Operation) (datamodel.Node, error) {
 				if err != nil {
 					return nil, err
 				}
-				for itr := n.ListIterator(); !itr.Done(); {
+				for itr := parent.ListIterator(); !itr.Done(); {
 					i, v, err := itr.Next()
 					if err != nil {
 						return nil, err
 					}
 					if idx == i {
-						la.AssembleValue().AssignNode(op.Value)
+						if err := la.AssembleValue().AssignNode(op.Value); err != nil {
+							return nil, err
+						}
 					}
 					if err := la.AssembleValue().AssignNode(v); err != nil {
 						return nil, err
 					}
 				}
-				// TODO: is one-past-the-end supposed to be supported or supposed to be ruled out?
-				if idx == -1 {
-					la.AssembleValue().AssignNode(op.Value)
+				if idx == parent.Length() {
+					if err := la.AssembleValue().AssignNode(op.Value); err != nil {
+						return nil, err
+					}
 				}
 				if err := la.Finish(); err != nil {
 					return nil, err
@@ -91,54 +108,117 @@ func EvalOne(n datamodel.Node, op Operation) (datamodel.Node, error) {
 			}
 			return prog.FocusedTransform(parent, datamodel.NewPath([]datamodel.PathSegment{op.Path.Last()}), func(prog traversal.Progress, point datamodel.Node) (datamodel.Node, error) {
 				if point != nil && !point.IsAbsent() {
-					return nil, fmt.Errorf("patch-target-exists: at %q", op.Path) // TODO error structuralization and review the code
+					return nil, &ErrTargetExists{Path: op.Path}
 				}
 				return op.Value, nil
 			}, false)
 		}, false)
-	case "remove":
+	case Op_Remove:
+		if op.Path.Len() == 0 {
+			return nil, &ErrInvalidPath{Path: op.Path, Reason: "cannot remove the document itself"}
+		}
+		if err := checkExists(n, op.Path); err != nil {
+			return nil, err
+		}
+		// FocusedTransform can remove map entries, but not list entries; for those, rebuild the list without the entry.
+		if parent, err := get(n, op.Path.Pop()); err == nil && parent.Kind() == datamodel.Kind_List {
+			return traversal.FocusedTransform(n, op.Path.Pop(), func(_ traversal.Progress, parent datamodel.Node) (datamodel.Node, error) {
+				return removeListEntry(parent, op.Path)
+			}, false)
+		}
 		return traversal.FocusedTransform(n, op.Path, func(_ traversal.Progress, point datamodel.Node) (datamodel.Node, error) {
 			return nil, nil // Returning a nil value here means "remove what's here".
 		}, false)
-	case "replace":
+	case Op_Replace:
+		// Replacing the whole document can change its kind, which FocusedTransform can't do, since it builds with the existing node's prototype.
+		if op.Path.Len() == 0 {
+			return op.Value, nil
+		}
+		if err := checkExists(n, op.Path.Pop()); err != nil {
+			return nil, err
+		}
 		// TODO i think you need a check that it's not landing under itself here
 		return traversal.FocusedTransform(n, op.Path, func(_ traversal.Progress, point datamodel.Node) (datamodel.Node, error) {
 			return op.Value, nil // is this right?  what does FocusedTransform do re upsert?
 		}, false)
-	case "move":
+	case Op_Move:
 		// TODO i think you need a check that it's not landing under itself here
-		source, err := traversal.Get(n, op.From)
+		source, err := get(n, op.From)
 		if err != nil {
 			return nil, err
 		}
-		n, err := traversal.FocusedTransform(n, op.Path, func(_ traversal.Progress, point datamodel.Node) (datamodel.Node, error) {
-			return source, nil // is this right?  what does FocusedTransform do re upsert?
-		}, false)
+		n, err := EvalOne(n, Operation{Op: Op_Replace, Path: op.Path, Value: source})
 		if err != nil {
 			return nil, err
 		}
-		return traversal.FocusedTransform(n, op.From, func(_ traversal.Progress, point datamodel.Node) (datamodel.Node, error) {
-			return nil, nil // Returning a nil value here means "remove what's here".
-		}, false)
-	case "copy":
+		return EvalOne(n, Operation{Op: Op_Remove, Path: op.From})
+	case Op_Copy:
 		// TODO i think you need a check that it's not landing under itself here
-		source, err := traversal.Get(n, op.From)
+		source, err := get(n, op.From)
 		if err != nil {
 			return nil, err
 		}
-		return traversal.FocusedTransform(n, op.Path, func(_ traversal.Progress, point datamodel.Node) (datamodel.Node, error) {
-			return source, nil // is this right?  what does FocusedTransform do re upsert?
-		}, false)
-	case "test":
-		point, err := traversal.Get(n, op.Path)
+		return EvalOne(n, Operation{Op: Op_Replace, Path: op.Path, Value: source})
+	case Op_Test:
+		point, err := get(n, op.Path)
 		if err != nil {
 			return nil, err
 		}
-		if datamodel.DeepEqual(point, op.Value) {
-			return n, nil
+		if !datamodel.DeepEqual(point, op.Value) {
+			return nil, &ErrTestFailed{Path: op.Path, Expected: op.Value, Actual: point}
 		}
-		return n, fmt.Errorf("test failed") // TODO real error handling and a code
+		return n, nil
 	default:
-		return nil, fmt.Errorf("misuse: invalid operation: %s", op.Op) // TODO real error handling and a code
+		return nil, &ErrInvalidOp{Op: op.Op}
+	}
+}
+
+// get finds the node at the path within n, with failures reported as ErrPathNotFound.
+// Unlike traversal.Get, it never loads links, nor crosses them.
+func get(n datamodel.Node, p datamodel.Path) (datamodel.Node, error) {
+	for _, seg := range p.Segments() {
+		if n.Kind() == datamodel.Kind_Link {
+			return nil, &ErrPathNotFound{Path: p, Err: fmt.Errorf("cannot cross links (consider EvalLinked)")}
+		}
+		child, err := n.LookupBySegment(seg)
+		if err != nil {
+			return nil, &ErrPathNotFound{Path: p, Err: err}
+		}
+		n = child
+	}
+	return n, nil
+}
+
+func checkExists(n datamodel.Node, p datamodel.Path) error {
+	_, err := get(n, p)
+	return err
+}
+
+// removeListEntry returns a copy of the list with the entry at the last segment of the path removed.
+func removeListEntry(list datamodel.Node, path datamodel.Path) (datamodel.Node, error) {
+	idx, err := path.Last().Index()
+	if err != nil || idx < 0 || idx >= list.Length() {
+		return nil, &ErrInvalidPath{Path: path, Reason: "not an index within the list"}
+	}
+	nb := list.Prototype().NewBuilder()
+	la, err := nb.BeginList(list.Length() - 1)
+	if err != nil {
+		return nil, err
+	}
+	for itr := list.ListIterator(); !itr.Done(); {
+		i, v, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if i == idx {
+			continue
+		}
+		if err := la.AssembleValue().AssignNode(v); err != nil {
+			return nil, err
+		}
+	}
+	if err := la.Finish(); err != nil {
+		return nil, err
 	}
+	return nb.Build(), nil
 }
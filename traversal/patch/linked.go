@@ -0,0 +1,302 @@
+package patch
+
+import (
+	"fmt"
+
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/linking"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/traversal"
+)
+
+// EvalLinked is like Eval, but applies the operations to a graph of blocks,
+// whose paths may cross links from one block into another.
+//
+// The root block, and any blocks the operations' paths lead into, are loaded using the LinkSystem.
+// Once all the operations have been applied, every block that was changed is stored again, using the LinkPrototype,
+// and the links to it in its parent are rewritten, all the way up to the root.
+// EvalLinked returns the link to the new root, and all the links it stored, with the new root last.
+// If no operation changes anything, the original root link is returned, and nothing is stored.
+// If an operation fails, the error is an ErrOpFailed, as from Eval.
+//
+// A path only crosses a link if it continues beyond it:
+// an operation on the path to a link itself, such as replacing or removing it, applies to the link, not the block it points to.
+//
+// Blocks that aren't changed are neither stored again nor, if no path leads into them, loaded at all.
+// Changed blocks are kept in memory until all the operations have been applied, so nothing is stored if any operation fails,
+// and blocks changed by several operations are only stored once.
+//
+// Loaded blocks are built using basicnode.Prototype.Any, and all the blocks which are stored again use the given LinkPrototype,
+// whatever codec or hash they used before.
+func EvalLinked(lnkCtx linking.LinkContext, lsys *linking.LinkSystem, lp datamodel.LinkPrototype, root datamodel.Link, ops []Operation) (datamodel.Link, []datamodel.Link, error) {
+	e := linkedEval{
+		lnkCtx:  lnkCtx,
+		lsys:    lsys,
+		lp:      lp,
+		pending: make(map[pendingLink]datamodel.Node),
+		stored:  make(map[pendingLink]datamodel.Link),
+	}
+	original, err := e.load(datamodel.Path{}, root)
+	if err != nil {
+		return nil, nil, err
+	}
+	n := original
+	var changed bool
+	for i, op := range ops {
+		var opChanged bool
+		n, opChanged, err = e.evalOne(n, op)
+		if err != nil {
+			return nil, nil, &ErrOpFailed{Index: i, Op: op, Err: err}
+		}
+		changed = changed || opChanged
+	}
+	if !changed {
+		return root, nil, nil
+	}
+	newRoot, err := e.store(datamodel.Path{}, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	return newRoot, e.written, nil
+}
+
+// pendingLink stands in for the link to a block which has been changed, but not yet stored.
+// It never leaves EvalLinked: they're all replaced with real links before any block is stored.
+type pendingLink struct {
+	id int
+}
+
+func (l pendingLink) Prototype() datamodel.LinkPrototype { return nil }
+func (l pendingLink) String() string                     { return fmt.Sprintf("<pending block %d>", l.id) }
+func (l pendingLink) Binary() string                     { return l.String() }
+
+type linkedEval struct {
+	lnkCtx linking.LinkContext
+	lsys   *linking.LinkSystem
+	lp     datamodel.LinkPrototype
+
+	pending map[pendingLink]datamodel.Node // the contents of changed blocks.
+	stored  map[pendingLink]datamodel.Link // the links of changed blocks once stored, in case they're referred to more than once.
+	written []datamodel.Link
+}
+
+// evalOne applies one operation, and reports whether it changed anything.
+// Nodes can't be compared to find out (not all of them are comparable), so every operation but test counts as a change.
+func (e *linkedEval) evalOne(n datamodel.Node, op Operation) (datamodel.Node, bool, error) {
+	switch op.Op {
+	case Op_Add, Op_Remove, Op_Replace, Op_Test:
+		return e.transform(n, datamodel.Path{}, op.Path, func(blk datamodel.Node, p datamodel.Path) (datamodel.Node, bool, error) {
+			blk, err := EvalOne(blk, Operation{Op: op.Op, Path: p, Value: op.Value})
+			return blk, op.Op != Op_Test, err
+		})
+	case Op_Move, Op_Copy:
+		// These are done the same way as Eval does them: by putting the source at the path, replacing anything there,
+		// and then removing the source, for a move.
+		source, err := e.get(n, op.From)
+		if err != nil {
+			return nil, false, err
+		}
+		n, _, err = e.evalOne(n, Operation{Op: Op_Replace, Path: op.Path, Value: source})
+		if err != nil || op.Op == Op_Copy {
+			return n, true, err
+		}
+		return e.evalOne(n, Operation{Op: Op_Remove, Path: op.From})
+	default:
+		return nil, false, &ErrInvalidOp{Op: op.Op}
+	}
+}
+
+// transform finds the block within n which the path leads into, and calls fn with that block and the rest of the path within it.
+// If fn reports that it changed the block, the new block is recorded as pending, and n is returned with the link to the block replaced.
+func (e *linkedEval) transform(n datamodel.Node, at, p datamodel.Path, fn func(datamodel.Node, datamodel.Path) (datamodel.Node, bool, error)) (datamodel.Node, bool, error) {
+	segs := p.Segments()
+	cur := n
+	for i := 0; i < len(segs)-1; i++ {
+		child, err := cur.LookupBySegment(segs[i])
+		if err != nil {
+			break // Let fn report the problem, in the same way as Eval.
+		}
+		if child.Kind() != datamodel.Kind_Link {
+			cur = child
+			continue
+		}
+		lnk, err := child.AsLink()
+		if err != nil {
+			return nil, false, err
+		}
+		toLink := datamodel.NewPath(segs[:i+1])
+		blkAt := at.Join(toLink)
+		blk, err := e.load(blkAt, lnk)
+		if err != nil {
+			return nil, false, err
+		}
+		newBlk, changed, err := e.transform(blk, blkAt, datamodel.NewPath(segs[i+1:]), fn)
+		if err != nil {
+			return nil, false, err
+		}
+		if !changed {
+			return n, false, nil
+		}
+		pl := pendingLink{len(e.pending)}
+		e.pending[pl] = newBlk
+		n, err = traversal.FocusedTransform(n, toLink, func(traversal.Progress, datamodel.Node) (datamodel.Node, error) {
+			return basicnode.NewLink(pl), nil
+		}, false)
+		return n, err == nil, err
+	}
+	n, changed, err := fn(n, p)
+	if err != nil {
+		prefixErrorPath(err, at)
+		return nil, false, err
+	}
+	return n, changed, nil
+}
+
+// get finds the node at the path, loading blocks along the way as necessary.
+func (e *linkedEval) get(n datamodel.Node, p datamodel.Path) (datamodel.Node, error) {
+	var at datamodel.Path
+	for _, seg := range p.Segments() {
+		if n.Kind() == datamodel.Kind_Link {
+			lnk, err := n.AsLink()
+			if err != nil {
+				return nil, err
+			}
+			if n, err = e.load(at, lnk); err != nil {
+				return nil, err
+			}
+		}
+		at = at.AppendSegment(seg)
+		var err error
+		if n, err = n.LookupBySegment(seg); err != nil {
+			return nil, &ErrPathNotFound{Path: at, Err: err}
+		}
+	}
+	return n, nil
+}
+
+func (e *linkedEval) load(at datamodel.Path, lnk datamodel.Link) (datamodel.Node, error) {
+	if pl, ok := lnk.(pendingLink); ok {
+		return e.pending[pl], nil
+	}
+	lnkCtx := e.lnkCtx
+	lnkCtx.LinkPath = at
+	n, err := e.lsys.Load(lnkCtx, lnk, basicnode.Prototype.Any)
+	if err != nil {
+		return nil, fmt.Errorf("patch: could not load link %q at %q: %w", lnk, at, err)
+	}
+	return n, nil
+}
+
+// store stores a changed block, after storing any changed blocks it links to.
+func (e *linkedEval) store(at datamodel.Path, n datamodel.Node) (datamodel.Link, error) {
+	n, _, err := e.resolvePending(at, n)
+	if err != nil {
+		return nil, err
+	}
+	lnkCtx := e.lnkCtx
+	lnkCtx.LinkPath = at
+	lnk, err := e.lsys.Store(lnkCtx, e.lp, n)
+	if err != nil {
+		return nil, fmt.Errorf("patch: could not store block at %q: %w", at, err)
+	}
+	e.written = append(e.written, lnk)
+	return lnk, nil
+}
+
+// resolvePending returns n with any pending links within it (but not within other blocks) replaced by real ones,
+// storing the pending blocks as needed. It reports whether there were any to replace.
+func (e *linkedEval) resolvePending(at datamodel.Path, n datamodel.Node) (datamodel.Node, bool, error) {
+	switch n.Kind() {
+	case datamodel.Kind_Link:
+		lnk, err := n.AsLink()
+		if err != nil {
+			return nil, false, err
+		}
+		pl, ok := lnk.(pendingLink)
+		if !ok {
+			return n, false, nil
+		}
+		stored, ok := e.stored[pl]
+		if !ok {
+			if stored, err = e.store(at, e.pending[pl]); err != nil {
+				return nil, false, err
+			}
+			e.stored[pl] = stored
+		}
+		return basicnode.NewLink(stored), true, nil
+	case datamodel.Kind_Map:
+		var changed bool
+		var keys, values []datamodel.Node
+		for itr := n.MapIterator(); !itr.Done(); {
+			k, v, err := itr.Next()
+			if err != nil {
+				return nil, false, err
+			}
+			ks, err := k.AsString()
+			if err != nil {
+				return nil, false, err
+			}
+			v2, vChanged, err := e.resolvePending(at.AppendSegmentString(ks), v)
+			if err != nil {
+				return nil, false, err
+			}
+			changed = changed || vChanged
+			keys = append(keys, k)
+			values = append(values, v2)
+		}
+		if !changed {
+			return n, false, nil
+		}
+		nb := n.Prototype().NewBuilder()
+		ma, err := nb.BeginMap(int64(len(keys)))
+		if err != nil {
+			return nil, false, err
+		}
+		for i := range keys {
+			if err := ma.AssembleKey().AssignNode(keys[i]); err != nil {
+				return nil, false, err
+			}
+			if err := ma.AssembleValue().AssignNode(values[i]); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := ma.Finish(); err != nil {
+			return nil, false, err
+		}
+		return nb.Build(), true, nil
+	case datamodel.Kind_List:
+		var changed bool
+		var values []datamodel.Node
+		for itr := n.ListIterator(); !itr.Done(); {
+			i, v, err := itr.Next()
+			if err != nil {
+				return nil, false, err
+			}
+			v2, vChanged, err := e.resolvePending(at.AppendSegmentInt(i), v)
+			if err != nil {
+				return nil, false, err
+			}
+			changed = changed || vChanged
+			values = append(values, v2)
+		}
+		if !changed {
+			return n, false, nil
+		}
+		nb := n.Prototype().NewBuilder()
+		la, err := nb.BeginList(int64(len(values)))
+		if err != nil {
+			return nil, false, err
+		}
+		for _, v := range values {
+			if err := la.AssembleValue().AssignNode(v); err != nil {
+				return nil, false, err
+			}
+		}
+		if err := la.Finish(); err != nil {
+			return nil, false, err
+		}
+		return nb.Build(), true, nil
+	default:
+		return n, false, nil
+	}
+}
@@ -0,0 +1,270 @@
+package patch
+
+import (
+	"io"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/ipfs/go-cid"
+
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	_ "github.com/ipld/go-ipld-prime/codec/raw"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
+	"github.com/ipld/go-ipld-prime/linking"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
+)
+
+var jsonLinkProto = cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x0129, MhType: 0x12, MhLength: 32}}
+
+// linkedFixture stores a small graph of blocks:
+//
+//	root: {"name": "root", "child": &child, "list": [&leafA, &leafB]}
+//	child: {"n": 1, "grandchild": &leafA}
+//	leafA: {"v": "a"}
+//	leafB: {"v": "b"}
+type linkedFixture struct {
+	lsys   linking.LinkSystem
+	store  *memstore.Store
+	loads  map[datamodel.Link]int
+	root   datamodel.Link
+	child  datamodel.Link
+	leafA  datamodel.Link
+	leafB  datamodel.Link
+	stores int
+}
+
+func newLinkedFixture(t *testing.T) *linkedFixture {
+	f := &linkedFixture{store: &memstore.Store{}, loads: make(map[datamodel.Link]int)}
+	f.lsys = cidlink.DefaultLinkSystem()
+	f.lsys.SetWriteStorage(f.store)
+	f.lsys.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		f.loads[lnk]++
+		return f.store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	storeJSON := func(n datamodel.Node) datamodel.Link {
+		lnk, err := f.lsys.Store(linking.LinkContext{}, jsonLinkProto, n)
+		qt.Assert(t, err, qt.IsNil)
+		return lnk
+	}
+	f.leafA = storeJSON(mustParseJSON(t, `{"v":"a"}`))
+	f.leafB = storeJSON(mustParseJSON(t, `{"v":"b"}`))
+	child, err := qp.BuildMap(basicnode.Prototype.Any, 2, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "n", qp.Int(1))
+		qp.MapEntry(ma, "grandchild", qp.Link(f.leafA))
+	})
+	qt.Assert(t, err, qt.IsNil)
+	f.child = storeJSON(child)
+	root, err := qp.BuildMap(basicnode.Prototype.Any, 3, func(ma datamodel.MapAssembler) {
+		qp.MapEntry(ma, "name", qp.String("root"))
+		qp.MapEntry(ma, "child", qp.Link(f.child))
+		qp.MapEntry(ma, "list", qp.List(2, func(la datamodel.ListAssembler) {
+			qp.ListEntry(la, qp.Link(f.leafA))
+			qp.ListEntry(la, qp.Link(f.leafB))
+		}))
+	})
+	qt.Assert(t, err, qt.IsNil)
+	f.root = storeJSON(root)
+	writeOpener := f.lsys.StorageWriteOpener
+	f.lsys.StorageWriteOpener = func(lnkCtx linking.LinkContext) (io.Writer, linking.BlockWriteCommitter, error) {
+		f.stores++
+		return writeOpener(lnkCtx)
+	}
+	return f
+}
+
+// flatten loads the whole graph under a link, replacing links with the blocks they point to, and encodes it as dag-json.
+func (f *linkedFixture) flatten(t *testing.T, lnk datamodel.Link) string {
+	t.Helper()
+	var expand func(n datamodel.Node) datamodel.Node
+	expand = func(n datamodel.Node) datamodel.Node {
+		switch n.Kind() {
+		case datamodel.Kind_Link:
+			lnk, _ := n.AsLink()
+			blk, err := f.lsys.Load(linking.LinkContext{}, lnk, basicnode.Prototype.Any)
+			qt.Assert(t, err, qt.IsNil)
+			return expand(blk)
+		case datamodel.Kind_Map:
+			nb := basicnode.Prototype.Map.NewBuilder()
+			ma, _ := nb.BeginMap(n.Length())
+			for itr := n.MapIterator(); !itr.Done(); {
+				k, v, _ := itr.Next()
+				qt.Assert(t, ma.AssembleKey().AssignNode(k), qt.IsNil)
+				qt.Assert(t, ma.AssembleValue().AssignNode(expand(v)), qt.IsNil)
+			}
+			qt.Assert(t, ma.Finish(), qt.IsNil)
+			return nb.Build()
+		case datamodel.Kind_List:
+			nb := basicnode.Prototype.List.NewBuilder()
+			la, _ := nb.BeginList(n.Length())
+			for itr := n.ListIterator(); !itr.Done(); {
+				_, v, _ := itr.Next()
+				qt.Assert(t, la.AssembleValue().AssignNode(expand(v)), qt.IsNil)
+			}
+			qt.Assert(t, la.Finish(), qt.IsNil)
+			return nb.Build()
+		default:
+			return n
+		}
+	}
+	return mustEncodeJSON(t, expand(basicnode.NewLink(lnk)))
+}
+
+func TestEvalLinked(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		ops     string
+		expect  string
+		written int
+	}{
+		{
+			"within the root block",
+			`[{"op":"replace","path":"/name","value":"new"}]`,
+			`{"child":{"grandchild":{"v":"a"},"n":1},"list":[{"v":"a"},{"v":"b"}],"name":"new"}`,
+			1,
+		},
+		{
+			"across two links",
+			`[{"op":"add","path":"/child/grandchild/w","value":"added"}]`,
+			`{"child":{"grandchild":{"v":"a","w":"added"},"n":1},"list":[{"v":"a"},{"v":"b"}],"name":"root"}`,
+			3,
+		},
+		{
+			"through a list, several ops on one block",
+			`[
+				{"op":"replace","path":"/list/1/v","value":"B"},
+				{"op":"add","path":"/list/1/x","value":1},
+				{"op":"test","path":"/list/1/v","value":"B"}
+			]`,
+			`{"child":{"grandchild":{"v":"a"},"n":1},"list":[{"v":"a"},{"v":"B","x":1}],"name":"root"}`,
+			2,
+		},
+		{
+			"replacing a link itself",
+			`[{"op":"remove","path":"/list/0"}]`,
+			`{"child":{"grandchild":{"v":"a"},"n":1},"list":[{"v":"b"}],"name":"root"}`,
+			1,
+		},
+		{
+			"move between blocks",
+			`[{"op":"move","path":"/child/moved","from":"/list/1/v"}]`,
+			`{"child":{"grandchild":{"v":"a"},"moved":"b","n":1},"list":[{"v":"a"},{}],"name":"root"}`,
+			3,
+		},
+		{
+			"copy a link",
+			`[{"op":"copy","path":"/child/copied","from":"/list/1"}]`,
+			`{"child":{"copied":{"v":"b"},"grandchild":{"v":"a"},"n":1},"list":[{"v":"a"},{"v":"b"}],"name":"root"}`,
+			2,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f := newLinkedFixture(t)
+			ops, err := ParseBytes([]byte(tc.ops), dagjson.Decode)
+			qt.Assert(t, err, qt.IsNil)
+			newRoot, written, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+			qt.Assert(t, err, qt.IsNil)
+			qt.Check(t, written, qt.HasLen, tc.written)
+			qt.Check(t, f.stores, qt.Equals, tc.written)
+			qt.Assert(t, written[len(written)-1], qt.Equals, newRoot)
+			qt.Check(t, f.flatten(t, newRoot), qt.Equals, tc.expect)
+		})
+	}
+}
+
+func TestEvalLinkedLoadsOnlyWhatItNeeds(t *testing.T) {
+	f := newLinkedFixture(t)
+	ops := []Operation{{Op: Op_Replace, Path: datamodel.ParsePath("list/1/v"), Value: basicnode.NewString("B")}}
+	_, _, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, f.loads, qt.DeepEquals, map[datamodel.Link]int{f.root: 1, f.leafB: 1})
+}
+
+func TestEvalLinkedNoChange(t *testing.T) {
+	f := newLinkedFixture(t)
+	ops := []Operation{{Op: Op_Test, Path: datamodel.ParsePath("child/grandchild/v"), Value: basicnode.NewString("a")}}
+	newRoot, written, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, newRoot, qt.Equals, f.root)
+	qt.Check(t, written, qt.HasLen, 0)
+	qt.Check(t, f.stores, qt.Equals, 0)
+}
+
+func TestEvalLinkedFailureStoresNothing(t *testing.T) {
+	f := newLinkedFixture(t)
+	ops := []Operation{
+		{Op: Op_Replace, Path: datamodel.ParsePath("child/n"), Value: basicnode.NewInt(2)},
+		{Op: Op_Test, Path: datamodel.ParsePath("child/grandchild/v"), Value: basicnode.NewString("nope")},
+	}
+	_, _, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+	qt.Check(t, err, qt.IsNotNil)
+	qt.Check(t, f.stores, qt.Equals, 0)
+}
+
+func TestEvalLinkedAppliesLinkedDiff(t *testing.T) {
+	f := newLinkedFixture(t)
+	ops := []Operation{
+		{Op: Op_Add, Path: datamodel.ParsePath("child/grandchild/w"), Value: basicnode.NewString("added")},
+		{Op: Op_Replace, Path: datamodel.ParsePath("list/1/v"), Value: basicnode.NewString("B")},
+	}
+	changed, _, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+	qt.Assert(t, err, qt.IsNil)
+
+	// Diffing the two graphs through the LinkSystem, and applying the result, gets back to the same root.
+	load := func(lnk datamodel.Link) datamodel.Node {
+		n, err := f.lsys.Load(linking.LinkContext{}, lnk, basicnode.Prototype.Any)
+		qt.Assert(t, err, qt.IsNil)
+		return n
+	}
+	diff, err := DiffOptions{LinkSystem: &f.lsys}.Diff(load(f.root), load(changed))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, opStrings(t, diff), qt.DeepEquals, []string{
+		`add child/grandchild/w "added"`,
+		`replace list/1/v "B"`,
+	})
+	again, _, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, diff)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, again, qt.Equals, changed)
+}
+
+// Bytes nodes built by basicnode's builder can't be compared with ==, so they're a good check that EvalLinked never tries to.
+func TestEvalLinkedBytes(t *testing.T) {
+	newBytes := func(b []byte) datamodel.Node {
+		nb := basicnode.Prototype.Bytes.NewBuilder()
+		qt.Assert(t, nb.AssignBytes(b), qt.IsNil)
+		return nb.Build()
+	}
+	t.Run("value", func(t *testing.T) {
+		f := newLinkedFixture(t)
+		ops := []Operation{{Op: Op_Replace, Path: datamodel.ParsePath("child/x"), Value: newBytes([]byte{1, 2, 3})}}
+		newRoot, written, err := EvalLinked(linking.LinkContext{}, &f.lsys, jsonLinkProto, f.root, ops)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, written, qt.HasLen, 2)
+		qt.Check(t, f.flatten(t, newRoot), qt.Equals, `{"child":{"grandchild":{"v":"a"},"n":1,"x":{"/":{"bytes":"AQID"}}},"list":[{"v":"a"},{"v":"b"}],"name":"root"}`)
+	})
+	t.Run("raw block", func(t *testing.T) {
+		f := newLinkedFixture(t)
+		rawLinkProto := cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x55, MhType: 0x12, MhLength: 32}}
+		raw, err := f.lsys.Store(linking.LinkContext{}, rawLinkProto, newBytes([]byte("raw")))
+		qt.Assert(t, err, qt.IsNil)
+		f.stores = 0
+
+		ops := []Operation{{Op: Op_Test, Path: datamodel.ParsePath(""), Value: newBytes([]byte("raw"))}}
+		newRoot, written, err := EvalLinked(linking.LinkContext{}, &f.lsys, rawLinkProto, raw, ops)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, newRoot, qt.Equals, raw)
+		qt.Check(t, written, qt.HasLen, 0)
+
+		ops = []Operation{{Op: Op_Replace, Path: datamodel.ParsePath(""), Value: newBytes([]byte("new"))}}
+		newRoot, written, err = EvalLinked(linking.LinkContext{}, &f.lsys, rawLinkProto, raw, ops)
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, written, qt.HasLen, 1)
+		blk, err := f.lsys.Load(linking.LinkContext{}, newRoot, basicnode.Prototype.Any)
+		qt.Assert(t, err, qt.IsNil)
+		b, err := blk.AsBytes()
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, string(b), qt.Equals, "new")
+	})
+}
@@ -9,19 +9,43 @@ import (
 
 // Condition provides a mechanism for matching and limiting matching and
 // exploration of selectors.
-// Not all types of conditions which are imagined in the selector specification
-// are encoded at present, instead we currently only implement a subset that
-// is sufficient for initial pressing use cases.
+//
+// Conditions are evaluated against a single node, without loading anything:
+// when a condition is checked against a node that's a link (as the stopAt
+// condition of ExploreRecursive is, for links it's about to follow), it sees
+// the link, not the node the link points to.
+// The modes which inspect the contents of a node (such as ConditionMode_HasField)
+// are therefore best used in Matcher conditions, or against data within a block.
 type Condition struct {
-	mode  ConditionMode
-	match datamodel.Node
+	mode     ConditionMode
+	match    datamodel.Node // the operand of Link, HasValue, and HasKind modes.
+	field    string         // the operand of HasField and Field modes.
+	children []Condition    // the operands of And, Or, Not, and Field modes.
 }
 
 // A ConditionMode is the keyed representation for the union that is the condition
 type ConditionMode string
 
 const (
+	// ConditionMode_Link matches a link equal to the given link.
 	ConditionMode_Link ConditionMode = "/"
+	// ConditionMode_HasValue matches a node equal to the given value, as by datamodel.DeepEqual.
+	ConditionMode_HasValue ConditionMode = "="
+	// ConditionMode_HasKind matches a node of the named kind, such as "map" or "string".
+	ConditionMode_HasKind ConditionMode = "%"
+	// ConditionMode_HasField matches a map which has an entry with the given key.
+	ConditionMode_HasField ConditionMode = "hasField"
+	// ConditionMode_Field matches a map which has an entry with the given key,
+	// whose value matches the given condition; it's a map of one key to a condition.
+	// This mode is an extension: it isn't part of the selector spec, so other implementations won't understand it.
+	ConditionMode_Field ConditionMode = "field"
+	// ConditionMode_And matches when every one of a list of conditions matches.
+	ConditionMode_And ConditionMode = "and"
+	// ConditionMode_Or matches when any one of a list of conditions matches.
+	ConditionMode_Or ConditionMode = "or"
+	// ConditionMode_Not matches when the given condition doesn't.
+	// Like ConditionMode_Field, this mode is an extension to the selector spec.
+	ConditionMode_Not ConditionMode = "not"
 )
 
 // Match decides if a given datamodel.Node matches the condition.
@@ -45,6 +69,42 @@ func (c *Condition) Match(n datamodel.Node) bool {
 			return cidmatch.Equals(cidlnk.Cid)
 		}
 		return match.String() == lnk.String()
+	case ConditionMode_HasValue:
+		return datamodel.DeepEqual(c.match, n)
+	case ConditionMode_HasKind:
+		kind, _ := c.match.AsString()
+		return n.Kind().String() == kind
+	case ConditionMode_HasField:
+		if n.Kind() != datamodel.Kind_Map {
+			return false
+		}
+		v, err := n.LookupByString(c.field)
+		return err == nil && !v.IsAbsent()
+	case ConditionMode_Field:
+		if n.Kind() != datamodel.Kind_Map {
+			return false
+		}
+		v, err := n.LookupByString(c.field)
+		if err != nil || v.IsAbsent() {
+			return false
+		}
+		return c.children[0].Match(v)
+	case ConditionMode_And:
+		for i := range c.children {
+			if !c.children[i].Match(n) {
+				return false
+			}
+		}
+		return true
+	case ConditionMode_Or:
+		for i := range c.children {
+			if c.children[i].Match(n) {
+				return true
+			}
+		}
+		return false
+	case ConditionMode_Not:
+		return !c.children[0].Match(n)
 	default:
 		return false
 	}
@@ -68,6 +128,59 @@ func (pc ParseContext) ParseCondition(n datamodel.Node) (Condition, error) {
 			return Condition{}, fmt.Errorf("selector spec parse rejected: condition_link must be a link")
 		}
 		return Condition{mode: ConditionMode_Link, match: v}, nil
+	case ConditionMode_HasValue:
+		return Condition{mode: ConditionMode_HasValue, match: v}, nil
+	case ConditionMode_HasKind:
+		kind, err := v.AsString()
+		if err != nil {
+			return Condition{}, fmt.Errorf("selector spec parse rejected: condition_hasKind must be a string")
+		}
+		switch kind {
+		case "map", "list", "null", "bool", "int", "float", "string", "bytes", "link":
+		default:
+			return Condition{}, fmt.Errorf("selector spec parse rejected: condition_hasKind: %q is not a known kind", kind)
+		}
+		return Condition{mode: ConditionMode_HasKind, match: v}, nil
+	case ConditionMode_HasField:
+		field, err := v.AsString()
+		if err != nil {
+			return Condition{}, fmt.Errorf("selector spec parse rejected: condition_hasField must be a string")
+		}
+		return Condition{mode: ConditionMode_HasField, field: field}, nil
+	case ConditionMode_Field:
+		if v.Kind() != datamodel.Kind_Map || v.Length() != 1 {
+			return Condition{}, fmt.Errorf("selector spec parse rejected: condition_field must be a single-entry map")
+		}
+		fn, fv, _ := v.MapIterator().Next()
+		field, _ := fn.AsString()
+		child, err := pc.ParseCondition(fv)
+		if err != nil {
+			return Condition{}, err
+		}
+		return Condition{mode: ConditionMode_Field, field: field, children: []Condition{child}}, nil
+	case ConditionMode_And, ConditionMode_Or:
+		if v.Kind() != datamodel.Kind_List || v.Length() == 0 {
+			return Condition{}, fmt.Errorf("selector spec parse rejected: condition_%s must be a non-empty list", kstr)
+		}
+		children := make([]Condition, 0, v.Length())
+		for itr := v.ListIterator(); !itr.Done(); {
+			_, cv, err := itr.Next()
+			if err != nil {
+				return Condition{}, err
+			}
+			child, err := pc.ParseCondition(cv)
+			if err != nil {
+				return Condition{}, err
+			}
+			children = append(children, child)
+		}
+		return Condition{mode: ConditionMode(kstr), children: children}, nil
+	case ConditionMode_Not:
+		child, err := pc.ParseCondition(v)
+		if err != nil {
+			return Condition{}, err
+		}
+		return Condition{mode: ConditionMode_Not, children: []Condition{child}}, nil
 	default:
 		return Condition{}, fmt.Errorf("selector spec parse rejected: %q is not a known member of the condition union", kstr)
 	}
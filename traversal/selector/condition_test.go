@@ -2,12 +2,15 @@ package selector
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
 	"github.com/google/go-cmp/cmp"
 	"github.com/ipfs/go-cid"
 
+	"github.com/ipld/go-ipld-prime/codec/dagjson"
+	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/fluent"
 	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
@@ -45,3 +48,69 @@ func TestParseCondition(t *testing.T) {
 		qt.Check(t, s, deepEqualsAllowAllUnexported, Condition{mode: ConditionMode_Link, match: lnkNode})
 	})
 }
+
+func mustDecodeJSON(t *testing.T, s string) datamodel.Node {
+	t.Helper()
+	nb := basicnode.Prototype.Any.NewBuilder()
+	qt.Assert(t, dagjson.Decode(nb, strings.NewReader(s)), qt.IsNil)
+	return nb.Build()
+}
+
+func TestParseConditionRejects(t *testing.T) {
+	for _, tc := range []struct {
+		condition string
+		err       string
+	}{
+		{`{"%":"struct"}`, `selector spec parse rejected: condition_hasKind: "struct" is not a known kind`},
+		{`{"%":1}`, `selector spec parse rejected: condition_hasKind must be a string`},
+		{`{"hasField":[]}`, `selector spec parse rejected: condition_hasField must be a string`},
+		{`{"field":{"a":{"=":1},"b":{"=":2}}}`, `selector spec parse rejected: condition_field must be a single-entry map`},
+		{`{"and":[]}`, `selector spec parse rejected: condition_and must be a non-empty list`},
+		{`{"or":{"=":1}}`, `selector spec parse rejected: condition_or must be a non-empty list`},
+		{`{"not":{"nope":1}}`, `selector spec parse rejected: "nope" is not a known member of the condition union`},
+		{`{"and":[{"=":1},{"%":"thing"}]}`, `selector spec parse rejected: condition_hasKind: "thing" is not a known kind`},
+	} {
+		t.Run(tc.condition, func(t *testing.T) {
+			_, err := ParseContext{}.ParseCondition(mustDecodeJSON(t, tc.condition))
+			qt.Check(t, err, qt.ErrorMatches, tc.err)
+		})
+	}
+}
+
+func TestConditionMatch(t *testing.T) {
+	node := mustDecodeJSON(t, `{"name":"alpha","size":3,"tags":["x","y"],"empty":null}`)
+	for _, tc := range []struct {
+		condition string
+		expected  bool
+	}{
+		{`{"=":{"name":"alpha","size":3,"tags":["x","y"],"empty":null}}`, true},
+		{`{"=":{"name":"alpha"}}`, false},
+		{`{"%":"map"}`, true},
+		{`{"%":"list"}`, false},
+		{`{"hasField":"name"}`, true},
+		{`{"hasField":"empty"}`, true},
+		{`{"hasField":"missing"}`, false},
+		{`{"field":{"name":{"=":"alpha"}}}`, true},
+		{`{"field":{"name":{"=":"beta"}}}`, false},
+		{`{"field":{"tags":{"%":"list"}}}`, true},
+		{`{"field":{"missing":{"not":{"=":1}}}}`, false},
+		{`{"and":[{"hasField":"name"},{"field":{"size":{"=":3}}}]}`, true},
+		{`{"and":[{"hasField":"name"},{"field":{"size":{"=":4}}}]}`, false},
+		{`{"or":[{"hasField":"missing"},{"field":{"size":{"=":3}}}]}`, true},
+		{`{"or":[{"hasField":"missing"},{"%":"string"}]}`, false},
+		{`{"not":{"hasField":"missing"}}`, true},
+		{`{"not":{"%":"map"}}`, false},
+	} {
+		t.Run(tc.condition, func(t *testing.T) {
+			c, err := ParseContext{}.ParseCondition(mustDecodeJSON(t, tc.condition))
+			qt.Assert(t, err, qt.IsNil)
+			qt.Check(t, c.Match(node), qt.Equals, tc.expected)
+		})
+	}
+
+	t.Run("non-map nodes never have fields", func(t *testing.T) {
+		c, err := ParseContext{}.ParseCondition(mustDecodeJSON(t, `{"hasField":"0"}`))
+		qt.Assert(t, err, qt.IsNil)
+		qt.Check(t, c.Match(mustDecodeJSON(t, `["a"]`)), qt.IsFalse)
+	})
+}
@@ -18,9 +18,13 @@ import (
 //
 // A selector tree with only "explore*"-type selectors and no Matcher selectors
 // is valid; it will just generate a "covered" set of nodes and no "result" set.
-// TODO: From spec: implement conditions and labels
+//
+// A matcher parsed with an onlyIf condition only matches nodes which satisfy it;
+// the others are still covered, but not included in the result set.
+// TODO: From spec: implement labels
 type Matcher struct {
 	*Slice
+	onlyIf *Condition
 }
 
 // Slice limits a result node to a subset of the node.
@@ -128,14 +132,18 @@ func (s Matcher) Explore(n datamodel.Node, p datamodel.PathSegment) (Selector, e
 	return nil, nil
 }
 
-// Decide is always true for a match cause it's in the result set
+// Decide is true for a match unless an onlyIf condition rules it out
 // Deprecated: use Match instead
 func (s Matcher) Decide(n datamodel.Node) bool {
-	return true
+	return s.onlyIf == nil || s.onlyIf.Match(n)
 }
 
-// Match is always true for a match cause it's in the result set
+// Match returns the node (or the sliced part of it, if a Slice is set),
+// unless there's an onlyIf condition which the node doesn't satisfy.
 func (s Matcher) Match(node datamodel.Node) (datamodel.Node, error) {
+	if s.onlyIf != nil && !s.onlyIf.Match(node) {
+		return nil, nil
+	}
 	if s.Slice != nil {
 		return s.Slice.Slice(node)
 	}
@@ -144,18 +152,27 @@ func (s Matcher) Match(node datamodel.Node) (datamodel.Node, error) {
 
 // ParseMatcher assembles a Selector
 // from a matcher selector node
-// TODO: Parse labels and conditions
+// TODO: Parse labels
 func (pc ParseContext) ParseMatcher(n datamodel.Node) (Selector, error) {
 	if n.Kind() != datamodel.Kind_Map {
 		return nil, fmt.Errorf("selector spec parse rejected: selector body must be a map")
 	}
 
+	var matcher Matcher
+	// check if a condition is specified
+	if onlyIf, err := n.LookupByString(SelectorKey_Condition); err == nil {
+		condition, err := pc.ParseCondition(onlyIf)
+		if err != nil {
+			return nil, err
+		}
+		matcher.onlyIf = &condition
+	}
 	// check if a slice is specified
-	if subset, err := n.LookupByString("subset"); err == nil {
+	if subset, err := n.LookupByString(SelectorKey_Subset); err == nil {
 		if subset.Kind() != datamodel.Kind_Map {
 			return nil, fmt.Errorf("selector spec parse rejected: subset body must be a map")
 		}
-		from, err := subset.LookupByString("[")
+		from, err := subset.LookupByString(SelectorKey_From)
 		if err != nil {
 			return nil, fmt.Errorf("selector spec parse rejected: selector body must be a map with a from '[' key")
 		}
@@ -163,7 +180,7 @@ func (pc ParseContext) ParseMatcher(n datamodel.Node) (Selector, error) {
 		if err != nil {
 			return nil, fmt.Errorf("selector spec parse rejected: selector body must be a map with a 'from' key that is a number")
 		}
-		to, err := subset.LookupByString("]")
+		to, err := subset.LookupByString(SelectorKey_To)
 		if err != nil {
 			return nil, fmt.Errorf("selector spec parse rejected: selector body must be a map with a to ']' key")
 		}
@@ -174,10 +191,10 @@ func (pc ParseContext) ParseMatcher(n datamodel.Node) (Selector, error) {
 		if toN >= 0 && fromN > toN {
 			return nil, fmt.Errorf("selector spec parse rejected: selector body must be a map with a 'from' key that is less than or equal to the 'to' key")
 		}
-		return Matcher{&Slice{
+		matcher.Slice = &Slice{
 			From: fromN,
 			To:   toN,
-		}}, nil
+		}
 	}
-	return Matcher{}, nil
+	return matcher, nil
 }
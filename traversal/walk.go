@@ -7,6 +7,7 @@ import (
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/linking"
 	"github.com/ipld/go-ipld-prime/linking/preload"
+	"github.com/ipld/go-ipld-prime/schema"
 	"github.com/ipld/go-ipld-prime/traversal/selector"
 )
 
@@ -96,6 +97,10 @@ func WalkTransforming(n datamodel.Node, s selector.Selector, fn TransformFn) (da
 // potentially asynchronously preload any blocks that are going to be encountered at a future point in the walk.
 func (prog Progress) WalkMatching(n datamodel.Node, s selector.Selector, fn VisitFn) error {
 	prog.init()
+	if err := prog.startLinkLoader(); err != nil {
+		return err
+	}
+	defer prog.stopLinkLoader()
 	return prog.walkBlock(n, s, func(prog Progress, n datamodel.Node, tr VisitReason) error {
 		if tr != VisitReason_SelectionMatch {
 			return nil
@@ -157,9 +162,34 @@ func (prog Progress) WalkLocal(n datamodel.Node, fn VisitFn) error {
 // An AdvVisitFn is used instead of a VisitFn, so that the reason can be provided.
 func (prog Progress) WalkAdv(n datamodel.Node, s selector.Selector, fn AdvVisitFn) error {
 	prog.init()
+	if err := prog.startLinkLoader(); err != nil {
+		return err
+	}
+	defer prog.stopLinkLoader()
 	return prog.walkBlock(n, s, fn)
 }
 
+// startLinkLoader starts a pool of goroutines for loading links, if the config asks for concurrency.
+// stopLinkLoader must be called when the walk is done.
+func (prog *Progress) startLinkLoader() error {
+	prog.loader = nil
+	prog.walkPos = nil
+	if prog.Cfg.LinkLoadConcurrency <= 1 {
+		return nil
+	}
+	if prog.Cfg.LinkLoadUnordered && prog.Cfg.StartAtPath.Len() > 0 {
+		return fmt.Errorf("traversal: LinkLoadUnordered cannot be used together with StartAtPath")
+	}
+	prog.loader = newLinkLoader(prog.Cfg.LinkLoadConcurrency)
+	return nil
+}
+
+func (prog Progress) stopLinkLoader() {
+	if prog.loader != nil {
+		prog.loader.close()
+	}
+}
+
 // walkBlock anchors a walk at the beginning of the traversal and at the
 // beginning of each new link traversed. This allows us to do a preload phase if
 // we have a preloader configured.
@@ -194,6 +224,7 @@ func (prog Progress) walkBlock(n datamodel.Node, s selector.Selector, visitFn Ad
 // walkAdv is the main recursive walk function, called to iterate through
 // recursive nodes (root node, maps, lists and new link root nodes).
 func (prog Progress) walkAdv(ph phase, n datamodel.Node, s selector.Selector, visitFn AdvVisitFn) error {
+	prog.applyResumedBudget()
 	if err := prog.checkNodeBudget(); err != nil {
 		return err
 	}
@@ -222,7 +253,7 @@ func (prog Progress) walkAdv(ph phase, n datamodel.Node, s selector.Selector, vi
 
 	haveStartAtPath := prog.Cfg.StartAtPath.Len() > 0
 	var reachedStartAtPath bool
-	recurse := func(v datamodel.Node, ps datamodel.PathSegment) error {
+	recurse := func(v datamodel.Node, ps datamodel.PathSegment, idx int) error {
 		// First, make sure we're past the start path; if one is specified.
 		if haveStartAtPath {
 			if reachedStartAtPath {
@@ -237,7 +268,7 @@ func (prog Progress) walkAdv(ph phase, n datamodel.Node, s selector.Selector, vi
 			}
 		}
 
-		if err := prog.explore(ph, s, n, visitFn, v, ps); err != nil {
+		if err := prog.explore(ph, s, n, visitFn, v, ps, idx); err != nil {
 			return err
 		}
 
@@ -246,13 +277,17 @@ func (prog Progress) walkAdv(ph phase, n datamodel.Node, s selector.Selector, vi
 
 	attn := s.Interests()
 
+	if prog.loader != nil && ph == phaseTraverse && (attn == nil || len(attn) > 0) {
+		return prog.walkChildrenConcurrently(n, s, attn, recurse)
+	}
+
 	if attn == nil { // no specific interests; recurse on all children.
-		for itr := selector.NewSegmentIterator(n); !itr.Done(); {
+		for idx, itr := 0, selector.NewSegmentIterator(n); !itr.Done(); idx++ {
 			ps, v, err := itr.Next()
 			if err != nil {
 				return err
 			}
-			if err := recurse(v, ps); err != nil {
+			if err := recurse(v, ps, idx); err != nil {
 				return err
 			}
 		}
@@ -265,10 +300,10 @@ func (prog Progress) walkAdv(ph phase, n datamodel.Node, s selector.Selector, vi
 	}
 
 	// specific interests, recurse on those.
-	for _, ps := range attn {
+	for idx, ps := range attn {
 		if v, err := n.LookupBySegment(ps); err != nil {
 			continue
-		} else if err := recurse(v, ps); err != nil {
+		} else if err := recurse(v, ps, idx); err != nil {
 			return err
 		}
 	}
@@ -276,6 +311,63 @@ func (prog Progress) walkAdv(ph phase, n datamodel.Node, s selector.Selector, vi
 	return nil
 }
 
+// walkChildrenConcurrently is the equivalent of the loops over children at the end of walkAdv, for walks with a linkLoader:
+// it gathers up the children to recurse on first, so that loads of the links among them can be queued,
+// before recursing on them.
+func (prog Progress) walkChildrenConcurrently(
+	n datamodel.Node,
+	s selector.Selector,
+	attn []datamodel.PathSegment,
+	recurse func(v datamodel.Node, ps datamodel.PathSegment, idx int) error,
+) error {
+	var children []child
+	if attn == nil {
+		for itr := selector.NewSegmentIterator(n); !itr.Done(); {
+			ps, v, err := itr.Next()
+			if err != nil {
+				return err
+			}
+			children = append(children, child{ps, v})
+		}
+	} else {
+		for _, ps := range attn {
+			if v, err := n.LookupBySegment(ps); err == nil {
+				children = append(children, child{ps, v})
+			}
+		}
+	}
+
+	batch := prog.loader.schedule(prog, s, n, children)
+	defer batch.release()
+
+	if !prog.Cfg.LinkLoadUnordered {
+		for idx, c := range children {
+			if err := recurse(c.v, c.ps, idx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for idx, c := range children {
+		if batch.has(idx) {
+			continue
+		}
+		if err := recurse(c.v, c.ps, idx); err != nil {
+			return err
+		}
+	}
+	for {
+		idx, ok := batch.next()
+		if !ok {
+			return nil
+		}
+		if err := recurse(children[idx].v, children[idx].ps, idx); err != nil {
+			return err
+		}
+	}
+}
+
 func (prog Progress) checkNodeBudget() error {
 	if prog.Budget != nil {
 		if prog.Budget.NodeBudget <= 0 {
@@ -328,6 +420,39 @@ func (prog Progress) reify(n datamodel.Node, s selector.Selector) (datamodel.Nod
 	return nil, nil, nil
 }
 
+// reifyAdvanced reifies a node loaded from a link, if the link is a typed node whose type points to
+// a type with an advanced representation, such as the link type `&Foo` given `type Foo {String:Int} representation advanced HAMT`.
+// The NodeReifier used is the one registered for the ADL's name in the LinkSystem's KnownReifiers.
+// Nodes loaded from other links are returned as they are.
+func (prog Progress) reifyAdvanced(lnkCtx linking.LinkContext, n datamodel.Node) (datamodel.Node, error) {
+	tlnk, ok := lnkCtx.LinkNode.(schema.TypedNode)
+	if !ok {
+		return n, nil
+	}
+	lnkType, ok := tlnk.Type().(*schema.TypeLink)
+	if !ok || !lnkType.HasReferencedType() {
+		return n, nil
+	}
+	target := lnkType.ReferencedType()
+	adl := schema.AdvancedLayoutOf(target)
+	if adl == "" {
+		return n, nil
+	}
+	reifier, ok := prog.Cfg.LinkSystem.KnownReifiers[adl]
+	if !ok {
+		return nil, fmt.Errorf("unregistered adl %q, needed for type %s", adl, target.Name())
+	}
+	// The reifier wants the substrate, which is the representation if the node was loaded as a typed node.
+	if tn, ok := n.(schema.TypedNode); ok {
+		n = tn.Representation()
+	}
+	rn, err := reifier(lnkCtx, n, &prog.Cfg.LinkSystem)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reify node as %q: %w", adl, err)
+	}
+	return rn, nil
+}
+
 // visit calls the visitor if required
 func (prog Progress) visit(ph phase, n datamodel.Node, s selector.Selector, visitFn AdvVisitFn) error {
 	if ph != phaseTraverse {
@@ -358,6 +483,7 @@ func (prog Progress) explore(
 	visitFn AdvVisitFn,
 	v datamodel.Node,
 	ps datamodel.PathSegment,
+	idx int,
 ) error {
 	sNext, err := s.Explore(n, ps)
 	if err != nil {
@@ -369,6 +495,10 @@ func (prog Progress) explore(
 
 	progNext := prog
 	progNext.Path = prog.Path.AppendSegment(ps)
+	if prog.loader != nil {
+		progNext.walkPos = appendWalkPos(prog.walkPos, idx)
+	}
+	progNext.applyResumedBudget()
 
 	if v.Kind() != datamodel.Kind_Link {
 		return progNext.walkAdv(ph, v, sNext, visitFn)
@@ -376,7 +506,8 @@ func (prog Progress) explore(
 
 	lnk, _ := v.AsLink()
 	if prog.Cfg.LinkVisitOnlyOnce {
-		if _, seen := prog.SeenLinks[lnk]; seen {
+		// Links on the way to StartAtPath are followed regardless, so that a walk resumed from a Checkpoint can get back to where it was.
+		if _, seen := prog.SeenLinks[lnk]; seen && !progNext.onWayToStartAtPath() {
 			return nil
 		}
 		if ph == phaseTraverse {
@@ -422,6 +553,17 @@ func (prog Progress) loadLink(lnk datamodel.Link, v datamodel.Node, parent datam
 	if err := prog.checkLinkBudget(lnk); err != nil {
 		return nil, err
 	}
+	if prog.loader != nil {
+		if pl, ok := prog.loader.claim(prog.Path); ok {
+			return pl.n, pl.err
+		}
+	}
+	return prog.loadLinkNow(lnk, v, parent)
+}
+
+// loadLinkNow does the work of loadLink, other than accounting for the link budget.
+// It may be called from a linkLoader's goroutines.
+func (prog Progress) loadLinkNow(lnk datamodel.Link, v datamodel.Node, parent datamodel.Node) (datamodel.Node, error) {
 	// Put together the context info we'll offer to the loader and prototypeChooser.
 	lnkCtx := linking.LinkContext{
 		Ctx:        prog.Cfg.Ctx,
@@ -442,6 +584,9 @@ func (prog Progress) loadLink(lnk datamodel.Link, v datamodel.Node, parent datam
 		}
 		return nil, fmt.Errorf("error traversing node at %q: could not load link %q: %w", prog.Path, lnk, err)
 	}
+	if n, err = prog.reifyAdvanced(lnkCtx, n); err != nil {
+		return nil, fmt.Errorf("error traversing node at %q: could not load link %q: %w", prog.Path, lnk, err)
+	}
 	return n, nil
 }
 
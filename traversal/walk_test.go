@@ -6,17 +6,22 @@ import (
 	"testing"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/ipfs/go-cid"
 
 	"github.com/ipld/go-ipld-prime"
+	"github.com/ipld/go-ipld-prime/adl/hamt"
 	_ "github.com/ipld/go-ipld-prime/codec/dagjson"
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/fluent/qp"
 	"github.com/ipld/go-ipld-prime/linking"
 	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
 	"github.com/ipld/go-ipld-prime/linking/preload"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
+	"github.com/ipld/go-ipld-prime/node/bindnode"
 	nodetests "github.com/ipld/go-ipld-prime/node/tests"
 	"github.com/ipld/go-ipld-prime/storage"
+	"github.com/ipld/go-ipld-prime/storage/memstore"
 	"github.com/ipld/go-ipld-prime/traversal"
 	"github.com/ipld/go-ipld-prime/traversal/selector"
 	"github.com/ipld/go-ipld-prime/traversal/selector/builder"
@@ -805,3 +810,75 @@ func TestWalkTransforming(t *testing.T) {
 		}))
 	})
 }
+
+func TestWalkAdvancedLayout(t *testing.T) {
+	ts, err := ipld.LoadSchemaBytes([]byte(`
+		advanced HAMT
+		type Big {String:Int} representation advanced HAMT
+		type Root struct {
+			name String
+			big &Big
+		}
+	`))
+	qt.Assert(t, err, qt.IsNil)
+	type Root struct {
+		Name string
+		Big  datamodel.Link
+	}
+
+	var adlStore memstore.Store
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.SetReadStorage(&adlStore)
+	lsys.SetWriteStorage(&adlStore)
+	lp := cidlink.LinkPrototype{Prefix: cid.Prefix{Version: 1, Codec: 0x0129, MhType: 0x12, MhLength: 32}}
+
+	// Narrow shards, so that the map is spread over several blocks.
+	big, err := qp.BuildMap(hamt.NodePrototype{LinkSystem: &lsys, LinkPrototype: lp, BitWidth: 3, BucketSize: 2}, 50, func(ma datamodel.MapAssembler) {
+		for i := 0; i < 50; i++ {
+			qp.MapEntry(ma, fmt.Sprintf("key-%d", i), qp.Int(int64(i)))
+		}
+	})
+	qt.Assert(t, err, qt.IsNil)
+	bigLnk, err := lsys.Store(linking.LinkContext{}, lp, big.(*hamt.Node).Substrate())
+	qt.Assert(t, err, qt.IsNil)
+	rootLnk, err := lsys.Store(linking.LinkContext{}, lp, bindnode.Wrap(&Root{Name: "root", Big: bigLnk}, ts.TypeByName("Root")).Representation())
+	qt.Assert(t, err, qt.IsNil)
+
+	root, err := lsys.Load(linking.LinkContext{}, rootLnk, bindnode.Prototype((*Root)(nil), ts.TypeByName("Root")))
+	qt.Assert(t, err, qt.IsNil)
+	sel, err := selectorparse.ParseAndCompileJSONSelector(`{"f":{"f>":{"big":{"f":{"f>":{"key-42":{".":{}}}}}}}}`)
+	qt.Assert(t, err, qt.IsNil)
+	walk := func(lsys linking.LinkSystem) (visited []string, err error) {
+		err = traversal.Progress{
+			Cfg: &traversal.Config{
+				LinkSystem:                     lsys,
+				LinkTargetNodePrototypeChooser: basicnode.Chooser,
+			},
+		}.WalkMatching(root, sel, func(prog traversal.Progress, n datamodel.Node) error {
+			v, err := n.AsInt()
+			visited = append(visited, fmt.Sprintf("%s=%d", prog.Path, v))
+			return err
+		})
+		return visited, err
+	}
+
+	// Without a reifier for the ADL, the link to the map can't be followed.
+	_, err = walk(lsys)
+	qt.Assert(t, err, qt.ErrorMatches, `.*unregistered adl "HAMT", needed for type Big`)
+
+	// With one, the walk sees the map, rather than the HAMT's root shard.
+	lsys.KnownReifiers = map[string]linking.NodeReifier{"HAMT": hamt.Reify}
+	visited, err := walk(lsys)
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, visited, qt.DeepEquals, []string{"big/key-42=42"})
+
+	// The same goes for getting a path.
+	n, err := traversal.Progress{
+		Cfg: &traversal.Config{
+			LinkSystem:                     lsys,
+			LinkTargetNodePrototypeChooser: basicnode.Chooser,
+		},
+	}.Get(root, datamodel.ParsePath("big/key-7"))
+	qt.Assert(t, err, qt.IsNil)
+	qt.Check(t, n, nodetests.NodeContentEquals, basicnode.NewInt(7))
+}
@@ -2,6 +2,7 @@ package traversal_test
 
 import (
 	"fmt"
+	"io"
 	"testing"
 
 	qt "github.com/frankban/quicktest"
@@ -9,12 +10,14 @@ import (
 	_ "github.com/ipld/go-ipld-prime/codec/dagjson"
 	"github.com/ipld/go-ipld-prime/datamodel"
 	"github.com/ipld/go-ipld-prime/fluent"
+	"github.com/ipld/go-ipld-prime/linking"
 	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
 	"github.com/ipld/go-ipld-prime/node/basicnode"
 	nodetests "github.com/ipld/go-ipld-prime/node/tests"
 	"github.com/ipld/go-ipld-prime/traversal"
 	"github.com/ipld/go-ipld-prime/traversal/selector"
 	"github.com/ipld/go-ipld-prime/traversal/selector/builder"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
 )
 
 /* Remember, we've got the following fixtures in scope:
@@ -237,3 +240,53 @@ func stopAtInChainTest(t *testing.T, chainNode datamodel.Node, stopLnk datamodel
 		qt.Check(t, order, qt.Equals, len(expectedPaths))
 	})
 }
+
+func TestStopAtCondition(t *testing.T) {
+	// Recurse through everything, matching only strings, and not descending into any map whose
+	// "nonlink" field is "zoo" -- which is the "nested" map in middleMapNode, so its link is never loaded.
+	s, err := selectorparse.ParseAndCompileJSONSelector(`{"R":{
+		"l": {"none": {}},
+		":>": {"|": [
+			{".": {"&": {"%": "string"}}},
+			{"a": {">": {"@": {}}}}
+		]},
+		"!": {"field": {"nonlink": {"=": "zoo"}}}
+	}}`)
+	qt.Assert(t, err, qt.IsNil)
+
+	var loaded []string
+	lsys := cidlink.DefaultLinkSystem()
+	lsys.StorageReadOpener = func(lnkCtx linking.LinkContext, lnk datamodel.Link) (io.Reader, error) {
+		loaded = append(loaded, lnkCtx.LinkPath.String())
+		return store.GetStream(lnkCtx.Ctx, lnk.Binary())
+	}
+	var matched []string
+	err = traversal.Progress{
+		Cfg: &traversal.Config{
+			LinkSystem:                     lsys,
+			LinkTargetNodePrototypeChooser: basicnode.Chooser,
+		},
+	}.WalkMatching(rootNode, s, func(prog traversal.Progress, n datamodel.Node) error {
+		qt.Check(t, n.Kind(), qt.Equals, datamodel.Kind_String)
+		matched = append(matched, prog.Path.String())
+		return nil
+	})
+	qt.Check(t, err, qt.IsNil)
+	qt.Check(t, matched, qt.DeepEquals, []string{
+		"plain",
+		"linkedString",
+		"linkedList/0",
+		"linkedList/1",
+		"linkedList/2",
+		"linkedList/3",
+	})
+	qt.Check(t, loaded, qt.DeepEquals, []string{
+		"linkedString",
+		"linkedMap",
+		"linkedList",
+		"linkedList/0",
+		"linkedList/1",
+		"linkedList/2",
+		"linkedList/3",
+	})
+}